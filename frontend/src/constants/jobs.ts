@@ -32,7 +32,8 @@ export type ExecutionStatus =
   | "validated"
   | "publishing"
   | "published"
-  | "failed";
+  | "failed"
+  | "paused_budget";
 
 export const ExecutionStatusConst = {
   Pending: "pending" as ExecutionStatus,
@@ -42,4 +43,5 @@ export const ExecutionStatusConst = {
   Publishing: "publishing" as ExecutionStatus,
   Published: "published" as ExecutionStatus,
   Failed: "failed" as ExecutionStatus,
+  PausedBudget: "paused_budget" as ExecutionStatus,
 } as const;
@@ -1,6 +1,6 @@
 import { dto } from "@/wailsjs/wailsjs/go/models";
 
-export type PromptCategory = "post_gen" | "page_gen" | "link_suggest" | "link_apply" | "sitemap_gen";
+export type PromptCategory = "post_gen" | "page_gen" | "link_suggest" | "link_apply" | "sitemap_gen" | "article_rewrite";
 
 export const PROMPT_CATEGORIES: Record<PromptCategory, string> = {
     post_gen: "Post Generation",
@@ -8,6 +8,7 @@ export const PROMPT_CATEGORIES: Record<PromptCategory, string> = {
     link_suggest: "Link Suggestions",
     link_apply: "Link Insertion",
     sitemap_gen: "Sitemap Structure",
+    article_rewrite: "Article Rewrite",
 };
 
 // Context field types for v2 prompts
@@ -14,6 +14,7 @@ require (
 	github.com/gosimple/slug v1.15.0
 	github.com/invopop/jsonschema v0.13.0
 	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0
+	github.com/ledongthuc/pdf v0.0.0-20250511090121-5959a4027728
 	github.com/ncruces/go-sqlite3 v0.28.0
 	github.com/openai/openai-go/v3 v3.7.0
 	github.com/pressly/goose/v3 v3.26.0
@@ -24,6 +25,7 @@ require (
 	golang.org/x/net v0.42.0
 	golang.org/x/time v0.8.0
 	google.golang.org/api v0.189.0
+	google.golang.org/grpc v1.64.1
 )
 
 require (
@@ -113,7 +115,6 @@ require (
 	golang.org/x/text v0.28.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20240617180043-68d350f18fd4 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240722135656-d784300faade // indirect
-	google.golang.org/grpc v1.64.1 // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
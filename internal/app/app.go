@@ -24,25 +24,30 @@ type App struct {
 
 func New(cfg *config.Config) (*App, error) {
 	var (
-		articlesHandler    *handlers.ArticlesHandler
-		categoriesHandler  *handlers.CategoriesHandler
-		jobsHandler        *handlers.JobsHandler
-		promptsHandler     *handlers.PromptsHandler
-		providersHandler   *handlers.ProvidersHandler
-		sitesHandler       *handlers.SitesHandler
-		healthCheckHandler *handlers.HealthCheckHandler
-		statsHandler       *handlers.StatsHandler
-		topicsHandler      *handlers.TopicsHandler
-		importerHandler    *handlers.ImporterHandler
-		settingsHandler    *handlers.SettingsHandler
-		proxyHandler       *handlers.ProxyHandler
-		mediaHandler       *handlers.MediaHandler
-		dialogsHandler     *handlers.DialogsHandler
-		appHandler         *handlers.AppHandler
-		sitemapsHandler    *handlers.SitemapsHandler
-		aiUsageHandler     *handlers.AIUsageHandler
-		linkingHandler     *handlers.LinkingHandler
-		eventsBridge       *events.WailsBridge
+		articlesHandler       *handlers.ArticlesHandler
+		categoriesHandler     *handlers.CategoriesHandler
+		jobsHandler           *handlers.JobsHandler
+		promptsHandler        *handlers.PromptsHandler
+		providersHandler      *handlers.ProvidersHandler
+		sitesHandler          *handlers.SitesHandler
+		healthCheckHandler    *handlers.HealthCheckHandler
+		statsHandler          *handlers.StatsHandler
+		topicsHandler         *handlers.TopicsHandler
+		importerHandler       *handlers.ImporterHandler
+		settingsHandler       *handlers.SettingsHandler
+		proxyHandler          *handlers.ProxyHandler
+		mediaHandler          *handlers.MediaHandler
+		dialogsHandler        *handlers.DialogsHandler
+		appHandler            *handlers.AppHandler
+		sitemapsHandler       *handlers.SitemapsHandler
+		aiUsageHandler        *handlers.AIUsageHandler
+		linkingHandler        *handlers.LinkingHandler
+		budgetsHandler        *handlers.BudgetsHandler
+		embeddingsHandler     *handlers.EmbeddingsHandler
+		batchesHandler        *handlers.BatchesHandler
+		knowledgeHandler      *handlers.KnowledgeHandler
+		providerHealthHandler *handlers.ProviderHealthHandler
+		eventsBridge          *events.WailsBridge
 	)
 
 	fxApp := fx.New(
@@ -71,6 +76,11 @@ func New(cfg *config.Config) (*App, error) {
 			&sitemapsHandler,
 			&aiUsageHandler,
 			&linkingHandler,
+			&budgetsHandler,
+			&embeddingsHandler,
+			&batchesHandler,
+			&knowledgeHandler,
+			&providerHealthHandler,
 			&eventsBridge,
 		),
 	)
@@ -96,6 +106,11 @@ func New(cfg *config.Config) (*App, error) {
 			sitemapsHandler,
 			aiUsageHandler,
 			linkingHandler,
+			budgetsHandler,
+			embeddingsHandler,
+			batchesHandler,
+			knowledgeHandler,
+			providerHealthHandler,
 		},
 		dialogsHandler: dialogsHandler,
 		appHandler:     appHandler,
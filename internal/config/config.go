@@ -17,6 +17,9 @@ type Config struct {
 	PrettyPrint  bool   `json:"prettyPrint"`
 	AppLogFile   string `json:"appLogFile"`
 	ErrLogFile   string `json:"errLogFile"`
+	// RecordFixturesDir, when set, captures every call to an AI provider into the
+	// directory as fixtures for the mock provider's replay mode
+	RecordFixturesDir string `json:"recordFixturesDir,omitempty"`
 }
 
 func LoadConfig() (*Config, error) {
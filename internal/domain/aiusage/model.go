@@ -14,24 +14,32 @@ const (
 	OperationPageGeneration    OperationType = "page_generation"
 	OpLinkSuggestion           OperationType = "link_suggestion"
 	OpLinkInsertion            OperationType = "link_insertion"
+	OperationEmbeddings        OperationType = "embeddings"
+	OperationImageGeneration   OperationType = "image_generation"
+	OperationHealthCheck       OperationType = "health_check"
+	OperationArticleRewrite    OperationType = "article_rewrite"
+	OperationTranslation       OperationType = "article_translation"
 )
 
 // UsageLog represents a single AI usage log entry
 type UsageLog struct {
 	ID            int64
 	SiteID        int64
+	ProviderID    int64
 	OperationType OperationType
 	ProviderName  string
 	ModelName     string
-	InputTokens   int
-	OutputTokens  int
-	TotalTokens   int
-	CostUSD       float64
-	DurationMs    int64
-	Success       bool
-	ErrorMessage  string
-	Metadata      map[string]interface{}
-	CreatedAt     time.Time
+	// KeyFingerprint identifies which of the provider's API keys served the call
+	KeyFingerprint string
+	InputTokens    int
+	OutputTokens   int
+	TotalTokens    int
+	CostUSD        float64
+	DurationMs     int64
+	Success        bool
+	ErrorMessage   string
+	Metadata       map[string]interface{}
+	CreatedAt      time.Time
 }
 
 // UsageSummary represents aggregated usage statistics
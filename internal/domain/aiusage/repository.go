@@ -14,6 +14,8 @@ type Repository interface {
 	Create(ctx context.Context, log *UsageLog) error
 	GetLogs(ctx context.Context, siteID *int64, timeRange *TimeRange, limit, offset int) (*LogsResult, error)
 	GetSummary(ctx context.Context, siteID *int64, timeRange *TimeRange) (*UsageSummary, error)
+	GetTotalCost(ctx context.Context, siteID, providerID *int64, timeRange *TimeRange) (float64, error)
+	GetAverageCost(ctx context.Context, siteID, providerID *int64, timeRange *TimeRange) (float64, error)
 	GetByPeriod(ctx context.Context, siteID *int64, timeRange *TimeRange, groupBy string) ([]UsageByPeriod, error)
 	GetByOperation(ctx context.Context, siteID *int64, timeRange *TimeRange) ([]UsageByOperation, error)
 	GetByProvider(ctx context.Context, siteID *int64, timeRange *TimeRange) ([]UsageByProvider, error)
@@ -51,14 +53,24 @@ func (r *repository) Create(ctx context.Context, log *UsageLog) error {
 		errorMsg = &log.ErrorMessage
 	}
 
+	var providerID *int64
+	if log.ProviderID > 0 {
+		providerID = &log.ProviderID
+	}
+
+	var keyFingerprint *string
+	if log.KeyFingerprint != "" {
+		keyFingerprint = &log.KeyFingerprint
+	}
+
 	query, args, err := dbx.ST.Insert("ai_usage_logs").
 		Columns(
-			"site_id", "operation_type", "provider_name", "model_name",
+			"site_id", "provider_id", "operation_type", "provider_name", "model_name", "key_fingerprint",
 			"input_tokens", "output_tokens", "total_tokens", "cost_usd",
 			"duration_ms", "success", "error_message", "metadata", "created_at",
 		).
 		Values(
-			log.SiteID, string(log.OperationType), log.ProviderName, log.ModelName,
+			log.SiteID, providerID, string(log.OperationType), log.ProviderName, log.ModelName, keyFingerprint,
 			log.InputTokens, log.OutputTokens, log.TotalTokens, log.CostUSD,
 			log.DurationMs, successInt, errorMsg, metadataJSON,
 			log.CreatedAt.Format(time.RFC3339),
@@ -109,7 +121,7 @@ func (r *repository) GetLogs(ctx context.Context, siteID *int64, timeRange *Time
 	// Build main query
 	qb := dbx.ST.Select(
 		"a.id", "a.site_id", "COALESCE(s.name, 'Unknown') as site_name",
-		"a.operation_type", "a.provider_name", "a.model_name",
+		"a.operation_type", "a.provider_name", "a.model_name", "a.key_fingerprint",
 		"a.input_tokens", "a.output_tokens", "a.total_tokens", "a.cost_usd",
 		"a.duration_ms", "a.success", "a.error_message", "a.metadata", "a.created_at",
 	).From("ai_usage_logs a").
@@ -142,12 +154,12 @@ func (r *repository) GetLogs(ctx context.Context, siteID *int64, timeRange *Time
 		var log UsageLog
 		var opType string
 		var success int
-		var errorMsg, metadataJSON, siteName *string
+		var errorMsg, metadataJSON, siteName, keyFingerprint *string
 		var createdAtStr string
 
 		if err := rows.Scan(
 			&log.ID, &log.SiteID, &siteName,
-			&opType, &log.ProviderName, &log.ModelName,
+			&opType, &log.ProviderName, &log.ModelName, &keyFingerprint,
 			&log.InputTokens, &log.OutputTokens, &log.TotalTokens, &log.CostUSD,
 			&log.DurationMs, &success, &errorMsg, &metadataJSON, &createdAtStr,
 		); err != nil {
@@ -159,6 +171,9 @@ func (r *repository) GetLogs(ctx context.Context, siteID *int64, timeRange *Time
 		if errorMsg != nil {
 			log.ErrorMessage = *errorMsg
 		}
+		if keyFingerprint != nil {
+			log.KeyFingerprint = *keyFingerprint
+		}
 		if metadataJSON != nil && *metadataJSON != "" {
 			var metadata map[string]interface{}
 			if err := json.Unmarshal([]byte(*metadataJSON), &metadata); err == nil {
@@ -220,6 +235,66 @@ func (r *repository) GetSummary(ctx context.Context, siteID *int64, timeRange *T
 	return &summary, nil
 }
 
+func (r *repository) GetTotalCost(ctx context.Context, siteID, providerID *int64, timeRange *TimeRange) (float64, error) {
+	qb := dbx.ST.Select("COALESCE(SUM(cost_usd), 0)").From("ai_usage_logs")
+
+	if siteID != nil {
+		qb = qb.Where(squirrel.Eq{"site_id": *siteID})
+	}
+
+	if providerID != nil {
+		qb = qb.Where(squirrel.Eq{"provider_id": *providerID})
+	}
+
+	if timeRange != nil {
+		qb = qb.Where(squirrel.GtOrEq{"created_at": timeRange.Start.Format(time.RFC3339)}).
+			Where(squirrel.LtOrEq{"created_at": timeRange.End.Format(time.RFC3339)})
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	if err = r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *repository) GetAverageCost(ctx context.Context, siteID, providerID *int64, timeRange *TimeRange) (float64, error) {
+	qb := dbx.ST.Select("COALESCE(AVG(cost_usd), 0)").From("ai_usage_logs").
+		Where(squirrel.Eq{"success": 1}).
+		Where(squirrel.Gt{"cost_usd": 0})
+
+	if siteID != nil {
+		qb = qb.Where(squirrel.Eq{"site_id": *siteID})
+	}
+
+	if providerID != nil {
+		qb = qb.Where(squirrel.Eq{"provider_id": *providerID})
+	}
+
+	if timeRange != nil {
+		qb = qb.Where(squirrel.GtOrEq{"created_at": timeRange.Start.Format(time.RFC3339)}).
+			Where(squirrel.LtOrEq{"created_at": timeRange.End.Format(time.RFC3339)})
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var average float64
+	if err = r.db.QueryRowContext(ctx, query, args...).Scan(&average); err != nil {
+		return 0, err
+	}
+
+	return average, nil
+}
+
 func (r *repository) GetByPeriod(ctx context.Context, siteID *int64, timeRange *TimeRange, groupBy string) ([]UsageByPeriod, error) {
 	// groupBy: "day" or "month"
 	var dateFormat string
@@ -13,7 +13,7 @@ type Service interface {
 	LogUsage(ctx context.Context, log *UsageLog) error
 
 	// LogFromResult is a convenience method to log usage from an AI result
-	LogFromResult(ctx context.Context, siteID int64, operationType OperationType, client ai.Client, usage ai.Usage, durationMs int64, err error, metadata map[string]interface{}) error
+	LogFromResult(ctx context.Context, siteID, providerID int64, operationType OperationType, client ai.Client, usage ai.Usage, durationMs int64, err error, metadata map[string]interface{}) error
 
 	// GetLogs returns paginated usage logs
 	GetLogs(ctx context.Context, siteID *int64, timeRange *TimeRange, limit, offset int) (*LogsResult, error)
@@ -21,6 +21,13 @@ type Service interface {
 	// GetSummary returns aggregated usage statistics
 	GetSummary(ctx context.Context, siteID *int64, timeRange *TimeRange) (*UsageSummary, error)
 
+	// GetTotalCost returns the total cost in USD, optionally filtered by site and provider
+	GetTotalCost(ctx context.Context, siteID, providerID *int64, timeRange *TimeRange) (float64, error)
+
+	// GetAverageCost returns the average cost in USD of a successful paid call, optionally
+	// filtered by site and provider
+	GetAverageCost(ctx context.Context, siteID, providerID *int64, timeRange *TimeRange) (float64, error)
+
 	// GetUsageByPeriod returns usage grouped by time period (day/month)
 	GetUsageByPeriod(ctx context.Context, siteID *int64, timeRange *TimeRange, groupBy string) ([]UsageByPeriod, error)
 
@@ -56,20 +63,22 @@ func (s *service) LogUsage(ctx context.Context, log *UsageLog) error {
 	return s.repo.Create(ctx, log)
 }
 
-func (s *service) LogFromResult(ctx context.Context, siteID int64, operationType OperationType, client ai.Client, usage ai.Usage, durationMs int64, err error, metadata map[string]interface{}) error {
+func (s *service) LogFromResult(ctx context.Context, siteID, providerID int64, operationType OperationType, client ai.Client, usage ai.Usage, durationMs int64, err error, metadata map[string]interface{}) error {
 	log := &UsageLog{
-		SiteID:        siteID,
-		OperationType: operationType,
-		ProviderName:  client.GetProviderName(),
-		ModelName:     client.GetModelName(),
-		InputTokens:   usage.InputTokens,
-		OutputTokens:  usage.OutputTokens,
-		TotalTokens:   usage.TotalTokens,
-		CostUSD:       usage.CostUSD,
-		DurationMs:    durationMs,
-		Success:       err == nil,
-		Metadata:      metadata,
-		CreatedAt:     time.Now(),
+		SiteID:         siteID,
+		ProviderID:     providerID,
+		OperationType:  operationType,
+		ProviderName:   client.GetProviderName(),
+		ModelName:      client.GetModelName(),
+		KeyFingerprint: ai.UsedKey(usage, err),
+		InputTokens:    usage.InputTokens,
+		OutputTokens:   usage.OutputTokens,
+		TotalTokens:    usage.TotalTokens,
+		CostUSD:        usage.CostUSD,
+		DurationMs:     durationMs,
+		Success:        err == nil,
+		Metadata:       metadata,
+		CreatedAt:      time.Now(),
 	}
 
 	if err != nil {
@@ -92,6 +101,14 @@ func (s *service) GetSummary(ctx context.Context, siteID *int64, timeRange *Time
 	return s.repo.GetSummary(ctx, siteID, timeRange)
 }
 
+func (s *service) GetTotalCost(ctx context.Context, siteID, providerID *int64, timeRange *TimeRange) (float64, error) {
+	return s.repo.GetTotalCost(ctx, siteID, providerID, timeRange)
+}
+
+func (s *service) GetAverageCost(ctx context.Context, siteID, providerID *int64, timeRange *TimeRange) (float64, error) {
+	return s.repo.GetAverageCost(ctx, siteID, providerID, timeRange)
+}
+
 func (s *service) GetUsageByPeriod(ctx context.Context, siteID *int64, timeRange *TimeRange, groupBy string) ([]UsageByPeriod, error) {
 	return s.repo.GetByPeriod(ctx, siteID, timeRange, groupBy)
 }
@@ -40,6 +40,7 @@ type Repository interface {
 	ListBySite(ctx context.Context, siteID int64, limit, offset int) ([]*entities.Article, error)
 	ListByJob(ctx context.Context, jobID int64) ([]*entities.Article, error)
 	ListByTopic(ctx context.Context, topicID int64) ([]*entities.Article, error)
+	ListByTranslationGroup(ctx context.Context, group string) ([]*entities.Article, error)
 
 	GetByStatus(ctx context.Context, siteID int64, status entities.ArticleStatus) ([]*entities.Article, error)
 	GetBySource(ctx context.Context, siteID int64, source entities.Source) ([]*entities.Article, error)
@@ -78,6 +79,26 @@ type GenerateContentResult struct {
 	TopicID         *int64 // The topic ID that was used or created
 }
 
+// RewriteArticleInput represents input for revising an existing article with AI
+type RewriteArticleInput struct {
+	ArticleID         int64
+	ProviderID        int64
+	PromptID          int64  // Optional - if 0, the builtin article_rewrite prompt is used
+	Instructions      string // What to change, e.g. "update for 2026", "expand section X"
+	PlaceholderValues map[string]string
+}
+
+// TranslateArticleInput represents input for translating an article into other languages
+type TranslateArticleInput struct {
+	ArticleID         int64  // Source article, ignored when SitemapNodeID is set
+	SitemapNodeID     *int64 // Optional - translate the article linked to this node
+	ProviderID        int64
+	PromptID          int64    // Optional - the article_rewrite prompt translating each language, the builtin one if 0
+	SourceLanguage    string   // Optional - language of the source when it isn't known yet
+	Languages         []string // Target language codes, e.g. "fr", "de"
+	PlaceholderValues map[string]string
+}
+
 type Service interface {
 	CreateArticle(ctx context.Context, article *entities.Article) error
 	CreateAndPublishArticle(ctx context.Context, article *entities.Article) (*entities.Article, error)
@@ -102,4 +123,11 @@ type Service interface {
 	PublishDraft(ctx context.Context, id int64) error
 
 	GenerateContent(ctx context.Context, input *GenerateContentInput) (*GenerateContentResult, error)
+	// RewriteArticle returns revised content for an existing article. The article itself
+	// is not modified, the result is meant to be reviewed and saved with UpdateArticle.
+	RewriteArticle(ctx context.Context, input *RewriteArticleInput) (*GenerateContentResult, error)
+	// TranslateArticle creates a draft article per target language, linked to the source
+	// through its translation group. Languages the group already has are skipped.
+	TranslateArticle(ctx context.Context, input *TranslateArticleInput) ([]*entities.Article, error)
+	ListTranslations(ctx context.Context, id int64) ([]*entities.Article, error)
 }
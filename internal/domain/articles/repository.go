@@ -27,6 +27,7 @@ var articleColumns = []string{
 	"status", "source", "is_edited", "word_count",
 	"slug", "featured_media_id", "featured_media_url", "meta_description", "author",
 	"created_at", "published_at", "updated_at", "last_synced_at",
+	"language", "translation_group",
 }
 
 type repository struct {
@@ -63,6 +64,7 @@ func (r *repository) Create(ctx context.Context, article *entities.Article) erro
 			"status", "source", "is_edited", "word_count",
 			"slug", "featured_media_id", "featured_media_url", "meta_description", "author",
 			"created_at", "published_at", "last_synced_at",
+			"language", "translation_group",
 		).
 		Values(
 			article.SiteID, article.JobID, article.TopicID,
@@ -71,6 +73,7 @@ func (r *repository) Create(ctx context.Context, article *entities.Article) erro
 			article.Status, article.Source, article.IsEdited, article.WordCount,
 			article.Slug, article.FeaturedMediaID, article.FeaturedMediaURL, article.MetaDescription, article.Author,
 			article.CreatedAt, article.PublishedAt, article.LastSyncedAt,
+			article.Language, article.TranslationGroup,
 		).
 		MustSql()
 
@@ -159,6 +162,8 @@ func (r *repository) Update(ctx context.Context, article *entities.Article) erro
 		Set("author", article.Author).
 		Set("published_at", article.PublishedAt).
 		Set("last_synced_at", article.LastSyncedAt).
+		Set("language", article.Language).
+		Set("translation_group", article.TranslationGroup).
 		Set("updated_at", time.Now()).
 		Where(squirrel.Eq{"id": article.ID}).
 		MustSql()
@@ -340,6 +345,17 @@ func (r *repository) ListByTopic(ctx context.Context, topicID int64) ([]*entitie
 	return r.scanArticles(query, args, ctx)
 }
 
+func (r *repository) ListByTranslationGroup(ctx context.Context, group string) ([]*entities.Article, error) {
+	query, args := dbx.ST.
+		Select(articleColumns...).
+		From("articles").
+		Where(squirrel.Eq{"translation_group": group}).
+		OrderBy("created_at ASC").
+		MustSql()
+
+	return r.scanArticles(query, args, ctx)
+}
+
 func (r *repository) GetByStatus(ctx context.Context, siteID int64, status entities.ArticleStatus) ([]*entities.Article, error) {
 	query, args := dbx.ST.
 		Select(articleColumns...).
@@ -547,6 +563,7 @@ func (r *repository) BulkCreate(ctx context.Context, articles []*entities.Articl
 				"status", "source", "is_edited", "word_count",
 				"slug", "featured_media_id", "featured_media_url", "meta_description", "author",
 				"created_at", "published_at", "last_synced_at",
+				"language", "translation_group",
 			).
 			Values(
 				article.SiteID, article.JobID, article.TopicID,
@@ -555,6 +572,7 @@ func (r *repository) BulkCreate(ctx context.Context, articles []*entities.Articl
 				article.Status, article.Source, article.IsEdited, article.WordCount,
 				article.Slug, article.FeaturedMediaID, article.FeaturedMediaURL, article.MetaDescription, article.Author,
 				article.CreatedAt, article.PublishedAt, article.LastSyncedAt,
+				article.Language, article.TranslationGroup,
 			).
 			MustSql()
 
@@ -635,7 +653,7 @@ func (r *repository) scanArticle(query string, args []interface{}, ctx context.C
 	var article entities.Article
 	var jobID, topicID sql.NullInt64
 	var excerpt, categoryIDsJSON, tagIDsJSON sql.NullString
-	var slug, featuredMediaURL, metaDescription sql.NullString
+	var slug, featuredMediaURL, metaDescription, translationGroup sql.NullString
 	var wordCount, featuredMediaID, author sql.NullInt32
 	var publishedAt, lastSyncedAt sql.NullTime
 
@@ -665,6 +683,8 @@ func (r *repository) scanArticle(query string, args []interface{}, ctx context.C
 		&publishedAt,
 		&article.UpdatedAt,
 		&lastSyncedAt,
+		&article.Language,
+		&translationGroup,
 	)
 	if err != nil {
 		return nil, err
@@ -707,6 +727,9 @@ func (r *repository) scanArticle(query string, args []interface{}, ctx context.C
 		a := int(author.Int32)
 		article.Author = &a
 	}
+	if translationGroup.Valid {
+		article.TranslationGroup = &translationGroup.String
+	}
 
 	// Parse category IDs JSON
 	if categoryIDsJSON.Valid {
@@ -766,7 +789,7 @@ func (r *repository) scanArticleFromRow(rows *sql.Rows) (*entities.Article, erro
 	var article entities.Article
 	var jobID, topicID sql.NullInt64
 	var excerpt, categoryIDsJSON, tagIDsJSON sql.NullString
-	var slug, featuredMediaURL, metaDescription sql.NullString
+	var slug, featuredMediaURL, metaDescription, translationGroup sql.NullString
 	var wordCount, featuredMediaID, author sql.NullInt32
 	var publishedAt, lastSyncedAt sql.NullTime
 
@@ -796,6 +819,8 @@ func (r *repository) scanArticleFromRow(rows *sql.Rows) (*entities.Article, erro
 		&publishedAt,
 		&article.UpdatedAt,
 		&lastSyncedAt,
+		&article.Language,
+		&translationGroup,
 	)
 	if err != nil {
 		return nil, err
@@ -838,6 +863,9 @@ func (r *repository) scanArticleFromRow(rows *sql.Rows) (*entities.Article, erro
 		a := int(author.Int32)
 		article.Author = &a
 	}
+	if translationGroup.Valid {
+		article.TranslationGroup = &translationGroup.String
+	}
 
 	// Parse category IDs JSON
 	if categoryIDsJSON.Valid {
@@ -5,9 +5,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/domain/sitemap"
 	"github.com/davidmovas/postulator/internal/domain/sites"
 	"github.com/davidmovas/postulator/internal/domain/topics"
 	"github.com/davidmovas/postulator/internal/infra/ai"
@@ -25,6 +28,9 @@ type service struct {
 	providerService providers.Service
 	promptService   prompts.Service
 	topicService    topics.Service
+	budgetService   budgets.Service
+	aiUsageService  aiusage.Service
+	sitemapService  sitemap.Service
 	logger          *logger.Logger
 }
 
@@ -34,6 +40,9 @@ func NewService(
 	providerService providers.Service,
 	promptService prompts.Service,
 	topicService topics.Service,
+	budgetService budgets.Service,
+	aiUsageService aiusage.Service,
+	sitemapService sitemap.Service,
 	wp wp.Client,
 	logger *logger.Logger,
 ) Service {
@@ -43,6 +52,9 @@ func NewService(
 		providerService: providerService,
 		promptService:   promptService,
 		topicService:    topicService,
+		budgetService:   budgetService,
+		aiUsageService:  aiUsageService,
+		sitemapService:  sitemapService,
 		wp:              wp,
 		logger:          logger.WithScope("service").WithScope("articles"),
 	}
@@ -446,7 +458,7 @@ func (s *service) PublishToWordPress(ctx context.Context, article *entities.Arti
 		}
 	}
 
-	wpPostID, err := s.wp.CreatePost(ctx, site, article, &wp.PostOptions{Status: "publish"})
+	wpPostID, err := s.wp.CreatePost(ctx, site, article, s.postOptions(ctx, article, "publish"))
 	if err != nil {
 		s.logger.ErrorWithErr(err, "Failed to publish article to WordPress")
 		return err
@@ -734,6 +746,16 @@ func (s *service) GenerateContent(ctx context.Context, input *GenerateContentInp
 		return nil, err
 	}
 
+	prompt, err := s.promptService.GetPrompt(ctx, input.PromptID)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get prompt for content generation")
+		return nil, err
+	}
+
+	if err = s.budgetService.Check(ctx, input.SiteID, provider.ID); err != nil {
+		return nil, err
+	}
+
 	// Create AI client
 	aiClient, err := ai.CreateClient(provider)
 	if err != nil {
@@ -743,7 +765,7 @@ func (s *service) GenerateContent(ctx context.Context, input *GenerateContentInp
 
 	// Generate article
 	s.logger.Info("Starting AI content generation")
-	aiResult, err := aiClient.GenerateArticle(ctx, systemPrompt, userPrompt, nil)
+	aiResult, err := ai.ComposeArticle(ctx, aiClient, systemPrompt, userPrompt, ai.NewGenerateArticleOptions(prompt.GenerationParams), nil)
 	if err != nil {
 		s.logger.ErrorWithErr(err, "Failed to generate article content")
 		return nil, errors.Internal(err)
@@ -770,6 +792,161 @@ func (s *service) GenerateContent(ctx context.Context, input *GenerateContentInp
 	return result, nil
 }
 
+func (s *service) RewriteArticle(ctx context.Context, input *RewriteArticleInput) (*GenerateContentResult, error) {
+	if input.ArticleID <= 0 {
+		return nil, errors.Validation("Article ID is required")
+	}
+	if input.ProviderID <= 0 {
+		return nil, errors.Validation("Provider ID is required")
+	}
+	if strings.TrimSpace(input.Instructions) == "" {
+		return nil, errors.Validation("Rewrite instructions are required")
+	}
+
+	article, err := s.repo.GetByID(ctx, input.ArticleID)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get article for rewrite")
+		return nil, err
+	}
+	if strings.TrimSpace(article.Content) == "" {
+		return nil, errors.Validation("Article has no content to rewrite")
+	}
+
+	return s.rewriteContent(ctx, aiusage.OperationArticleRewrite, article, input.ProviderID, input.PromptID, input.Instructions, input.PlaceholderValues)
+}
+
+// rewriteContent runs an article through an article_rewrite prompt with the given
+// instructions, the usage is logged under the operation
+func (s *service) rewriteContent(
+	ctx context.Context,
+	operation aiusage.OperationType,
+	article *entities.Article,
+	providerID, promptID int64,
+	instructions string,
+	placeholders map[string]string,
+) (*GenerateContentResult, error) {
+	provider, err := s.providerService.GetProvider(ctx, providerID)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get provider for rewrite")
+		return nil, err
+	}
+
+	prompt, err := s.getRewritePrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build runtime data, custom placeholders can't replace the article itself
+	runtimeData := make(map[string]string)
+	for k, v := range placeholders {
+		runtimeData[k] = v
+	}
+	runtimeData["articleTitle"] = article.Title
+	runtimeData["articleContent"] = article.Content
+	runtimeData["rewriteInstructions"] = instructions
+
+	systemPrompt, userPrompt, err := s.promptService.RenderPromptWithOverrides(ctx, prompt, runtimeData, nil)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to render prompts for rewrite")
+		return nil, err
+	}
+
+	if err = s.budgetService.Check(ctx, article.SiteID, provider.ID); err != nil {
+		return nil, err
+	}
+
+	aiClient, err := ai.CreateClient(provider)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to create AI client for rewrite")
+		return nil, err
+	}
+
+	s.logger.Infof("Starting AI rewrite of article %d", article.ID)
+	startTime := time.Now()
+	aiResult, err := aiClient.RewriteArticle(ctx, &ai.RewriteArticleRequest{
+		Title:        article.Title,
+		Content:      article.Content,
+		Instructions: instructions,
+		Language:     runtimeData["language"],
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+		Options:      ai.NewGenerateArticleOptions(prompt.GenerationParams),
+	})
+	durationMs := time.Since(startTime).Milliseconds()
+
+	// Log AI usage regardless of success/failure
+	if s.aiUsageService != nil {
+		var usage ai.Usage
+		if aiResult != nil {
+			usage = aiResult.Usage
+		}
+		_ = s.aiUsageService.LogFromResult(
+			ctx,
+			article.SiteID,
+			provider.ID,
+			operation,
+			aiClient,
+			usage,
+			durationMs,
+			err,
+			map[string]interface{}{
+				"article_id":  article.ID,
+				"prompt_id":   prompt.ID,
+				"language":    runtimeData["language"],
+				"provider_id": provider.ID,
+			},
+		)
+	}
+
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to rewrite article content")
+		return nil, errors.Internal(err)
+	}
+
+	title := aiResult.Title
+	if strings.TrimSpace(title) == "" {
+		title = article.Title
+	}
+
+	s.logger.Info("AI rewrite completed successfully")
+	return &GenerateContentResult{
+		Title:           title,
+		Content:         aiResult.Content,
+		Excerpt:         aiResult.Excerpt,
+		MetaDescription: s.generateMetaDescription(aiResult.Excerpt, aiResult.Content),
+		TopicID:         article.TopicID,
+	}, nil
+}
+
+// getRewritePrompt returns the requested prompt, or the builtin article_rewrite one
+func (s *service) getRewritePrompt(ctx context.Context, promptID int64) (*entities.Prompt, error) {
+	if promptID > 0 {
+		prompt, err := s.promptService.GetPrompt(ctx, promptID)
+		if err != nil {
+			s.logger.ErrorWithErr(err, "Failed to get prompt for rewrite")
+			return nil, err
+		}
+		if prompt.Category != entities.PromptCategoryArticleRewrite {
+			return nil, errors.Validation("Prompt is not an article rewrite prompt")
+		}
+		return prompt, nil
+	}
+
+	prompts, err := s.promptService.ListPromptsByCategory(ctx, entities.PromptCategoryArticleRewrite)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get builtin rewrite prompts")
+		return nil, err
+	}
+
+	for _, p := range prompts {
+		if p.IsBuiltin {
+			return p, nil
+		}
+	}
+
+	return nil, errors.NotFound("prompt", entities.PromptCategoryArticleRewrite)
+}
+
 func (s *service) generateMetaDescription(excerpt, content string) string {
 	// Use excerpt if available and not too long
 	if len(excerpt) > 0 && len(excerpt) <= 160 {
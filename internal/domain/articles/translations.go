@@ -0,0 +1,203 @@
+package articles
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/wp"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/google/uuid"
+)
+
+func (s *service) TranslateArticle(ctx context.Context, input *TranslateArticleInput) ([]*entities.Article, error) {
+	if input.ProviderID <= 0 {
+		return nil, errors.Validation("Provider ID is required")
+	}
+	if len(input.Languages) == 0 {
+		return nil, errors.Validation("At least one target language is required")
+	}
+
+	source, err := s.getTranslationSource(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(source.Content) == "" {
+		return nil, errors.Validation("Article has no content to translate")
+	}
+
+	// Put the source into a group on its first translation
+	if source.TranslationGroup == nil || source.Language == "" {
+		if source.TranslationGroup == nil {
+			group := uuid.New().String()
+			source.TranslationGroup = &group
+		}
+		if source.Language == "" {
+			source.Language = normalizeLanguage(input.SourceLanguage)
+		}
+		if err = s.repo.Update(ctx, source); err != nil {
+			s.logger.ErrorWithErr(err, "Failed to update source article translation group")
+			return nil, err
+		}
+	}
+
+	existing, err := s.repo.ListByTranslationGroup(ctx, *source.TranslationGroup)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to list article translations")
+		return nil, err
+	}
+
+	languages := make([]string, 0, len(existing))
+	for _, article := range existing {
+		if article.Language != "" {
+			languages = append(languages, article.Language)
+		}
+	}
+
+	var translations []*entities.Article
+	for _, language := range input.Languages {
+		language = normalizeLanguage(language)
+		if language == "" {
+			continue
+		}
+		if slices.Contains(languages, language) {
+			s.logger.Infof("Skipping translation of article %d into %q: already in the group", source.ID, language)
+			continue
+		}
+
+		placeholders := make(map[string]string, len(input.PlaceholderValues)+1)
+		for k, v := range input.PlaceholderValues {
+			placeholders[k] = v
+		}
+		placeholders["language"] = language
+
+		result, err := s.rewriteContent(ctx, aiusage.OperationTranslation, source, input.ProviderID, input.PromptID, translationInstructions(language), placeholders)
+		if err != nil {
+			return translations, err
+		}
+
+		translation := s.newTranslation(source, language, result)
+		if err = s.repo.Create(ctx, translation); err != nil {
+			s.logger.ErrorWithErr(err, "Failed to save article translation")
+			return translations, err
+		}
+
+		languages = append(languages, language)
+		translations = append(translations, translation)
+	}
+
+	s.logger.Infof("Translated article %d into %d languages", source.ID, len(translations))
+	return translations, nil
+}
+
+func (s *service) ListTranslations(ctx context.Context, id int64) ([]*entities.Article, error) {
+	article, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get article for translations")
+		return nil, err
+	}
+
+	if article.TranslationGroup == nil {
+		return []*entities.Article{article}, nil
+	}
+
+	return s.repo.ListByTranslationGroup(ctx, *article.TranslationGroup)
+}
+
+// getTranslationSource resolves the article to translate, directly or through its sitemap node
+func (s *service) getTranslationSource(ctx context.Context, input *TranslateArticleInput) (*entities.Article, error) {
+	articleID := input.ArticleID
+	if input.SitemapNodeID != nil {
+		node, err := s.sitemapService.GetNode(ctx, *input.SitemapNodeID)
+		if err != nil {
+			s.logger.ErrorWithErr(err, "Failed to get sitemap node for translation")
+			return nil, err
+		}
+		if node.ArticleID == nil {
+			return nil, errors.Validation("Sitemap node has no generated content to translate")
+		}
+		articleID = *node.ArticleID
+	}
+
+	if articleID <= 0 {
+		return nil, errors.Validation("Article ID or sitemap node ID is required")
+	}
+
+	article, err := s.repo.GetByID(ctx, articleID)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get article for translation")
+		return nil, err
+	}
+
+	return article, nil
+}
+
+// newTranslation builds the draft article of a translation, keeping the source taxonomy
+func (s *service) newTranslation(source *entities.Article, language string, result *GenerateContentResult) *entities.Article {
+	now := time.Now()
+	wordCount := s.calculateWordCount(result.Content)
+	excerpt := result.Excerpt
+	metaDescription := result.MetaDescription
+
+	return &entities.Article{
+		SiteID:           source.SiteID,
+		TopicID:          source.TopicID,
+		Title:            result.Title,
+		OriginalTitle:    result.Title,
+		Content:          result.Content,
+		Excerpt:          &excerpt,
+		MetaDescription:  &metaDescription,
+		WPCategoryIDs:    source.WPCategoryIDs,
+		WPTagIDs:         source.WPTagIDs,
+		FeaturedMediaID:  source.FeaturedMediaID,
+		FeaturedMediaURL: source.FeaturedMediaURL,
+		Author:           source.Author,
+		Status:           entities.StatusDraft,
+		Source:           entities.SourceGenerated,
+		WordCount:        &wordCount,
+		ContentType:      source.ContentType,
+		Language:         language,
+		TranslationGroup: source.TranslationGroup,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// postOptions adds the language and the already published translations of an article
+// to its WordPress post, so translation plugins link the versions together
+func (s *service) postOptions(ctx context.Context, article *entities.Article, status string) *wp.PostOptions {
+	opts := &wp.PostOptions{Status: status, Language: article.Language}
+	if article.Language == "" || article.TranslationGroup == nil {
+		return opts
+	}
+
+	group, err := s.repo.ListByTranslationGroup(ctx, *article.TranslationGroup)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to list translations for publishing, publishing without links")
+		return opts
+	}
+
+	opts.Translations = make(map[string]int)
+	for _, translation := range group {
+		if translation.ID != article.ID && translation.SiteID == article.SiteID &&
+			translation.Language != "" && translation.WPPostID > 0 {
+			opts.Translations[translation.Language] = translation.WPPostID
+		}
+	}
+
+	return opts
+}
+
+func translationInstructions(language string) string {
+	return fmt.Sprintf("Translate the whole article, including the title and excerpt, into the language with code %q. "+
+		"Translate naturally for native readers instead of word for word, keep the HTML structure, links and formatting unchanged "+
+		"and adapt keywords to what readers search for in that language.", language)
+}
+
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.TrimSpace(language))
+}
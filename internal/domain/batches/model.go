@@ -0,0 +1,74 @@
+package batches
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+)
+
+type Repository interface {
+	// Create inserts the batch together with its items
+	Create(ctx context.Context, batch *entities.Batch, items []*entities.BatchItem) error
+	GetByID(ctx context.Context, id int64) (*entities.Batch, error)
+	GetBySite(ctx context.Context, siteID int64) ([]*entities.Batch, error)
+	GetByStatus(ctx context.Context, status entities.BatchStatus) ([]*entities.Batch, error)
+	GetItems(ctx context.Context, batchID int64) ([]*entities.BatchItem, error)
+	Update(ctx context.Context, batch *entities.Batch) error
+	UpdateItem(ctx context.Context, item *entities.BatchItem) error
+}
+
+// SubmitRequest describes a batch of article generations. Settings (optional) is
+// stored as JSON and handed back to the result handler of the source.
+type SubmitRequest struct {
+	SiteID     int64
+	ProviderID int64
+	Source     entities.BatchSource
+	SourceID   int64
+	Settings   any
+	Items      []*SubmitItem
+}
+
+// SubmitItem is the rendered request for one sitemap node or topic
+type SubmitItem struct {
+	EntityID     int64
+	SystemPrompt string
+	UserPrompt   string
+	Options      *ai.GenerateArticleOptions
+	// Words is the article length the prompts ask for, it sizes the cost estimate
+	// checked against the budgets. Zero counts the default length.
+	Words int
+}
+
+// ItemResult is the outcome of one item of a finished batch. Article is nil when
+// the provider failed the request, Err then holds the reason. Handlers publish the
+// article, set Item.ArticleID, and set Err if publishing fails.
+type ItemResult struct {
+	Item    *entities.BatchItem
+	Article *ai.ArticleResult
+	Err     error
+}
+
+// ResultHandler publishes the results of a finished batch of its source
+type ResultHandler func(ctx context.Context, batch *entities.Batch, results []*ItemResult)
+
+type Service interface {
+	// RegisterHandler sets the handler that publishes the results of batches of the source
+	RegisterHandler(source entities.BatchSource, handler ResultHandler)
+
+	// Submit sends the items to the provider's batch API and stores the batch for polling
+	Submit(ctx context.Context, req *SubmitRequest) (*entities.Batch, error)
+	GetBatch(ctx context.Context, id int64) (*entities.Batch, error)
+	ListBatches(ctx context.Context, siteID int64) ([]*entities.Batch, error)
+	GetBatchItems(ctx context.Context, id int64) ([]*entities.BatchItem, error)
+	CancelBatch(ctx context.Context, id int64) error
+
+	// Poll checks every submitted batch with its provider and hands the results
+	// of the finished ones to their source's handler
+	Poll(ctx context.Context) error
+}
+
+type Poller interface {
+	Start(ctx context.Context) error
+	Stop() error
+}
@@ -0,0 +1,95 @@
+package batches
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+const (
+	// PollInterval is how often submitted batches are checked with their providers
+	PollInterval = time.Minute
+	// pollTimeout bounds a poll, publishing the results of a large batch takes a while
+	pollTimeout = 30 * time.Minute
+)
+
+var _ Poller = (*poller)(nil)
+
+type poller struct {
+	service Service
+	logger  *logger.Logger
+
+	stopChan chan struct{}
+	running  bool
+	mu       sync.Mutex
+}
+
+func NewPoller(service Service, logger *logger.Logger) Poller {
+	return &poller{
+		service: service,
+		logger: logger.
+			WithScope("scheduler").
+			WithScope("batches"),
+	}
+}
+
+// Start polls right away, so batches that finished while the app was closed are
+// published on startup, and then every PollInterval
+func (p *poller) Start(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		p.logger.Warn("Batch poller already running")
+		return nil
+	}
+
+	p.stopChan = make(chan struct{})
+	p.running = true
+
+	go p.run(p.stopChan)
+
+	p.logger.Infof("Batch poller started with interval: %v", PollInterval)
+	return nil
+}
+
+func (p *poller) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.running {
+		return nil
+	}
+
+	p.logger.Info("Stopping batch poller")
+	p.running = false
+	close(p.stopChan)
+
+	return nil
+}
+
+func (p *poller) run(stopChan <-chan struct{}) {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.poll()
+
+		select {
+		case <-ticker.C:
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+func (p *poller) poll() {
+	ctx, cancel := context.WithTimeout(context.Background(), pollTimeout)
+	defer cancel()
+
+	if err := p.service.Poll(ctx); err != nil {
+		p.logger.ErrorWithErr(err, "Failed to poll batches")
+	}
+}
@@ -0,0 +1,308 @@
+package batches
+
+import (
+	"context"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+
+	"github.com/Masterminds/squirrel"
+)
+
+var _ Repository = (*repository)(nil)
+
+var batchColumns = []string{
+	"id",
+	"site_id",
+	"provider_id",
+	"external_id",
+	"source",
+	"source_id",
+	"status",
+	"settings",
+	"total_items",
+	"completed_items",
+	"failed_items",
+	"error",
+	"created_at",
+	"updated_at",
+	"completed_at",
+	"key_fingerprint",
+	"publishing_at",
+}
+
+var itemColumns = []string{
+	"id",
+	"batch_id",
+	"custom_id",
+	"entity_id",
+	"status",
+	"article_id",
+	"error",
+	"updated_at",
+}
+
+type repository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewRepository(db *database.DB, logger *logger.Logger) Repository {
+	return &repository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("batches"),
+	}
+}
+
+func (r *repository) Create(ctx context.Context, batch *entities.Batch, items []*entities.BatchItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Database(err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query, args := dbx.ST.
+		Insert("ai_batches").
+		Columns("site_id", "provider_id", "external_id", "key_fingerprint", "source", "source_id", "status", "settings", "total_items", "created_at", "updated_at").
+		Values(batch.SiteID, batch.ProviderID, batch.ExternalID, batch.KeyFingerprint, batch.Source, batch.SourceID, batch.Status, batch.Settings, batch.TotalItems, batch.CreatedAt, batch.UpdatedAt).
+		MustSql()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Database(err)
+	}
+	batch.ID = id
+
+	for _, item := range items {
+		item.BatchID = id
+
+		query, args = dbx.ST.
+			Insert("ai_batch_items").
+			Columns("batch_id", "custom_id", "entity_id", "status", "updated_at").
+			Values(item.BatchID, item.CustomID, item.EntityID, item.Status, item.UpdatedAt).
+			MustSql()
+
+		result, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return errors.Database(err)
+		}
+
+		if item.ID, err = result.LastInsertId(); err != nil {
+			return errors.Database(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Database(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Batch, error) {
+	query, args := dbx.ST.
+		Select(batchColumns...).
+		From("ai_batches").
+		Where(squirrel.Eq{"id": id}).
+		MustSql()
+
+	batch, err := scanBatch(r.db.QueryRowContext(ctx, query, args...))
+	switch {
+	case dbx.IsNoRows(err):
+		return nil, errors.NotFound("batch", id)
+	case err != nil:
+		return nil, errors.Database(err)
+	}
+
+	return batch, nil
+}
+
+func (r *repository) GetBySite(ctx context.Context, siteID int64) ([]*entities.Batch, error) {
+	query, args := dbx.ST.
+		Select(batchColumns...).
+		From("ai_batches").
+		Where(squirrel.Eq{"site_id": siteID}).
+		OrderBy("created_at DESC", "id DESC").
+		MustSql()
+
+	return r.query(ctx, query, args)
+}
+
+func (r *repository) GetByStatus(ctx context.Context, status entities.BatchStatus) ([]*entities.Batch, error) {
+	query, args := dbx.ST.
+		Select(batchColumns...).
+		From("ai_batches").
+		Where(squirrel.Eq{"status": status}).
+		OrderBy("created_at", "id").
+		MustSql()
+
+	return r.query(ctx, query, args)
+}
+
+func (r *repository) GetItems(ctx context.Context, batchID int64) ([]*entities.BatchItem, error) {
+	query, args := dbx.ST.
+		Select(itemColumns...).
+		From("ai_batch_items").
+		Where(squirrel.Eq{"batch_id": batchID}).
+		OrderBy("id").
+		MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var items []*entities.BatchItem
+	for rows.Next() {
+		var item entities.BatchItem
+		scanErr := rows.Scan(
+			&item.ID,
+			&item.BatchID,
+			&item.CustomID,
+			&item.EntityID,
+			&item.Status,
+			&item.ArticleID,
+			&item.Error,
+			&item.UpdatedAt,
+		)
+		if scanErr != nil {
+			return nil, errors.Database(scanErr)
+		}
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return items, nil
+}
+
+func (r *repository) Update(ctx context.Context, batch *entities.Batch) error {
+	query, args := dbx.ST.
+		Update("ai_batches").
+		Set("status", batch.Status).
+		Set("completed_items", batch.CompletedItems).
+		Set("failed_items", batch.FailedItems).
+		Set("error", batch.Error).
+		Set("updated_at", time.Now()).
+		Set("completed_at", batch.CompletedAt).
+		Set("publishing_at", batch.PublishingAt).
+		Where(squirrel.Eq{"id": batch.ID}).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("batch", batch.ID)
+	}
+
+	return nil
+}
+
+func (r *repository) UpdateItem(ctx context.Context, item *entities.BatchItem) error {
+	query, args := dbx.ST.
+		Update("ai_batch_items").
+		Set("status", item.Status).
+		Set("article_id", item.ArticleID).
+		Set("error", item.Error).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": item.ID}).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("batch item", item.ID)
+	}
+
+	return nil
+}
+
+func (r *repository) query(ctx context.Context, query string, args []any) ([]*entities.Batch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var batches []*entities.Batch
+	for rows.Next() {
+		batch, scanErr := scanBatch(rows)
+		if scanErr != nil {
+			return nil, errors.Database(scanErr)
+		}
+		batches = append(batches, batch)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return batches, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBatch(row scanner) (*entities.Batch, error) {
+	var batch entities.Batch
+	err := row.Scan(
+		&batch.ID,
+		&batch.SiteID,
+		&batch.ProviderID,
+		&batch.ExternalID,
+		&batch.Source,
+		&batch.SourceID,
+		&batch.Status,
+		&batch.Settings,
+		&batch.TotalItems,
+		&batch.CompletedItems,
+		&batch.FailedItems,
+		&batch.Error,
+		&batch.CreatedAt,
+		&batch.UpdatedAt,
+		&batch.CompletedAt,
+		&batch.KeyFingerprint,
+		&batch.PublishingAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
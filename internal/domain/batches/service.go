@@ -0,0 +1,458 @@
+package batches
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+var _ Service = (*service)(nil)
+
+type service struct {
+	repo        Repository
+	providerSvc providers.Service
+	aiUsageSvc  aiusage.Service
+	budgetSvc   budgets.Service
+	logger      *logger.Logger
+
+	// newClient creates the client of a provider, replaced in tests
+	newClient func(provider *entities.Provider) (ai.Client, error)
+
+	handlersMu sync.RWMutex
+	handlers   map[entities.BatchSource]ResultHandler
+
+	// pollMu serializes polls, so the results of a batch are published only once
+	pollMu sync.Mutex
+}
+
+func NewService(
+	repo Repository,
+	providerSvc providers.Service,
+	aiUsageSvc aiusage.Service,
+	budgetSvc budgets.Service,
+	logger *logger.Logger,
+) Service {
+	return &service{
+		repo:        repo,
+		providerSvc: providerSvc,
+		aiUsageSvc:  aiUsageSvc,
+		budgetSvc:   budgetSvc,
+		handlers:    make(map[entities.BatchSource]ResultHandler),
+		newClient:   ai.CreateClient,
+		logger: logger.
+			WithScope("service").
+			WithScope("batches"),
+	}
+}
+
+func (s *service) RegisterHandler(source entities.BatchSource, handler ResultHandler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+
+	s.handlers[source] = handler
+}
+
+func (s *service) Submit(ctx context.Context, req *SubmitRequest) (*entities.Batch, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.Validation("Nothing to generate")
+	}
+
+	provider, err := s.providerSvc.GetProvider(ctx, req.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, batchClient, keyFingerprint, err := s.batchClient(provider, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole batch is admitted at once, every item has to fit the budgets
+	if s.budgetSvc != nil {
+		estimate := ai.NewCostEstimate(provider.Type, provider.Model)
+		for _, item := range req.Items {
+			estimate.AddArticle(item.SystemPrompt, item.UserPrompt, item.Words, item.Options)
+		}
+		if err = s.budgetSvc.CheckCost(ctx, req.SiteID, provider.ID, estimate.CostUSD*ai.BatchDiscount); err != nil {
+			return nil, err
+		}
+	}
+
+	settings := []byte("{}")
+	if req.Settings != nil {
+		if settings, err = json.Marshal(req.Settings); err != nil {
+			return nil, errors.Internal(err)
+		}
+	}
+
+	now := time.Now()
+	requests := make([]ai.BatchRequest, len(req.Items))
+	items := make([]*entities.BatchItem, len(req.Items))
+	for i, item := range req.Items {
+		customID := fmt.Sprintf("%s-%d", req.Source, item.EntityID)
+		requests[i] = ai.BatchRequest{
+			CustomID:     customID,
+			SystemPrompt: item.SystemPrompt,
+			UserPrompt:   item.UserPrompt,
+			Options:      item.Options,
+		}
+		items[i] = &entities.BatchItem{
+			CustomID:  customID,
+			EntityID:  item.EntityID,
+			Status:    entities.BatchItemStatusPending,
+			UpdatedAt: now,
+		}
+	}
+
+	externalID, err := batchClient.SubmitArticleBatch(ctx, requests)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to submit batch")
+		return nil, err
+	}
+
+	batch := &entities.Batch{
+		SiteID:         req.SiteID,
+		ProviderID:     provider.ID,
+		ExternalID:     externalID,
+		KeyFingerprint: keyFingerprint,
+		Source:         req.Source,
+		SourceID:       req.SourceID,
+		Status:         entities.BatchStatusSubmitted,
+		Settings:       string(settings),
+		TotalItems:     len(items),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err = s.repo.Create(ctx, batch, items); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to save batch")
+		// Results of an unknown batch could never be published
+		if cancelErr := batchClient.CancelBatch(ctx, externalID); cancelErr != nil {
+			s.logger.ErrorWithErr(cancelErr, "Failed to cancel unsaved batch")
+		}
+		return nil, err
+	}
+
+	s.logger.Infof("Submitted %s batch %d (%s) with %d items to %s/%s",
+		batch.Source, batch.ID, externalID, len(items), client.GetProviderName(), client.GetModelName())
+
+	return batch, nil
+}
+
+func (s *service) GetBatch(ctx context.Context, id int64) (*entities.Batch, error) {
+	batch, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get batch")
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (s *service) ListBatches(ctx context.Context, siteID int64) ([]*entities.Batch, error) {
+	batches, err := s.repo.GetBySite(ctx, siteID)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to list batches")
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+func (s *service) GetBatchItems(ctx context.Context, id int64) ([]*entities.BatchItem, error) {
+	items, err := s.repo.GetItems(ctx, id)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get batch items")
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// CancelBatch asks the provider to stop the batch. Requests it already finished
+// are still published by the next poll.
+func (s *service) CancelBatch(ctx context.Context, id int64) error {
+	batch, err := s.GetBatch(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if batch.Status != entities.BatchStatusSubmitted {
+		return errors.Validation("Batch is already finished")
+	}
+
+	provider, err := s.providerSvc.GetProvider(ctx, batch.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	_, batchClient, _, err := s.batchClient(provider, batch.KeyFingerprint)
+	if err != nil {
+		return err
+	}
+
+	if err = batchClient.CancelBatch(ctx, batch.ExternalID); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to cancel batch")
+		return err
+	}
+
+	s.logger.Infof("Cancellation of batch %d requested", batch.ID)
+	return nil
+}
+
+func (s *service) Poll(ctx context.Context) error {
+	s.pollMu.Lock()
+	defer s.pollMu.Unlock()
+
+	batches, err := s.repo.GetByStatus(ctx, entities.BatchStatusSubmitted)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get submitted batches")
+		return err
+	}
+
+	// A failing batch must not hold back the others
+	for _, batch := range batches {
+		if err = s.pollBatch(ctx, batch); err != nil {
+			s.logger.ErrorWithErr(err, fmt.Sprintf("Failed to poll batch %d", batch.ID))
+		}
+	}
+
+	return nil
+}
+
+func (s *service) pollBatch(ctx context.Context, batch *entities.Batch) error {
+	handler := s.handler(batch.Source)
+	if handler == nil {
+		return fmt.Errorf("no result handler for %s batches", batch.Source)
+	}
+
+	provider, err := s.providerSvc.GetProvider(ctx, batch.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	client, batchClient, _, err := s.batchClient(provider, batch.KeyFingerprint)
+	if err != nil {
+		return err
+	}
+
+	state, err := batchClient.GetBatch(ctx, batch.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	if !state.Status.Finished() {
+		s.logger.Debugf("Batch %d in progress: %d/%d done", batch.ID, state.Succeeded+state.Failed, state.Total)
+		return nil
+	}
+
+	items, err := s.repo.GetItems(ctx, batch.ID)
+	if err != nil {
+		return err
+	}
+
+	// The app stopped while publishing the results, some of them may be on the site
+	// already. The items left pending are failed rather than published twice, the
+	// handler only records their failure.
+	if batch.PublishingAt != nil {
+		s.logger.Warnf("Publishing of batch %d was interrupted, failing its unpublished items", batch.ID)
+		itemResults := interruptedResults(items)
+		handler(ctx, batch, itemResults)
+		return s.complete(ctx, batch, state, itemResults)
+	}
+
+	var results []*ai.BatchItemResult
+	if state.Status != ai.BatchStatusFailed {
+		if results, err = batchClient.GetBatchResults(ctx, batch.ExternalID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	batch.PublishingAt = &now
+	if err = s.repo.Update(ctx, batch); err != nil {
+		return err
+	}
+
+	itemResults := s.collectResults(ctx, batch, client, state, items, results)
+	handler(ctx, batch, itemResults)
+
+	return s.complete(ctx, batch, state, itemResults)
+}
+
+// interruptedResults fails the items whose publishing state was lost
+func interruptedResults(items []*entities.BatchItem) []*ItemResult {
+	var results []*ItemResult
+	for _, item := range items {
+		if item.Status == entities.BatchItemStatusPending {
+			results = append(results, &ItemResult{
+				Item: item,
+				Err:  fmt.Errorf("publishing was interrupted, the article may already be on the site"),
+			})
+		}
+	}
+	return results
+}
+
+// collectResults pairs the pending items with their results and logs the usage of
+// each. Items the provider returned no result for fail with the batch status.
+func (s *service) collectResults(
+	ctx context.Context,
+	batch *entities.Batch,
+	client ai.Client,
+	state *ai.BatchState,
+	items []*entities.BatchItem,
+	results []*ai.BatchItemResult,
+) []*ItemResult {
+	resultsByID := make(map[string]*ai.BatchItemResult, len(results))
+	for _, result := range results {
+		resultsByID[result.CustomID] = result
+	}
+
+	var itemResults []*ItemResult
+	for _, item := range items {
+		if item.Status != entities.BatchItemStatusPending {
+			continue
+		}
+
+		result, ok := resultsByID[item.CustomID]
+		if !ok {
+			reason := fmt.Sprintf("batch %s before the request was processed", state.Status)
+			if state.Error != "" {
+				reason = state.Error
+			}
+			itemResults = append(itemResults, &ItemResult{Item: item, Err: errors.AI(client.GetProviderName(), fmt.Errorf("%s", reason))})
+			continue
+		}
+
+		s.logUsage(ctx, batch, client, item, result)
+		itemResults = append(itemResults, &ItemResult{Item: item, Article: result.Article, Err: result.Err})
+	}
+
+	return itemResults
+}
+
+func (s *service) logUsage(ctx context.Context, batch *entities.Batch, client ai.Client, item *entities.BatchItem, result *ai.BatchItemResult) {
+	if s.aiUsageSvc == nil {
+		return
+	}
+
+	operation := aiusage.OperationArticleGeneration
+	if batch.Source == entities.BatchSourceSitemap {
+		operation = aiusage.OperationPageGeneration
+	}
+
+	var usage ai.Usage
+	if result.Article != nil {
+		usage = result.Article.Usage
+	}
+
+	// Batched requests queue at the provider, their duration says nothing about the model
+	_ = s.aiUsageSvc.LogFromResult(
+		ctx,
+		batch.SiteID,
+		batch.ProviderID,
+		operation,
+		client,
+		usage,
+		0,
+		result.Err,
+		map[string]interface{}{
+			"batch_id":  batch.ID,
+			"custom_id": item.CustomID,
+			"entity_id": item.EntityID,
+		},
+	)
+}
+
+// complete stores the outcome of every item the handler processed and closes the batch
+func (s *service) complete(ctx context.Context, batch *entities.Batch, state *ai.BatchState, results []*ItemResult) error {
+	for _, result := range results {
+		item := result.Item
+		item.Status = entities.BatchItemStatusCompleted
+		if result.Err != nil {
+			errStr := result.Err.Error()
+			item.Status = entities.BatchItemStatusFailed
+			item.Error = &errStr
+		}
+
+		if err := s.repo.UpdateItem(ctx, item); err != nil {
+			s.logger.ErrorWithErr(err, fmt.Sprintf("Failed to update batch item %d", item.ID))
+		}
+
+		if item.Status == entities.BatchItemStatusCompleted {
+			batch.CompletedItems++
+		} else {
+			batch.FailedItems++
+		}
+	}
+
+	switch state.Status {
+	case ai.BatchStatusCancelled:
+		batch.Status = entities.BatchStatusCancelled
+	case ai.BatchStatusFailed:
+		batch.Status = entities.BatchStatusFailed
+	default:
+		batch.Status = entities.BatchStatusCompleted
+	}
+
+	if state.Error != "" {
+		batch.Error = &state.Error
+	} else if state.Status == ai.BatchStatusExpired {
+		reason := "Batch expired before all requests were processed"
+		batch.Error = &reason
+	}
+
+	now := time.Now()
+	batch.CompletedAt = &now
+
+	if err := s.repo.Update(ctx, batch); err != nil {
+		return err
+	}
+
+	s.logger.Infof("Batch %d %s: %d completed, %d failed",
+		batch.ID, batch.Status, batch.CompletedItems, batch.FailedItems)
+
+	return nil
+}
+
+func (s *service) handler(source entities.BatchSource) ResultHandler {
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	return s.handlers[source]
+}
+
+// batchClient returns the client of the provider together with its batch API bound
+// to the API key of the fingerprint, and the fingerprint of that key. An empty
+// fingerprint picks the key for a new batch.
+func (s *service) batchClient(provider *entities.Provider, keyFingerprint string) (ai.Client, ai.BatchClient, string, error) {
+	if !ai.SupportsBatch(provider.Type) {
+		return nil, nil, "", errors.Validation(fmt.Sprintf("Provider %s has no batch API", provider.Name))
+	}
+
+	client, err := s.newClient(provider)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	batchClient, fingerprint, ok := ai.AsBatchClient(client, keyFingerprint)
+	switch {
+	case !ok && keyFingerprint != "":
+		return nil, nil, "", errors.Validation(fmt.Sprintf("The API key %s the batch was submitted with was removed from provider %s", keyFingerprint, provider.Name))
+	case !ok:
+		return nil, nil, "", errors.Validation(fmt.Sprintf("Provider %s has no batch API", provider.Name))
+	}
+
+	return client, batchClient, fingerprint, nil
+}
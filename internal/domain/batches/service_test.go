@@ -0,0 +1,356 @@
+package batches
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+type stubProviders struct {
+	providers.Service
+	provider *entities.Provider
+}
+
+func (p *stubProviders) GetProvider(context.Context, int64) (*entities.Provider, error) {
+	return p.provider, nil
+}
+
+// roomyBudgets admits requests whose cost fits in the room left by the budgets
+type roomyBudgets struct {
+	budgets.Service
+	room    float64
+	checked []float64
+}
+
+func (b *roomyBudgets) Check(context.Context, int64, int64) error {
+	return nil
+}
+
+func (b *roomyBudgets) CheckCost(_ context.Context, _, _ int64, costUSD float64) error {
+	b.checked = append(b.checked, costUSD)
+	if costUSD > b.room {
+		return errors.BudgetExceeded("Global daily", 1-b.room, 1)
+	}
+	return nil
+}
+
+// keyedMock is the mock client of one API key, it records the key batches are read with
+type keyedMock struct {
+	*ai.MockClient
+	key   string
+	reads *[]string
+}
+
+func (c *keyedMock) GetBatch(ctx context.Context, batchID string) (*ai.BatchState, error) {
+	*c.reads = append(*c.reads, c.key)
+	return c.MockClient.GetBatch(ctx, batchID)
+}
+
+type serviceFixture struct {
+	repo       Repository
+	aiUsageSvc aiusage.Service
+	siteID     int64
+	providers  *stubProviders
+	budgets    budgets.Service
+	log        *logger.Logger
+	// keys are the API keys of the provider in rotation order
+	keys  []string
+	reads []string
+}
+
+func newServiceFixture(t *testing.T) *serviceFixture {
+	t.Helper()
+
+	db, log := database.SetupTestEnv(t)
+
+	// Batches reference sites and providers by foreign key
+	siteID := database.SeedTestSite(t, db, "https://example.com")
+	providerID := database.SeedTestProvider(t, db, "Offline")
+
+	return &serviceFixture{
+		repo:       NewRepository(db, log),
+		aiUsageSvc: aiusage.NewService(aiusage.NewRepository(db), log),
+		siteID:     siteID,
+		providers: &stubProviders{provider: &entities.Provider{
+			ID: providerID, Name: "Offline", Type: entities.TypeMock, Model: ai.MockModelSynthetic, IsActive: true,
+		}},
+		log:  log,
+		keys: []string{"sk-first", "sk-second"},
+	}
+}
+
+// newService starts a service on the fixture's database, as the app does on every launch
+func (f *serviceFixture) newService(t *testing.T, handler ResultHandler) Service {
+	t.Helper()
+
+	svc := NewService(f.repo, f.providers, f.aiUsageSvc, f.budgets, f.log).(*service)
+	svc.newClient = func(*entities.Provider) (ai.Client, error) {
+		rotating := ai.NewRotatingClient(ai.NewKeyPool())
+		for _, key := range f.keys {
+			mock, err := ai.NewMockClient(ai.MockConfig{Model: ai.MockModelSynthetic})
+			if err != nil {
+				return nil, err
+			}
+			rotating.AddKey(key, &keyedMock{MockClient: mock, key: key, reads: &f.reads})
+		}
+		return rotating, nil
+	}
+	svc.RegisterHandler(entities.BatchSourceJob, handler)
+	return svc
+}
+
+func (f *serviceFixture) submit(t *testing.T, svc Service) *entities.Batch {
+	t.Helper()
+
+	batch, err := svc.Submit(context.Background(), &SubmitRequest{
+		SiteID:     f.siteID,
+		ProviderID: f.providers.provider.ID,
+		Source:     entities.BatchSourceJob,
+		SourceID:   9,
+		Items: []*SubmitItem{
+			{EntityID: 11, SystemPrompt: "system", UserPrompt: "first topic"},
+			{EntityID: 12, SystemPrompt: "system", UserPrompt: "second topic"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	return batch
+}
+
+func TestSubmitAndPollWithTheSubmittingKey(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	var delivered [][]*ItemResult
+	svc := f.newService(t, func(_ context.Context, _ *entities.Batch, results []*ItemResult) {
+		delivered = append(delivered, results)
+		for _, result := range results {
+			if result.Item.EntityID == 12 {
+				result.Err = context.DeadlineExceeded
+			}
+		}
+	})
+
+	batch := f.submit(t, svc)
+
+	stored, err := svc.GetBatch(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatch: %v", err)
+	}
+	if stored.KeyFingerprint != ai.KeyFingerprint("sk-first") {
+		t.Fatalf("expected the batch to keep the key it was submitted with, got %q", stored.KeyFingerprint)
+	}
+	if stored.Status != entities.BatchStatusSubmitted || stored.TotalItems != 2 {
+		t.Fatalf("unexpected stored batch %+v", stored)
+	}
+
+	// The rotation changed since, the batch must still be read with its own key
+	f.keys = []string{"sk-second", "sk-first"}
+
+	if err = svc.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	if len(f.reads) != 1 || f.reads[0] != "sk-first" {
+		t.Errorf("expected the batch to be read with sk-first, got %v", f.reads)
+	}
+	if len(delivered) != 1 || len(delivered[0]) != 2 {
+		t.Fatalf("expected both results handed to the handler once, got %v", delivered)
+	}
+	for _, result := range delivered[0] {
+		if result.Article == nil {
+			t.Errorf("expected an article for item %d", result.Item.EntityID)
+		}
+	}
+
+	completed, err := svc.GetBatch(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatch: %v", err)
+	}
+	if completed.Status != entities.BatchStatusCompleted || completed.CompletedItems != 1 || completed.FailedItems != 1 {
+		t.Errorf("unexpected completed batch %+v", completed)
+	}
+
+	items, err := svc.GetBatchItems(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatchItems: %v", err)
+	}
+	for _, item := range items {
+		want := entities.BatchItemStatusCompleted
+		if item.EntityID == 12 {
+			want = entities.BatchItemStatusFailed
+		}
+		if item.Status != want {
+			t.Errorf("expected item %d %s, got %s", item.EntityID, want, item.Status)
+		}
+	}
+
+	// A finished batch is not polled again
+	if err = svc.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(delivered) != 1 {
+		t.Errorf("expected the results to be delivered once, got %d deliveries", len(delivered))
+	}
+}
+
+func TestPollDoesNotPublishTwiceAfterInterruptedPublishing(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	published := make(map[int64]int)
+	publish := func(results []*ItemResult) {
+		for _, result := range results {
+			if result.Err == nil {
+				published[result.Item.EntityID]++
+			}
+		}
+	}
+
+	// The app stops after publishing, before the items are saved
+	svc := f.newService(t, func(_ context.Context, _ *entities.Batch, results []*ItemResult) {
+		publish(results)
+		panic("app stopped")
+	})
+	batch := f.submit(t, svc)
+
+	func() {
+		defer func() { _ = recover() }()
+		_ = svc.Poll(ctx)
+	}()
+
+	if len(published) != 2 {
+		t.Fatalf("expected both items published before the stop, got %v", published)
+	}
+
+	var redelivered []*ItemResult
+	restarted := f.newService(t, func(_ context.Context, _ *entities.Batch, results []*ItemResult) {
+		redelivered = append(redelivered, results...)
+		publish(results)
+	})
+
+	if err := restarted.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	for entityID, count := range published {
+		if count != 1 {
+			t.Errorf("expected item %d published once, got %d", entityID, count)
+		}
+	}
+	if len(redelivered) != 2 {
+		t.Fatalf("expected the handler to record the interrupted items, got %d", len(redelivered))
+	}
+	for _, result := range redelivered {
+		if result.Err == nil || result.Article != nil {
+			t.Errorf("expected item %d to be failed without its article, got %+v", result.Item.EntityID, result)
+		}
+	}
+
+	completed, err := restarted.GetBatch(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatch: %v", err)
+	}
+	if completed.Status != entities.BatchStatusCompleted || completed.FailedItems != 2 {
+		t.Errorf("unexpected batch %+v", completed)
+	}
+
+	items, err := restarted.GetBatchItems(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatchItems: %v", err)
+	}
+	for _, item := range items {
+		if item.Status != entities.BatchItemStatusFailed || item.Error == nil || !strings.Contains(*item.Error, "interrupted") {
+			t.Errorf("expected item %d failed as interrupted, got %+v", item.EntityID, item)
+		}
+	}
+}
+
+func TestPollLeavesBatchOfRemovedKey(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	delivered := 0
+	svc := f.newService(t, func(context.Context, *entities.Batch, []*ItemResult) {
+		delivered++
+	})
+	batch := f.submit(t, svc)
+
+	f.keys = []string{"sk-second"}
+
+	if err := svc.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	if delivered != 0 || len(f.reads) != 0 {
+		t.Errorf("expected the batch not to be read with another key, got %v", f.reads)
+	}
+
+	stored, err := svc.GetBatch(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatch: %v", err)
+	}
+	if stored.Status != entities.BatchStatusSubmitted {
+		t.Errorf("expected the batch to stay submitted, got %s", stored.Status)
+	}
+}
+
+func TestSubmitChecksTheBudgetsForTheWholeBatch(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	// A priced model, the batch is checked at its discounted estimate
+	f.providers.provider.Type, f.providers.provider.Model = entities.TypeOpenAI, "gpt-4o-mini"
+
+	item := &SubmitItem{EntityID: 11, SystemPrompt: "system", UserPrompt: "first topic", Words: 1500}
+	single := ai.NewCostEstimate(entities.TypeOpenAI, "gpt-4o-mini")
+	single.AddArticle(item.SystemPrompt, item.UserPrompt, item.Words, nil)
+	articleCost := single.CostUSD * ai.BatchDiscount
+	if articleCost <= 0 {
+		t.Fatal("expected the model to be priced")
+	}
+
+	// The budgets have room for one article but not for three
+	budgetSvc := &roomyBudgets{room: articleCost * 1.5}
+	f.budgets = budgetSvc
+	svc := f.newService(t, func(context.Context, *entities.Batch, []*ItemResult) {})
+
+	request := func(count int) *SubmitRequest {
+		items := make([]*SubmitItem, count)
+		for i := range items {
+			copied := *item
+			copied.EntityID = int64(11 + i)
+			items[i] = &copied
+		}
+		return &SubmitRequest{SiteID: f.siteID, ProviderID: f.providers.provider.ID, Source: entities.BatchSourceJob, SourceID: 9, Items: items}
+	}
+
+	if _, err := svc.Submit(ctx, request(3)); !errors.IsBudgetExceeded(err) {
+		t.Fatalf("expected the batch to be rejected by the budget, got %v", err)
+	}
+	if got := budgetSvc.checked[0]; got < articleCost*2.99 || got > articleCost*3.01 {
+		t.Errorf("expected the budgets to be checked for 3 articles ($%.6f), got $%.6f", articleCost*3, got)
+	}
+
+	stored, err := svc.ListBatches(ctx, f.siteID)
+	if err != nil {
+		t.Fatalf("ListBatches: %v", err)
+	}
+	if len(stored) != 0 {
+		t.Fatalf("expected no batch to be submitted, got %d", len(stored))
+	}
+
+	if _, err = svc.Submit(ctx, request(1)); err != nil {
+		t.Fatalf("expected a single article to fit the budget, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package budgets
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+type Repository interface {
+	Create(ctx context.Context, budget *entities.Budget) error
+	GetByID(ctx context.Context, id int64) (*entities.Budget, error)
+	GetAll(ctx context.Context) ([]*entities.Budget, error)
+	GetActive(ctx context.Context) ([]*entities.Budget, error)
+	Update(ctx context.Context, budget *entities.Budget) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type Service interface {
+	CreateBudget(ctx context.Context, budget *entities.Budget) error
+	GetBudget(ctx context.Context, id int64) (*entities.Budget, error)
+	ListBudgets(ctx context.Context) ([]*entities.Budget, error)
+	UpdateBudget(ctx context.Context, budget *entities.Budget) error
+	DeleteBudget(ctx context.Context, id int64) error
+
+	// GetBudgetStatuses returns every budget with its spend in the current period
+	GetBudgetStatuses(ctx context.Context) ([]*entities.BudgetStatus, error)
+
+	// Check returns a BUDGET_EXCEEDED error if any active budget covering the site
+	// or provider would be used up in its current period by the request, counting
+	// the requests admitted and not logged yet. It must be called before every AI
+	// request, which it holds a share of the budget for.
+	Check(ctx context.Context, siteID, providerID int64) error
+
+	// CheckCost is Check for requests of a known expected cost, such as a batch of
+	// articles. The cost is held until its spend is logged or the batch window ends.
+	CheckCost(ctx context.Context, siteID, providerID int64, costUSD float64) error
+}
@@ -0,0 +1,206 @@
+package budgets
+
+import (
+	"context"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+
+	"github.com/Masterminds/squirrel"
+)
+
+var _ Repository = (*repository)(nil)
+
+var budgetColumns = []string{
+	"id",
+	"name",
+	"scope",
+	"site_id",
+	"provider_id",
+	"period",
+	"limit_usd",
+	"is_active",
+	"created_at",
+	"updated_at",
+}
+
+type repository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewRepository(db *database.DB, logger *logger.Logger) Repository {
+	return &repository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("budgets"),
+	}
+}
+
+func (r *repository) Create(ctx context.Context, budget *entities.Budget) error {
+	query, args := dbx.ST.
+		Insert("ai_budgets").
+		Columns("name", "scope", "site_id", "provider_id", "period", "limit_usd", "is_active").
+		Values(budget.Name, budget.Scope, budget.SiteID, budget.ProviderID, budget.Period, budget.LimitUSD, budget.IsActive).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	budget.ID = id
+	return nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Budget, error) {
+	query, args := dbx.ST.
+		Select(budgetColumns...).
+		From("ai_budgets").
+		Where(squirrel.Eq{"id": id}).
+		MustSql()
+
+	budget, err := scanBudget(r.db.QueryRowContext(ctx, query, args...))
+	switch {
+	case dbx.IsNoRows(err):
+		return nil, errors.NotFound("budget", id)
+	case err != nil:
+		return nil, errors.Database(err)
+	}
+
+	return budget, nil
+}
+
+func (r *repository) GetAll(ctx context.Context) ([]*entities.Budget, error) {
+	query, args := dbx.ST.
+		Select(budgetColumns...).
+		From("ai_budgets").
+		OrderBy("created_at DESC").
+		MustSql()
+
+	return r.query(ctx, query, args)
+}
+
+func (r *repository) GetActive(ctx context.Context) ([]*entities.Budget, error) {
+	query, args := dbx.ST.
+		Select(budgetColumns...).
+		From("ai_budgets").
+		Where(squirrel.Eq{"is_active": true}).
+		OrderBy("created_at DESC").
+		MustSql()
+
+	return r.query(ctx, query, args)
+}
+
+func (r *repository) Update(ctx context.Context, budget *entities.Budget) error {
+	query, args := dbx.ST.
+		Update("ai_budgets").
+		Set("name", budget.Name).
+		Set("scope", budget.Scope).
+		Set("site_id", budget.SiteID).
+		Set("provider_id", budget.ProviderID).
+		Set("period", budget.Period).
+		Set("limit_usd", budget.LimitUSD).
+		Set("is_active", budget.IsActive).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": budget.ID}).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("budget", budget.ID)
+	}
+
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	query, args := dbx.ST.
+		Delete("ai_budgets").
+		Where(squirrel.Eq{"id": id}).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("budget", id)
+	}
+
+	return nil
+}
+
+func (r *repository) query(ctx context.Context, query string, args []any) ([]*entities.Budget, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var budgets []*entities.Budget
+	for rows.Next() {
+		budget, scanErr := scanBudget(rows)
+		if scanErr != nil {
+			return nil, errors.Database(scanErr)
+		}
+		budgets = append(budgets, budget)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return budgets, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBudget(row scanner) (*entities.Budget, error) {
+	var budget entities.Budget
+	err := row.Scan(
+		&budget.ID,
+		&budget.Name,
+		&budget.Scope,
+		&budget.SiteID,
+		&budget.ProviderID,
+		&budget.Period,
+		&budget.LimitUSD,
+		&budget.IsActive,
+		&budget.CreatedAt,
+		&budget.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
@@ -0,0 +1,399 @@
+package budgets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/notification"
+	"github.com/davidmovas/postulator/internal/infra/notifyicon"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+const exceededNotificationTitle = "AI Budget Exceeded"
+
+// reservationWindow bounds how long the expected cost of an admitted call counts
+// against its budgets when no spend shows up for it, as for a call that failed
+const reservationWindow = 10 * time.Minute
+
+// batchReservationWindow is the completion window of a batch, its spend is logged
+// once the batch finishes
+const batchReservationWindow = 24 * time.Hour
+
+// reservation is the expected cost of a call admitted by Check and not logged yet
+type reservation struct {
+	cost float64
+	// base is the spend of the budget when the call was admitted
+	base  float64
+	until time.Time
+}
+
+var _ Service = (*service)(nil)
+
+type service struct {
+	repo       Repository
+	aiUsageSvc aiusage.Service
+	notifier   notification.Notifier
+	logger     *logger.Logger
+
+	mu sync.Mutex
+	// notified holds the period start each budget was last reported for,
+	// so a notification is shown once per budget and period
+	notified map[int64]time.Time
+
+	// checkMu makes checking and reserving one step, so concurrent calls see each other
+	checkMu sync.Mutex
+	// reserved holds the calls admitted against each budget, oldest first
+	reserved map[int64][]reservation
+}
+
+func NewService(repo Repository, aiUsageSvc aiusage.Service, logger *logger.Logger) Service {
+	return &service{
+		repo:       repo,
+		aiUsageSvc: aiUsageSvc,
+		notifier:   notification.NewWithConfig("Postulator", notifyicon.Icon()),
+		notified:   make(map[int64]time.Time),
+		reserved:   make(map[int64][]reservation),
+		logger: logger.
+			WithScope("service").
+			WithScope("budgets"),
+	}
+}
+
+func (s *service) CreateBudget(ctx context.Context, budget *entities.Budget) error {
+	if err := s.validateBudget(budget); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	budget.CreatedAt = now
+	budget.UpdatedAt = now
+
+	if err := s.repo.Create(ctx, budget); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to create budget")
+		return err
+	}
+
+	s.logger.Info("Budget created successfully")
+	return nil
+}
+
+func (s *service) GetBudget(ctx context.Context, id int64) (*entities.Budget, error) {
+	budget, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get budget")
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func (s *service) ListBudgets(ctx context.Context) ([]*entities.Budget, error) {
+	budgets, err := s.repo.GetAll(ctx)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to list budgets")
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+func (s *service) UpdateBudget(ctx context.Context, budget *entities.Budget) error {
+	if err := s.validateBudget(budget); err != nil {
+		return err
+	}
+
+	budget.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, budget); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to update budget")
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.notified, budget.ID)
+	s.mu.Unlock()
+
+	s.logger.Info("Budget updated successfully")
+	return nil
+}
+
+func (s *service) DeleteBudget(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to delete budget")
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.notified, id)
+	s.mu.Unlock()
+
+	s.checkMu.Lock()
+	delete(s.reserved, id)
+	s.checkMu.Unlock()
+
+	s.logger.Info("Budget deleted successfully")
+	return nil
+}
+
+func (s *service) GetBudgetStatuses(ctx context.Context) ([]*entities.BudgetStatus, error) {
+	budgets, err := s.repo.GetAll(ctx)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to list budgets")
+		return nil, err
+	}
+
+	statuses := make([]*entities.BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		spent, spentErr := s.spent(ctx, budget)
+		if spentErr != nil {
+			return nil, spentErr
+		}
+
+		statuses = append(statuses, &entities.BudgetStatus{
+			Budget:   budget,
+			SpentUSD: spent,
+			Exceeded: spent >= budget.LimitUSD,
+		})
+	}
+
+	return statuses, nil
+}
+
+func (s *service) Check(ctx context.Context, siteID, providerID int64) error {
+	return s.check(ctx, siteID, providerID, s.expectedCost, reservationWindow)
+}
+
+func (s *service) CheckCost(ctx context.Context, siteID, providerID int64, costUSD float64) error {
+	cost := func(context.Context, *entities.Budget) (float64, error) {
+		return costUSD, nil
+	}
+	return s.check(ctx, siteID, providerID, cost, batchReservationWindow)
+}
+
+// check admits a request whose cost against each budget is given by expectedCost,
+// and holds that cost against the budgets for at most window
+func (s *service) check(
+	ctx context.Context,
+	siteID, providerID int64,
+	expectedCost func(ctx context.Context, budget *entities.Budget) (float64, error),
+	window time.Duration,
+) error {
+	budgets, err := s.repo.GetActive(ctx)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to load budgets")
+		return err
+	}
+
+	s.checkMu.Lock()
+	defer s.checkMu.Unlock()
+
+	now := time.Now()
+	admitted := make(map[int64]reservation)
+
+	for _, budget := range budgets {
+		if !appliesTo(budget, siteID, providerID) {
+			continue
+		}
+
+		spent, spentErr := s.spent(ctx, budget)
+		if spentErr != nil {
+			return spentErr
+		}
+
+		expected, expectedErr := expectedCost(ctx, budget)
+		if expectedErr != nil {
+			return expectedErr
+		}
+
+		// The calls admitted and not logged yet, and this one, must fit as well
+		pending := s.pendingLocked(budget.ID, spent, now)
+		if spent >= budget.LimitUSD || spent+pending+expected > budget.LimitUSD {
+			s.logger.Warnf("Budget %q exceeded: $%.4f of $%.2f, $%.4f pending, $%.4f expected (site=%d, provider=%d)",
+				budget.Name, spent, budget.LimitUSD, pending, expected, siteID, providerID)
+			s.notifyExceeded(ctx, budget, spent)
+			return errors.BudgetExceeded(budget.Name, spent, budget.LimitUSD).
+				WithContext("budget_id", budget.ID).
+				WithContext("period", string(budget.Period)).
+				WithContext("pending_usd", pending).
+				WithContext("expected_usd", expected)
+		}
+
+		if expected > 0 {
+			admitted[budget.ID] = reservation{cost: expected, base: spent, until: now.Add(window)}
+		}
+	}
+
+	for budgetID, r := range admitted {
+		s.reserved[budgetID] = append(s.reserved[budgetID], r)
+	}
+
+	return nil
+}
+
+// pendingLocked returns the expected cost of the calls admitted against the budget
+// whose spend has not shown up yet. The spend logged since the oldest call was
+// admitted settles the calls in the order they were admitted.
+func (s *service) pendingLocked(budgetID int64, spent float64, now time.Time) float64 {
+	reservations := s.reserved[budgetID]
+	if len(reservations) == 0 {
+		return 0
+	}
+
+	logged := spent - reservations[0].base
+	kept := reservations[:0]
+	var pending float64
+	for _, r := range reservations {
+		if logged >= r.cost {
+			logged -= r.cost
+			continue
+		}
+		if now.After(r.until) {
+			continue
+		}
+		kept = append(kept, r)
+		pending += r.cost
+	}
+
+	if len(kept) == 0 {
+		delete(s.reserved, budgetID)
+	} else {
+		s.reserved[budgetID] = kept
+	}
+	return pending
+}
+
+// expectedCost returns the cost to expect from the next call counted against the
+// budget: the average call in its current period, zero before the first one
+func (s *service) expectedCost(ctx context.Context, budget *entities.Budget) (float64, error) {
+	timeRange := periodRange(budget.Period, time.Now())
+	siteID, providerID := budgetFilter(budget)
+
+	expected, err := s.aiUsageSvc.GetAverageCost(ctx, siteID, providerID, &timeRange)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get average AI call cost")
+		return 0, errors.Database(err)
+	}
+
+	return expected, nil
+}
+
+// spent returns the usage counted against the budget in its current period
+func (s *service) spent(ctx context.Context, budget *entities.Budget) (float64, error) {
+	timeRange := periodRange(budget.Period, time.Now())
+	siteID, providerID := budgetFilter(budget)
+
+	spent, err := s.aiUsageSvc.GetTotalCost(ctx, siteID, providerID, &timeRange)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get AI spend")
+		return 0, errors.Database(err)
+	}
+
+	return spent, nil
+}
+
+// budgetFilter returns the site and provider the spend of the budget is counted for
+func budgetFilter(budget *entities.Budget) (siteID, providerID *int64) {
+	switch budget.Scope {
+	case entities.BudgetScopeSite:
+		siteID = budget.SiteID
+	case entities.BudgetScopeProvider:
+		providerID = budget.ProviderID
+	}
+	return siteID, providerID
+}
+
+func (s *service) notifyExceeded(ctx context.Context, budget *entities.Budget, spent float64) {
+	periodStart := periodRange(budget.Period, time.Now()).Start
+
+	s.mu.Lock()
+	if last, ok := s.notified[budget.ID]; ok && last.Equal(periodStart) {
+		s.mu.Unlock()
+		return
+	}
+	s.notified[budget.ID] = periodStart
+	s.mu.Unlock()
+
+	message := fmt.Sprintf("%s: $%.2f of $%.2f %s budget spent. AI generation is paused until the next period or until the limit is raised.",
+		budget.Name, spent, budget.LimitUSD, periodLabel(budget.Period))
+
+	opts := &notification.Options{
+		Title:     exceededNotificationTitle,
+		Message:   message,
+		WithSound: true,
+	}
+
+	if err := s.notifier.Notify(ctx, opts); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to send budget notification")
+	}
+}
+
+func (s *service) validateBudget(budget *entities.Budget) error {
+	budget.Name = strings.TrimSpace(budget.Name)
+	if budget.Name == "" {
+		return errors.Validation("Budget name is required")
+	}
+
+	if budget.LimitUSD <= 0 {
+		return errors.Validation("Budget limit must be greater than zero")
+	}
+
+	switch budget.Period {
+	case entities.BudgetPeriodDay, entities.BudgetPeriodMonth:
+	default:
+		return errors.Validation("Budget period must be day or month")
+	}
+
+	switch budget.Scope {
+	case entities.BudgetScopeGlobal:
+		budget.SiteID, budget.ProviderID = nil, nil
+	case entities.BudgetScopeSite:
+		if budget.SiteID == nil || *budget.SiteID <= 0 {
+			return errors.Validation("Site is required for a site budget")
+		}
+		budget.ProviderID = nil
+	case entities.BudgetScopeProvider:
+		if budget.ProviderID == nil || *budget.ProviderID <= 0 {
+			return errors.Validation("Provider is required for a provider budget")
+		}
+		budget.SiteID = nil
+	default:
+		return errors.Validation("Budget scope must be global, site or provider")
+	}
+
+	return nil
+}
+
+func appliesTo(budget *entities.Budget, siteID, providerID int64) bool {
+	switch budget.Scope {
+	case entities.BudgetScopeGlobal:
+		return true
+	case entities.BudgetScopeSite:
+		return budget.SiteID != nil && *budget.SiteID == siteID
+	case entities.BudgetScopeProvider:
+		return budget.ProviderID != nil && *budget.ProviderID == providerID
+	default:
+		return false
+	}
+}
+
+// periodRange returns the calendar day or month containing now
+func periodRange(period entities.BudgetPeriod, now time.Time) aiusage.TimeRange {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if period == entities.BudgetPeriodMonth {
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	}
+	return aiusage.TimeRange{Start: start, End: now}
+}
+
+func periodLabel(period entities.BudgetPeriod) string {
+	if period == entities.BudgetPeriodMonth {
+		return "monthly"
+	}
+	return "daily"
+}
@@ -0,0 +1,276 @@
+package budgets
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/internal/infra/notification"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+type recordingNotifier struct {
+	notification.Notifier
+	sent []*notification.Options
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, opts *notification.Options) error {
+	n.sent = append(n.sent, opts)
+	return nil
+}
+
+func newTestService(t *testing.T) (*service, aiusage.Service, *recordingNotifier) {
+	t.Helper()
+
+	db, log := database.SetupTestEnv(t)
+
+	// Budgets reference sites and providers by foreign key
+	for _, stmt := range []string{
+		`INSERT INTO sites (id, name, url, wp_username, wp_password) VALUES (1, 'One', 'https://one.test', 'u', 'p')`,
+		`INSERT INTO sites (id, name, url, wp_username, wp_password) VALUES (5, 'Five', 'https://five.test', 'u', 'p')`,
+		`INSERT INTO ai_providers (id, name, provider, model, api_key) VALUES (2, 'Main', 'openai', 'gpt-4o-mini', 'k')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to seed: %v", err)
+		}
+	}
+
+	aiUsageSvc := aiusage.NewService(aiusage.NewRepository(db), log)
+	notifier := &recordingNotifier{}
+
+	svc := NewService(NewRepository(db, log), aiUsageSvc, log).(*service)
+	svc.notifier = notifier
+
+	return svc, aiUsageSvc, notifier
+}
+
+func logSpend(t *testing.T, aiUsageSvc aiusage.Service, siteID, providerID int64, cost float64, at time.Time) {
+	t.Helper()
+
+	err := aiUsageSvc.LogUsage(context.Background(), &aiusage.UsageLog{
+		SiteID:        siteID,
+		ProviderID:    providerID,
+		OperationType: aiusage.OperationArticleGeneration,
+		ProviderName:  "openai",
+		ModelName:     "gpt-4o-mini",
+		CostUSD:       cost,
+		Success:       true,
+		CreatedAt:     at,
+	})
+	if err != nil {
+		t.Fatalf("LogUsage: %v", err)
+	}
+}
+
+func TestCheckEnforcesScopedBudgets(t *testing.T) {
+	svc, aiUsageSvc, notifier := newTestService(t)
+	ctx := context.Background()
+
+	siteID, providerID := int64(1), int64(2)
+	budget := &entities.Budget{
+		Name:       "Site daily",
+		Scope:      entities.BudgetScopeSite,
+		SiteID:     &siteID,
+		ProviderID: &providerID, // dropped for site budgets
+		Period:     entities.BudgetPeriodDay,
+		LimitUSD:   1,
+		IsActive:   true,
+	}
+	if err := svc.CreateBudget(ctx, budget); err != nil {
+		t.Fatalf("CreateBudget: %v", err)
+	}
+	if budget.ProviderID != nil {
+		t.Error("expected provider to be cleared for a site budget")
+	}
+
+	// Spend from yesterday and from other sites does not count
+	logSpend(t, aiUsageSvc, siteID, providerID, 5, time.Now().AddDate(0, 0, -1))
+	logSpend(t, aiUsageSvc, 99, providerID, 5, time.Now())
+	logSpend(t, aiUsageSvc, siteID, providerID, 0.3, time.Now())
+	logSpend(t, aiUsageSvc, siteID, providerID, 0.3, time.Now())
+
+	if err := svc.Check(ctx, siteID, providerID); err != nil {
+		t.Fatalf("expected budget to allow spend, got %v", err)
+	}
+
+	logSpend(t, aiUsageSvc, siteID, providerID, 0.4, time.Now())
+
+	err := svc.Check(ctx, siteID, providerID)
+	if !errors.IsBudgetExceeded(err) {
+		t.Fatalf("expected budget exceeded error, got %v", err)
+	}
+	if err = svc.Check(ctx, 99, providerID); err != nil {
+		t.Errorf("expected other sites to be unaffected, got %v", err)
+	}
+
+	// Repeated checks within the same period notify once
+	_ = svc.Check(ctx, siteID, providerID)
+	if len(notifier.sent) != 1 {
+		t.Errorf("expected one notification, got %d", len(notifier.sent))
+	}
+
+	statuses, err := svc.GetBudgetStatuses(ctx)
+	if err != nil {
+		t.Fatalf("GetBudgetStatuses: %v", err)
+	}
+	if len(statuses) != 1 || !statuses[0].Exceeded || statuses[0].SpentUSD < 0.99 {
+		t.Errorf("unexpected statuses: %+v", statuses[0])
+	}
+}
+
+func TestCheckIgnoresInactiveBudgets(t *testing.T) {
+	svc, aiUsageSvc, _ := newTestService(t)
+	ctx := context.Background()
+
+	providerID := int64(2)
+	budget := &entities.Budget{
+		Name:       "Provider monthly",
+		Scope:      entities.BudgetScopeProvider,
+		ProviderID: &providerID,
+		Period:     entities.BudgetPeriodMonth,
+		LimitUSD:   1,
+		IsActive:   true,
+	}
+	if err := svc.CreateBudget(ctx, budget); err != nil {
+		t.Fatalf("CreateBudget: %v", err)
+	}
+
+	logSpend(t, aiUsageSvc, 1, providerID, 2, time.Now())
+
+	if err := svc.Check(ctx, 5, providerID); !errors.IsBudgetExceeded(err) {
+		t.Fatalf("expected provider budget to apply to any site, got %v", err)
+	}
+	if err := svc.Check(ctx, 5, 3); err != nil {
+		t.Errorf("expected other providers to be unaffected, got %v", err)
+	}
+
+	budget.IsActive = false
+	if err := svc.UpdateBudget(ctx, budget); err != nil {
+		t.Fatalf("UpdateBudget: %v", err)
+	}
+	if err := svc.Check(ctx, 5, providerID); err != nil {
+		t.Errorf("expected inactive budget to be ignored, got %v", err)
+	}
+}
+
+func TestCheckBlocksCallExpectedToCrossLimit(t *testing.T) {
+	svc, aiUsageSvc, _ := newTestService(t)
+	ctx := context.Background()
+
+	budget := &entities.Budget{
+		Name:     "Global daily",
+		Scope:    entities.BudgetScopeGlobal,
+		Period:   entities.BudgetPeriodDay,
+		LimitUSD: 1,
+		IsActive: true,
+	}
+	if err := svc.CreateBudget(ctx, budget); err != nil {
+		t.Fatalf("CreateBudget: %v", err)
+	}
+
+	// $0.80 spent, the next call should cost about $0.40 like the previous ones
+	logSpend(t, aiUsageSvc, 1, 2, 0.4, time.Now())
+	logSpend(t, aiUsageSvc, 1, 2, 0.4, time.Now())
+
+	if err := svc.Check(ctx, 1, 2); !errors.IsBudgetExceeded(err) {
+		t.Fatalf("expected the call crossing the limit to be blocked, got %v", err)
+	}
+}
+
+func TestCheckCountsConcurrentCalls(t *testing.T) {
+	svc, aiUsageSvc, _ := newTestService(t)
+	ctx := context.Background()
+
+	budget := &entities.Budget{
+		Name:     "Global daily",
+		Scope:    entities.BudgetScopeGlobal,
+		Period:   entities.BudgetPeriodDay,
+		LimitUSD: 1,
+		IsActive: true,
+	}
+	if err := svc.CreateBudget(ctx, budget); err != nil {
+		t.Fatalf("CreateBudget: %v", err)
+	}
+
+	// $0.20 spent at $0.20 a call leaves room for 4 more calls
+	logSpend(t, aiUsageSvc, 1, 2, 0.2, time.Now())
+
+	var wg sync.WaitGroup
+	var admitted atomic.Int32
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := svc.Check(ctx, 1, 2); err == nil {
+				admitted.Add(1)
+			} else if !errors.IsBudgetExceeded(err) {
+				t.Errorf("Check: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := admitted.Load(); got != 4 {
+		t.Fatalf("expected 4 concurrent calls to be admitted, got %d", got)
+	}
+
+	// The spend of a call that ended settles its reservation, not the others
+	logSpend(t, aiUsageSvc, 1, 2, 0.2, time.Now())
+	if err := svc.Check(ctx, 1, 2); !errors.IsBudgetExceeded(err) {
+		t.Fatalf("expected the calls still running to hold the budget, got %v", err)
+	}
+}
+
+func TestCheckCostHoldsTheWholeBatch(t *testing.T) {
+	svc, aiUsageSvc, _ := newTestService(t)
+	ctx := context.Background()
+
+	budget := &entities.Budget{
+		Name:     "Global daily",
+		Scope:    entities.BudgetScopeGlobal,
+		Period:   entities.BudgetPeriodDay,
+		LimitUSD: 1,
+		IsActive: true,
+	}
+	if err := svc.CreateBudget(ctx, budget); err != nil {
+		t.Fatalf("CreateBudget: %v", err)
+	}
+
+	// $0.20 spent at $0.20 a call, a single article fits
+	logSpend(t, aiUsageSvc, 1, 2, 0.2, time.Now())
+
+	if err := svc.CheckCost(ctx, 1, 2, 0.9); !errors.IsBudgetExceeded(err) {
+		t.Fatalf("expected a batch crossing the limit to be blocked, got %v", err)
+	}
+
+	if err := svc.CheckCost(ctx, 1, 2, 0.7); err != nil {
+		t.Fatalf("expected a batch within the limit to be admitted, got %v", err)
+	}
+
+	// The admitted batch holds its cost until its spend is logged
+	if err := svc.Check(ctx, 1, 2); !errors.IsBudgetExceeded(err) {
+		t.Fatalf("expected the batch to hold the budget, got %v", err)
+	}
+}
+
+func TestCreateBudgetValidates(t *testing.T) {
+	svc, _, _ := newTestService(t)
+
+	tests := []*entities.Budget{
+		{Name: "", Scope: entities.BudgetScopeGlobal, Period: entities.BudgetPeriodDay, LimitUSD: 1},
+		{Name: "No limit", Scope: entities.BudgetScopeGlobal, Period: entities.BudgetPeriodDay},
+		{Name: "Bad period", Scope: entities.BudgetScopeGlobal, Period: "week", LimitUSD: 1},
+		{Name: "No site", Scope: entities.BudgetScopeSite, Period: entities.BudgetPeriodDay, LimitUSD: 1},
+	}
+
+	for _, budget := range tests {
+		if err := svc.CreateBudget(context.Background(), budget); err == nil {
+			t.Errorf("expected validation error for %+v", budget)
+		}
+	}
+}
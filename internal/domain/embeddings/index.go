@@ -0,0 +1,66 @@
+package embeddings
+
+import (
+	"sort"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+)
+
+// VectorIndex is an in-memory index of entity vectors searched by cosine similarity.
+// Indexes are built per operation from stored vectors, so a linear scan is enough.
+type VectorIndex struct {
+	entityType entities.EmbeddingEntity
+	ids        []int64
+	vectors    map[int64][]float32
+}
+
+func NewVectorIndex(entityType entities.EmbeddingEntity) *VectorIndex {
+	return &VectorIndex{
+		entityType: entityType,
+		vectors:    make(map[int64][]float32),
+	}
+}
+
+// Add stores the vector of an entity, replacing any previous one
+func (x *VectorIndex) Add(id int64, vector []float32) {
+	if _, ok := x.vectors[id]; !ok {
+		x.ids = append(x.ids, id)
+	}
+	x.vectors[id] = vector
+}
+
+func (x *VectorIndex) Vector(id int64) ([]float32, bool) {
+	vector, ok := x.vectors[id]
+	return vector, ok
+}
+
+func (x *VectorIndex) Len() int {
+	return len(x.ids)
+}
+
+// Similarity returns the cosine similarity of two indexed entities, 0 if either is missing
+func (x *VectorIndex) Similarity(a, b int64) float64 {
+	return ai.CosineSimilarity(x.vectors[a], x.vectors[b])
+}
+
+// Nearest returns up to limit entities closest to the vector, most similar first.
+// Entities for which skip returns true are left out. A limit <= 0 returns all entities.
+func (x *VectorIndex) Nearest(vector []float32, limit int, skip func(id int64) bool) []Match {
+	matches := make([]Match, 0, len(x.ids))
+	for _, id := range x.ids {
+		if skip != nil && skip(id) {
+			continue
+		}
+		matches = append(matches, Match{EntityType: x.entityType, EntityID: id, Similarity: ai.CosineSimilarity(vector, x.vectors[id])})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,57 @@
+package embeddings
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+// DefaultDuplicateThreshold is the similarity above which two topics are reported as duplicates
+const DefaultDuplicateThreshold = 0.9
+
+type Repository interface {
+	Upsert(ctx context.Context, embedding *entities.Embedding) error
+	// ListByEntities returns the stored vectors of the given entities for the model
+	ListByEntities(ctx context.Context, entityType entities.EmbeddingEntity, model string, entityIDs []int64) ([]*entities.Embedding, error)
+}
+
+// Document is the text of an entity to embed
+type Document struct {
+	EntityType entities.EmbeddingEntity
+	EntityID   int64
+	Text       string
+}
+
+// Match is an entity found by a similarity search
+type Match struct {
+	EntityType entities.EmbeddingEntity
+	EntityID   int64
+	Similarity float64
+}
+
+// DuplicateTopics is a pair of topics whose titles mean nearly the same thing
+type DuplicateTopics struct {
+	First      *entities.Topic
+	Second     *entities.Topic
+	Similarity float64
+}
+
+// RelatedArticle is an article close in meaning to another one
+type RelatedArticle struct {
+	Article    *entities.Article
+	Similarity float64
+}
+
+type Service interface {
+	// Index embeds the documents with the provider's embedding model and returns an
+	// index of their vectors. Documents whose text is unchanged since they were last
+	// indexed reuse the stored vector instead of calling the provider again.
+	Index(ctx context.Context, siteID, providerID int64, documents []Document) (*VectorIndex, error)
+
+	// FindDuplicateTopics returns the pairs of site topics with a similarity of at least threshold,
+	// most similar first. A zero threshold uses DefaultDuplicateThreshold.
+	FindDuplicateTopics(ctx context.Context, siteID, providerID int64, threshold float64) ([]*DuplicateTopics, error)
+
+	// FindRelatedArticles returns the articles of the same site closest in meaning to the article
+	FindRelatedArticles(ctx context.Context, providerID, articleID int64, limit int) ([]*RelatedArticle, error)
+}
@@ -0,0 +1,138 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+
+	"github.com/Masterminds/squirrel"
+)
+
+var _ Repository = (*repository)(nil)
+
+var embeddingColumns = []string{
+	"id",
+	"entity_type",
+	"entity_id",
+	"model",
+	"content_hash",
+	"vector",
+	"created_at",
+	"updated_at",
+}
+
+type repository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewRepository(db *database.DB, logger *logger.Logger) Repository {
+	return &repository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("embeddings"),
+	}
+}
+
+func (r *repository) Upsert(ctx context.Context, embedding *entities.Embedding) error {
+	query, args := dbx.ST.
+		Insert("embeddings").
+		Columns("entity_type", "entity_id", "model", "content_hash", "dimensions", "vector", "updated_at").
+		Values(
+			embedding.EntityType,
+			embedding.EntityID,
+			embedding.Model,
+			embedding.ContentHash,
+			len(embedding.Vector),
+			encodeVector(embedding.Vector),
+			time.Now(),
+		).
+		Suffix("ON CONFLICT(entity_type, entity_id, model) DO UPDATE SET content_hash = EXCLUDED.content_hash, dimensions = EXCLUDED.dimensions, vector = EXCLUDED.vector, updated_at = EXCLUDED.updated_at").
+		MustSql()
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return errors.Database(err)
+	}
+
+	return nil
+}
+
+func (r *repository) ListByEntities(ctx context.Context, entityType entities.EmbeddingEntity, model string, entityIDs []int64) ([]*entities.Embedding, error) {
+	if len(entityIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args := dbx.ST.
+		Select(embeddingColumns...).
+		From("embeddings").
+		Where(squirrel.Eq{"entity_type": entityType, "model": model, "entity_id": entityIDs}).
+		MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var result []*entities.Embedding
+	for rows.Next() {
+		var embedding entities.Embedding
+		var vector []byte
+		err = rows.Scan(
+			&embedding.ID,
+			&embedding.EntityType,
+			&embedding.EntityID,
+			&embedding.Model,
+			&embedding.ContentHash,
+			&vector,
+			&embedding.CreatedAt,
+			&embedding.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.Database(err)
+		}
+
+		if embedding.Vector, err = decodeVector(vector); err != nil {
+			return nil, errors.Database(err)
+		}
+		result = append(result, &embedding)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return result, nil
+}
+
+// encodeVector stores a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector size %d", len(buf))
+	}
+
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vector, nil
+}
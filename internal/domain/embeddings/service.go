@@ -0,0 +1,303 @@
+package embeddings
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/articles"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/domain/topics"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+// maxArticleTextChars keeps article texts well below the input limit of embedding models
+const maxArticleTextChars = 6000
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+var _ Service = (*service)(nil)
+
+type service struct {
+	repo        Repository
+	providerSvc providers.Service
+	topicSvc    topics.Service
+	articleSvc  articles.Service
+	aiUsageSvc  aiusage.Service
+	budgetSvc   budgets.Service
+	logger      *logger.Logger
+}
+
+func NewService(
+	repo Repository,
+	providerSvc providers.Service,
+	topicSvc topics.Service,
+	articleSvc articles.Service,
+	aiUsageSvc aiusage.Service,
+	budgetSvc budgets.Service,
+	logger *logger.Logger,
+) Service {
+	return &service{
+		repo:        repo,
+		providerSvc: providerSvc,
+		topicSvc:    topicSvc,
+		articleSvc:  articleSvc,
+		aiUsageSvc:  aiUsageSvc,
+		budgetSvc:   budgetSvc,
+		logger: logger.
+			WithScope("service").
+			WithScope("embeddings"),
+	}
+}
+
+func (s *service) Index(ctx context.Context, siteID, providerID int64, documents []Document) (*VectorIndex, error) {
+	if len(documents) == 0 {
+		return nil, errors.Validation("No documents to index")
+	}
+
+	entityType := documents[0].EntityType
+	ids := make([]int64, len(documents))
+	for i, doc := range documents {
+		if doc.EntityType != entityType {
+			return nil, errors.Validation("Documents of an index must be of the same entity type")
+		}
+		ids[i] = doc.EntityID
+	}
+
+	provider, err := s.providerSvc.GetProvider(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	if !ai.SupportsEmbeddings(provider.Type) {
+		return nil, errors.Validation(fmt.Sprintf("Provider %s does not support embeddings", provider.Name))
+	}
+	model := ai.EmbeddingModel(provider)
+
+	stored, err := s.repo.ListByEntities(ctx, entityType, model, ids)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to load stored embeddings")
+		return nil, err
+	}
+
+	storedByID := make(map[int64]*entities.Embedding, len(stored))
+	for _, embedding := range stored {
+		storedByID[embedding.EntityID] = embedding
+	}
+
+	index := NewVectorIndex(entityType)
+	var pending []Document
+	for _, doc := range documents {
+		if embedding, ok := storedByID[doc.EntityID]; ok && embedding.ContentHash == contentHash(doc.Text) {
+			index.Add(doc.EntityID, embedding.Vector)
+			continue
+		}
+		pending = append(pending, doc)
+	}
+
+	if len(pending) > 0 {
+		if err = s.embed(ctx, siteID, provider, model, pending, index); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.Debugf("Indexed %d %s documents, %d embedded", len(documents), entityType, len(pending))
+	return index, nil
+}
+
+// embed embeds the documents, stores their vectors and adds them to the index
+func (s *service) embed(ctx context.Context, siteID int64, provider *entities.Provider, model string, documents []Document, index *VectorIndex) error {
+	if s.budgetSvc != nil {
+		if err := s.budgetSvc.Check(ctx, siteID, provider.ID); err != nil {
+			return err
+		}
+	}
+
+	client, err := ai.CreateClient(provider)
+	if err != nil {
+		return err
+	}
+
+	texts := make([]string, len(documents))
+	for i, doc := range documents {
+		texts[i] = doc.Text
+	}
+
+	startTime := time.Now()
+	result, err := client.Embed(ctx, texts)
+	durationMs := time.Since(startTime).Milliseconds()
+
+	if s.aiUsageSvc != nil {
+		var usage ai.Usage
+		if result != nil {
+			usage = result.Usage
+		}
+		_ = s.aiUsageSvc.LogFromResult(
+			ctx,
+			siteID,
+			provider.ID,
+			aiusage.OperationEmbeddings,
+			client,
+			usage,
+			durationMs,
+			err,
+			map[string]interface{}{
+				"embedding_model": model,
+				"entity_type":     documents[0].EntityType,
+				"documents":       len(documents),
+			},
+		)
+	}
+
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to embed documents")
+		return err
+	}
+
+	if len(result.Vectors) != len(documents) {
+		return errors.Internal(fmt.Errorf("expected %d embeddings, got %d", len(documents), len(result.Vectors)))
+	}
+
+	for i, doc := range documents {
+		embedding := &entities.Embedding{
+			EntityType:  doc.EntityType,
+			EntityID:    doc.EntityID,
+			Model:       model,
+			ContentHash: contentHash(doc.Text),
+			Vector:      result.Vectors[i],
+		}
+		if err = s.repo.Upsert(ctx, embedding); err != nil {
+			s.logger.ErrorWithErr(err, "Failed to store embedding")
+			return err
+		}
+		index.Add(doc.EntityID, embedding.Vector)
+	}
+
+	return nil
+}
+
+func (s *service) FindDuplicateTopics(ctx context.Context, siteID, providerID int64, threshold float64) ([]*DuplicateTopics, error) {
+	if threshold <= 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+
+	siteTopics, err := s.topicSvc.GetSiteTopics(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	if len(siteTopics) < 2 {
+		return nil, nil
+	}
+
+	documents := make([]Document, len(siteTopics))
+	for i, topic := range siteTopics {
+		documents[i] = Document{EntityType: entities.EmbeddingTopic, EntityID: topic.ID, Text: topic.Title}
+	}
+
+	index, err := s.Index(ctx, siteID, providerID, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	var duplicates []*DuplicateTopics
+	for i := 0; i < len(siteTopics); i++ {
+		for j := i + 1; j < len(siteTopics); j++ {
+			similarity := index.Similarity(siteTopics[i].ID, siteTopics[j].ID)
+			if similarity >= threshold {
+				duplicates = append(duplicates, &DuplicateTopics{
+					First:      siteTopics[i],
+					Second:     siteTopics[j],
+					Similarity: similarity,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
+
+	s.logger.Infof("Found %d possible duplicate topic pairs for site %d", len(duplicates), siteID)
+	return duplicates, nil
+}
+
+func (s *service) FindRelatedArticles(ctx context.Context, providerID, articleID int64, limit int) ([]*RelatedArticle, error) {
+	article, err := s.articleSvc.GetArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	listed, err := s.articleSvc.ListArticles(ctx, &articles.ListFilter{SiteID: article.SiteID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Translations of the article are the same content, not related content
+	sameGroup := make(map[int64]bool)
+	articleMap := make(map[int64]*entities.Article, len(listed.Articles))
+	documents := make([]Document, 0, len(listed.Articles))
+	for _, a := range listed.Articles {
+		articleMap[a.ID] = a
+		if a.ID != article.ID && article.TranslationGroup != nil && a.TranslationGroup != nil &&
+			*a.TranslationGroup == *article.TranslationGroup {
+			sameGroup[a.ID] = true
+		}
+		documents = append(documents, Document{EntityType: entities.EmbeddingArticle, EntityID: a.ID, Text: articleText(a)})
+	}
+
+	index, err := s.Index(ctx, article.SiteID, providerID, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	vector, ok := index.Vector(article.ID)
+	if !ok {
+		return nil, errors.NotFound("article embedding", article.ID)
+	}
+
+	matches := index.Nearest(vector, limit, func(id int64) bool {
+		return id == article.ID || sameGroup[id]
+	})
+
+	related := make([]*RelatedArticle, len(matches))
+	for i, match := range matches {
+		related[i] = &RelatedArticle{Article: articleMap[match.EntityID], Similarity: match.Similarity}
+	}
+	return related, nil
+}
+
+// articleText is the text embedded for an article: title, excerpt and the beginning of the content
+func articleText(article *entities.Article) string {
+	var sb strings.Builder
+	sb.WriteString(article.Title)
+	if article.Excerpt != nil && *article.Excerpt != "" {
+		sb.WriteString("\n")
+		sb.WriteString(*article.Excerpt)
+	}
+
+	content := strings.Join(strings.Fields(htmlTagPattern.ReplaceAllString(article.Content, " ")), " ")
+	if content != "" {
+		sb.WriteString("\n")
+		sb.WriteString(content)
+	}
+
+	text := sb.String()
+	if len(text) > maxArticleTextChars {
+		text = strings.ToValidUTF8(text[:maxArticleTextChars], "")
+	}
+	return text
+}
+
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
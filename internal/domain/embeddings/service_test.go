@@ -0,0 +1,134 @@
+package embeddings
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/deletion"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/domain/topics"
+	"github.com/davidmovas/postulator/internal/infra/database"
+)
+
+type serviceFixture struct {
+	service    Service
+	topics     topics.Service
+	aiUsage    aiusage.Service
+	siteID     int64
+	providerID int64
+}
+
+// newServiceFixture stores a site and a synthetic mock provider in a fresh database
+func newServiceFixture(t *testing.T) *serviceFixture {
+	t.Helper()
+
+	db, log := database.SetupTestEnv(t)
+
+	deletionValidator := deletion.NewValidator(db)
+	providerSvc := providers.NewService(providers.NewRepository(db, log), providers.NewModelRepository(db, log), providers.NewKeyRepository(db, log), deletionValidator, log)
+	topicSvc := topics.NewService(
+		providerSvc,
+		nil,
+		topics.NewRepository(db, log),
+		topics.NewSiteTopicRepository(db, log),
+		topics.NewUsageRepository(db, log),
+		nil,
+		deletionValidator,
+		log,
+	)
+	aiUsageSvc := aiusage.NewService(aiusage.NewRepository(db), log)
+
+	return &serviceFixture{
+		service:    NewService(NewRepository(db, log), providerSvc, topicSvc, nil, aiUsageSvc, nil, log),
+		topics:     topicSvc,
+		aiUsage:    aiUsageSvc,
+		siteID:     database.SeedTestSite(t, db, "https://example.com"),
+		providerID: database.SeedTestProvider(t, db, "Offline"),
+	}
+}
+
+func (f *serviceFixture) embedCalls(t *testing.T) int {
+	t.Helper()
+
+	logs, err := f.aiUsage.GetLogs(context.Background(), &f.siteID, nil, 100, 0)
+	if err != nil {
+		t.Fatalf("GetLogs: %v", err)
+	}
+
+	calls := 0
+	for _, log := range logs.Items {
+		if log.OperationType == aiusage.OperationEmbeddings {
+			calls++
+		}
+	}
+	return calls
+}
+
+func TestIndexReusesStoredVectors(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	documents := []Document{
+		{EntityType: entities.EmbeddingSitemapNode, EntityID: 1, Text: "Hiking boots"},
+		{EntityType: entities.EmbeddingSitemapNode, EntityID: 2, Text: "Trail running shoes"},
+	}
+
+	index, err := f.service.Index(ctx, f.siteID, f.providerID, documents)
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if index.Len() != 2 {
+		t.Fatalf("expected 2 indexed documents, got %d", index.Len())
+	}
+
+	// Unchanged documents are served from the database
+	if _, err = f.service.Index(ctx, f.siteID, f.providerID, documents); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if calls := f.embedCalls(t); calls != 1 {
+		t.Fatalf("expected 1 embeddings call, got %d", calls)
+	}
+
+	documents[1].Text = "Waterproof hiking boots"
+	index, err = f.service.Index(ctx, f.siteID, f.providerID, documents)
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if calls := f.embedCalls(t); calls != 2 {
+		t.Fatalf("expected changed document to be embedded again, got %d calls", calls)
+	}
+	if index.Similarity(1, 2) <= 0 {
+		t.Error("expected updated vectors to share the word 'hiking'")
+	}
+}
+
+func TestFindDuplicateTopics(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	_, err := f.topics.CreateAndAssignToSite(ctx, f.siteID,
+		&entities.Topic{Title: "Best hiking trails in Colorado"},
+		&entities.Topic{Title: "Colorado: the best hiking trails"},
+		&entities.Topic{Title: "Baking sourdough bread at home"},
+	)
+	if err != nil {
+		t.Fatalf("CreateAndAssignToSite: %v", err)
+	}
+
+	duplicates, err := f.service.FindDuplicateTopics(ctx, f.siteID, f.providerID, 0.75)
+	if err != nil {
+		t.Fatalf("FindDuplicateTopics: %v", err)
+	}
+
+	if len(duplicates) != 1 {
+		t.Fatalf("expected 1 duplicate pair, got %d", len(duplicates))
+	}
+	titles := []string{duplicates[0].First.Title, duplicates[0].Second.Title}
+	for _, title := range titles {
+		if title == "Baking sourdough bread at home" {
+			t.Errorf("unrelated topic reported as duplicate: %v", titles)
+		}
+	}
+}
@@ -57,6 +57,10 @@ type Article struct {
 	MetaDescription  *string
 	Author           *int
 
+	// Translation fields
+	Language         string  // Language code of the content, empty when unknown
+	TranslationGroup *string // Shared by an article and its translations
+
 	// Page-specific fields
 	ContentType   ContentType
 	WPPageID      *int
@@ -0,0 +1,62 @@
+package entities
+
+import "time"
+
+// BatchSource is the feature that submitted a batch and publishes its results
+type BatchSource string
+
+const (
+	BatchSourceSitemap BatchSource = "sitemap"
+	BatchSourceJob     BatchSource = "job"
+)
+
+type BatchStatus string
+
+const (
+	BatchStatusSubmitted BatchStatus = "submitted"
+	BatchStatusCompleted BatchStatus = "completed"
+	BatchStatusFailed    BatchStatus = "failed"
+	BatchStatusCancelled BatchStatus = "cancelled"
+)
+
+type BatchItemStatus string
+
+const (
+	BatchItemStatusPending   BatchItemStatus = "pending"
+	BatchItemStatusCompleted BatchItemStatus = "completed"
+	BatchItemStatusFailed    BatchItemStatus = "failed"
+)
+
+// Batch is a group of article generations submitted to a provider's batch API.
+// The provider works on it asynchronously, results are collected by polling.
+type Batch struct {
+	ID             int64
+	SiteID         int64
+	ProviderID     int64
+	ExternalID     string // Batch ID at the provider
+	KeyFingerprint string // API key the batch was submitted with, it is read back with the same key
+	Source         BatchSource
+	SourceID       int64 // Sitemap or job ID
+	Status         BatchStatus
+	Settings       string // Source-specific publishing options (JSON)
+	TotalItems     int
+	CompletedItems int
+	FailedItems    int
+	Error          *string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	CompletedAt    *time.Time
+	PublishingAt   *time.Time // Set once the results are handed to the publisher
+}
+
+// BatchItem is one request of a batch
+type BatchItem struct {
+	ID        int64
+	BatchID   int64
+	CustomID  string
+	EntityID  int64 // Sitemap node or topic ID
+	Status    BatchItemStatus
+	ArticleID *int64
+	Error     *string
+	UpdatedAt time.Time
+}
@@ -0,0 +1,40 @@
+package entities
+
+import "time"
+
+type BudgetScope string
+
+const (
+	BudgetScopeGlobal   BudgetScope = "global"
+	BudgetScopeSite     BudgetScope = "site"
+	BudgetScopeProvider BudgetScope = "provider"
+)
+
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDay   BudgetPeriod = "day"
+	BudgetPeriodMonth BudgetPeriod = "month"
+)
+
+// Budget caps AI spend over a calendar day or month. Site and provider budgets
+// only count usage of their site or provider; global budgets count everything.
+type Budget struct {
+	ID         int64
+	Name       string
+	Scope      BudgetScope
+	SiteID     *int64
+	ProviderID *int64
+	Period     BudgetPeriod
+	LimitUSD   float64
+	IsActive   bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// BudgetStatus is a budget together with the spend of its current period
+type BudgetStatus struct {
+	Budget   *Budget
+	SpentUSD float64
+	Exceeded bool
+}
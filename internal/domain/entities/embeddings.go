@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+type EmbeddingEntity string
+
+const (
+	EmbeddingTopic       EmbeddingEntity = "topic"
+	EmbeddingArticle     EmbeddingEntity = "article"
+	EmbeddingSitemapNode EmbeddingEntity = "sitemap_node"
+)
+
+// Embedding is the vector of an entity's text produced by an embedding model
+type Embedding struct {
+	ID          int64
+	EntityType  EmbeddingEntity
+	EntityID    int64
+	Model       string
+	ContentHash string // Hash of the embedded text, used to skip unchanged entities
+	Vector      []float32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
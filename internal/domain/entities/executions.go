@@ -13,6 +13,9 @@ const (
 	ExecutionStatusPublished         ExecutionStatus = "published"
 	ExecutionStatusRejected          ExecutionStatus = "rejected"
 	ExecutionStatusFailed            ExecutionStatus = "failed"
+	// ExecutionStatusPausedBudget closes an execution stopped by an AI budget before
+	// anything was generated, it is not counted as a failed article
+	ExecutionStatusPausedBudget ExecutionStatus = "paused_budget"
 )
 
 type Execution struct {
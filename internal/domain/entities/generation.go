@@ -0,0 +1,129 @@
+package entities
+
+import (
+	"fmt"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+// GenerationParams tunes how the model samples article output and how the
+// article is produced. Nil fields fall back to the provider defaults.
+type GenerationParams struct {
+	Temperature     *float64       `json:"temperature,omitempty"`
+	TopP            *float64       `json:"topP,omitempty"`
+	MaxOutputTokens *int           `json:"maxOutputTokens,omitempty"`
+	Seed            *int64         `json:"seed,omitempty"` // Honored by OpenAI and OpenAI-compatible providers, ignored by the others
+	Stop            []string       `json:"stop,omitempty"`
+	Mode            GenerationMode `json:"mode,omitempty"`
+	// FeaturedImage generates a featured image from the title and summary of the article
+	FeaturedImage *bool `json:"featuredImage,omitempty"`
+}
+
+// GenerationMode selects how an article is produced
+type GenerationMode string
+
+const (
+	// GenerationModeSingle produces the whole article with a single call (the default)
+	GenerationModeSingle GenerationMode = "single"
+	// GenerationModeOutline first generates an outline, then each section with its own
+	// call. MaxOutputTokens applies per section, which allows long-form pillar pages.
+	GenerationModeOutline GenerationMode = "outline"
+)
+
+const maxStopSequences = 4
+
+func (p *GenerationParams) IsEmpty() bool {
+	return p == nil ||
+		p.Temperature == nil && p.TopP == nil && p.MaxOutputTokens == nil && p.Seed == nil &&
+			len(p.Stop) == 0 && p.Mode == "" && p.FeaturedImage == nil
+}
+
+// WantsFeaturedImage reports whether a featured image should be generated for the article
+func (p *GenerationParams) WantsFeaturedImage() bool {
+	return p != nil && p.FeaturedImage != nil && *p.FeaturedImage
+}
+
+func (p *GenerationParams) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return errors.Validation("Temperature must be between 0 and 2")
+	}
+
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		return errors.Validation("Top-p must be greater than 0 and at most 1")
+	}
+
+	if p.MaxOutputTokens != nil && *p.MaxOutputTokens < 1 {
+		return errors.Validation("Max output tokens must be positive")
+	}
+
+	if len(p.Stop) > maxStopSequences {
+		return errors.Validation("At most 4 stop sequences are allowed")
+	}
+
+	for _, stop := range p.Stop {
+		if stop == "" {
+			return errors.Validation("Stop sequences must not be empty")
+		}
+	}
+
+	switch p.Mode {
+	case "", GenerationModeSingle, GenerationModeOutline:
+	default:
+		return errors.Validation("Unsupported generation mode")
+	}
+
+	return nil
+}
+
+// ValidateFor validates the params against the ranges accepted by the provider type
+func (p *GenerationParams) ValidateFor(providerType Type) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if p != nil && p.Temperature != nil && *p.Temperature > providerType.MaxTemperature() {
+		return errors.Validation(fmt.Sprintf("Temperature must be between 0 and %g for %s providers", providerType.MaxTemperature(), providerType))
+	}
+
+	return nil
+}
+
+// Merge returns the params with every field set in override taking precedence.
+// Either side may be nil.
+func (p *GenerationParams) Merge(override *GenerationParams) *GenerationParams {
+	if p.IsEmpty() {
+		return override
+	}
+	if override.IsEmpty() {
+		return p
+	}
+
+	merged := *p
+	if override.Temperature != nil {
+		merged.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		merged.TopP = override.TopP
+	}
+	if override.MaxOutputTokens != nil {
+		merged.MaxOutputTokens = override.MaxOutputTokens
+	}
+	if override.Seed != nil {
+		merged.Seed = override.Seed
+	}
+	if len(override.Stop) > 0 {
+		merged.Stop = override.Stop
+	}
+	if override.Mode != "" {
+		merged.Mode = override.Mode
+	}
+	if override.FeaturedImage != nil {
+		merged.FeaturedImage = override.FeaturedImage
+	}
+
+	return &merged
+}
@@ -48,6 +48,52 @@ type Job struct {
 	State      *State
 	Categories []int64
 	Topics     []int64
+	// FallbackProviderIDs are tried in order when AIProviderID fails to generate
+	FallbackProviderIDs []int64
+	// GenerationParams override the prompt's sampling settings for this job
+	GenerationParams *GenerationParams
+	// MisfirePolicy, MisfireMaxRuns and MisfireSpacingMinutes decide how the runs
+	// missed while the app was closed or asleep are caught up
+	MisfirePolicy         MisfirePolicy
+	MisfireMaxRuns        int
+	MisfireSpacingMinutes int
+	// TimeoutMinutes bounds a run of the job, long generations may need more than
+	// the default
+	TimeoutMinutes int
+}
+
+// DefaultJobTimeoutMinutes bounds the runs of the jobs without a timeout of their own
+const DefaultJobTimeoutMinutes = 10
+
+// MisfirePolicy decides what happens to the runs a job missed while the app was
+// closed or asleep
+type MisfirePolicy string
+
+const (
+	// MisfireSkip runs nothing for the missed slots, the job goes on at its next slot
+	MisfireSkip MisfirePolicy = "skip"
+	// MisfireRunOnce runs the job once for all the missed slots
+	MisfireRunOnce MisfirePolicy = "run_once"
+	// MisfireRunAll runs the job for each missed slot, up to MisfireMaxRuns runs
+	// spaced MisfireSpacingMinutes apart
+	MisfireRunAll MisfirePolicy = "run_all"
+)
+
+type MissedRunOutcome string
+
+const (
+	MissedRunSkipped  MissedRunOutcome = "skipped"
+	MissedRunCaughtUp MissedRunOutcome = "caught_up"
+)
+
+// MissedRun records a slot a job missed and what became of it
+type MissedRun struct {
+	ID          int64
+	JobID       int64
+	ScheduledAt time.Time
+	Policy      MisfirePolicy
+	Outcome     MissedRunOutcome
+	DetectedAt  time.Time
 }
 
 type ScheduleType string
@@ -57,11 +103,22 @@ const (
 	ScheduleOnce     ScheduleType = "once"
 	ScheduleInterval ScheduleType = "interval"
 	ScheduleDaily    ScheduleType = "daily"
+	ScheduleCron     ScheduleType = "cron"
 )
 
 type Schedule struct {
 	Type   ScheduleType
 	Config json.RawMessage
+	// Timezone is the IANA zone the schedule is evaluated in, empty for the local time
+	Timezone string
+}
+
+// Location returns the time zone the schedule is evaluated in
+func (s *Schedule) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(s.Timezone)
 }
 
 type OnceSchedule struct {
@@ -80,6 +137,11 @@ type DailySchedule struct {
 	Weekdays []int `json:"weekdays"`
 }
 
+// CronSchedule fires on a cron expression, see pkg/cron for the syntax
+type CronSchedule struct {
+	Expression string `json:"expression"`
+}
+
 type State struct {
 	JobID             int64
 	LastRunAt         *time.Time
@@ -88,4 +150,6 @@ type State struct {
 	TotalExecutions   int
 	FailedExecutions  int
 	LastCategoryIndex int
+	// CatchUpRuns counts the catch-up runs still due for missed slots
+	CatchUpRuns int
 }
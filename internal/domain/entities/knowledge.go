@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// KnowledgeDocument is a local file whose passages ground the content generated for
+// a site. Documents with a JobID only apply to that job.
+type KnowledgeDocument struct {
+	ID         int64
+	SiteID     int64
+	JobID      *int64
+	Name       string
+	FileType   string // File extension without the dot, e.g. "pdf"
+	SizeBytes  int64
+	ChunkCount int
+	CreatedAt  time.Time
+}
+
+// KnowledgeChunk is a passage of a knowledge document, the unit of retrieval
+type KnowledgeChunk struct {
+	ID         int64
+	DocumentID int64
+	Position   int
+	Content    string
+}
@@ -10,6 +10,8 @@ const (
 	PromptCategoryLinkSuggest PromptCategory = "link_suggest"
 	PromptCategoryLinkApply   PromptCategory = "link_apply"
 	PromptCategorySitemapGen  PromptCategory = "sitemap_gen"
+	// PromptCategoryArticleRewrite revises existing articles (refresh, expand, change tone)
+	PromptCategoryArticleRewrite PromptCategory = "article_rewrite"
 )
 
 // ContextFieldType defines the UI control type for a context field
@@ -59,6 +61,8 @@ type Prompt struct {
 	Instructions  string        // User's instructions (becomes System Prompt for AI)
 	ContextConfig ContextConfig // JSON config for context fields
 	Version       int           // 2 = new format, 1 = legacy format
+	// GenerationParams are the default sampling settings for content generated with this prompt
+	GenerationParams *GenerationParams
 	// Legacy fields (kept for backward compatibility and migration)
 	SystemPrompt string
 	UserPrompt   string
@@ -8,19 +8,81 @@ const (
 	TypeOpenAI    Type = "openai"
 	TypeAnthropic Type = "anthropic"
 	TypeGoogle    Type = "google"
+	// TypeOpenAICompatible targets any server speaking the OpenAI Chat Completions API
+	// (Ollama, LM Studio, vLLM, ...). Models are free-form and usage is not billed.
+	TypeOpenAICompatible Type = "openai_compatible"
+	// TypeMock is an offline provider producing deterministic synthetic output or
+	// replaying recorded fixtures. Its BaseURL holds the fixtures directory.
+	TypeMock Type = "mock"
 )
 
+// MaxTemperature is the highest sampling temperature the provider's API accepts
+func (t Type) MaxTemperature() float64 {
+	if t == TypeAnthropic {
+		return 1
+	}
+	return 2
+}
+
 type Provider struct {
-	ID        int64
-	Name      string
-	Type      Type
-	APIKey    string
-	Model     string
+	ID      int64
+	Name    string
+	Type    Type
+	APIKey  string
+	Model   string
+	BaseURL string
+	// EmbeddingModel overrides the provider's default embedding model, empty keeps the default
+	EmbeddingModel string
+	// Keys are additional API keys rotated in when APIKey hits a rate limit or its quota
+	Keys      []*ProviderKey
 	IsActive  bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// ProviderHealth is the outcome of a provider health check
+type ProviderHealth string
+
+const (
+	ProviderHealthy      ProviderHealth = "healthy"
+	ProviderRateLimited  ProviderHealth = "rate_limited"
+	ProviderUnavailable  ProviderHealth = "unavailable"
+	ProviderKeyRevoked   ProviderHealth = "key_revoked"
+	ProviderOutOfCredit  ProviderHealth = "out_of_credit"
+	ProviderModelRetired ProviderHealth = "model_retired"
+	ProviderFailing      ProviderHealth = "failing"
+)
+
+// NeedsAction reports whether the provider stays broken until the user steps in,
+// unlike a rate limit or an outage that passes by itself
+func (h ProviderHealth) NeedsAction() bool {
+	return h == ProviderKeyRevoked || h == ProviderOutOfCredit || h == ProviderModelRetired
+}
+
+// ProviderHealthCheck is the result of checking one API key of a provider
+type ProviderHealthCheck struct {
+	ID         int64
+	ProviderID int64
+	// KeyFingerprint identifies the key checked, empty for keyless providers
+	KeyFingerprint string
+	Model          string
+	CheckedAt      time.Time
+	Status         ProviderHealth
+	LatencyMs      int
+	ErrorMessage   string
+}
+
+// ProviderKey is an API key of a provider's key pool
+type ProviderKey struct {
+	ID         int64
+	ProviderID int64
+	Label      string
+	APIKey     string
+	IsActive   bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
 type Model struct {
 	ID              string
 	Name            string
@@ -35,4 +97,8 @@ type Model struct {
 	UsesCompletionTokens bool
 	// IsReasoningModel indicates models that don't support temperature (o1, o3, gpt-5 series)
 	IsReasoningModel bool
+	// IsBuiltin marks models shipped with the app, IsUserDefined marks models added or
+	// edited by the user. Both are set for a built-in model with user overrides.
+	IsBuiltin     bool
+	IsUserDefined bool
 }
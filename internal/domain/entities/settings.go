@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/davidmovas/postulator/pkg/errors"
@@ -10,6 +11,10 @@ const (
 	SettingsKeyHealthCheck = "health_check"
 	SettingsKeyProxy       = "proxy"
 	SettingsKeyDashboard   = "dashboard"
+	// SettingsKeyProviderHealth holds the settings of the AI provider health checks
+	SettingsKeyProviderHealth = "provider_health"
+	// SettingsKeyScheduler holds the concurrency limits of the job scheduler
+	SettingsKeyScheduler = "scheduler"
 )
 
 type ProxyType string
@@ -185,6 +190,58 @@ func (s *HealthCheckSettings) Validate() error {
 	return nil
 }
 
+// ProviderHealthSettings control the periodic checks of the active AI providers.
+// Every check sends a one-token request per API key, so the interval is kept long.
+type ProviderHealthSettings struct {
+	Enabled         bool `json:"enabled"`
+	IntervalMinutes int  `json:"interval_minutes"`
+	NotifyWithSound bool `json:"notify_with_sound"`
+}
+
+const minProviderHealthInterval = 15
+
+func DefaultProviderHealthSettings() *ProviderHealthSettings {
+	return &ProviderHealthSettings{
+		Enabled:         true,
+		IntervalMinutes: 60,
+		NotifyWithSound: true,
+	}
+}
+
+func (s *ProviderHealthSettings) Validate() error {
+	if s.IntervalMinutes < minProviderHealthInterval {
+		return errors.Validation(fmt.Sprintf("Interval cannot be less than %d minutes", minProviderHealthInterval))
+	}
+	return nil
+}
+
+// SchedulerSettings bound how many jobs the scheduler runs at once, overall and on a
+// single site, so jobs due together don't overload a WordPress host or the AI rate
+// limits. The jobs over the limits wait in a queue.
+type SchedulerSettings struct {
+	MaxConcurrentJobs    int `json:"max_concurrent_jobs"`
+	MaxConcurrentPerSite int `json:"max_concurrent_per_site"`
+}
+
+const maxSchedulerConcurrency = 20
+
+func DefaultSchedulerSettings() *SchedulerSettings {
+	return &SchedulerSettings{
+		MaxConcurrentJobs:    3,
+		MaxConcurrentPerSite: 1,
+	}
+}
+
+func (s *SchedulerSettings) Validate() error {
+	if s.MaxConcurrentJobs < 1 || s.MaxConcurrentJobs > maxSchedulerConcurrency {
+		return errors.Validation(fmt.Sprintf("Concurrent jobs must be between 1 and %d", maxSchedulerConcurrency))
+	}
+	if s.MaxConcurrentPerSite < 1 || s.MaxConcurrentPerSite > s.MaxConcurrentJobs {
+		return errors.Validation("Concurrent jobs per site must be between 1 and the concurrent jobs overall")
+	}
+	return nil
+}
+
 type HealthCheckHistory struct {
 	ID             int64
 	SiteID         int64
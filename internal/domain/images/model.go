@@ -0,0 +1,15 @@
+package images
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/wp"
+)
+
+type Service interface {
+	// CreateFeaturedImage generates an image from the article's title and summary and
+	// uploads it to the site's media library with the title as alt text. The provider
+	// is used when it can generate images, otherwise the first active one that can.
+	CreateFeaturedImage(ctx context.Context, site *entities.Site, providerID int64, title, summary string) (*wp.MediaResult, error)
+}
@@ -0,0 +1,151 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/internal/infra/wp"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+// maxFilenameSlug keeps media filenames readable
+const maxFilenameSlug = 60
+
+var nonSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+var _ Service = (*service)(nil)
+
+type service struct {
+	providerSvc providers.Service
+	aiUsageSvc  aiusage.Service
+	budgetSvc   budgets.Service
+	wpClient    wp.Client
+	logger      *logger.Logger
+}
+
+func NewService(
+	providerSvc providers.Service,
+	aiUsageSvc aiusage.Service,
+	budgetSvc budgets.Service,
+	wpClient wp.Client,
+	logger *logger.Logger,
+) Service {
+	return &service{
+		providerSvc: providerSvc,
+		aiUsageSvc:  aiUsageSvc,
+		budgetSvc:   budgetSvc,
+		wpClient:    wpClient,
+		logger: logger.
+			WithScope("service").
+			WithScope("images"),
+	}
+}
+
+func (s *service) CreateFeaturedImage(ctx context.Context, site *entities.Site, providerID int64, title, summary string) (*wp.MediaResult, error) {
+	if strings.TrimSpace(title) == "" {
+		return nil, errors.Validation("Featured images need an article title")
+	}
+
+	provider, err := s.imageProvider(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.budgetSvc != nil {
+		if err = s.budgetSvc.Check(ctx, site.ID, provider.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := ai.CreateClient(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	result, err := client.GenerateImage(ctx, &ai.ImageRequest{
+		Prompt: ai.FeaturedImagePrompt(title, summary),
+	})
+	durationMs := time.Since(startTime).Milliseconds()
+
+	if s.aiUsageSvc != nil {
+		var usage ai.Usage
+		metadata := map[string]interface{}{
+			"title": title,
+		}
+		if result != nil {
+			usage = result.Usage
+			metadata["image_model"] = result.Model
+		}
+		_ = s.aiUsageSvc.LogFromResult(
+			ctx,
+			site.ID,
+			provider.ID,
+			aiusage.OperationImageGeneration,
+			client,
+			usage,
+			durationMs,
+			err,
+			metadata,
+		)
+	}
+
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to generate featured image")
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("%s.%s", imageSlug(title), result.Extension())
+	media, err := s.wpClient.UploadMedia(ctx, site, filename, result.Data, strings.TrimSpace(title))
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to upload featured image")
+		return nil, err
+	}
+
+	s.logger.Debugf("Uploaded featured image %d for %q", media.ID, title)
+	return media, nil
+}
+
+// imageProvider returns the provider when it can generate images, otherwise the first
+// active provider that can
+func (s *service) imageProvider(ctx context.Context, providerID int64) (*entities.Provider, error) {
+	provider, err := s.providerSvc.GetProvider(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	if ai.SupportsImages(provider.Type) {
+		return provider, nil
+	}
+
+	active, err := s.providerSvc.ListActiveProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range active {
+		if ai.SupportsImages(candidate.Type) {
+			return candidate, nil
+		}
+	}
+
+	return nil, errors.Validation(fmt.Sprintf("Provider %s does not generate images and no active provider does", provider.Name))
+}
+
+func imageSlug(title string) string {
+	slug := strings.Trim(nonSlugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > maxFilenameSlug {
+		slug = strings.TrimRight(slug[:maxFilenameSlug], "-")
+	}
+	if slug == "" {
+		return "featured-image"
+	}
+	return slug
+}
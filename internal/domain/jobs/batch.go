@@ -0,0 +1,227 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/davidmovas/postulator/internal/domain/batches"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+// BackfillJob submits an article for each remaining topic of the job to the provider's
+// batch API. Articles are created once the batch finishes, within 24 hours.
+func (s *service) BackfillJob(ctx context.Context, jobID int64) (*entities.Batch, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	siteBatches, err := s.batchService.ListBatches(ctx, job.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	for _, batch := range siteBatches {
+		if batch.Source == entities.BatchSourceJob && batch.SourceID == job.ID && batch.Status == entities.BatchStatusSubmitted {
+			return nil, errors.Validation("Job already has a backfill in progress")
+		}
+	}
+
+	prompt, err := s.promptService.GetPrompt(ctx, job.PromptID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, _, err := s.topicService.GetJobRemainingTopics(ctx, job)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get remaining topics")
+		return nil, err
+	}
+
+	if len(remaining) == 0 {
+		return nil, errors.Validation("Job has no remaining topics")
+	}
+
+	rendered, err := s.renderTopicPrompts(ctx, job, prompt, remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := ai.NewGenerateArticleOptions(prompt.GenerationParams.Merge(job.GenerationParams))
+	words := estimateWords(job, prompt)
+	items := make([]*batches.SubmitItem, len(rendered))
+	for i, topicPrompt := range rendered {
+		items[i] = &batches.SubmitItem{
+			EntityID:     topicPrompt.topic.ID,
+			SystemPrompt: topicPrompt.systemPrompt,
+			UserPrompt:   topicPrompt.userPrompt,
+			Options:      opts,
+			Words:        words,
+		}
+	}
+
+	batch, err := s.batchService.Submit(ctx, &batches.SubmitRequest{
+		SiteID:     job.SiteID,
+		ProviderID: job.AIProviderID,
+		Source:     entities.BatchSourceJob,
+		SourceID:   job.ID,
+		Items:      items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Job %d backfill of %d topics submitted as batch %d", job.ID, len(items), batch.ID)
+	return batch, nil
+}
+
+// publishBatchResults creates the articles of a finished backfill batch. Topics the
+// job used up while the batch was running are skipped.
+func (s *service) publishBatchResults(ctx context.Context, batch *entities.Batch, results []*batches.ItemResult) {
+	job, err := s.GetJob(ctx, batch.SourceID)
+	if err == nil {
+		err = s.publishJobResults(ctx, job, results)
+	}
+
+	if err != nil {
+		s.logger.ErrorWithErr(err, fmt.Sprintf("Failed to publish results of batch %d", batch.ID))
+		for _, result := range results {
+			if result.Err == nil && result.Item.ArticleID == nil {
+				result.Err = err
+			}
+		}
+	}
+}
+
+func (s *service) publishJobResults(ctx context.Context, job *entities.Job, results []*batches.ItemResult) error {
+	strategy, err := s.topicService.GetStrategy(job.TopicStrategy)
+	if err != nil {
+		return err
+	}
+
+	remaining, _, err := s.topicService.GetJobRemainingTopics(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	remainingIDs := make(map[int64]struct{}, len(remaining))
+	for _, topic := range remaining {
+		remainingIDs[topic.ID] = struct{}{}
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		if _, ok := remainingIDs[result.Item.EntityID]; !ok {
+			result.Err = errors.Validation("Topic was used while the batch was running")
+			continue
+		}
+
+		topic, err := s.topicService.GetTopic(ctx, result.Item.EntityID)
+		if err != nil {
+			result.Err = err
+			continue
+		}
+
+		// An article that failed to publish is kept as a draft, its topic is used up as well
+		article, err := s.createBatchArticle(ctx, job, topic, result.Article)
+		if err != nil {
+			result.Err = err
+		}
+		if article == nil {
+			continue
+		}
+		result.Item.ArticleID = &article.ID
+
+		if err = strategy.OnExecutionSuccess(ctx, job, topic); err != nil {
+			s.logger.ErrorWithErr(err, fmt.Sprintf("Failed to mark topic %d as used", topic.ID))
+		}
+	}
+
+	return nil
+}
+
+// createBatchArticle stores the article and publishes it, unless the job requires
+// validation, in which case it is kept as a draft for review
+func (s *service) createBatchArticle(ctx context.Context, job *entities.Job, topic *entities.Topic, result *ai.ArticleResult) (*entities.Article, error) {
+	categoryIDs, err := s.nextCategoryIDs(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	excerpt := result.Excerpt
+	article := &entities.Article{
+		SiteID:        job.SiteID,
+		JobID:         &job.ID,
+		TopicID:       &topic.ID,
+		Title:         result.Title,
+		Excerpt:       &excerpt,
+		OriginalTitle: topic.Title,
+		Content:       result.Content,
+		WPCategoryIDs: categoryIDs,
+		Status:        entities.StatusDraft,
+		Source:        entities.SourceGenerated,
+	}
+
+	if job.RequiresValidation {
+		if err = s.articleService.CreateArticle(ctx, article); err != nil {
+			return nil, err
+		}
+		return article, nil
+	}
+
+	published, err := s.articleService.CreateAndPublishArticle(ctx, article)
+	if err != nil {
+		if article.ID != 0 {
+			return article, err
+		}
+		return nil, err
+	}
+
+	return published, nil
+}
+
+// nextCategoryIDs picks the WordPress categories of the next article of the job
+// like the select_category step of an execution
+func (s *service) nextCategoryIDs(ctx context.Context, job *entities.Job) ([]int, error) {
+	if len(job.Categories) == 0 {
+		return nil, nil
+	}
+
+	var categoryIDs []int64
+	switch job.CategoryStrategy {
+	case entities.CategoryRandom:
+		categoryIDs = []int64{job.Categories[rand.IntN(len(job.Categories))]}
+
+	case entities.CategoryRotate:
+		if job.State == nil {
+			job.State = &entities.State{JobID: job.ID}
+		}
+
+		index := job.State.LastCategoryIndex % len(job.Categories)
+		categoryIDs = []int64{job.Categories[index]}
+
+		job.State.LastCategoryIndex = (index + 1) % len(job.Categories)
+		if err := s.stateRepo.UpdateCategoryIndex(ctx, job.ID, job.State.LastCategoryIndex); err != nil {
+			s.logger.ErrorWithErr(err, "Failed to update category index")
+		}
+
+	default:
+		categoryIDs = job.Categories
+	}
+
+	wpCategoryIDs := make([]int, 0, len(categoryIDs))
+	for _, categoryID := range categoryIDs {
+		category, err := s.categoryService.GetCategory(ctx, categoryID)
+		if err != nil {
+			return nil, err
+		}
+		wpCategoryIDs = append(wpCategoryIDs, category.WPCategoryID)
+	}
+
+	return wpCategoryIDs, nil
+}
@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+)
+
+// EstimateJob renders the job prompt for each of its remaining topics and sums the
+// expected token usage and cost on the job's provider. Nothing is sent to the provider.
+func (s *service) EstimateJob(ctx context.Context, jobID int64) (*ai.CostEstimate, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, err := s.promptService.GetPrompt(ctx, job.PromptID)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.providerService.GetProvider(ctx, job.AIProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, _, err := s.topicService.GetJobRemainingTopics(ctx, job)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get remaining topics")
+		return nil, err
+	}
+
+	rendered, err := s.renderTopicPrompts(ctx, job, prompt, remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	words := estimateWords(job, prompt)
+	opts := ai.NewGenerateArticleOptions(prompt.GenerationParams.Merge(job.GenerationParams))
+	estimate := ai.NewCostEstimate(provider.Type, provider.Model)
+
+	for _, topicPrompt := range rendered {
+		estimate.AddArticle(topicPrompt.systemPrompt, topicPrompt.userPrompt, words, opts)
+	}
+
+	s.logger.Infof("Estimated job %d for %d remaining topics: %d input, %d output tokens, $%.2f",
+		job.ID, estimate.Items, estimate.InputTokens, estimate.OutputTokens, estimate.CostUSD)
+
+	return estimate, nil
+}
+
+// estimateWords resolves the article length the job asks for: its placeholder
+// values first, then the word count configured on the prompt
+func estimateWords(job *entities.Job, prompt *entities.Prompt) int {
+	if value := job.PlaceholdersValues["wordCount"]; value != "" {
+		return ai.ParseWordCount(value)
+	}
+	if field, ok := prompt.ContextConfig["wordCount"]; ok && field.Enabled && field.Value != "" {
+		return ai.ParseWordCount(field.Value)
+	}
+	return ai.DefaultEstimateWords
+}
+
+// topicPrompt is the job prompt rendered for one topic
+type topicPrompt struct {
+	topic        *entities.Topic
+	systemPrompt string
+	userPrompt   string
+}
+
+// renderTopicPrompts renders the job prompt for each topic with the same runtime
+// data as the render_prompt step of an execution
+func (s *service) renderTopicPrompts(ctx context.Context, job *entities.Job, prompt *entities.Prompt, topics []*entities.Topic) ([]*topicPrompt, error) {
+	site, err := s.siteService.GetSite(ctx, job.SiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryNames []string
+	for _, categoryID := range job.Categories {
+		category, err := s.categoryService.GetCategory(ctx, categoryID)
+		if err != nil {
+			return nil, err
+		}
+		categoryNames = append(categoryNames, category.Name)
+	}
+
+	rendered := make([]*topicPrompt, 0, len(topics))
+	for _, topic := range topics {
+		placeholders := make(map[string]string)
+		for _, placeholder := range prompt.Placeholders {
+			placeholders[placeholder] = ""
+		}
+		placeholders["title"] = topic.Title
+		placeholders["siteName"] = site.Name
+		placeholders["siteUrl"] = site.URL
+		placeholders["category"] = strings.Join(categoryNames, ", ")
+
+		query := topic.Title + " " + placeholders["category"]
+		if placeholders["knowledge"], err = s.knowledgeService.PromptContext(ctx, job.SiteID, &job.ID, query); err != nil {
+			s.logger.ErrorWithErr(err, "Failed to find knowledge base passages")
+		}
+
+		for placeholder, value := range job.PlaceholdersValues {
+			placeholders[placeholder] = value
+		}
+
+		systemPrompt, userPrompt, err := s.promptService.RenderPrompt(ctx, prompt.ID, placeholders)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered = append(rendered, &topicPrompt{topic: topic, systemPrompt: systemPrompt, userPrompt: userPrompt})
+	}
+
+	return rendered, nil
+}
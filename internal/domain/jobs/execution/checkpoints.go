@@ -0,0 +1,180 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+var _ CheckpointRepository = (*checkpointRepository)(nil)
+
+type checkpointRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewCheckpointRepository(db *database.DB, logger *logger.Logger) CheckpointRepository {
+	return &checkpointRepository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("pipeline_checkpoints"),
+	}
+}
+
+func (r *checkpointRepository) Save(ctx context.Context, checkpoint *pipeline.Checkpoint) error {
+	payload, err := json.Marshal(checkpoint)
+	if err != nil {
+		return errors.Internal(err)
+	}
+
+	query, args := dbx.ST.
+		Insert("pipeline_checkpoints").
+		Columns("execution_id", "job_id", "state", "payload", "updated_at").
+		Values(checkpoint.ExecutionID, checkpoint.JobID, checkpoint.State, string(payload), checkpoint.UpdatedAt).
+		Suffix("ON CONFLICT(execution_id) DO UPDATE SET state = EXCLUDED.state, payload = EXCLUDED.payload, updated_at = EXCLUDED.updated_at").
+		MustSql()
+
+	if _, err = r.db.ExecContext(ctx, query, args...); err != nil {
+		return errors.Database(err)
+	}
+
+	return nil
+}
+
+func (r *checkpointRepository) GetAll(ctx context.Context) ([]*pipeline.Checkpoint, error) {
+	query, args := dbx.ST.
+		Select("payload").
+		From("pipeline_checkpoints").
+		OrderBy("updated_at ASC").
+		MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var checkpoints []*pipeline.Checkpoint
+	for rows.Next() {
+		var payload string
+		if err = rows.Scan(&payload); err != nil {
+			return nil, errors.Database(err)
+		}
+
+		var checkpoint pipeline.Checkpoint
+		if err = json.Unmarshal([]byte(payload), &checkpoint); err != nil {
+			r.logger.Warnf("Skipping unreadable pipeline checkpoint: %v", err)
+			continue
+		}
+		checkpoints = append(checkpoints, &checkpoint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return checkpoints, nil
+}
+
+func (r *checkpointRepository) Delete(ctx context.Context, executionID int64) error {
+	query, args := dbx.ST.
+		Delete("pipeline_checkpoints").
+		Where(squirrel.Eq{"execution_id": executionID}).
+		MustSql()
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return errors.Database(err)
+	}
+
+	return nil
+}
+
+var _ pipeline.Checkpointer = (*checkpointer)(nil)
+
+// checkpointer keeps the checkpoints of the pipeline runs and closes the execution
+// record of a failed or paused run, which would otherwise stay generating and be
+// taken for an interrupted run on the next start
+type checkpointer struct {
+	checkpoints CheckpointRepository
+	executions  Repository
+}
+
+func (c *checkpointer) Save(ctx context.Context, checkpoint *pipeline.Checkpoint) error {
+	return c.checkpoints.Save(ctx, checkpoint)
+}
+
+func (c *checkpointer) Release(ctx context.Context, checkpoint *pipeline.Checkpoint, cause error) error {
+	var reason string
+	switch {
+	case cause != nil:
+		reason = failureReason(cause)
+	case isPausedState(checkpoint.State):
+		// A paused run does not go on, its job is paused or waits for the next run
+		reason = pauseReason(checkpoint)
+	}
+
+	if reason != "" {
+		exec, err := c.executions.GetByID(ctx, checkpoint.ExecutionID)
+		if err != nil {
+			return err
+		}
+		if isUnfinished(exec.Status) {
+			if err = failExecution(ctx, c.executions, exec, reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.checkpoints.Delete(ctx, checkpoint.ExecutionID)
+}
+
+func failureReason(cause error) string {
+	switch {
+	case stderrors.Is(cause, context.Canceled):
+		return "execution cancelled"
+	case stderrors.Is(cause, context.DeadlineExceeded):
+		return "execution timed out"
+	default:
+		return cause.Error()
+	}
+}
+
+func pauseReason(checkpoint *pipeline.Checkpoint) string {
+	if n := len(checkpoint.History); n > 0 && checkpoint.History[n-1].To == checkpoint.State {
+		return checkpoint.History[n-1].Reason
+	}
+	return fmt.Sprintf("paused at state %s", checkpoint.State)
+}
+
+func isPausedState(state pipeline.State) bool {
+	return state == pipeline.StatePausedForValidation ||
+		state == pipeline.StatePausedNoResources ||
+		state == pipeline.StatePausedBudget
+}
+
+func isUnfinished(status entities.ExecutionStatus) bool {
+	return status == entities.ExecutionStatusPending ||
+		status == entities.ExecutionStatusGenerating ||
+		status == entities.ExecutionStatusPublishing
+}
+
+func failExecution(ctx context.Context, executions Repository, exec *entities.Execution, reason string) error {
+	now := time.Now()
+	exec.Status = entities.ExecutionStatusFailed
+	exec.ErrorMessage = &reason
+	exec.CompletedAt = &now
+	return executions.Update(ctx, exec)
+}
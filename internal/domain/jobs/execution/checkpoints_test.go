@@ -0,0 +1,89 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
+)
+
+func TestCheckpointerRelease(t *testing.T) {
+	pausedForValidation := checkpointAt(2, pipeline.StatePausedForValidation, true)
+	pausedForValidation.History = []pipeline.StateTransition{{
+		From:   pipeline.StateOutputValidated,
+		To:     pipeline.StatePausedForValidation,
+		Reason: "paused at validate_output: generated content is empty",
+	}}
+
+	tests := []struct {
+		name       string
+		status     entities.ExecutionStatus
+		checkpoint *pipeline.Checkpoint
+		cause      error
+		wantStatus entities.ExecutionStatus
+		wantReason string
+	}{
+		{
+			name:       "completed",
+			status:     entities.ExecutionStatusPublished,
+			checkpoint: checkpointAt(1, pipeline.StateCompleted, true),
+			wantStatus: entities.ExecutionStatusPublished,
+		},
+		{
+			name:       "paused for validation",
+			status:     entities.ExecutionStatusGenerating,
+			checkpoint: pausedForValidation,
+			wantStatus: entities.ExecutionStatusFailed,
+			wantReason: "generated content is empty",
+		},
+		{
+			name:       "paused for budget",
+			status:     entities.ExecutionStatusGenerating,
+			checkpoint: checkpointAt(3, pipeline.StatePausedBudget, false),
+			wantStatus: entities.ExecutionStatusFailed,
+			wantReason: "paused at state paused_budget_exceeded",
+		},
+		{
+			name:       "timed out",
+			status:     entities.ExecutionStatusGenerating,
+			checkpoint: checkpointAt(4, pipeline.StatePromptRendered, false),
+			cause:      context.DeadlineExceeded,
+			wantStatus: entities.ExecutionStatusFailed,
+			wantReason: "execution timed out",
+		},
+		{
+			name:       "failed after publishing",
+			status:     entities.ExecutionStatusPendingValidation,
+			checkpoint: checkpointAt(5, pipeline.StateRecordingStats, true),
+			cause:      context.Canceled,
+			wantStatus: entities.ExecutionStatusPendingValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := tt.checkpoint.ExecutionID
+			executions := newMemoryExecutions(execution(id, tt.status))
+			checkpoints := newMemoryCheckpoints(tt.checkpoint)
+			c := &checkpointer{checkpoints: checkpoints, executions: executions}
+
+			if err := c.Release(context.Background(), tt.checkpoint, tt.cause); err != nil {
+				t.Fatalf("Release: %v", err)
+			}
+
+			exec := executions.Get(id)
+			if exec.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, exec.Status)
+			}
+			if tt.wantReason != "" {
+				expectFailed(t, exec, tt.wantReason)
+			} else if exec.ErrorMessage != nil {
+				t.Errorf("expected no error message, got %q", *exec.ErrorMessage)
+			}
+			if checkpoints.Has(id) {
+				t.Error("expected the checkpoint to be deleted")
+			}
+		})
+	}
+}
@@ -43,6 +43,20 @@ func (c *CreateExecutionCommand) Execute(ctx *pipeline.Context) error {
 		return fault.WrapError(err, fault.ErrCodeNoProvider, c.Name(), "failed to get AI provider")
 	}
 
+	var fallbacks []*entities.Provider
+	for _, providerID := range ctx.Job.FallbackProviderIDs {
+		fallback, err := c.providersService.GetProvider(ctx.Context(), providerID)
+		if err != nil {
+			ctx.Logger().Warnf("Skipping fallback AI provider %d: %v", providerID, err)
+			continue
+		}
+		if !fallback.IsActive {
+			ctx.Logger().Warnf("Skipping inactive fallback AI provider %d", providerID)
+			continue
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+
 	prompt, err := c.promptsService.GetPrompt(ctx.Context(), ctx.Job.PromptID)
 	if err != nil {
 		return fault.WrapError(err, fault.ErrCodeRecordNotFound, c.Name(), "failed to get prompt")
@@ -69,7 +83,7 @@ func (c *CreateExecutionCommand) Execute(ctx *pipeline.Context) error {
 		return fault.WrapError(err, fault.ErrCodeDatabaseError, c.Name(), "failed to create execution record")
 	}
 
-	ctx.InitExecutionPhase(exec, prompt, provider)
+	ctx.InitExecutionPhase(exec, prompt, provider, fallbacks...)
 
 	return nil
 }
@@ -1,9 +1,11 @@
 package generation
 
 import (
+	"errors"
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/jobs/execution/commands"
 	"github.com/davidmovas/postulator/internal/domain/jobs/execution/fault"
@@ -12,6 +14,7 @@ import (
 	"github.com/davidmovas/postulator/internal/domain/stats"
 	"github.com/davidmovas/postulator/internal/infra/ai"
 	"github.com/davidmovas/postulator/internal/infra/events"
+	appErrors "github.com/davidmovas/postulator/pkg/errors"
 )
 
 var _ pipeline.Command = (*GenerateContentCommand)(nil)
@@ -21,9 +24,15 @@ type GenerateContentCommand struct {
 	executionProvider commands.ExecutionProvider
 	statsRecorder     stats.Recorder
 	aiUsageService    aiusage.Service
+	budgetService     budgets.Service
 }
 
-func NewGenerateContentCommand(executionProvider commands.ExecutionProvider, statsRecorder stats.Recorder, aiUsageService aiusage.Service) *GenerateContentCommand {
+func NewGenerateContentCommand(
+	executionProvider commands.ExecutionProvider,
+	statsRecorder stats.Recorder,
+	aiUsageService aiusage.Service,
+	budgetService budgets.Service,
+) *GenerateContentCommand {
 	return &GenerateContentCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"generate_content",
@@ -33,6 +42,7 @@ func NewGenerateContentCommand(executionProvider commands.ExecutionProvider, sta
 		executionProvider: executionProvider,
 		statsRecorder:     statsRecorder,
 		aiUsageService:    aiUsageService,
+		budgetService:     budgetService,
 	}
 }
 
@@ -51,43 +61,90 @@ func (c *GenerateContentCommand) Execute(ctx *pipeline.Context) error {
 		return fault.WrapError(err, fault.ErrCodeDatabaseError, c.Name(), "failed to update execution status")
 	}
 
-	aiClient, err := ai.CreateClient(ctx.Execution.Provider)
-	if err != nil {
-		_ = c.statsRecorder.RecordArticleFailed(ctx.Context(), ctx.Job.SiteID)
-		return fault.WrapError(err, fault.ErrCodeNoProvider, c.Name(), "failed to create AI client")
+	onProgress := func(progress ai.StreamProgress) {
+		events.Publish(ctx.Context(), events.NewEvent(
+			pipevents.EventGenerationProgress,
+			&pipevents.GenerationProgressEvent{
+				JobID:        ctx.Job.ID,
+				ExecutionID:  ctx.Execution.Execution.ID,
+				Chars:        progress.Chars,
+				Tokens:       progress.Tokens,
+				PartialTitle: progress.PartialTitle,
+			},
+		))
+	}
+
+	// Job settings override the prompt defaults field by field
+	var params *entities.GenerationParams
+	if ctx.Execution.Prompt != nil {
+		params = ctx.Execution.Prompt.GenerationParams
 	}
+	opts := ai.NewGenerateArticleOptions(params.Merge(ctx.Job.GenerationParams))
+
+	// The same rendered prompt is replayed on each provider of the chain
+	// until one of them produces the article
+	chain := append([]*entities.Provider{ctx.Execution.Provider}, ctx.Execution.Fallbacks...)
+
+	var (
+		result     *ai.ArticleResult
+		provider   *entities.Provider
+		durationMs int64
+		lastErr    error
+	)
 
 	startTime := time.Now()
 
-	result, err := aiClient.GenerateArticle(ctx.Context(), ctx.Generation.SystemPrompt, ctx.Generation.UserPrompt, nil)
-	durationMs := time.Since(startTime).Milliseconds()
+	for attempt, candidate := range chain {
+		if attempt > 0 {
+			ctx.Logger().Warnf("Falling back to AI provider %d (%s) after: %v", candidate.ID, candidate.Name, lastErr)
+		}
 
-	// Log AI usage regardless of success/failure
-	if c.aiUsageService != nil {
-		var usage ai.Usage
-		if result != nil {
-			usage = result.Usage
+		var attemptResult *ai.ArticleResult
+		attemptResult, durationMs, lastErr = c.generate(ctx, candidate, attempt, opts, onProgress)
+
+		// A context overflow may still fit once the output budget is smaller
+		for candidateOpts := opts; lastErr != nil && appErrors.IsAIContextLength(lastErr); {
+			var ok bool
+			if candidateOpts, ok = ai.ShrinkOutput(candidateOpts); !ok {
+				break
+			}
+			ctx.Logger().Warnf("Retrying AI provider %d (%s) with %d max output tokens after context overflow",
+				candidate.ID, candidate.Name, *candidateOpts.MaxOutputTokens)
+			attemptResult, durationMs, lastErr = c.generate(ctx, candidate, attempt, candidateOpts, onProgress)
+		}
+
+		if lastErr == nil {
+			result = attemptResult
+			provider = candidate
+			break
+		}
+
+		if !canFallback(ctx, lastErr) {
+			break
 		}
-		_ = c.aiUsageService.LogFromResult(
-			ctx.Context(),
-			ctx.Job.SiteID,
-			aiusage.OperationArticleGeneration,
-			aiClient,
-			usage,
-			durationMs,
-			err,
-			map[string]interface{}{
-				"job_id":       ctx.Job.ID,
-				"execution_id": ctx.Execution.Execution.ID,
-			},
-		)
 	}
 
-	if err != nil {
+	if result == nil {
+		if appErrors.IsBudgetExceeded(lastErr) {
+			// Nothing was generated, the execution is closed as paused by the budget
+			// instead of counting a failed article, the pipeline pauses the job
+			errMsg := lastErr.Error()
+			now := time.Now()
+			ctx.Execution.Execution.Status = entities.ExecutionStatusPausedBudget
+			ctx.Execution.Execution.ErrorMessage = &errMsg
+			ctx.Execution.Execution.CompletedAt = &now
+			_ = c.executionProvider.Update(ctx.Context(), ctx.Execution.Execution)
+			return lastErr
+		}
+
 		_ = c.statsRecorder.RecordArticleFailed(ctx.Context(), ctx.Job.SiteID)
-		return fault.WrapError(err, fault.ErrCodeAIGenerationFailed, c.Name(), "AI generation failed")
+		return lastErr
 	}
 
+	ctx.Execution.Provider = provider
+	ctx.Execution.Execution.AIProviderID = provider.ID
+	ctx.Execution.Execution.AIModel = provider.Model
+
 	generationTime := int(durationMs)
 
 	ctx.Generation.GeneratedTitle = result.Title
@@ -113,7 +170,7 @@ func (c *GenerateContentCommand) Execute(ctx *pipeline.Context) error {
 		ctx.Execution.Execution.CostUSD = &result.Cost
 	}
 
-	if err = c.executionProvider.Update(ctx.Context(), ctx.Execution.Execution); err != nil {
+	if err := c.executionProvider.Update(ctx.Context(), ctx.Execution.Execution); err != nil {
 		_ = c.statsRecorder.RecordArticleFailed(ctx.Context(), ctx.Job.SiteID)
 		return fault.WrapError(err, fault.ErrCodeDatabaseError, c.Name(), "failed to update execution with generated content")
 	}
@@ -133,3 +190,81 @@ func (c *GenerateContentCommand) Execute(ctx *pipeline.Context) error {
 
 	return nil
 }
+
+// generate runs a single generation attempt on the given provider and logs its AI usage
+func (c *GenerateContentCommand) generate(
+	ctx *pipeline.Context,
+	provider *entities.Provider,
+	attempt int,
+	opts *ai.GenerateArticleOptions,
+	onProgress ai.StreamProgressFunc,
+) (*ai.ArticleResult, int64, error) {
+	if c.budgetService != nil {
+		if err := c.budgetService.Check(ctx.Context(), ctx.Job.SiteID, provider.ID); err != nil {
+			if appErrors.IsBudgetExceeded(err) {
+				return nil, 0, fault.WrapError(err, fault.ErrCodeBudgetExceeded, c.Name(), "AI budget exceeded")
+			}
+			return nil, 0, fault.WrapError(err, fault.ErrCodeDatabaseError, c.Name(), "failed to check AI budgets")
+		}
+	}
+
+	aiClient, err := ai.CreateClient(provider)
+	if err != nil {
+		return nil, 0, fault.WrapError(err, fault.ErrCodeNoProvider, c.Name(), "failed to create AI client")
+	}
+
+	startTime := time.Now()
+	result, err := ai.ComposeArticle(ctx.Context(), aiClient, ctx.Generation.SystemPrompt, ctx.Generation.UserPrompt, opts, onProgress)
+	durationMs := time.Since(startTime).Milliseconds()
+
+	// Log AI usage regardless of success/failure
+	if c.aiUsageService != nil {
+		var usage ai.Usage
+		if result != nil {
+			usage = result.Usage
+		}
+		_ = c.aiUsageService.LogFromResult(
+			ctx.Context(),
+			ctx.Job.SiteID,
+			provider.ID,
+			aiusage.OperationArticleGeneration,
+			aiClient,
+			usage,
+			durationMs,
+			err,
+			map[string]interface{}{
+				"job_id":       ctx.Job.ID,
+				"execution_id": ctx.Execution.Execution.ID,
+				"provider_id":  provider.ID,
+				"attempt":      attempt + 1,
+			},
+		)
+	}
+
+	if err != nil {
+		if ctx.Context().Err() != nil {
+			return nil, durationMs, fault.WrapError(err, fault.ErrCodeCancelled, c.Name(), "AI generation cancelled")
+		}
+		return nil, durationMs, fault.WrapError(err, fault.AICode(err), c.Name(), "AI generation failed")
+	}
+
+	return result, durationMs, nil
+}
+
+// canFallback reports whether a failed attempt may be retried on the next provider.
+// Provider-side failures (API errors, rate limits, quota), unusable provider
+// configurations and exhausted provider budgets qualify; cancellation and internal
+// errors do not.
+func canFallback(ctx *pipeline.Context, err error) bool {
+	if ctx.Context().Err() != nil {
+		return false
+	}
+
+	var pipelineErr *fault.PipelineError
+	if errors.As(err, &pipelineErr) &&
+		(pipelineErr.Code == fault.ErrCodeNoProvider || pipelineErr.Code == fault.ErrCodeBudgetExceeded) {
+		return true
+	}
+
+	return appErrors.IsAI(err)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/davidmovas/postulator/internal/domain/jobs/execution/commands"
 	"github.com/davidmovas/postulator/internal/domain/jobs/execution/fault"
 	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
+	"github.com/davidmovas/postulator/internal/domain/knowledge"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 )
 
@@ -14,17 +15,19 @@ var _ pipeline.Command = (*RenderPromptCommand)(nil)
 
 type RenderPromptCommand struct {
 	*commands.BaseCommand
-	promptService prompts.Service
+	promptService    prompts.Service
+	knowledgeService knowledge.Service
 }
 
-func NewRenderPromptCommand(promptService prompts.Service) *RenderPromptCommand {
+func NewRenderPromptCommand(promptService prompts.Service, knowledgeService knowledge.Service) *RenderPromptCommand {
 	return &RenderPromptCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"render_prompt",
 			pipeline.StateExecutionCreated,
 			pipeline.StatePromptRendered,
 		),
-		promptService: promptService,
+		promptService:    promptService,
+		knowledgeService: knowledgeService,
 	}
 }
 
@@ -84,6 +87,14 @@ func (c *RenderPromptCommand) buildPlaceholders(ctx *pipeline.Context) map[strin
 
 	placeholders["category"] = strings.Join(categoryNames, ", ")
 
+	// Passages are looked up by the topic, a missing knowledge base never fails the step
+	query := ctx.Selection.VariationTopic.Title + " " + placeholders["category"]
+	passages, err := c.knowledgeService.PromptContext(ctx.Context(), ctx.Job.SiteID, &ctx.Job.ID, query)
+	if err != nil {
+		ctx.Logger().Warnf("Rendering job %d prompt without knowledge base passages: %v", ctx.Job.ID, err)
+	}
+	placeholders["knowledge"] = passages
+
 	if ctx.Job.PlaceholdersValues != nil {
 		for placeholder, value := range ctx.Job.PlaceholdersValues {
 			placeholders[placeholder] = value
@@ -33,7 +33,7 @@ func NewPublishArticleCommand(
 	return &PublishArticleCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"publish_article",
-			pipeline.StateOutputValidated,
+			pipeline.StateImageGenerated,
 			pipeline.StatePublished,
 		),
 		wpClient:          wpClient,
@@ -75,19 +75,21 @@ func (c *PublishArticleCommand) Execute(ctx *pipeline.Context) error {
 
 	now := time.Now()
 	article := &entities.Article{
-		SiteID:        ctx.Job.SiteID,
-		JobID:         &ctx.Job.ID,
-		TopicID:       &ctx.Selection.VariationTopic.ID,
-		Title:         ctx.Generation.GeneratedTitle,
-		Excerpt:       &ctx.Generation.GeneratedExcerpt,
-		OriginalTitle: ctx.Selection.OriginalTopic.Title,
-		Content:       ctx.Generation.GeneratedContent,
-		WPCategoryIDs: categoryIDs,
-		Status:        desiredStatus,
-		Source:        entities.SourceGenerated,
-		IsEdited:      false,
-		CreatedAt:     now,
-		UpdatedAt:     now,
+		SiteID:           ctx.Job.SiteID,
+		JobID:            &ctx.Job.ID,
+		TopicID:          &ctx.Selection.VariationTopic.ID,
+		Title:            ctx.Generation.GeneratedTitle,
+		Excerpt:          &ctx.Generation.GeneratedExcerpt,
+		OriginalTitle:    ctx.Selection.OriginalTopic.Title,
+		Content:          ctx.Generation.GeneratedContent,
+		WPCategoryIDs:    categoryIDs,
+		FeaturedMediaID:  ctx.Generation.FeaturedMediaID,
+		FeaturedMediaURL: ctx.Generation.FeaturedMediaURL,
+		Status:           desiredStatus,
+		Source:           entities.SourceGenerated,
+		IsEdited:         false,
+		CreatedAt:        now,
+		UpdatedAt:        now,
 	}
 
 	wordCount := len(strings.Fields(ctx.Generation.GeneratedContent))
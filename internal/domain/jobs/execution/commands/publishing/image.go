@@ -0,0 +1,67 @@
+package publishing
+
+import (
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/images"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/commands"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/fault"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
+)
+
+var _ pipeline.Command = (*FeaturedImageCommand)(nil)
+
+// FeaturedImageCommand generates and uploads the featured image of the article when the
+// job or its prompt asks for one. A failed image never stops the article from publishing.
+type FeaturedImageCommand struct {
+	*commands.BaseCommand
+	imageService images.Service
+}
+
+func NewFeaturedImageCommand(imageService images.Service) *FeaturedImageCommand {
+	return &FeaturedImageCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"featured_image",
+			pipeline.StateOutputValidated,
+			pipeline.StateImageGenerated,
+		),
+		imageService: imageService,
+	}
+}
+
+func (c *FeaturedImageCommand) Execute(ctx *pipeline.Context) error {
+	if !ctx.HasExecution() {
+		return fault.NewFatalError(fault.ErrCodeInvalidJob, c.Name(), "execution not created")
+	}
+
+	if !ctx.HasGeneration() {
+		return fault.NewFatalError(fault.ErrCodeInvalidJob, c.Name(), "content not generated")
+	}
+
+	var params *entities.GenerationParams
+	if ctx.Execution.Prompt != nil {
+		params = ctx.Execution.Prompt.GenerationParams
+	}
+	if !params.Merge(ctx.Job.GenerationParams).WantsFeaturedImage() {
+		return nil
+	}
+
+	media, err := c.imageService.CreateFeaturedImage(
+		ctx.Context(),
+		ctx.Validated.Site,
+		ctx.Execution.Provider.ID,
+		ctx.Generation.GeneratedTitle,
+		ctx.Generation.GeneratedExcerpt,
+	)
+	if err != nil {
+		ctx.Logger().Warnf("Publishing job %d article without featured image: %v", ctx.Job.ID, err)
+		return nil
+	}
+
+	ctx.Generation.FeaturedMediaID = &media.ID
+	ctx.Generation.FeaturedMediaURL = &media.SourceURL
+	return nil
+}
+
+func (c *FeaturedImageCommand) NextState() pipeline.State {
+	return pipeline.StateImageGenerated
+}
@@ -2,14 +2,19 @@ package execution
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
 	"github.com/davidmovas/postulator/internal/domain/articles"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/categories"
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/images"
 	"github.com/davidmovas/postulator/internal/domain/jobs"
 	"github.com/davidmovas/postulator/internal/domain/jobs/execution/phase"
 	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
+	"github.com/davidmovas/postulator/internal/domain/knowledge"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
 	"github.com/davidmovas/postulator/internal/domain/sites"
@@ -21,12 +26,19 @@ import (
 )
 
 type Executor struct {
-	pipeline *pipeline.Pipeline
-	logger   *logger.Logger
+	pipeline    *pipeline.Pipeline
+	running     sync.Map // run id -> *run
+	nextRun     atomic.Int64
+	checkpoints CheckpointRepository
+	execRepo    Repository
+	jobRepo     jobs.Repository
+	restorer    contextRestorer
+	logger      *logger.Logger
 }
 
 func NewExecutor(
 	execRepo Repository,
+	checkpointRepo CheckpointRepository,
 	articleRepo articles.Repository,
 	stateRepo jobs.StateRepository,
 	jobRepo jobs.Repository,
@@ -37,20 +49,25 @@ func NewExecutor(
 	providerService providers.Service,
 	categoryService categories.Service,
 	aiUsageService aiusage.Service,
+	budgetService budgets.Service,
+	imageService images.Service,
+	knowledgeService knowledge.Service,
 	wpClient wp.Client,
 	logger *logger.Logger,
 ) jobs.Executor {
 	builder := pipeline.NewPipelineBuilder().
 		WithLogger(logger).
 		WithEventBus(events.GetGlobalEventBus()).
+		WithCheckpointer(&checkpointer{checkpoints: checkpointRepo, executions: execRepo}).
 		AddCommands(
 			phase.ValidateJobCommand(siteService, topicService, providerService),
 			phase.SelectTopicCommand(),
 			phase.SelectCategoryCommand(categoryService, stateRepo),
 			phase.CreateExecutionCommand(execRepo, providerService, promptService),
-			phase.RenderPromptCommand(promptService),
-			phase.GenerateContentCommand(execRepo, statsRecorder, aiUsageService),
+			phase.RenderPromptCommand(promptService, knowledgeService),
+			phase.GenerateContentCommand(execRepo, statsRecorder, aiUsageService, budgetService),
 			phase.ValidateOutputCommand(),
+			phase.FeaturedImageCommand(imageService),
 			phase.PublishArticleCommand(execRepo, articleRepo, wpClient, statsRecorder),
 			phase.RecordCategoryStatsCommand(categoryService),
 			phase.MarkTopicUsedCommand(),
@@ -58,12 +75,61 @@ func NewExecutor(
 		)
 
 	return &Executor{
-		pipeline: builder.Build(),
-		logger:   logger.WithScope("executor"),
+		pipeline:    builder.Build(),
+		checkpoints: checkpointRepo,
+		execRepo:    execRepo,
+		jobRepo:     jobRepo,
+		restorer: &restorer{
+			siteService:     siteService,
+			topicService:    topicService,
+			categoryService: categoryService,
+			promptService:   promptService,
+			providerService: providerService,
+			articleRepo:     articleRepo,
+		},
+		logger: logger.WithScope("executor"),
 	}
 }
 
+// run is a pipeline run in progress. A job may have several, a manual run next to a
+// scheduled one, so each is tracked on its own.
+type run struct {
+	jobID  int64
+	cancel context.CancelFunc
+}
+
 func (e *Executor) Execute(ctx context.Context, job *entities.Job) error {
 	e.logger.Infof("Starting new pipeline execution for job %d (%s)", job.ID, job.Name)
-	return e.pipeline.Execute(ctx, job)
+
+	execCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer e.track(job.ID, cancel)()
+
+	return e.pipeline.Execute(execCtx, job)
+}
+
+// Cancel aborts every run of the job in progress
+func (e *Executor) Cancel(jobID int64) bool {
+	cancelled := false
+	e.running.Range(func(_, value any) bool {
+		if r := value.(*run); r.jobID == jobID {
+			r.cancel()
+			cancelled = true
+		}
+		return true
+	})
+
+	if cancelled {
+		e.logger.Infof("Cancelling pipeline execution for job %d", jobID)
+	}
+	return cancelled
+}
+
+// track registers a run for Cancel and returns the func that removes it once it ended
+func (e *Executor) track(jobID int64, cancel context.CancelFunc) func() {
+	id := e.nextRun.Add(1)
+	e.running.Store(id, &run{jobID: jobID, cancel: cancel})
+	return func() {
+		e.running.Delete(id)
+	}
 }
@@ -0,0 +1,44 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+func TestCancelReachesOverlappingRuns(t *testing.T) {
+	e := &Executor{logger: logger.Global()}
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	defer cancelFirst()
+	second, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+	other, cancelOther := context.WithCancel(context.Background())
+	defer cancelOther()
+
+	untrackFirst := e.track(7, cancelFirst)
+	untrackSecond := e.track(7, cancelSecond)
+	defer e.track(8, cancelOther)()
+
+	// The first run ending must not untrack the second one
+	untrackFirst()
+
+	if !e.Cancel(7) {
+		t.Fatal("expected the second run of job 7 to be cancelled")
+	}
+	if second.Err() == nil {
+		t.Error("expected the second run to be cancelled")
+	}
+	if first.Err() != nil {
+		t.Error("expected the ended run to be left alone")
+	}
+	if other.Err() != nil {
+		t.Error("expected the run of another job to be left alone")
+	}
+
+	untrackSecond()
+	if e.Cancel(7) {
+		t.Error("expected no run of job 7 left to cancel")
+	}
+}
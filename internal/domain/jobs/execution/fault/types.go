@@ -3,6 +3,9 @@ package fault
 import (
 	"errors"
 	"fmt"
+	"time"
+
+	appErrors "github.com/davidmovas/postulator/pkg/errors"
 )
 
 type ErrorType string
@@ -28,8 +31,17 @@ const (
 	ErrCodeNoCategories ErrorCode = "no_categories"
 	ErrCodeNoProvider   ErrorCode = "no_provider"
 
+	ErrCodeBudgetExceeded ErrorCode = "budget_exceeded"
+
 	ErrCodePromptRenderFailed ErrorCode = "prompt_render_failed"
 	ErrCodeAIGenerationFailed ErrorCode = "ai_generation_failed"
+	ErrCodeAIRateLimited      ErrorCode = "ai_rate_limited"
+	ErrCodeAIQuotaExceeded    ErrorCode = "ai_quota_exceeded"
+	ErrCodeAIContextLength    ErrorCode = "ai_context_length"
+	ErrCodeAIContentFiltered  ErrorCode = "ai_content_filtered"
+	ErrCodeAIAuthInvalid      ErrorCode = "ai_auth_invalid"
+	ErrCodeAIUnavailable      ErrorCode = "ai_unavailable"
+	ErrCodeAIModelNotFound    ErrorCode = "ai_model_not_found"
 	ErrCodeEmptyContent       ErrorCode = "empty_content"
 	ErrCodeInvalidOutput      ErrorCode = "invalid_output"
 
@@ -43,6 +55,7 @@ const (
 
 	ErrCodeNetworkError ErrorCode = "network_error"
 	ErrCodeTimeout      ErrorCode = "timeout"
+	ErrCodeCancelled    ErrorCode = "cancelled"
 )
 
 type PipelineError struct {
@@ -54,6 +67,8 @@ type PipelineError struct {
 	Cause     error
 	Context   map[string]any
 	Retryable bool
+	// RetryAfter is the minimum delay before a retry, as requested by the provider
+	RetryAfter time.Duration
 }
 
 func (e *PipelineError) Error() string {
@@ -139,22 +154,45 @@ func WrapError(err error, code ErrorCode, step string, message string) *Pipeline
 	retryable := false
 
 	switch code {
-	case ErrCodeNetworkError, ErrCodeTimeout:
+	case ErrCodeNetworkError, ErrCodeTimeout, ErrCodeAIRateLimited, ErrCodeAIUnavailable:
 		errType = ErrorTypeRetryable
 		retryable = true
 	case ErrCodeNoTopics, ErrCodeNoCategories:
 		errType = ErrorTypeRecoverable
-	case ErrCodeInvalidJob, ErrCodeInactiveSite, ErrCodeMissingConfig:
+	case ErrCodeInvalidJob, ErrCodeInactiveSite, ErrCodeMissingConfig, ErrCodeBudgetExceeded:
 		errType = ErrorTypeValidation
 	}
 
 	return &PipelineError{
-		Type:      errType,
-		Code:      code,
-		Step:      step,
-		Message:   message,
-		Cause:     err,
-		Retryable: retryable,
+		Type:       errType,
+		Code:       code,
+		Step:       step,
+		Message:    message,
+		Cause:      err,
+		Retryable:  retryable,
+		RetryAfter: appErrors.RetryAfter(err),
+	}
+}
+
+// AICode maps a classified AI provider error onto its pipeline error code
+func AICode(err error) ErrorCode {
+	switch appErrors.AICode(err) {
+	case appErrors.ErrCodeAIRateLimit:
+		return ErrCodeAIRateLimited
+	case appErrors.ErrCodeAIQuotaExceeded:
+		return ErrCodeAIQuotaExceeded
+	case appErrors.ErrCodeAIContextLength:
+		return ErrCodeAIContextLength
+	case appErrors.ErrCodeAIContentFilter:
+		return ErrCodeAIContentFiltered
+	case appErrors.ErrCodeAIAuth:
+		return ErrCodeAIAuthInvalid
+	case appErrors.ErrCodeAIUnavailable:
+		return ErrCodeAIUnavailable
+	case appErrors.ErrCodeAIModelNotFound:
+		return ErrCodeAIModelNotFound
+	default:
+		return ErrCodeAIGenerationFailed
 	}
 }
 
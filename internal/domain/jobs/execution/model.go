@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
 )
 
 type Repository interface {
@@ -13,6 +14,8 @@ type Repository interface {
 	GetByJobID(ctx context.Context, jobID int64, limit, offset int) ([]*entities.Execution, int, error)
 	GetPendingValidation(ctx context.Context) ([]*entities.Execution, error)
 	GetByStatus(ctx context.Context, status entities.Status) ([]*entities.Execution, error)
+	// GetUnfinished returns the executions still pending, generating or publishing
+	GetUnfinished(ctx context.Context) ([]*entities.Execution, error)
 	Update(ctx context.Context, exec *entities.Execution) error
 	Delete(ctx context.Context, id int64) error
 
@@ -22,6 +25,13 @@ type Repository interface {
 	GetAverageGenerationTime(ctx context.Context, jobID int64) (int, error)
 }
 
+// CheckpointRepository stores the checkpoints of running executions
+type CheckpointRepository interface {
+	Save(ctx context.Context, checkpoint *pipeline.Checkpoint) error
+	GetAll(ctx context.Context) ([]*pipeline.Checkpoint, error)
+	Delete(ctx context.Context, executionID int64) error
+}
+
 type Service interface {
 	CreateExecution(ctx context.Context, exec *entities.Execution) error
 	GetExecution(ctx context.Context, id int64) (*entities.Execution, error)
@@ -17,6 +17,7 @@ var (
 	CreateExecutionCommand     = execution.NewCreateExecutionCommand
 	RenderPromptCommand        = generation.NewRenderPromptCommand
 	GenerateContentCommand     = generation.NewGenerateContentCommand
+	FeaturedImageCommand       = publishing.NewFeaturedImageCommand
 	PublishArticleCommand      = publishing.NewPublishArticleCommand
 	RecordCategoryStatsCommand = tracking.NewRecordCategoryStatsCommand
 	MarkTopicUsedCommand       = tracking.NewMarkTopicUsedCommand
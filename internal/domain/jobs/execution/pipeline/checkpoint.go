@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+// Checkpoint is the progress of a run persisted after each command: its state and the
+// generated content, which cannot be produced again without paying for it twice.
+// Everything else is loaded again from the execution record.
+type Checkpoint struct {
+	ExecutionID     int64             `json:"execution_id"`
+	JobID           int64             `json:"job_id"`
+	State           State             `json:"state"`
+	History         []StateTransition `json:"history"`
+	OriginalTopicID int64             `json:"original_topic_id,omitempty"`
+	Generation      *GenerationPhase  `json:"generation,omitempty"`
+	ArticleID       *int64            `json:"article_id,omitempty"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// HasContent reports whether the checkpoint holds generated content ready to publish
+func (c *Checkpoint) HasContent() bool {
+	return c.Generation != nil && c.Generation.GeneratedTitle != "" && c.Generation.GeneratedContent != ""
+}
+
+// Checkpointer persists the progress of runs, so that a run interrupted by a crash or
+// by quitting the app can be recovered on the next start
+type Checkpointer interface {
+	// Save stores the checkpoint reached once a command completed
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+	// Release drops the checkpoint of a run that ended. cause is the error that failed
+	// the run, nil when it completed or paused.
+	Release(ctx context.Context, checkpoint *Checkpoint, cause error) error
+}
+
+// Checkpoint returns the current progress of the run, nil until its execution record
+// was created since there is nothing to recover before
+func (c *Context) Checkpoint() *Checkpoint {
+	exec := c.GetExecution()
+	if exec == nil || exec.ID == 0 {
+		return nil
+	}
+
+	checkpoint := &Checkpoint{
+		ExecutionID: exec.ID,
+		JobID:       c.Job.ID,
+		State:       c.State.CurrentState(),
+		History:     c.State.History(),
+		Generation:  c.Generation,
+		ArticleID:   exec.ArticleID,
+		UpdatedAt:   time.Now(),
+	}
+
+	if original := c.GetOriginalTopic(); original != nil {
+		checkpoint.OriginalTopicID = original.ID
+	}
+
+	return checkpoint
+}
+
+// RestoreContext rebuilds the context of an interrupted run from its checkpoint. The
+// caller loads the site, selection and execution phases again before resuming it.
+func RestoreContext(job *entities.Job, checkpoint *Checkpoint, state State) *Context {
+	pctx := NewContext(job)
+	pctx.State = RestoreStateMachine(checkpoint.State, checkpoint.History)
+	if state != checkpoint.State {
+		pctx.State.Restore(state, "recovered after restart")
+	}
+	pctx.Generation = checkpoint.Generation
+	return pctx
+}
@@ -44,6 +44,8 @@ type ExecutionPhase struct {
 	Execution *entities.Execution
 	Prompt    *entities.Prompt
 	Provider  *entities.Provider
+	// Fallbacks are tried in order when Provider fails to generate the article
+	Fallbacks []*entities.Provider
 }
 
 type GenerationPhase struct {
@@ -55,6 +57,9 @@ type GenerationPhase struct {
 	TokensUsed       int
 	CostUSD          float64
 	GenerationTimeMs int
+	// FeaturedMediaID and FeaturedMediaURL are set once a featured image was uploaded
+	FeaturedMediaID  *int
+	FeaturedMediaURL *string
 }
 
 type PublicationPhase struct {
@@ -125,11 +130,12 @@ func (c *Context) InitSelectionPhase(original, variation *entities.Topic, catego
 	}
 }
 
-func (c *Context) InitExecutionPhase(exec *entities.Execution, prompt *entities.Prompt, provider *entities.Provider) {
+func (c *Context) InitExecutionPhase(exec *entities.Execution, prompt *entities.Prompt, provider *entities.Provider, fallbacks ...*entities.Provider) {
 	c.Execution = &ExecutionPhase{
 		Execution: exec,
 		Prompt:    prompt,
 		Provider:  provider,
+		Fallbacks: fallbacks,
 	}
 }
 
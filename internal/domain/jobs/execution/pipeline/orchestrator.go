@@ -20,6 +20,7 @@ type Pipeline struct {
 	errorHandler  fault.ErrorHandler
 	logger        *logger.Logger
 	retryStrategy RetryStrategy
+	checkpointer  Checkpointer
 }
 
 type Builder struct {
@@ -28,6 +29,7 @@ type Builder struct {
 	errorHandler  fault.ErrorHandler
 	logger        *logger.Logger
 	retryStrategy RetryStrategy
+	checkpointer  Checkpointer
 }
 
 func NewPipelineBuilder() *Builder {
@@ -59,6 +61,11 @@ func (b *Builder) WithRetryStrategy(strategy RetryStrategy) *Builder {
 	return b
 }
 
+func (b *Builder) WithCheckpointer(checkpointer Checkpointer) *Builder {
+	b.checkpointer = checkpointer
+	return b
+}
+
 func (b *Builder) AddCommand(cmd Command) *Builder {
 	b.commands = append(b.commands, cmd)
 	return b
@@ -76,6 +83,7 @@ func (b *Builder) Build() *Pipeline {
 		errorHandler:  b.errorHandler,
 		logger:        b.logger,
 		retryStrategy: b.retryStrategy,
+		checkpointer:  b.checkpointer,
 	}
 }
 
@@ -95,7 +103,35 @@ func (p *Pipeline) Execute(ctx context.Context, job *entities.Job) error {
 
 	p.log("Pipeline started for job %d (%s)", job.ID, job.Name)
 
+	return p.run(ctx, pctx, 0)
+}
+
+// Resume continues a run restored from its checkpoint with the first command that
+// starts from the restored state
+func (p *Pipeline) Resume(ctx context.Context, pctx *Context) error {
+	pctx.WithContext(ctx).WithLogger(p.logger)
+
 	for i, cmd := range p.commands {
+		if cmd.RequiredState() == pctx.State.CurrentState() {
+			p.log("Pipeline resumed for job %d at state %s", pctx.Job.ID, pctx.State.CurrentState())
+			return p.run(ctx, pctx, i)
+		}
+	}
+
+	err := fmt.Errorf("no command resumes from state %s", pctx.State.CurrentState())
+	p.releaseCheckpoint(pctx, err)
+	return err
+}
+
+func (p *Pipeline) run(ctx context.Context, pctx *Context, from int) (err error) {
+	job := pctx.Job
+	defer func() {
+		p.releaseCheckpoint(pctx, err)
+	}()
+
+	for i := from; i < len(p.commands); i++ {
+		cmd := p.commands[i]
+
 		if ctx.Err() != nil {
 			return p.handleCancellation(pctx, ctx.Err())
 		}
@@ -113,6 +149,8 @@ func (p *Pipeline) Execute(ctx context.Context, job *entities.Job) error {
 			return p.handleError(pctx, cmd, err)
 		}
 
+		p.saveCheckpoint(pctx)
+
 		if pctx.State.IsFinalState() {
 			p.log("Pipeline reached final state: %s", pctx.State.CurrentState())
 			break
@@ -181,7 +219,7 @@ func (p *Pipeline) executeCommand(ctx *Context, cmd Command, cmdIndex int) error
 
 			p.log("Retrying command %s (attempt %d/%d)", cmdName, attempt, maxRetries)
 
-			if err := p.retryStrategy.Wait(ctx.Context(), attempt); err != nil {
+			if err := p.retryStrategy.Wait(ctx.Context(), attempt, lastErr); err != nil {
 				return err
 			}
 		}
@@ -274,11 +312,18 @@ func (p *Pipeline) handleError(ctx *Context, cmd Command, err error) error {
 	case fault.ActionPause:
 		if pErr != nil {
 			pauseState := StatePausedForValidation
-			if pErr.Code == fault.ErrCodeNoTopics || pErr.Code == fault.ErrCodeNoCategories {
+			switch pErr.Code {
+			case fault.ErrCodeNoTopics, fault.ErrCodeNoCategories:
 				pauseState = StatePausedNoResources
+			case fault.ErrCodeBudgetExceeded:
+				pauseState = StatePausedBudget
 			}
 			_ = ctx.State.Transition(pauseState, fmt.Sprintf("paused at %s: %s", cmd.Name(), err.Error()))
 			p.publishPausedEvent(ctx)
+			// The scheduler has to pause the job itself until the budget allows new spend
+			if pauseState == StatePausedBudget {
+				return pErr
+			}
 			return nil
 		}
 
@@ -305,6 +350,44 @@ func (p *Pipeline) handleCancellation(pctx *Context, err error) error {
 	return err
 }
 
+func (p *Pipeline) saveCheckpoint(pctx *Context) {
+	if p.checkpointer == nil {
+		return
+	}
+
+	checkpoint := pctx.Checkpoint()
+	if checkpoint == nil {
+		return
+	}
+
+	// The run goes on without a checkpoint, it only matters if the app quits meanwhile
+	if err := p.checkpointer.Save(ctx.FastCtx(), checkpoint); err != nil && p.logger != nil {
+		p.logger.Warnf("Failed to save checkpoint of execution %d at state %s: %v", checkpoint.ExecutionID, checkpoint.State, err)
+	}
+}
+
+// releaseCheckpoint drops the checkpoint of a run that ended, whatever the outcome.
+// Only a run cut short by the process exiting leaves its checkpoint behind.
+func (p *Pipeline) releaseCheckpoint(pctx *Context, err error) {
+	if p.checkpointer == nil {
+		return
+	}
+
+	checkpoint := pctx.Checkpoint()
+	if checkpoint == nil {
+		return
+	}
+
+	var cause error
+	if err != nil && !pctx.State.IsPausedState() {
+		cause = err
+	}
+
+	if releaseErr := p.checkpointer.Release(ctx.FastCtx(), checkpoint, cause); releaseErr != nil && p.logger != nil {
+		p.logger.Warnf("Failed to release checkpoint of execution %d: %v", checkpoint.ExecutionID, releaseErr)
+	}
+}
+
 func (p *Pipeline) publishEvent(event events.Event) {
 	if p.eventBus != nil {
 		p.eventBus.Publish(ctx.FastCtx(), event)
@@ -357,6 +440,8 @@ func (p *Pipeline) publishPausedEvent(ctx *Context) {
 		reason = "requires validation"
 	} else if state == StatePausedNoResources {
 		reason = "no resources available"
+	} else if state == StatePausedBudget {
+		reason = "AI budget exceeded"
 	}
 
 	p.publishEvent(events.NewEvent(
@@ -400,27 +485,39 @@ func (p *Pipeline) log(format string, args ...any) {
 }
 
 type RetryStrategy interface {
-	Wait(ctx context.Context, attempt int) error
+	// Wait blocks before the given retry attempt. lastErr is the failure being retried.
+	Wait(ctx context.Context, attempt int, lastErr error) error
 }
 
+// ExponentialBackoffRetry doubles the delay on every attempt. A provider asking to
+// back off (429 with Retry-After) is honored up to MaxRetryAfter.
 type ExponentialBackoffRetry struct {
-	BaseDelay time.Duration
-	MaxDelay  time.Duration
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	MaxRetryAfter time.Duration
 }
 
-func (r *ExponentialBackoffRetry) Wait(ctx context.Context, attempt int) error {
+func (r *ExponentialBackoffRetry) Wait(ctx context.Context, attempt int, lastErr error) error {
 	if r.BaseDelay == 0 {
 		r.BaseDelay = 100 * time.Millisecond
 	}
 	if r.MaxDelay == 0 {
 		r.MaxDelay = 10 * time.Second
 	}
+	if r.MaxRetryAfter == 0 {
+		r.MaxRetryAfter = 2 * time.Minute
+	}
 
 	delay := r.BaseDelay * time.Duration(1<<uint(attempt))
 	if delay > r.MaxDelay {
 		delay = r.MaxDelay
 	}
 
+	var pErr *fault.PipelineError
+	if errors.As(lastErr, &pErr) && pErr.RetryAfter > delay {
+		delay = min(pErr.RetryAfter, r.MaxRetryAfter)
+	}
+
 	select {
 	case <-time.After(delay):
 		return nil
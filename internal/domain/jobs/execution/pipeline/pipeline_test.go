@@ -0,0 +1,438 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/config"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/commands"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/fault"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/phase"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/internal/infra/events"
+	appErrors "github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logDir, err := os.MkdirTemp("", "postulator_pipeline_test")
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err = logger.New(&config.Config{LogLevel: "error", LogDir: logDir}); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	_ = os.RemoveAll(logDir)
+	os.Exit(code)
+}
+
+// stepCommand is a stand-in for the commands that need sites, topics or WordPress
+type stepCommand struct {
+	*commands.BaseCommand
+	run func(ctx *pipeline.Context) error
+}
+
+func step(name string, from, to pipeline.State, run func(ctx *pipeline.Context) error) *stepCommand {
+	return &stepCommand{
+		BaseCommand: commands.NewBaseCommand(name, from, to),
+		run:         run,
+	}
+}
+
+func (c *stepCommand) Execute(ctx *pipeline.Context) error {
+	if c.run == nil {
+		return nil
+	}
+	return c.run(ctx)
+}
+
+type memoryExecutions struct {
+	mu     sync.Mutex
+	nextID int64
+	execs  map[int64]*entities.Execution
+}
+
+func newMemoryExecutions() *memoryExecutions {
+	return &memoryExecutions{execs: make(map[int64]*entities.Execution)}
+}
+
+func (m *memoryExecutions) Create(_ context.Context, exec *entities.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	exec.ID = m.nextID
+	cp := *exec
+	m.execs[exec.ID] = &cp
+	return nil
+}
+
+func (m *memoryExecutions) Update(_ context.Context, exec *entities.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *exec
+	m.execs[exec.ID] = &cp
+	return nil
+}
+
+func (m *memoryExecutions) Get(id int64) *entities.Execution {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.execs[id]
+}
+
+type countingRecorder struct {
+	mu        sync.Mutex
+	published int
+	failed    int
+}
+
+func (r *countingRecorder) RecordArticlePublished(context.Context, int64, int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.published++
+	return nil
+}
+
+func (r *countingRecorder) RecordArticleFailed(context.Context, int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed++
+	return nil
+}
+
+func (r *countingRecorder) RecordLinksCreated(context.Context, int64, int, int) error {
+	return nil
+}
+
+// exhaustedBudgets reports the budget of the given providers as used up
+type exhaustedBudgets struct {
+	budgets.Service
+	providers map[int64]bool
+}
+
+func (b *exhaustedBudgets) Check(_ context.Context, _, providerID int64) error {
+	if b.providers[providerID] {
+		return appErrors.BudgetExceeded("provider", 10, 10)
+	}
+	return nil
+}
+
+// memoryCheckpoints keeps every checkpoint saved, by state
+type memoryCheckpoints struct {
+	mu       sync.Mutex
+	saved    map[pipeline.State]pipeline.Checkpoint
+	released bool
+	cause    error
+}
+
+func newMemoryCheckpoints() *memoryCheckpoints {
+	return &memoryCheckpoints{saved: make(map[pipeline.State]pipeline.Checkpoint)}
+}
+
+func (m *memoryCheckpoints) Save(_ context.Context, checkpoint *pipeline.Checkpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saved[checkpoint.State] = *checkpoint
+	return nil
+}
+
+func (m *memoryCheckpoints) Release(_ context.Context, _ *pipeline.Checkpoint, cause error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.released = true
+	m.cause = cause
+	return nil
+}
+
+type noWaitRetry struct{}
+
+func (noWaitRetry) Wait(ctx context.Context, _ int, _ error) error {
+	return ctx.Err()
+}
+
+type harness struct {
+	executions  *memoryExecutions
+	recorder    *countingRecorder
+	checkpoints *memoryCheckpoints
+	pipeline    *pipeline.Pipeline
+	generated   *pipeline.GenerationPhase
+}
+
+// newHarness builds the real generation and validation commands around stub
+// selection and publishing steps, so the pipeline runs fully offline
+func newHarness(provider *entities.Provider, fallbacks ...*entities.Provider) *harness {
+	return newBudgetHarness(nil, provider, fallbacks...)
+}
+
+func newBudgetHarness(budgetSvc budgets.Service, provider *entities.Provider, fallbacks ...*entities.Provider) *harness {
+	h := &harness{
+		executions:  newMemoryExecutions(),
+		recorder:    &countingRecorder{},
+		checkpoints: newMemoryCheckpoints(),
+	}
+
+	h.pipeline = pipeline.NewPipelineBuilder().
+		WithEventBus(events.NewEventBus()).
+		WithRetryStrategy(noWaitRetry{}).
+		WithCheckpointer(h.checkpoints).
+		WithLogger(logger.Global()).
+		AddCommands(
+			step("validate_job", pipeline.StateInitialized, pipeline.StateValidated, nil),
+			step("select_topic", pipeline.StateValidated, pipeline.StateTopicSelected, nil),
+			step("select_category", pipeline.StateTopicSelected, pipeline.StateCategorySelected, func(ctx *pipeline.Context) error {
+				topic := &entities.Topic{ID: 1, Title: "Offline testing"}
+				ctx.InitSelectionPhase(topic, topic)
+				return nil
+			}),
+			step("create_execution", pipeline.StateCategorySelected, pipeline.StateExecutionCreated, func(ctx *pipeline.Context) error {
+				exec := &entities.Execution{
+					JobID:        ctx.Job.ID,
+					SiteID:       ctx.Job.SiteID,
+					TopicID:      1,
+					AIProviderID: provider.ID,
+					AIModel:      provider.Model,
+					Status:       entities.ExecutionStatusPending,
+					StartedAt:    time.Now(),
+				}
+				if err := h.executions.Create(ctx.Context(), exec); err != nil {
+					return err
+				}
+				ctx.InitExecutionPhase(exec, &entities.Prompt{}, provider, fallbacks...)
+				return nil
+			}),
+			step("render_prompt", pipeline.StateExecutionCreated, pipeline.StatePromptRendered, func(ctx *pipeline.Context) error {
+				ctx.InitGenerationPhase()
+				ctx.Generation.SystemPrompt = "You are a helpful writer."
+				ctx.Generation.UserPrompt = "Offline testing\nWrite a short article."
+				return nil
+			}),
+			phase.GenerateContentCommand(h.executions, h.recorder, nil, budgetSvc),
+			phase.ValidateOutputCommand(),
+			step("publish_article", pipeline.StateOutputValidated, pipeline.StatePublished, func(ctx *pipeline.Context) error {
+				h.generated = ctx.Generation
+				return nil
+			}),
+			step("record_stats", pipeline.StatePublished, pipeline.StateRecordingStats, nil),
+			step("mark_used", pipeline.StateRecordingStats, pipeline.StateMarkingUsed, nil),
+			step("complete", pipeline.StateMarkingUsed, pipeline.StateCompleted, nil),
+		).
+		Build()
+
+	return h
+}
+
+func mockProvider(id int64, model, fixturesDir string) *entities.Provider {
+	return &entities.Provider{
+		ID:       id,
+		Name:     "mock-" + model,
+		Type:     entities.TypeMock,
+		Model:    model,
+		BaseURL:  fixturesDir,
+		IsActive: true,
+	}
+}
+
+func testJob() *entities.Job {
+	return &entities.Job{ID: 7, Name: "offline job", SiteID: 3}
+}
+
+func TestPipelineGeneratesWithMockProvider(t *testing.T) {
+	h := newHarness(mockProvider(1, ai.MockModelSynthetic, ""))
+
+	if err := h.pipeline.Execute(context.Background(), testJob()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if h.generated == nil || h.generated.GeneratedTitle != "Offline testing" {
+		t.Fatalf("expected generated article titled from the prompt, got %+v", h.generated)
+	}
+	if h.generated.GeneratedContent == "" {
+		t.Error("expected generated content")
+	}
+
+	exec := h.executions.Get(1)
+	if exec.GeneratedAt == nil || exec.GenerationTimeMs == nil {
+		t.Error("expected execution to record generation time")
+	}
+	if exec.AIProviderID != 1 || exec.AIModel != ai.MockModelSynthetic {
+		t.Errorf("unexpected provider recorded: %d/%s", exec.AIProviderID, exec.AIModel)
+	}
+}
+
+func TestPipelineIsDeterministic(t *testing.T) {
+	first := newHarness(mockProvider(1, ai.MockModelSynthetic, ""))
+	second := newHarness(mockProvider(1, ai.MockModelSynthetic, ""))
+
+	if err := first.pipeline.Execute(context.Background(), testJob()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if err := second.pipeline.Execute(context.Background(), testJob()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if first.generated.GeneratedContent != second.generated.GeneratedContent {
+		t.Error("expected identical content across runs")
+	}
+}
+
+func TestPipelineFallsBackToNextProvider(t *testing.T) {
+	// An empty fixtures directory makes every replay request fail
+	primary := mockProvider(1, ai.MockModelReplay, t.TempDir())
+	fallback := mockProvider(2, ai.MockModelSynthetic, "")
+	h := newHarness(primary, fallback)
+
+	if err := h.pipeline.Execute(context.Background(), testJob()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	exec := h.executions.Get(1)
+	if exec.AIProviderID != fallback.ID || exec.AIModel != fallback.Model {
+		t.Errorf("expected execution to record fallback provider, got %d/%s", exec.AIProviderID, exec.AIModel)
+	}
+	if h.recorder.failed != 0 {
+		t.Errorf("expected no failed articles, got %d", h.recorder.failed)
+	}
+}
+
+func TestPipelineFailsWhenAllProvidersFail(t *testing.T) {
+	h := newHarness(mockProvider(1, ai.MockModelReplay, t.TempDir()))
+
+	err := h.pipeline.Execute(context.Background(), testJob())
+
+	var pErr *fault.PipelineError
+	if !errors.As(err, &pErr) || pErr.Code != fault.ErrCodeAIGenerationFailed {
+		t.Fatalf("expected AI generation failure, got %v", err)
+	}
+	if h.recorder.failed != 1 {
+		t.Errorf("expected one failed article, got %d", h.recorder.failed)
+	}
+	if h.generated != nil {
+		t.Error("expected publishing to be skipped")
+	}
+}
+
+func TestPipelinePausesWhenBudgetExceeded(t *testing.T) {
+	budgetSvc := &exhaustedBudgets{providers: map[int64]bool{1: true}}
+	h := newBudgetHarness(budgetSvc, mockProvider(1, ai.MockModelSynthetic, ""))
+
+	err := h.pipeline.Execute(context.Background(), testJob())
+
+	var pErr *fault.PipelineError
+	if !errors.As(err, &pErr) || pErr.Code != fault.ErrCodeBudgetExceeded || !appErrors.IsBudgetExceeded(err) {
+		t.Fatalf("expected budget exceeded error, got %v", err)
+	}
+	if h.recorder.failed != 0 {
+		t.Errorf("expected no failed articles, got %d", h.recorder.failed)
+	}
+	if exec := h.executions.Get(1); exec.Status != entities.ExecutionStatusPausedBudget || exec.ErrorMessage == nil || exec.CompletedAt == nil {
+		t.Errorf("expected execution to be closed as paused by the budget, got %+v", exec)
+	}
+	if h.generated != nil {
+		t.Error("expected publishing to be skipped")
+	}
+}
+
+func TestPipelineFallsBackWhenProviderBudgetExceeded(t *testing.T) {
+	budgetSvc := &exhaustedBudgets{providers: map[int64]bool{1: true}}
+	fallback := mockProvider(2, ai.MockModelSynthetic, "")
+	h := newBudgetHarness(budgetSvc, mockProvider(1, ai.MockModelSynthetic, ""), fallback)
+
+	if err := h.pipeline.Execute(context.Background(), testJob()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if exec := h.executions.Get(1); exec.AIProviderID != fallback.ID {
+		t.Errorf("expected fallback provider to generate, got %d", exec.AIProviderID)
+	}
+}
+
+func TestPipelineStopsWhenCancelled(t *testing.T) {
+	h := newHarness(mockProvider(1, ai.MockModelSynthetic, ""))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := h.pipeline.Execute(ctx, testJob()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if h.executions.Get(1) != nil {
+		t.Error("expected no execution to be created")
+	}
+}
+
+func TestPipelineCheckpointsEachStep(t *testing.T) {
+	h := newHarness(mockProvider(1, ai.MockModelSynthetic, ""))
+
+	if err := h.pipeline.Execute(context.Background(), testJob()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if _, ok := h.checkpoints.saved[pipeline.StateCategorySelected]; ok {
+		t.Error("expected no checkpoint before the execution record exists")
+	}
+
+	generated, ok := h.checkpoints.saved[pipeline.StateGenerated]
+	if !ok || !generated.HasContent() {
+		t.Fatalf("expected a checkpoint with the generated content, got %+v", generated)
+	}
+	if generated.ExecutionID != 1 || generated.OriginalTopicID != 1 || len(generated.History) == 0 {
+		t.Errorf("unexpected checkpoint %+v", generated)
+	}
+
+	if !h.checkpoints.released || h.checkpoints.cause != nil {
+		t.Errorf("expected checkpoint released without cause, got %v/%v", h.checkpoints.released, h.checkpoints.cause)
+	}
+}
+
+func TestPipelineReleasesCheckpointWithFailure(t *testing.T) {
+	h := newHarness(mockProvider(1, ai.MockModelReplay, t.TempDir()))
+
+	if err := h.pipeline.Execute(context.Background(), testJob()); err == nil {
+		t.Fatal("expected generation to fail")
+	}
+
+	if !h.checkpoints.released || h.checkpoints.cause == nil {
+		t.Error("expected checkpoint released with the failure")
+	}
+}
+
+func TestPipelineResumesFromCheckpoint(t *testing.T) {
+	first := newHarness(mockProvider(1, ai.MockModelSynthetic, ""))
+	if err := first.pipeline.Execute(context.Background(), testJob()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	checkpoint := first.checkpoints.saved[pipeline.StateOutputValidated]
+
+	// A fresh pipeline stands in for the app started again after quitting
+	h := newHarness(mockProvider(1, ai.MockModelSynthetic, ""))
+
+	job := testJob()
+	pctx := pipeline.RestoreContext(job, &checkpoint, checkpoint.State)
+	pctx.InitExecutionPhase(&entities.Execution{ID: checkpoint.ExecutionID, JobID: job.ID}, &entities.Prompt{}, mockProvider(1, ai.MockModelSynthetic, ""))
+
+	if err := h.pipeline.Resume(context.Background(), pctx); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+
+	if h.generated == nil || h.generated.GeneratedContent != checkpoint.Generation.GeneratedContent {
+		t.Fatal("expected the checkpointed content to be published")
+	}
+	if h.executions.Get(1) != nil {
+		t.Error("expected content not to be generated again")
+	}
+	if pctx.State.CurrentState() != pipeline.StateCompleted {
+		t.Errorf("expected resumed run to complete, got %s", pctx.State.CurrentState())
+	}
+	if !h.checkpoints.released || h.checkpoints.cause != nil {
+		t.Error("expected checkpoint released once the resumed run completed")
+	}
+}
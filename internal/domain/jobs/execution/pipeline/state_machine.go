@@ -16,6 +16,7 @@ const (
 	StatePromptRendered   State = "prompt_rendered"
 	StateGenerated        State = "generated"
 	StateOutputValidated  State = "output_validated"
+	StateImageGenerated   State = "image_generated"
 	StatePublished        State = "published"
 	StateRecordingStats   State = "recording_stats"
 	StateMarkingUsed      State = "marking_used"
@@ -23,6 +24,7 @@ const (
 
 	StatePausedForValidation State = "paused_for_validation"
 	StatePausedNoResources   State = "paused_no_resources"
+	StatePausedBudget        State = "paused_budget_exceeded"
 	StateFailed              State = "failed"
 )
 
@@ -50,6 +52,13 @@ func NewStateMachine(initialState State) *StateMachine {
 	return sm
 }
 
+// RestoreStateMachine rebuilds the state machine of a checkpointed run
+func RestoreStateMachine(state State, history []StateTransition) *StateMachine {
+	sm := NewStateMachine(state)
+	sm.history = append(sm.history, history...)
+	return sm
+}
+
 func (sm *StateMachine) defineTransitions() {
 	sm.transitions = map[State][]State{
 		StateInitialized: {
@@ -74,6 +83,7 @@ func (sm *StateMachine) defineTransitions() {
 		},
 		StatePromptRendered: {
 			StateGenerated,
+			StatePausedBudget,
 			StateFailed,
 		},
 		StateGenerated: {
@@ -81,6 +91,12 @@ func (sm *StateMachine) defineTransitions() {
 			StateFailed,
 		},
 		StateOutputValidated: {
+			StateImageGenerated,
+			StatePublished,
+			StatePausedForValidation,
+			StateFailed,
+		},
+		StateImageGenerated: {
 			StatePublished,
 			StatePausedForValidation,
 			StateFailed,
@@ -101,6 +117,7 @@ func (sm *StateMachine) defineTransitions() {
 		StateFailed:              {},
 		StatePausedForValidation: {},
 		StatePausedNoResources:   {},
+		StatePausedBudget:        {},
 	}
 }
 
@@ -141,6 +158,18 @@ func (sm *StateMachine) Transition(to State, reason string) error {
 	return nil
 }
 
+// Restore moves to a state without checking the transitions, for a recovered run whose
+// records show it got further than its last checkpoint
+func (sm *StateMachine) Restore(to State, reason string) {
+	sm.history = append(sm.history, StateTransition{
+		From:      sm.currentState,
+		To:        to,
+		Timestamp: time.Now(),
+		Reason:    reason,
+	})
+	sm.currentState = to
+}
+
 func (sm *StateMachine) History() []StateTransition {
 	return sm.history
 }
@@ -149,7 +178,8 @@ func (sm *StateMachine) IsFinalState() bool {
 	return sm.currentState == StateCompleted ||
 		sm.currentState == StateFailed ||
 		sm.currentState == StatePausedForValidation ||
-		sm.currentState == StatePausedNoResources
+		sm.currentState == StatePausedNoResources ||
+		sm.currentState == StatePausedBudget
 }
 
 func (sm *StateMachine) IsErrorState() bool {
@@ -158,5 +188,6 @@ func (sm *StateMachine) IsErrorState() bool {
 
 func (sm *StateMachine) IsPausedState() bool {
 	return sm.currentState == StatePausedForValidation ||
-		sm.currentState == StatePausedNoResources
+		sm.currentState == StatePausedNoResources ||
+		sm.currentState == StatePausedBudget
 }
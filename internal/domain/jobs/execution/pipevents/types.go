@@ -31,6 +31,7 @@ const (
 	EventNoTopicsAvailable EventType = "no_topics.available"
 
 	EventGenerationStarted   EventType = "generation.started"
+	EventGenerationProgress  EventType = "generation.progress"
 	EventGenerationCompleted EventType = "generation.completed"
 	EventGenerationFailed    EventType = "generation.failed"
 
@@ -143,6 +144,14 @@ type NoTopicsAvailableEvent struct {
 	Strategy string
 }
 
+type GenerationProgressEvent struct {
+	JobID        int64
+	ExecutionID  int64
+	Chars        int
+	Tokens       int
+	PartialTitle string
+}
+
 type GenerationCompletedEvent struct {
 	JobID          int64
 	ExecutionID    int64
@@ -0,0 +1,227 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidmovas/postulator/internal/domain/articles"
+	"github.com/davidmovas/postulator/internal/domain/categories"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/jobs"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
+	"github.com/davidmovas/postulator/internal/domain/prompts"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/domain/sites"
+	"github.com/davidmovas/postulator/internal/domain/topics"
+)
+
+// Recover goes through the executions left unfinished when the app last quit. Runs
+// that generated their content are returned to be resumed from their checkpoint, so
+// the paid content gets published. The others are marked failed with the reason:
+// generating again is up to the next scheduled run.
+func (e *Executor) Recover(ctx context.Context) ([]*jobs.Resumption, error) {
+	checkpoints, err := e.checkpoints.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resumptions []*jobs.Resumption
+	checkpointed := make(map[int64]bool, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		checkpointed[checkpoint.ExecutionID] = true
+		if resumption := e.recover(ctx, checkpoint); resumption != nil {
+			resumptions = append(resumptions, resumption)
+		}
+	}
+
+	// Executions interrupted before their first checkpoint was saved
+	unfinished, err := e.execRepo.GetUnfinished(ctx)
+	if err != nil {
+		return resumptions, err
+	}
+
+	for _, exec := range unfinished {
+		if !checkpointed[exec.ID] {
+			e.fail(ctx, exec, "interrupted when the app quit, before any progress was saved")
+		}
+	}
+
+	return resumptions, nil
+}
+
+func (e *Executor) recover(ctx context.Context, checkpoint *pipeline.Checkpoint) *jobs.Resumption {
+	exec, err := e.execRepo.GetByID(ctx, checkpoint.ExecutionID)
+	if err != nil {
+		e.logger.Errorf("Failed to load interrupted execution %d: %v", checkpoint.ExecutionID, err)
+		return nil
+	}
+
+	state := checkpoint.State
+
+	switch {
+	case isPublished(exec) && checkpoint.HasContent() && state != pipeline.StateCompleted:
+		// Published right before quitting, only the bookkeeping steps are left
+		if !isPublishedState(state) {
+			state = pipeline.StatePublished
+		}
+
+	case !isUnfinished(exec.Status) || state == pipeline.StateCompleted:
+		e.dropCheckpoint(ctx, exec.ID)
+		return nil
+
+	case exec.Status == entities.ExecutionStatusPublishing:
+		// Publishing again could create the post twice
+		e.fail(ctx, exec, "interrupted when the app quit while publishing to WordPress, "+
+			"the post may exist already: check the site before running the job again")
+		return nil
+
+	case !checkpoint.HasContent():
+		e.fail(ctx, exec, fmt.Sprintf("interrupted when the app quit at state %s, before the content was generated", state))
+		return nil
+	}
+
+	job, err := e.jobRepo.GetByID(ctx, exec.JobID)
+	if err != nil {
+		e.fail(ctx, exec, fmt.Sprintf("interrupted when the app quit and could not be resumed: %v", err))
+		return nil
+	}
+
+	pctx, err := e.restorer.restore(ctx, job, exec, checkpoint, state)
+	if err != nil {
+		e.fail(ctx, exec, fmt.Sprintf("interrupted when the app quit and could not be resumed: %v", err))
+		return nil
+	}
+
+	e.logger.Infof("Execution %d of job %d can resume from state %s", exec.ID, job.ID, state)
+	return &jobs.Resumption{
+		Job: job,
+		Run: func(ctx context.Context) error {
+			return e.resume(ctx, pctx)
+		},
+	}
+}
+
+func (e *Executor) resume(ctx context.Context, pctx *pipeline.Context) error {
+	e.logger.Infof("Resuming interrupted execution %d of job %d", pctx.GetExecution().ID, pctx.Job.ID)
+
+	execCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer e.track(pctx.Job.ID, cancel)()
+
+	return e.pipeline.Resume(execCtx, pctx)
+}
+
+func (e *Executor) fail(ctx context.Context, exec *entities.Execution, reason string) {
+	e.logger.Warnf("Execution %d of job %d %s", exec.ID, exec.JobID, reason)
+
+	if err := failExecution(ctx, e.execRepo, exec, reason); err != nil {
+		e.logger.Errorf("Failed to mark interrupted execution %d as failed: %v", exec.ID, err)
+		return
+	}
+
+	e.dropCheckpoint(ctx, exec.ID)
+}
+
+func (e *Executor) dropCheckpoint(ctx context.Context, executionID int64) {
+	if err := e.checkpoints.Delete(ctx, executionID); err != nil {
+		e.logger.Errorf("Failed to delete checkpoint of execution %d: %v", executionID, err)
+	}
+}
+
+// isPublished reports whether the article of the execution reached WordPress, as a
+// post or as a draft waiting for validation
+func isPublished(exec *entities.Execution) bool {
+	return exec.ArticleID != nil &&
+		(exec.Status == entities.ExecutionStatusPublished || exec.Status == entities.ExecutionStatusPendingValidation)
+}
+
+func isPublishedState(state pipeline.State) bool {
+	return state == pipeline.StatePublished ||
+		state == pipeline.StateRecordingStats ||
+		state == pipeline.StateMarkingUsed
+}
+
+// contextRestorer rebuilds the context of an interrupted run
+type contextRestorer interface {
+	restore(
+		ctx context.Context,
+		job *entities.Job,
+		exec *entities.Execution,
+		checkpoint *pipeline.Checkpoint,
+		state pipeline.State,
+	) (*pipeline.Context, error)
+}
+
+// restorer loads again what a checkpoint leaves out to resume its run
+type restorer struct {
+	siteService     sites.Service
+	topicService    topics.Service
+	categoryService categories.Service
+	promptService   prompts.Service
+	providerService providers.Service
+	articleRepo     articles.Repository
+}
+
+func (r *restorer) restore(
+	ctx context.Context,
+	job *entities.Job,
+	exec *entities.Execution,
+	checkpoint *pipeline.Checkpoint,
+	state pipeline.State,
+) (*pipeline.Context, error) {
+	site, err := r.siteService.GetSiteWithPassword(ctx, job.SiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, err := r.topicService.GetStrategy(job.TopicStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err := r.topicService.GetTopic(ctx, exec.TopicID)
+	if err != nil {
+		return nil, err
+	}
+
+	original := topic
+	if checkpoint.OriginalTopicID != 0 && checkpoint.OriginalTopicID != topic.ID {
+		if original, err = r.topicService.GetTopic(ctx, checkpoint.OriginalTopicID); err != nil {
+			return nil, err
+		}
+	}
+
+	var cats []*entities.Category
+	for _, id := range exec.CategoryIDs {
+		var category *entities.Category
+		if category, err = r.categoryService.GetCategory(ctx, id); err != nil {
+			return nil, err
+		}
+		cats = append(cats, category)
+	}
+
+	prompt, err := r.promptService.GetPrompt(ctx, exec.PromptID)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := r.providerService.GetProvider(ctx, exec.AIProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	pctx := pipeline.RestoreContext(job, checkpoint, state)
+	pctx.InitValidatedPhase(site, strategy)
+	pctx.InitSelectionPhase(original, topic, cats...)
+	pctx.InitExecutionPhase(exec, prompt, provider)
+
+	if exec.ArticleID != nil {
+		var article *entities.Article
+		if article, err = r.articleRepo.GetByID(ctx, *exec.ArticleID); err != nil {
+			return nil, err
+		}
+		pctx.InitPublicationPhase(article)
+	}
+
+	return pctx, nil
+}
@@ -0,0 +1,402 @@
+package execution
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/config"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/jobs"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/commands"
+	"github.com/davidmovas/postulator/internal/domain/jobs/execution/pipeline"
+	"github.com/davidmovas/postulator/internal/infra/events"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logDir, err := os.MkdirTemp("", "postulator_execution_test")
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err = logger.New(&config.Config{LogLevel: "error", LogDir: logDir}); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	_ = os.RemoveAll(logDir)
+	os.Exit(code)
+}
+
+type memoryExecutions struct {
+	Repository
+	mu    sync.Mutex
+	execs map[int64]*entities.Execution
+}
+
+func newMemoryExecutions(execs ...*entities.Execution) *memoryExecutions {
+	m := &memoryExecutions{execs: make(map[int64]*entities.Execution)}
+	for _, exec := range execs {
+		m.execs[exec.ID] = exec
+	}
+	return m
+}
+
+func (m *memoryExecutions) GetByID(_ context.Context, id int64) (*entities.Execution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	exec, ok := m.execs[id]
+	if !ok {
+		return nil, errors.NotFound("execution", id)
+	}
+	cp := *exec
+	return &cp, nil
+}
+
+func (m *memoryExecutions) GetUnfinished(context.Context) ([]*entities.Execution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var unfinished []*entities.Execution
+	for _, exec := range m.execs {
+		if isUnfinished(exec.Status) {
+			cp := *exec
+			unfinished = append(unfinished, &cp)
+		}
+	}
+	return unfinished, nil
+}
+
+func (m *memoryExecutions) Update(_ context.Context, exec *entities.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *exec
+	m.execs[exec.ID] = &cp
+	return nil
+}
+
+func (m *memoryExecutions) Get(id int64) *entities.Execution {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.execs[id]
+}
+
+type memoryCheckpoints struct {
+	mu          sync.Mutex
+	checkpoints map[int64]*pipeline.Checkpoint
+}
+
+func newMemoryCheckpoints(checkpoints ...*pipeline.Checkpoint) *memoryCheckpoints {
+	m := &memoryCheckpoints{checkpoints: make(map[int64]*pipeline.Checkpoint)}
+	for _, checkpoint := range checkpoints {
+		m.checkpoints[checkpoint.ExecutionID] = checkpoint
+	}
+	return m
+}
+
+func (m *memoryCheckpoints) Save(_ context.Context, checkpoint *pipeline.Checkpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[checkpoint.ExecutionID] = checkpoint
+	return nil
+}
+
+func (m *memoryCheckpoints) GetAll(context.Context) ([]*pipeline.Checkpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var checkpoints []*pipeline.Checkpoint
+	for _, checkpoint := range m.checkpoints {
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, nil
+}
+
+func (m *memoryCheckpoints) Delete(_ context.Context, executionID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.checkpoints, executionID)
+	return nil
+}
+
+func (m *memoryCheckpoints) Has(executionID int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.checkpoints[executionID]
+	return ok
+}
+
+type memoryJobs struct {
+	jobs.Repository
+	jobs map[int64]*entities.Job
+}
+
+func (m *memoryJobs) GetByID(_ context.Context, id int64) (*entities.Job, error) {
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, errors.NotFound("job", id)
+	}
+	return job, nil
+}
+
+// stubRestorer rebuilds the context without the site, topic and prompt lookups
+type stubRestorer struct{}
+
+func (stubRestorer) restore(
+	_ context.Context,
+	job *entities.Job,
+	exec *entities.Execution,
+	checkpoint *pipeline.Checkpoint,
+	state pipeline.State,
+) (*pipeline.Context, error) {
+	pctx := pipeline.RestoreContext(job, checkpoint, state)
+	pctx.InitExecutionPhase(exec, &entities.Prompt{}, &entities.Provider{})
+	return pctx, nil
+}
+
+type stepCommand struct {
+	*commands.BaseCommand
+	run func(ctx *pipeline.Context) error
+}
+
+func (c *stepCommand) Execute(ctx *pipeline.Context) error {
+	return c.run(ctx)
+}
+
+type recoveryHarness struct {
+	executions  *memoryExecutions
+	checkpoints *memoryCheckpoints
+	executor    *Executor
+
+	mu    sync.Mutex
+	steps []string
+}
+
+// newRecoveryHarness builds an executor whose pipeline records the steps it runs
+// from the generated content on
+func newRecoveryHarness(execs []*entities.Execution, checkpoints ...*pipeline.Checkpoint) *recoveryHarness {
+	h := &recoveryHarness{
+		executions:  newMemoryExecutions(execs...),
+		checkpoints: newMemoryCheckpoints(checkpoints...),
+	}
+
+	step := func(name string, from, to pipeline.State, run func(ctx *pipeline.Context)) pipeline.Command {
+		return &stepCommand{
+			BaseCommand: commands.NewBaseCommand(name, from, to),
+			run: func(ctx *pipeline.Context) error {
+				h.mu.Lock()
+				h.steps = append(h.steps, name)
+				h.mu.Unlock()
+				if run != nil {
+					run(ctx)
+				}
+				return nil
+			},
+		}
+	}
+
+	p := pipeline.NewPipelineBuilder().
+		WithEventBus(events.NewEventBus()).
+		WithCheckpointer(&checkpointer{checkpoints: h.checkpoints, executions: h.executions}).
+		WithLogger(logger.Global()).
+		AddCommands(
+			step("validate_output", pipeline.StateGenerated, pipeline.StateOutputValidated, nil),
+			step("publish_article", pipeline.StateOutputValidated, pipeline.StatePublished, func(ctx *pipeline.Context) {
+				articleID := int64(40)
+				exec := ctx.GetExecution()
+				exec.ArticleID = &articleID
+				exec.Status = entities.ExecutionStatusPublished
+				_ = h.executions.Update(ctx.Context(), exec)
+			}),
+			step("record_stats", pipeline.StatePublished, pipeline.StateRecordingStats, nil),
+			step("mark_used", pipeline.StateRecordingStats, pipeline.StateMarkingUsed, nil),
+			step("complete", pipeline.StateMarkingUsed, pipeline.StateCompleted, nil),
+		).
+		Build()
+
+	h.executor = &Executor{
+		pipeline:    p,
+		checkpoints: h.checkpoints,
+		execRepo:    h.executions,
+		jobRepo:     &memoryJobs{jobs: map[int64]*entities.Job{7: {ID: 7, SiteID: 3, Name: "recovered job"}}},
+		restorer:    stubRestorer{},
+		logger:      logger.Global(),
+	}
+
+	return h
+}
+
+func (h *recoveryHarness) Steps() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.steps...)
+}
+
+func execution(id int64, status entities.ExecutionStatus) *entities.Execution {
+	return &entities.Execution{ID: id, JobID: 7, SiteID: 3, TopicID: 1, Status: status, StartedAt: time.Now()}
+}
+
+func checkpointAt(executionID int64, state pipeline.State, withContent bool) *pipeline.Checkpoint {
+	checkpoint := &pipeline.Checkpoint{ExecutionID: executionID, JobID: 7, State: state, UpdatedAt: time.Now()}
+	if withContent {
+		checkpoint.Generation = &pipeline.GenerationPhase{GeneratedTitle: "Title", GeneratedContent: "<p>Content</p>"}
+	}
+	return checkpoint
+}
+
+func expectFailed(t *testing.T, exec *entities.Execution, reason string) {
+	t.Helper()
+
+	if exec.Status != entities.ExecutionStatusFailed || exec.ErrorMessage == nil || exec.CompletedAt == nil {
+		t.Fatalf("expected execution %d to be failed, got %+v", exec.ID, exec)
+	}
+	if !strings.Contains(*exec.ErrorMessage, reason) {
+		t.Errorf("expected failure reason to mention %q, got %q", reason, *exec.ErrorMessage)
+	}
+}
+
+func TestRecoverResumesGeneratedContent(t *testing.T) {
+	h := newRecoveryHarness(
+		[]*entities.Execution{execution(1, entities.ExecutionStatusGenerating)},
+		checkpointAt(1, pipeline.StateGenerated, true),
+	)
+
+	resumptions, err := h.executor.Recover(context.Background())
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if len(resumptions) != 1 || resumptions[0].Job.ID != 7 {
+		t.Fatalf("expected the run of job 7 to be resumable, got %+v", resumptions)
+	}
+	if len(h.Steps()) != 0 {
+		t.Fatal("expected Recover to leave running the resumption to the caller")
+	}
+
+	if err = resumptions[0].Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := []string{"validate_output", "publish_article", "record_stats", "mark_used", "complete"}
+	if got := h.Steps(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected steps %v, got %v", want, got)
+	}
+	if exec := h.executions.Get(1); exec.Status != entities.ExecutionStatusPublished {
+		t.Errorf("expected the resumed run to publish, got %s", exec.Status)
+	}
+	if h.checkpoints.Has(1) {
+		t.Error("expected the checkpoint to be released once the run completed")
+	}
+}
+
+func TestRecoverFinishesTrackingAfterPublish(t *testing.T) {
+	articleID := int64(40)
+	published := execution(1, entities.ExecutionStatusPublished)
+	published.ArticleID = &articleID
+	// Drafts waiting for validation have been published to WordPress as well
+	draft := execution(2, entities.ExecutionStatusPendingValidation)
+	draft.ArticleID = &articleID
+
+	h := newRecoveryHarness(
+		[]*entities.Execution{published, draft},
+		checkpointAt(1, pipeline.StateImageGenerated, true),
+		checkpointAt(2, pipeline.StateRecordingStats, true),
+	)
+
+	resumptions, err := h.executor.Recover(context.Background())
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if len(resumptions) != 2 {
+		t.Fatalf("expected both runs to be resumable, got %d", len(resumptions))
+	}
+
+	for _, resumption := range resumptions {
+		if err = resumption.Run(context.Background()); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, name := range h.Steps() {
+		counts[name]++
+	}
+	if counts["publish_article"] != 0 {
+		t.Error("expected published runs not to publish again")
+	}
+	if counts["record_stats"] != 1 || counts["mark_used"] != 2 || counts["complete"] != 2 {
+		t.Errorf("expected the bookkeeping steps left to run, got %v", counts)
+	}
+	if exec := h.executions.Get(2); exec.Status != entities.ExecutionStatusPendingValidation {
+		t.Errorf("expected the draft to stay pending validation, got %s", exec.Status)
+	}
+}
+
+func TestRecoverFailsRunsThatCannotResume(t *testing.T) {
+	h := newRecoveryHarness(
+		[]*entities.Execution{
+			execution(1, entities.ExecutionStatusPublishing),
+			execution(2, entities.ExecutionStatusGenerating),
+			execution(3, entities.ExecutionStatusPending),
+		},
+		checkpointAt(1, pipeline.StateImageGenerated, true),
+		checkpointAt(2, pipeline.StatePromptRendered, false),
+	)
+
+	resumptions, err := h.executor.Recover(context.Background())
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if len(resumptions) != 0 {
+		t.Fatalf("expected no resumable run, got %d", len(resumptions))
+	}
+
+	expectFailed(t, h.executions.Get(1), "while publishing")
+	expectFailed(t, h.executions.Get(2), "before the content was generated")
+	expectFailed(t, h.executions.Get(3), "before any progress was saved")
+
+	if h.checkpoints.Has(1) || h.checkpoints.Has(2) {
+		t.Error("expected the checkpoints of failed runs to be dropped")
+	}
+}
+
+func TestRecoverDropsCheckpointsOfEndedRuns(t *testing.T) {
+	rejected := execution(1, entities.ExecutionStatusRejected)
+	h := newRecoveryHarness(
+		[]*entities.Execution{rejected, execution(2, entities.ExecutionStatusGenerating)},
+		checkpointAt(1, pipeline.StateGenerated, true),
+		checkpointAt(2, pipeline.StateCompleted, true),
+	)
+
+	resumptions, err := h.executor.Recover(context.Background())
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if len(resumptions) != 0 {
+		t.Fatalf("expected no resumable run, got %d", len(resumptions))
+	}
+
+	if exec := h.executions.Get(1); exec.Status != entities.ExecutionStatusRejected || exec.ErrorMessage != nil {
+		t.Errorf("expected the rejected execution to be left as is, got %+v", exec)
+	}
+	if h.checkpoints.Has(1) || h.checkpoints.Has(2) {
+		t.Error("expected the checkpoints of ended runs to be dropped")
+	}
+}
+
+func TestRecoverFailsRunWhenJobIsGone(t *testing.T) {
+	exec := execution(1, entities.ExecutionStatusGenerating)
+	exec.JobID = 8
+	h := newRecoveryHarness([]*entities.Execution{exec}, checkpointAt(1, pipeline.StateGenerated, true))
+
+	resumptions, err := h.executor.Recover(context.Background())
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if len(resumptions) != 0 {
+		t.Fatalf("expected no resumable run, got %d", len(resumptions))
+	}
+	expectFailed(t, h.executions.Get(1), "could not be resumed")
+}
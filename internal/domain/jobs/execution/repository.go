@@ -168,6 +168,27 @@ func (r *repository) GetByStatus(ctx context.Context, status entities.Status) ([
 	return r.scanExecutions(query, args, ctx)
 }
 
+func (r *repository) GetUnfinished(ctx context.Context) ([]*entities.Execution, error) {
+	query, args := dbx.ST.
+		Select(
+			"id", "job_id", "site_id", "topic_id", "article_id",
+			"prompt_id", "ai_provider_id", "ai_model", "category_ids",
+			"status", "error_message",
+			"generation_time_ms", "tokens_used",
+			"started_at", "generated_at", "validated_at", "published_at", "completed_at",
+		).
+		From("job_executions").
+		Where(squirrel.Eq{"status": []entities.ExecutionStatus{
+			entities.ExecutionStatusPending,
+			entities.ExecutionStatusGenerating,
+			entities.ExecutionStatusPublishing,
+		}}).
+		OrderBy("started_at ASC").
+		MustSql()
+
+	return r.scanExecutions(query, args, ctx)
+}
+
 func (r *repository) Update(ctx context.Context, exec *entities.Execution) error {
 	categoryIDsJSON, err := json.Marshal(exec.CategoryIDs)
 	if err != nil {
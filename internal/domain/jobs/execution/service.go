@@ -117,7 +117,7 @@ func (s *service) UpdateStatus(ctx context.Context, id int64, status entities.Ex
 	case entities.ExecutionStatusPublished:
 		exec.PublishedAt = &now
 		exec.CompletedAt = &now
-	case entities.ExecutionStatusRejected, entities.ExecutionStatusFailed:
+	case entities.ExecutionStatusRejected, entities.ExecutionStatusFailed, entities.ExecutionStatusPausedBudget:
 		exec.CompletedAt = &now
 	}
 
@@ -271,12 +271,14 @@ func (s *service) validateDependencies(ctx context.Context, exec *entities.Execu
 func (s *service) validateStatusTransition(from, to entities.ExecutionStatus) error {
 	validTransitions := map[entities.ExecutionStatus]map[entities.ExecutionStatus]bool{
 		entities.ExecutionStatusPending: {
-			entities.ExecutionStatusGenerating: true,
-			entities.ExecutionStatusFailed:     true,
+			entities.ExecutionStatusGenerating:   true,
+			entities.ExecutionStatusFailed:       true,
+			entities.ExecutionStatusPausedBudget: true,
 		},
 		entities.ExecutionStatusGenerating: {
 			entities.ExecutionStatusPendingValidation: true,
 			entities.ExecutionStatusFailed:            true,
+			entities.ExecutionStatusPausedBudget:      true,
 		},
 		entities.ExecutionStatusPendingValidation: {
 			entities.ExecutionStatusValidated: true,
@@ -291,9 +293,10 @@ func (s *service) validateStatusTransition(from, to entities.ExecutionStatus) er
 			entities.ExecutionStatusPublished: true,
 			entities.ExecutionStatusFailed:    true,
 		},
-		entities.ExecutionStatusPublished: {},
-		entities.ExecutionStatusRejected:  {},
-		entities.ExecutionStatusFailed:    {},
+		entities.ExecutionStatusPublished:    {},
+		entities.ExecutionStatusRejected:     {},
+		entities.ExecutionStatusFailed:       {},
+		entities.ExecutionStatusPausedBudget: {},
 	}
 
 	if transitions, exists := validTransitions[from]; exists {
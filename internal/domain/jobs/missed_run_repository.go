@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+var _ MissedRunRepository = (*missedRunRepository)(nil)
+
+type missedRunRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewMissedRunRepository(db *database.DB, logger *logger.Logger) MissedRunRepository {
+	return &missedRunRepository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("job_missed_runs"),
+	}
+}
+
+func (r *missedRunRepository) Create(ctx context.Context, runs []*entities.MissedRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	builder := dbx.ST.
+		Insert("job_missed_runs").
+		Columns("job_id", "scheduled_at", "policy", "outcome", "detected_at")
+
+	for _, run := range runs {
+		builder = builder.Values(run.JobID, run.ScheduledAt, run.Policy, run.Outcome, run.DetectedAt)
+	}
+
+	query, args := builder.MustSql()
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+	switch {
+	case dbx.IsForeignKeyViolation(err):
+		return errors.Validation("Invalid job ID")
+	case err != nil:
+		return errors.Database(err)
+	}
+
+	return nil
+}
+
+func (r *missedRunRepository) GetByJobID(ctx context.Context, jobID int64, limit int) ([]*entities.MissedRun, error) {
+	builder := dbx.ST.
+		Select("id", "job_id", "scheduled_at", "policy", "outcome", "detected_at").
+		From("job_missed_runs").
+		Where(squirrel.Eq{"job_id": jobID}).
+		OrderBy("scheduled_at DESC")
+
+	if limit > 0 {
+		builder = builder.Limit(uint64(limit))
+	}
+
+	query, args := builder.MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var runs []*entities.MissedRun
+	for rows.Next() {
+		var run entities.MissedRun
+		if err = rows.Scan(&run.ID, &run.JobID, &run.ScheduledAt, &run.Policy, &run.Outcome, &run.DetectedAt); err != nil {
+			return nil, errors.Database(err)
+		}
+		runs = append(runs, &run)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return runs, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
 )
 
 type Repository interface {
@@ -22,6 +23,9 @@ type Repository interface {
 	SetTopics(ctx context.Context, jobID int64, topicIDs []int64) error
 	GetTopics(ctx context.Context, jobID int64) ([]int64, error)
 
+	SetFallbackProviders(ctx context.Context, jobID int64, providerIDs []int64) error
+	GetFallbackProviders(ctx context.Context, jobID int64) ([]int64, error)
+
 	// GetTopicsAssignedToOtherUniqueJobs returns topic IDs that are assigned to
 	// active jobs with unique strategy on the same site, excluding the specified job
 	GetTopicsAssignedToOtherUniqueJobs(ctx context.Context, siteID int64, excludeJobID int64) ([]int64, error)
@@ -35,6 +39,14 @@ type StateRepository interface {
 	UpdateCategoryIndex(ctx context.Context, jobID int64, index int) error
 }
 
+// MissedRunRepository keeps the record of the slots jobs missed while the app was
+// closed or asleep
+type MissedRunRepository interface {
+	Create(ctx context.Context, runs []*entities.MissedRun) error
+	// GetByJobID returns the latest missed runs of the job, most recent first
+	GetByJobID(ctx context.Context, jobID int64, limit int) ([]*entities.MissedRun, error)
+}
+
 type Service interface {
 	CreateJob(ctx context.Context, job *entities.Job) error
 	GetJob(ctx context.Context, id int64) (*entities.Job, error)
@@ -46,6 +58,19 @@ type Service interface {
 	ResumeJob(ctx context.Context, id int64) error
 
 	ExecuteManually(ctx context.Context, jobID int64) error
+	CancelExecution(ctx context.Context, jobID int64) error
+
+	// EstimateJob returns the expected token usage and cost of generating an
+	// article for each remaining topic of the job
+	EstimateJob(ctx context.Context, jobID int64) (*ai.CostEstimate, error)
+	// BackfillJob generates an article for each remaining topic of the job through the
+	// provider's batch API at a discounted price, the articles arrive within 24 hours
+	BackfillJob(ctx context.Context, jobID int64) (*entities.Batch, error)
+	// PreviewSchedule returns the next count fire times of a schedule being edited
+	PreviewSchedule(ctx context.Context, schedule *entities.Schedule, count int) ([]time.Time, error)
+	// GetMissedRuns returns the latest slots the job missed and whether they were
+	// skipped or caught up
+	GetMissedRuns(ctx context.Context, jobID int64, limit int) ([]*entities.MissedRun, error)
 }
 
 type Scheduler interface {
@@ -53,10 +78,26 @@ type Scheduler interface {
 	Stop() error
 	RestoreState(ctx context.Context) error
 	CalculateNextRun(job *entities.Job, lastRun *time.Time) (baseTime time.Time, withJitter time.Time, err error)
+	// PreviewRuns returns the next count fire times of the schedule, before jitter
+	PreviewRuns(schedule *entities.Schedule, count int) ([]time.Time, error)
+	// ApplyLimits changes how many jobs run at once, overall and on a single site
+	ApplyLimits(maxJobs, maxPerSite int)
 	ScheduleJob(ctx context.Context, job *entities.Job) error
 	TriggerJob(ctx context.Context, jobID int64) error
+	CancelJob(jobID int64) error
 }
 
 type Executor interface {
 	Execute(ctx context.Context, job *entities.Job) error
+	// Cancel aborts the running execution of the job, returns false if none is running
+	Cancel(jobID int64) bool
+	// Recover fails the executions interrupted when the app last quit and returns
+	// the ones that can be resumed, for the scheduler to run
+	Recover(ctx context.Context) ([]*Resumption, error)
+}
+
+// Resumption is an interrupted run ready to go on from its checkpoint
+type Resumption struct {
+	Job *entities.Job
+	Run func(ctx context.Context) error
 }
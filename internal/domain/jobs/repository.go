@@ -48,19 +48,26 @@ func (r *repository) Create(ctx context.Context, job *entities.Job) error {
 		placeholdersJSON = []byte("{}")
 	}
 
+	generationParamsJSON, err := marshalGenerationParams(job.GenerationParams)
+	if err != nil {
+		return errors.Database(err)
+	}
+
 	query, args := dbx.ST.
 		Insert("jobs").
 		Columns(
 			"name", "site_id", "prompt_id", "ai_provider_id",
 			"placeholders_values", "topic_strategy", "category_strategy",
-			"requires_validation", "schedule_type", "schedule_config",
-			"jitter_enabled", "jitter_minutes", "status",
+			"requires_validation", "schedule_type", "schedule_config", "schedule_timezone",
+			"jitter_enabled", "jitter_minutes", "status", "generation_params",
+			"misfire_policy", "misfire_max_runs", "misfire_spacing_minutes", "timeout_minutes",
 		).
 		Values(
 			job.Name, job.SiteID, job.PromptID, job.AIProviderID,
 			placeholdersJSON, job.TopicStrategy, job.CategoryStrategy,
-			job.RequiresValidation, job.Schedule.Type, scheduleConfigJSON,
-			job.JitterEnabled, job.JitterMinutes, job.Status,
+			job.RequiresValidation, job.Schedule.Type, scheduleConfigJSON, job.Schedule.Timezone,
+			job.JitterEnabled, job.JitterMinutes, job.Status, generationParamsJSON,
+			job.MisfirePolicy, job.MisfireMaxRuns, job.MisfireSpacingMinutes, job.TimeoutMinutes,
 		).
 		MustSql()
 
@@ -103,6 +110,12 @@ func (r *repository) Create(ctx context.Context, job *entities.Job) error {
 		}
 	}
 
+	if len(job.FallbackProviderIDs) > 0 {
+		if err = r.SetFallbackProviders(ctx, id, job.FallbackProviderIDs); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -111,8 +124,9 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Job, erro
 		Select(
 			"id", "name", "site_id", "prompt_id", "ai_provider_id",
 			"placeholders_values", "topic_strategy", "category_strategy",
-			"requires_validation", "schedule_type", "schedule_config",
-			"jitter_enabled", "jitter_minutes", "status",
+			"requires_validation", "schedule_type", "schedule_config", "schedule_timezone",
+			"jitter_enabled", "jitter_minutes", "status", "generation_params",
+			"misfire_policy", "misfire_max_runs", "misfire_spacing_minutes", "timeout_minutes",
 			"created_at", "updated_at",
 		).
 		From("jobs").
@@ -139,6 +153,12 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Job, erro
 	}
 	job.Topics = topics
 
+	fallbacks, err := r.GetFallbackProviders(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job.FallbackProviderIDs = fallbacks
+
 	stateRepo := NewStateRepository(r.db, r.logger)
 	state, err := stateRepo.Get(ctx, id)
 	if err != nil && !dbx.IsNoRows(err) {
@@ -155,8 +175,9 @@ func (r *repository) GetAll(ctx context.Context) ([]*entities.Job, error) {
 			"id", "name", "site_id", "prompt_id", "ai_provider_id",
 			"placeholders_values",
 			"topic_strategy", "category_strategy", "requires_validation",
-			"schedule_type", "schedule_config",
-			"jitter_enabled", "jitter_minutes", "status",
+			"schedule_type", "schedule_config", "schedule_timezone",
+			"jitter_enabled", "jitter_minutes", "status", "generation_params",
+			"misfire_policy", "misfire_max_runs", "misfire_spacing_minutes", "timeout_minutes",
 			"created_at", "updated_at",
 		).
 		From("jobs").
@@ -187,8 +208,9 @@ func (r *repository) GetActive(ctx context.Context) ([]*entities.Job, error) {
 			"id", "name", "site_id", "prompt_id", "ai_provider_id",
 			"placeholders_values",
 			"topic_strategy", "category_strategy", "requires_validation",
-			"schedule_type", "schedule_config",
-			"jitter_enabled", "jitter_minutes", "status",
+			"schedule_type", "schedule_config", "schedule_timezone",
+			"jitter_enabled", "jitter_minutes", "status", "generation_params",
+			"misfire_policy", "misfire_max_runs", "misfire_spacing_minutes", "timeout_minutes",
 			"created_at", "updated_at",
 		).
 		From("jobs").
@@ -220,8 +242,9 @@ func (r *repository) GetDue(ctx context.Context, before time.Time) ([]*entities.
 			"j.id", "j.name", "j.site_id", "j.prompt_id", "j.ai_provider_id",
 			"j.placeholders_values",
 			"j.topic_strategy", "j.category_strategy", "j.requires_validation",
-			"j.schedule_type", "j.schedule_config",
-			"j.jitter_enabled", "j.jitter_minutes", "j.status",
+			"j.schedule_type", "j.schedule_config", "j.schedule_timezone",
+			"j.jitter_enabled", "j.jitter_minutes", "j.status", "j.generation_params",
+			"j.misfire_policy", "j.misfire_max_runs", "j.misfire_spacing_minutes", "j.timeout_minutes",
 			"j.created_at", "j.updated_at",
 		).
 		From("jobs j").
@@ -258,6 +281,13 @@ func (r *repository) GetDue(ctx context.Context, before time.Time) ([]*entities.
 			return nil, err
 		}
 		job.Topics = tops
+
+		// Load the provider fallback chain used during generation
+		fallbacks, err := r.GetFallbackProviders(ctx, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		job.FallbackProviderIDs = fallbacks
 	}
 
 	return jobs, nil
@@ -282,6 +312,11 @@ func (r *repository) Update(ctx context.Context, job *entities.Job) error {
 		scheduleConfigJSON = []byte("{}")
 	}
 
+	generationParamsJSON, err := marshalGenerationParams(job.GenerationParams)
+	if err != nil {
+		return errors.Database(err)
+	}
+
 	query, args := dbx.ST.
 		Update("jobs").
 		Set("name", job.Name).
@@ -294,9 +329,15 @@ func (r *repository) Update(ctx context.Context, job *entities.Job) error {
 		Set("requires_validation", job.RequiresValidation).
 		Set("schedule_type", job.Schedule.Type).
 		Set("schedule_config", scheduleConfigJSON).
+		Set("schedule_timezone", job.Schedule.Timezone).
 		Set("jitter_enabled", job.JitterEnabled).
 		Set("jitter_minutes", job.JitterMinutes).
 		Set("status", job.Status).
+		Set("generation_params", generationParamsJSON).
+		Set("misfire_policy", job.MisfirePolicy).
+		Set("misfire_max_runs", job.MisfireMaxRuns).
+		Set("misfire_spacing_minutes", job.MisfireSpacingMinutes).
+		Set("timeout_minutes", job.TimeoutMinutes).
 		Set("updated_at", time.Now()).
 		Where(squirrel.Eq{"id": job.ID}).
 		MustSql()
@@ -502,11 +543,91 @@ func (r *repository) GetTopics(ctx context.Context, jobID int64) ([]int64, error
 	return topicIDs, nil
 }
 
+func (r *repository) SetFallbackProviders(ctx context.Context, jobID int64, providerIDs []int64) error {
+	deleteQuery, deleteArgs := dbx.ST.
+		Delete("job_fallback_providers").
+		Where(squirrel.Eq{"job_id": jobID}).
+		MustSql()
+
+	_, err := r.db.ExecContext(ctx, deleteQuery, deleteArgs...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if len(providerIDs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Database(err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for i, providerID := range providerIDs {
+		query, args := dbx.ST.
+			Insert("job_fallback_providers").
+			Columns("job_id", "provider_id", "order_index").
+			Values(jobID, providerID, i).
+			MustSql()
+
+		_, err = tx.ExecContext(ctx, query, args...)
+		switch {
+		case dbx.IsForeignKeyViolation(err):
+			return errors.Validation("Invalid fallback AI provider ID")
+		case err != nil:
+			return errors.Database(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Database(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetFallbackProviders(ctx context.Context, jobID int64) ([]int64, error) {
+	query, args := dbx.ST.
+		Select("provider_id").
+		From("job_fallback_providers").
+		Where(squirrel.Eq{"job_id": jobID}).
+		OrderBy("order_index ASC").
+		MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var providerIDs []int64
+	for rows.Next() {
+		var providerID int64
+		if err = rows.Scan(&providerID); err != nil {
+			return nil, errors.Database(err)
+		}
+		providerIDs = append(providerIDs, providerID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return providerIDs, nil
+}
+
 func (r *repository) scanJobFromScanner(scn dbx.RowScanner) (*entities.Job, error) {
 	var (
 		job                                  entities.Job
 		scheduleType                         entities.ScheduleType
+		scheduleTimezone                     string
 		scheduleConfigJSON, placeholdersJSON []byte
+		generationParamsJSON                 []byte
 	)
 
 	if err := scn.Scan(
@@ -521,9 +642,15 @@ func (r *repository) scanJobFromScanner(scn dbx.RowScanner) (*entities.Job, erro
 		&job.RequiresValidation,
 		&scheduleType,
 		&scheduleConfigJSON,
+		&scheduleTimezone,
 		&job.JitterEnabled,
 		&job.JitterMinutes,
 		&job.Status,
+		&generationParamsJSON,
+		&job.MisfirePolicy,
+		&job.MisfireMaxRuns,
+		&job.MisfireSpacingMinutes,
+		&job.TimeoutMinutes,
 		&job.CreatedAt,
 		&job.UpdatedAt,
 	); err != nil {
@@ -538,13 +665,22 @@ func (r *repository) scanJobFromScanner(scn dbx.RowScanner) (*entities.Job, erro
 	}
 	job.PlaceholdersValues = placeholderValues
 
+	if len(generationParamsJSON) > 0 {
+		var params entities.GenerationParams
+		if err := json.Unmarshal(generationParamsJSON, &params); err != nil {
+			return nil, errors.Database(err)
+		}
+		job.GenerationParams = &params
+	}
+
 	config := scheduleConfigJSON
 	if len(config) == 0 {
 		config = []byte("{}")
 	}
 	job.Schedule = &entities.Schedule{
-		Type:   scheduleType,
-		Config: config,
+		Type:     scheduleType,
+		Config:   config,
+		Timezone: scheduleTimezone,
 	}
 
 	return &job, nil
@@ -619,3 +755,16 @@ func (r *repository) GetTopicsAssignedToOtherUniqueJobs(ctx context.Context, sit
 
 	return topicIDs, nil
 }
+
+// marshalGenerationParams encodes generation params for storage, empty params are stored as NULL
+func marshalGenerationParams(params *entities.GenerationParams) (any, error) {
+	if params.IsEmpty() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"math/rand"
 	"time"
+	// Zone data for machines without a system database, Windows in particular
+	_ "time/tzdata"
 
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/pkg/cron"
 )
 
 type Calculator struct{}
@@ -27,17 +30,14 @@ func (c *Calculator) CalculateNextRun(job *entities.Job, lastRun *time.Time) (ba
 
 	now := time.Now()
 
-	switch job.Schedule.Type {
-	case entities.ScheduleOnce:
-		baseTime = c.calculateOnce(job.Schedule.Config)
-	case entities.ScheduleInterval:
-		baseTime = c.calculateInterval(job.Schedule.Config, now, lastRun)
-	case entities.ScheduleDaily:
-		baseTime = c.calculateDaily(job.Schedule.Config, now)
-	default:
+	baseTime = c.calculateBase(job.Schedule, now, lastRun)
+	if baseTime.IsZero() {
 		return time.Time{}, time.Time{}, nil
 	}
 
+	// Stored run times are compared as local wall clock text
+	baseTime = baseTime.In(now.Location())
+
 	withJitter = baseTime
 	if job.JitterEnabled && job.JitterMinutes > 0 {
 		withJitter = c.applyJitter(baseTime, job.JitterMinutes, now)
@@ -46,6 +46,65 @@ func (c *Calculator) CalculateNextRun(job *entities.Job, lastRun *time.Time) (ba
 	return baseTime, withJitter, nil
 }
 
+// PreviewRuns returns the next count fire times of the schedule, before jitter, in
+// the schedule's time zone
+func (c *Calculator) PreviewRuns(schedule *entities.Schedule, count int) []time.Time {
+	var runs []time.Time
+
+	now := time.Now()
+	var lastRun *time.Time
+
+	for len(runs) < count {
+		next := c.calculateBase(schedule, now, lastRun)
+		if next.IsZero() || (len(runs) > 0 && !next.After(runs[len(runs)-1])) {
+			break
+		}
+		runs = append(runs, next)
+		now = next
+		lastRun = &next
+	}
+
+	return runs
+}
+
+// MissedRuns returns the slots of the schedule from the first missed one up to now,
+// at most limit of them
+func (c *Calculator) MissedRuns(schedule *entities.Schedule, first, now time.Time, limit int) []time.Time {
+	runs := []time.Time{first}
+
+	for len(runs) < limit {
+		last := runs[len(runs)-1]
+		next := c.calculateBase(schedule, last, &last)
+		if next.IsZero() || !next.After(last) || !next.Before(now) {
+			break
+		}
+		runs = append(runs, next.In(now.Location()))
+	}
+
+	return runs
+}
+
+func (c *Calculator) calculateBase(schedule *entities.Schedule, now time.Time, lastRun *time.Time) time.Time {
+	loc, err := schedule.Location()
+	if err != nil {
+		return time.Time{}
+	}
+	now = now.In(loc)
+
+	switch schedule.Type {
+	case entities.ScheduleOnce:
+		return c.calculateOnce(schedule.Config)
+	case entities.ScheduleInterval:
+		return c.calculateInterval(schedule.Config, now, lastRun)
+	case entities.ScheduleDaily:
+		return c.calculateDaily(schedule.Config, now)
+	case entities.ScheduleCron:
+		return c.calculateCron(schedule.Config, now)
+	default:
+		return time.Time{}
+	}
+}
+
 func (c *Calculator) calculateOnce(config json.RawMessage) time.Time {
 	var cfg entities.OnceSchedule
 	if err := json.Unmarshal(config, &cfg); err != nil {
@@ -70,7 +129,7 @@ func (c *Calculator) calculateInterval(config json.RawMessage, now time.Time, la
 		anchor = cfg.StartAt.In(now.Location())
 	} else {
 		if lastRun != nil && !lastRun.IsZero() {
-			anchor = *lastRun
+			anchor = lastRun.In(now.Location())
 		} else {
 			anchor = now
 		}
@@ -113,24 +172,32 @@ func (c *Calculator) calculateHourlyInterval(hours int, anchor, now time.Time) t
 	return nextRun
 }
 
+// calculateDailyInterval counts calendar days rather than 24 hour periods, so the
+// runs keep their wall clock time across DST transitions
 func (c *Calculator) calculateDailyInterval(days int, anchor, now time.Time) time.Time {
 	if anchor.After(now) {
 		return anchor
 	}
 
-	daysSince := int(now.Sub(anchor).Hours() / 24)
-	periodsComplete := daysSince / days
+	periodsComplete := daysBetween(anchor, now) / days
 
-	nextRun := anchor.AddDate(0, 0, (periodsComplete+1)*days)
+	nextRun := anchor.AddDate(0, 0, periodsComplete*days)
 
 	minNextRun := now.Add(30 * time.Second)
-	if nextRun.Before(minNextRun) {
-		nextRun = anchor.AddDate(0, 0, (periodsComplete+2)*days)
+	for nextRun.Before(minNextRun) {
+		nextRun = nextRun.AddDate(0, 0, days)
 	}
 
 	return nextRun
 }
 
+// daysBetween returns the number of calendar days from one date to another
+func daysBetween(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
 func (c *Calculator) calculateWeeklyInterval(weeks int, anchor, now time.Time) time.Time {
 	days := weeks * 7
 	return c.calculateDailyInterval(days, anchor, now)
@@ -149,11 +216,11 @@ func (c *Calculator) calculateMonthlyInterval(months int, anchor, now time.Time)
 
 	periodsComplete := monthsDiff / months
 
-	nextRun := anchor.AddDate(0, (periodsComplete+1)*months, 0)
+	nextRun := anchor.AddDate(0, periodsComplete*months, 0)
 
 	minNextRun := now.Add(30 * time.Second)
-	if nextRun.Before(minNextRun) {
-		nextRun = anchor.AddDate(0, (periodsComplete+2)*months, 0)
+	for periods := periodsComplete + 1; nextRun.Before(minNextRun); periods++ {
+		nextRun = anchor.AddDate(0, periods*months, 0)
 	}
 
 	return nextRun
@@ -174,19 +241,40 @@ func (c *Calculator) calculateDaily(config json.RawMessage, now time.Time) time.
 	candidate := time.Date(now.Year(), now.Month(), now.Day(),
 		cfg.Hour, cfg.Minute, 0, 0, now.Location())
 
+	// Days are stepped by date, a day lasts 23 or 25 hours on DST transitions
 	if !candidate.After(now) {
-		candidate = candidate.Add(24 * time.Hour)
+		candidate = nextDay(candidate, cfg.Hour, cfg.Minute)
 	}
 
 	for i := 0; i < 7; i++ {
 		if len(allowedDays) == 0 || allowedDays[candidate.Weekday()] {
 			return candidate
 		}
-		candidate = candidate.Add(24 * time.Hour)
+		candidate = nextDay(candidate, cfg.Hour, cfg.Minute)
+	}
+
+	return nextDay(time.Date(now.Year(), now.Month(), now.Day(),
+		cfg.Hour, cfg.Minute, 0, 0, now.Location()), cfg.Hour, cfg.Minute)
+}
+
+// nextDay returns the given wall clock time on the day after t. A time skipped by a
+// DST transition is moved forward by the length of the gap.
+func nextDay(t time.Time, hour, minute int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, hour, minute, 0, 0, t.Location())
+}
+
+func (c *Calculator) calculateCron(config json.RawMessage, now time.Time) time.Time {
+	var cfg entities.CronSchedule
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return time.Time{}
+	}
+
+	expr, err := cron.Parse(cfg.Expression)
+	if err != nil {
+		return time.Time{}
 	}
 
-	return time.Date(now.Year(), now.Month(), now.Day(),
-		cfg.Hour, cfg.Minute, 0, 0, now.Location()).Add(24 * time.Hour)
+	return expr.Next(now)
 }
 
 func (c *Calculator) applyJitter(baseTime time.Time, jitterMinutes int, now time.Time) time.Time {
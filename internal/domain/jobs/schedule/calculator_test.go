@@ -0,0 +1,190 @@
+package schedule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+func cronJob(t *testing.T, expression string) *entities.Job {
+	t.Helper()
+
+	config, err := json.Marshal(entities.CronSchedule{Expression: expression})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	return &entities.Job{
+		ID:     1,
+		Status: entities.JobStatusActive,
+		Schedule: &entities.Schedule{
+			Type:   entities.ScheduleCron,
+			Config: config,
+		},
+	}
+}
+
+func TestCalculateNextRunCron(t *testing.T) {
+	job := cronJob(t, "15 9 * * MON-FRI")
+
+	base, withJitter, err := NewCalculator().CalculateNextRun(job, nil)
+	if err != nil {
+		t.Fatalf("CalculateNextRun: %v", err)
+	}
+
+	if !base.After(time.Now()) || base.Hour() != 9 || base.Minute() != 15 {
+		t.Errorf("unexpected next run %s", base)
+	}
+	if wd := base.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		t.Errorf("expected a weekday, got %s", wd)
+	}
+	if !withJitter.Equal(base) {
+		t.Errorf("expected no jitter, got %s", withJitter)
+	}
+}
+
+func TestCalculateNextRunCronWithJitter(t *testing.T) {
+	job := cronJob(t, "0 12 * * *")
+	job.JitterEnabled = true
+	job.JitterMinutes = 10
+
+	base, withJitter, err := NewCalculator().CalculateNextRun(job, nil)
+	if err != nil {
+		t.Fatalf("CalculateNextRun: %v", err)
+	}
+
+	if diff := withJitter.Sub(base); diff < -10*time.Minute || diff > 10*time.Minute {
+		t.Errorf("jitter %v out of bounds", diff)
+	}
+}
+
+func TestPreviewRuns(t *testing.T) {
+	job := cronJob(t, "0 8 * * MON#1")
+
+	runs := NewCalculator().PreviewRuns(job.Schedule, 3)
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %v", runs)
+	}
+
+	for i, run := range runs {
+		if run.Weekday() != time.Monday || run.Day() > 7 || run.Hour() != 8 {
+			t.Errorf("run %d is not the first Monday at 8:00: %s", i, run)
+		}
+		if i > 0 && run.Month() == runs[i-1].Month() {
+			t.Errorf("expected one run per month, got %s and %s", runs[i-1], run)
+		}
+	}
+}
+
+func TestValidateCronSchedule(t *testing.T) {
+	if err := ValidateSchedule(cronJob(t, "*/5 * * * *").Schedule); err != nil {
+		t.Errorf("expected valid expression, got %v", err)
+	}
+	if err := ValidateSchedule(cronJob(t, "61 * * * *").Schedule); err == nil {
+		t.Error("expected invalid minute to be rejected")
+	}
+	if err := ValidateSchedule(cronJob(t, "0 0 31 FEB *").Schedule); err == nil {
+		t.Error("expected an expression that never fires to be rejected")
+	}
+}
+
+func dailySchedule(t *testing.T, timezone string, hour, minute int) *entities.Schedule {
+	t.Helper()
+
+	config, err := json.Marshal(entities.DailySchedule{Hour: hour, Minute: minute, Weekdays: []int{1, 2, 3, 4, 5, 6, 7}})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	return &entities.Schedule{
+		Type:     entities.ScheduleDaily,
+		Config:   config,
+		Timezone: timezone,
+	}
+}
+
+func TestCalculateDailyInTimezone(t *testing.T) {
+	schedule := dailySchedule(t, "Asia/Tokyo", 9, 0)
+	tokyo, err := schedule.Location()
+	if err != nil {
+		t.Fatalf("Location: %v", err)
+	}
+
+	// 23:00 UTC is 8:00 the next day in Tokyo
+	now := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+
+	next := NewCalculator().calculateBase(schedule, now, nil)
+	want := time.Date(2026, 10, 17, 9, 0, 0, 0, tokyo)
+	if !next.Equal(want) {
+		t.Errorf("expected %s, got %s", want, next.In(tokyo))
+	}
+}
+
+func TestCalculateDailyAcrossDST(t *testing.T) {
+	schedule := dailySchedule(t, "America/New_York", 9, 0)
+	newYork, err := schedule.Location()
+	if err != nil {
+		t.Fatalf("Location: %v", err)
+	}
+
+	// DST ends on November 1st 2026
+	now := time.Date(2026, 10, 31, 10, 0, 0, 0, newYork)
+
+	next := NewCalculator().calculateBase(schedule, now, nil)
+	want := time.Date(2026, 11, 1, 9, 0, 0, 0, newYork)
+	if !next.Equal(want) {
+		t.Errorf("expected %s, got %s", want, next.In(newYork))
+	}
+	if next.Sub(now) != 24*time.Hour {
+		t.Errorf("expected the 25 hour day to end at 9:00, got %s after", next.Sub(now))
+	}
+}
+
+func TestCalculateDailyIntervalAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	// DST starts on March 8th 2026
+	anchor := time.Date(2026, 3, 1, 9, 0, 0, 0, newYork)
+	now := time.Date(2026, 3, 8, 8, 0, 0, 0, newYork)
+
+	next := NewCalculator().calculateDailyInterval(1, anchor, now)
+	if h, m, _ := next.Clock(); h != 9 || m != 0 || next.Day() != 8 {
+		t.Errorf("expected March 8th at 9:00, got %s", next)
+	}
+}
+
+func TestValidateScheduleTimezone(t *testing.T) {
+	if err := ValidateSchedule(dailySchedule(t, "Europe/Berlin", 9, 0)); err != nil {
+		t.Errorf("expected valid zone, got %v", err)
+	}
+	if err := ValidateSchedule(dailySchedule(t, "Mars/Olympus_Mons", 9, 0)); err == nil {
+		t.Error("expected unknown zone to be rejected")
+	}
+}
+
+func TestMissedRuns(t *testing.T) {
+	schedule := dailySchedule(t, "UTC", 9, 0)
+
+	// Closed from Friday evening to Monday 10:00
+	first := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	runs := NewCalculator().MissedRuns(schedule, first, now, 10)
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 missed runs, got %v", runs)
+	}
+	for i, run := range runs {
+		if want := first.AddDate(0, 0, i); !run.Equal(want) {
+			t.Errorf("missed run %d is %s, want %s", i, run, want)
+		}
+	}
+
+	if runs = NewCalculator().MissedRuns(schedule, first, now, 2); len(runs) != 2 {
+		t.Errorf("expected the limit to cap the missed runs, got %v", runs)
+	}
+}
@@ -0,0 +1,110 @@
+package schedule
+
+import (
+	"sync"
+)
+
+// pool runs the due jobs under a global and a per-site limit of concurrent runs.
+// The jobs over the limits wait in a queue and start in the order they became due
+// as runs end, the jobs of a busy site letting the others go first.
+// Only the job ids are queued, a run loads its job when a slot frees.
+type pool struct {
+	mu         sync.Mutex
+	maxJobs    int
+	maxPerSite int
+	running    int
+	perSite    map[int64]int
+	// active holds the jobs queued or running, a job is never queued twice
+	active map[int64]bool
+	queue  []task
+}
+
+type task struct {
+	jobID  int64
+	siteID int64
+	run    func()
+}
+
+func newPool(maxJobs, maxPerSite int) *pool {
+	return &pool{
+		maxJobs:    max(maxJobs, 1),
+		maxPerSite: max(maxPerSite, 1),
+		perSite:    make(map[int64]int),
+		active:     make(map[int64]bool),
+	}
+}
+
+// setLimits changes the limits, queued jobs start right away when they were raised
+func (p *pool) setLimits(maxJobs, maxPerSite int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.maxJobs = max(maxJobs, 1)
+	p.maxPerSite = max(maxPerSite, 1)
+	p.dispatchLocked()
+}
+
+// submit queues a run of the job, it returns false when the job is queued or running already
+func (p *pool) submit(jobID, siteID int64, run func()) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active[jobID] {
+		return false
+	}
+
+	p.active[jobID] = true
+	p.queue = append(p.queue, task{jobID: jobID, siteID: siteID, run: run})
+	p.dispatchLocked()
+	return true
+}
+
+// contains reports whether the job is queued or running
+func (p *pool) contains(jobID int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.active[jobID]
+}
+
+// queued returns the number of jobs waiting for a free slot
+func (p *pool) queued() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.queue)
+}
+
+func (p *pool) dispatchLocked() {
+	waiting := p.queue[:0]
+	for _, t := range p.queue {
+		if p.running < p.maxJobs && p.perSite[t.siteID] < p.maxPerSite {
+			p.running++
+			p.perSite[t.siteID]++
+			go p.execute(t)
+			continue
+		}
+		waiting = append(waiting, t)
+	}
+
+	clear(p.queue[len(waiting):])
+	p.queue = waiting
+}
+
+func (p *pool) execute(t task) {
+	defer p.done(t)
+	t.run()
+}
+
+func (p *pool) done(t task) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.running--
+	if p.perSite[t.siteID]--; p.perSite[t.siteID] <= 0 {
+		delete(p.perSite, t.siteID)
+	}
+	delete(p.active, t.jobID)
+
+	p.dispatchLocked()
+}
@@ -0,0 +1,113 @@
+package schedule
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingRuns records the runs of a pool and holds them until released
+type blockingRuns struct {
+	mu      sync.Mutex
+	release map[int64]chan struct{}
+	started chan int64
+}
+
+func newBlockingRuns() *blockingRuns {
+	return &blockingRuns{
+		release: make(map[int64]chan struct{}),
+		started: make(chan int64, 16),
+	}
+}
+
+func (b *blockingRuns) hold(jobID int64) chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.release[jobID]; !ok {
+		b.release[jobID] = make(chan struct{})
+	}
+	return b.release[jobID]
+}
+
+// submit queues a run of the job that blocks until released
+func (b *blockingRuns) submit(p *pool, jobID, siteID int64) bool {
+	return p.submit(jobID, siteID, func() {
+		release := b.hold(jobID)
+		b.started <- jobID
+		<-release
+	})
+}
+
+// expectStarted waits for the given jobs to start, in any order, and for no other
+func (b *blockingRuns) expectStarted(t *testing.T, want ...int64) {
+	t.Helper()
+
+	pending := make(map[int64]bool, len(want))
+	for _, id := range want {
+		pending[id] = true
+	}
+
+	for len(pending) > 0 {
+		select {
+		case got := <-b.started:
+			if !pending[got] {
+				t.Fatalf("job %d started over the limits", got)
+			}
+			delete(pending, got)
+		case <-time.After(time.Second):
+			t.Fatalf("jobs %v did not start", pending)
+		}
+	}
+
+	select {
+	case got := <-b.started:
+		t.Fatalf("job %d started over the limits", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestPoolLimits(t *testing.T) {
+	runs := newBlockingRuns()
+	p := newPool(2, 1)
+
+	// Jobs 1 and 2 share a site
+	runs.submit(p, 1, 10)
+	runs.submit(p, 2, 10)
+	runs.submit(p, 3, 20)
+	runs.submit(p, 4, 30)
+
+	runs.expectStarted(t, 1, 3)
+	if queued := p.queued(); queued != 2 {
+		t.Fatalf("expected 2 queued jobs, got %d", queued)
+	}
+
+	if runs.submit(p, 2, 10) {
+		t.Error("expected a queued job not to be queued again")
+	}
+
+	// The site of job 1 stays busy for job 2, job 4 passes it
+	close(runs.hold(3))
+	runs.expectStarted(t, 4)
+
+	close(runs.hold(1))
+	runs.expectStarted(t, 2)
+
+	close(runs.hold(2))
+	close(runs.hold(4))
+}
+
+func TestPoolRaisedLimits(t *testing.T) {
+	runs := newBlockingRuns()
+	p := newPool(1, 1)
+
+	runs.submit(p, 1, 10)
+	runs.submit(p, 2, 10)
+	runs.expectStarted(t, 1)
+
+	p.setLimits(2, 2)
+	runs.expectStarted(t, 2)
+
+	close(runs.hold(1))
+	close(runs.hold(2))
+}
@@ -9,49 +9,89 @@ import (
 
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/jobs"
+	"github.com/davidmovas/postulator/internal/domain/settings"
 	appErrors "github.com/davidmovas/postulator/pkg/errors"
 	"github.com/davidmovas/postulator/pkg/logger"
 )
 
 const (
 	TickerInterval = 1 * time.Minute
+	// MisfireThreshold is how long the ticker may go without ticking before the
+	// machine counts as having slept, and how late a run gets to count as missed
+	MisfireThreshold = 5 * time.Minute
+	// maxMissedSlots bounds the missed slots looked up for a job
+	maxMissedSlots = 500
+	// catchUpDelay spreads the first catch-up runs of the jobs over a few minutes
+	// rather than firing them all at once
+	catchUpDelay = 5 * time.Minute
 )
 
 type Scheduler struct {
-	jobRepo    jobs.Repository
-	stateRepo  jobs.StateRepository
-	executor   jobs.Executor
-	calculator *Calculator
-	logger     *logger.Logger
-	stopChan   chan struct{}
-	running    bool
+	jobRepo         jobs.Repository
+	stateRepo       jobs.StateRepository
+	missedRunRepo   jobs.MissedRunRepository
+	executor        jobs.Executor
+	settingsService settings.Service
+	calculator      *Calculator
+	pool            *pool
+	logger          *logger.Logger
+	stopChan        chan struct{}
+	running         bool
+	// lastTick is the wall-clock time of the last check of the due jobs
+	lastTick time.Time
 }
 
 func NewScheduler(
 	jobRepo jobs.Repository,
 	stateRepo jobs.StateRepository,
+	missedRunRepo jobs.MissedRunRepository,
 	executor jobs.Executor,
+	settingsService settings.Service,
 	logger *logger.Logger,
 ) jobs.Scheduler {
-	return &Scheduler{
-		jobRepo:    jobRepo,
-		stateRepo:  stateRepo,
-		executor:   executor,
-		calculator: NewCalculator(),
-		logger:     logger,
-		stopChan:   make(chan struct{}),
+	s := &Scheduler{
+		jobRepo:         jobRepo,
+		stateRepo:       stateRepo,
+		missedRunRepo:   missedRunRepo,
+		executor:        executor,
+		settingsService: settingsService,
+		calculator:      NewCalculator(),
+		logger:          logger,
+		stopChan:        make(chan struct{}),
 	}
+
+	defaults := entities.DefaultSchedulerSettings()
+	s.pool = newPool(defaults.MaxConcurrentJobs, defaults.MaxConcurrentPerSite)
+
+	return s
 }
 
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.logger.Info("Starting job scheduler")
 
+	if limits, err := s.settingsService.GetSchedulerSettings(ctx); err != nil {
+		s.logger.Errorf("Failed to get scheduler settings, using the default limits: %v", err)
+	} else {
+		s.ApplyLimits(limits.MaxConcurrentJobs, limits.MaxConcurrentPerSite)
+	}
+
+	// Interrupted executions are settled before the jobs they belong to run again. The
+	// resumed runs hold their job's slot, so the job does not run again meanwhile.
+	resumptions, err := s.executor.Recover(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to recover interrupted executions: %v", err)
+	}
+	for _, resumption := range resumptions {
+		s.resume(resumption)
+	}
+
 	if err := s.RestoreState(ctx); err != nil {
 		s.logger.Errorf("Failed to restore scheduler state: %v", err)
 		return appErrors.Scheduler(err)
 	}
 
 	s.running = true
+	s.lastTick = time.Now().Round(0)
 	go s.run(ctx)
 
 	s.logger.Info("Job scheduler started successfully")
@@ -82,6 +122,8 @@ func (s *Scheduler) run(ctx context.Context) {
 
 func (s *Scheduler) checkAndExecuteDueJobs(ctx context.Context) {
 	now := time.Now()
+
+	woke := s.tick(now)
 	dueJobs, err := s.jobRepo.GetDue(ctx, now)
 	if err != nil {
 		s.logger.Errorf("Failed to get due jobs: %v", err)
@@ -95,14 +137,90 @@ func (s *Scheduler) checkAndExecuteDueJobs(ctx context.Context) {
 	s.logger.Infof("Found %d due jobs to execute", len(dueJobs))
 
 	for _, job := range dueJobs {
-		go s.executeAndReschedule(ctx, job)
+		// Still waiting for a slot or running since an earlier tick
+		if s.pool.contains(job.ID) {
+			continue
+		}
+
+		// Overdue after the machine slept: the policy of the job decides what runs
+		if woke && isMisfire(job, now) {
+			s.catchUp(ctx, job, now)
+			continue
+		}
+		s.submit(job)
 	}
+
+	if queued := s.pool.queued(); queued > 0 {
+		s.logger.Infof("%d due jobs waiting for a free execution slot", queued)
+	}
+}
+
+// tick records a check of the due jobs and reports whether the machine slept since
+// the last one, the runs due meanwhile were then missed. The monotonic clock stops
+// while the machine sleeps, so the gap is measured on the wall clock.
+func (s *Scheduler) tick(now time.Time) bool {
+	now = now.Round(0)
+	woke := now.Sub(s.lastTick) > MisfireThreshold
+	s.lastTick = now
+	return woke
+}
+
+// submit queues a run of the job, it returns false when the job is queued or running already
+func (s *Scheduler) submit(job *entities.Job) bool {
+	jobID := job.ID
+	return s.pool.submit(jobID, job.SiteID, func() {
+		s.runQueued(jobID)
+	})
+}
+
+// resume queues an interrupted run of a job under the limits of the regular runs
+func (s *Scheduler) resume(resumption *jobs.Resumption) {
+	job := resumption.Job
+	s.pool.submit(job.ID, job.SiteID, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), jobTimeout(job))
+		defer cancel()
+
+		if err := resumption.Run(ctx); err != nil {
+			s.logger.Errorf("Resumed execution of job %d failed: %v", job.ID, err)
+		}
+	})
+}
+
+// runQueued runs the job once it got a slot. The job is loaded again as it may have
+// been paused, edited or deleted while it waited
+func (s *Scheduler) runQueued(jobID int64) {
+	ctx := context.Background()
+
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		var appErr *appErrors.AppError
+		if errors.As(err, &appErr) && appErr.Code == appErrors.ErrCodeNotFound {
+			s.logger.Infof("Job %d was deleted while queued, run dropped", jobID)
+		} else {
+			s.logger.Errorf("Failed to load queued job %d: %v", jobID, err)
+		}
+		return
+	}
+
+	if job.Status != entities.JobStatusActive {
+		s.logger.Infof("Job %d is no longer active, queued run dropped", jobID)
+		return
+	}
+
+	state, err := s.stateRepo.Get(ctx, jobID)
+	if err != nil {
+		s.logger.Errorf("Failed to load state of queued job %d: %v", jobID, err)
+		return
+	}
+	job.State = state
+
+	s.executeAndReschedule(ctx, job)
 }
 
 func (s *Scheduler) executeAndReschedule(_ context.Context, job *entities.Job) {
 	s.logger.Infof("Executing job %d (%s)", job.ID, job.Name)
 
-	execCtx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	execCtx, cancel := context.WithTimeout(context.Background(), jobTimeout(job))
 	defer cancel()
 
 	executionStart := time.Now()
@@ -119,13 +237,17 @@ func (s *Scheduler) executeAndReschedule(_ context.Context, job *entities.Job) {
 	if err != nil {
 		s.logger.Errorf("Job %d execution failed: %v", job.ID, err)
 
-		if isNoTopicsError(err) {
-			s.logger.Warnf("No available topics for job %d, pausing job", job.ID)
+		if isNoTopicsError(err) || appErrors.IsBudgetExceeded(err) {
+			if appErrors.IsBudgetExceeded(err) {
+				s.logger.Warnf("AI budget exceeded for job %d, pausing job", job.ID)
+			} else {
+				s.logger.Warnf("No available topics for job %d, pausing job", job.ID)
+			}
 			job.Status = entities.JobStatusPaused
 			state.NextRunAt = nil
 			state.NextRunBase = nil
 
-			if updateErr := s.jobRepo.Update(execCtx, job); updateErr != nil {
+			if updateErr := s.setStatus(execCtx, job.ID, job.Status); updateErr != nil {
 				s.logger.Errorf("Failed to pause job %d: %v", job.ID, updateErr)
 			}
 
@@ -151,7 +273,7 @@ func (s *Scheduler) executeAndReschedule(_ context.Context, job *entities.Job) {
 			state.NextRunAt = nil
 			state.NextRunBase = nil
 
-			if updateErr := s.jobRepo.Update(execCtx, job); updateErr != nil {
+			if updateErr := s.setStatus(execCtx, job.ID, job.Status); updateErr != nil {
 				s.logger.Errorf("Failed to complete job %d: %v", job.ID, updateErr)
 			}
 
@@ -162,7 +284,21 @@ func (s *Scheduler) executeAndReschedule(_ context.Context, job *entities.Job) {
 			if deleteErr := s.jobRepo.Delete(execCtx, job.ID); deleteErr != nil {
 				s.logger.Errorf("Failed to delete completed job %d: %v", job.ID, deleteErr)
 			}
+		} else if state.CatchUpRuns > 1 {
+			// More missed slots to catch up, spaced apart
+			state.CatchUpRuns--
+			nextRun := time.Now().Add(time.Duration(job.MisfireSpacingMinutes) * time.Minute)
+			state.NextRunAt = &nextRun
+
+			s.logger.Infof("Job %d catch-up run scheduled at %s, %d left",
+				job.ID, nextRun.Format("15:04:05"), state.CatchUpRuns)
+
+			if updateErr := s.stateRepo.Update(execCtx, state); updateErr != nil {
+				s.logger.Errorf("Failed to update next run for job %d: %v", job.ID, updateErr)
+			}
 		} else {
+			state.CatchUpRuns = 0
+
 			runTime := state.LastRunAt
 			if state.NextRunBase != nil {
 				runTime = state.NextRunBase
@@ -187,6 +323,17 @@ func (s *Scheduler) executeAndReschedule(_ context.Context, job *entities.Job) {
 	}
 }
 
+// setStatus changes the status of the stored job, leaving the edits made during the run in place
+func (s *Scheduler) setStatus(ctx context.Context, jobID int64, status entities.JobStatus) error {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	job.Status = status
+	return s.jobRepo.Update(ctx, job)
+}
+
 func (s *Scheduler) RestoreState(ctx context.Context) error {
 	s.logger.Info("Restoring scheduler state")
 
@@ -213,53 +360,152 @@ func (s *Scheduler) RestoreState(ctx context.Context) error {
 			continue
 		}
 
-		needsReschedule := false
+		if state.NextRunAt != nil {
+			// Runs barely late and catch-up runs left from the last session run on the next tick
+			if isMisfire(job, now) {
+				missedCount++
+				if s.catchUp(ctx, job, now) {
+					restoredCount++
+				}
+			}
+			continue
+		}
 
-		if state.NextRunAt == nil {
-			needsReschedule = true
-		} else if state.NextRunAt.Before(now) {
-			missedCount++
-			delay := time.Duration(rand.Intn(300)) * time.Second
-			nextRun := now.Add(delay)
-			state.NextRunAt = &nextRun
+		baseTime, withJitter, calcErr := s.calculator.CalculateNextRun(job, state.LastRunAt)
+		if calcErr != nil {
+			s.logger.Errorf("Failed to calculate next run for job %d: %v", job.ID, calcErr)
+			continue
+		}
 
-			s.logger.Warnf("Job %d (%s) missed execution, rescheduling with %v delay",
-				job.ID, job.Name, delay)
+		state.NextRunBase = &baseTime
+		state.NextRunAt = &withJitter
 
-			needsReschedule = true
+		if err = s.stateRepo.Update(ctx, state); err != nil {
+			s.logger.Errorf("Failed to update state for job %d during restore: %v", job.ID, err)
+			continue
 		}
 
-		if needsReschedule {
-			if state.NextRunBase == nil || state.NextRunAt.Before(now) {
-				baseTime, withJitter, calcErr := s.calculator.CalculateNextRun(job, state.LastRunAt)
-				if calcErr != nil {
-					s.logger.Errorf("Failed to calculate next run for job %d: %v", job.ID, calcErr)
-					continue
-				}
+		restoredCount++
+	}
 
-				state.NextRunBase = &baseTime
-				state.NextRunAt = &withJitter
-			}
+	s.logger.Infof("State restored: %d active jobs, %d jobs restored, %d missed executions rescheduled",
+		len(activeJobs), restoredCount, missedCount)
 
-			if err = s.stateRepo.Update(ctx, state); err != nil {
-				s.logger.Errorf("Failed to update state for job %d during restore: %v", job.ID, err)
-				continue
-			}
+	return nil
+}
+
+// catchUp applies the misfire policy of a job whose run was missed while the app was
+// closed or asleep. It records the missed slots, then schedules the catch-up runs or
+// moves the job on to its next slot. It returns whether the job was rescheduled.
+func (s *Scheduler) catchUp(ctx context.Context, job *entities.Job, now time.Time) bool {
+	state := job.State
+
+	first := *state.NextRunAt
+	if state.NextRunBase != nil {
+		first = *state.NextRunBase
+	}
 
-			restoredCount++
+	slots := s.calculator.MissedRuns(job.Schedule, first, now, maxMissedSlots)
+	lastSlot := slots[len(slots)-1]
+
+	var runs int
+	switch {
+	case job.Schedule.Type == entities.ScheduleOnce:
+		// A one-time job has no next slot to move on to, it always runs
+		runs = 1
+	case job.MisfirePolicy == entities.MisfireSkip:
+		runs = 0
+	case job.MisfirePolicy == entities.MisfireRunAll:
+		runs = min(len(slots), max(job.MisfireMaxRuns, 1))
+	default:
+		runs = 1
+	}
+
+	// The latest slots are the ones caught up
+	missed := make([]*entities.MissedRun, 0, len(slots))
+	for i, slot := range slots {
+		outcome := entities.MissedRunSkipped
+		if i >= len(slots)-runs {
+			outcome = entities.MissedRunCaughtUp
 		}
+		missed = append(missed, &entities.MissedRun{
+			JobID:       job.ID,
+			ScheduledAt: slot,
+			Policy:      job.MisfirePolicy,
+			Outcome:     outcome,
+			DetectedAt:  now,
+		})
 	}
 
-	s.logger.Infof("State restored: %d active jobs, %d jobs restored, %d missed executions rescheduled",
-		len(activeJobs), restoredCount, missedCount)
+	if err := s.missedRunRepo.Create(ctx, missed); err != nil {
+		s.logger.Errorf("Failed to record missed runs of job %d: %v", job.ID, err)
+	}
 
-	return nil
+	if runs == 0 {
+		baseTime, withJitter, err := s.calculator.CalculateNextRun(job, &lastSlot)
+		if err != nil {
+			s.logger.Errorf("Failed to calculate next run for job %d: %v", job.ID, err)
+			return false
+		}
+
+		state.NextRunBase = &baseTime
+		state.NextRunAt = &withJitter
+		state.CatchUpRuns = 0
+
+		s.logger.Warnf("Job %d (%s) missed %d runs, skipped to %s",
+			job.ID, job.Name, len(slots), withJitter.Format("2006-01-02 15:04:05"))
+	} else {
+		delay := time.Duration(rand.Int63n(int64(catchUpDelay)))
+		nextRun := now.Add(delay).Truncate(time.Second)
+
+		state.NextRunBase = &lastSlot
+		state.NextRunAt = &nextRun
+		state.CatchUpRuns = runs
+
+		s.logger.Warnf("Job %d (%s) missed %d runs, catching up %d of them from %s",
+			job.ID, job.Name, len(slots), runs, nextRun.Format("2006-01-02 15:04:05"))
+	}
+
+	if err := s.stateRepo.Update(ctx, state); err != nil {
+		s.logger.Errorf("Failed to update state for job %d: %v", job.ID, err)
+		return false
+	}
+
+	return true
+}
+
+func jobTimeout(job *entities.Job) time.Duration {
+	timeout := job.TimeoutMinutes
+	if timeout <= 0 {
+		timeout = entities.DefaultJobTimeoutMinutes
+	}
+	return time.Duration(timeout) * time.Minute
+}
+
+// isMisfire reports whether the run of a due job is late enough to count as missed.
+// Catch-up runs are already the outcome of a misfire.
+func isMisfire(job *entities.Job, now time.Time) bool {
+	state := job.State
+	return state != nil && state.NextRunAt != nil && state.CatchUpRuns == 0 &&
+		now.Sub(*state.NextRunAt) > MisfireThreshold
 }
 
 func (s *Scheduler) CalculateNextRun(job *entities.Job, lastRun *time.Time) (baseTime time.Time, withJitter time.Time, err error) {
 	return s.calculator.CalculateNextRun(job, lastRun)
 }
 
+func (s *Scheduler) PreviewRuns(schedule *entities.Schedule, count int) ([]time.Time, error) {
+	if err := ValidateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return s.calculator.PreviewRuns(schedule, count), nil
+}
+
+func (s *Scheduler) ApplyLimits(maxJobs, maxPerSite int) {
+	s.pool.setLimits(maxJobs, maxPerSite)
+	s.logger.Infof("Job scheduler limits: %d jobs at once, %d per site", maxJobs, maxPerSite)
+}
+
 func (s *Scheduler) ScheduleJob(ctx context.Context, job *entities.Job) error {
 	if job.Schedule != nil {
 		if err := ValidateSchedule(job.Schedule); err != nil {
@@ -308,15 +554,20 @@ func (s *Scheduler) TriggerJob(ctx context.Context, jobID int64) error {
 		return appErrors.Validation("job is not active")
 	}
 
-	state, err := s.stateRepo.Get(ctx, jobID)
-	if err != nil {
-		return err
+	// Manual runs share the limits of the scheduled ones
+	if !s.submit(job) {
+		return appErrors.Validation("job is already queued or running")
 	}
 
-	job.State = state
+	return nil
+}
 
-	s.executeAndReschedule(ctx, job)
+func (s *Scheduler) CancelJob(jobID int64) error {
+	if !s.executor.Cancel(jobID) {
+		return appErrors.Validation("job is not running")
+	}
 
+	s.logger.Infof("Job %d execution cancelled", jobID)
 	return nil
 }
 
@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+func TestIsMisfire(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+	at := func(offset time.Duration) *time.Time {
+		runAt := now.Add(offset)
+		return &runAt
+	}
+
+	tests := []struct {
+		name  string
+		state *entities.State
+		want  bool
+	}{
+		{name: "no state", state: nil, want: false},
+		{name: "not scheduled", state: &entities.State{}, want: false},
+		{name: "due later", state: &entities.State{NextRunAt: at(time.Minute)}, want: false},
+		{name: "seconds late", state: &entities.State{NextRunAt: at(-30 * time.Second)}, want: false},
+		{name: "at the threshold", state: &entities.State{NextRunAt: at(-MisfireThreshold)}, want: false},
+		{name: "past the threshold", state: &entities.State{NextRunAt: at(-time.Hour)}, want: true},
+		{name: "catch-up run", state: &entities.State{NextRunAt: at(-time.Hour), CatchUpRuns: 2}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &entities.Job{ID: 1, State: tt.state}
+			if got := isMisfire(job, now); got != tt.want {
+				t.Errorf("isMisfire() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTickDetectsSleep(t *testing.T) {
+	s := &Scheduler{lastTick: time.Now().Round(0)}
+
+	if s.tick(time.Now().Add(TickerInterval)) {
+		t.Error("expected a regular tick not to count as a wake-up")
+	}
+
+	// The wall clock moved on while the machine slept
+	s.lastTick = s.lastTick.Add(-2 * MisfireThreshold)
+	if !s.tick(time.Now()) {
+		t.Error("expected a tick after a gap longer than the threshold to count as a wake-up")
+	}
+	if s.tick(time.Now()) {
+		t.Error("expected the next tick not to count as a wake-up")
+	}
+}
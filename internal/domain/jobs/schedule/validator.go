@@ -2,9 +2,11 @@ package schedule
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/pkg/cron"
 	"github.com/davidmovas/postulator/pkg/errors"
 )
 
@@ -13,6 +15,10 @@ func ValidateSchedule(schedule *entities.Schedule) error {
 		return errors.Validation("schedule is required")
 	}
 
+	if _, err := schedule.Location(); err != nil {
+		return errors.Validation("invalid time zone " + schedule.Timezone)
+	}
+
 	switch schedule.Type {
 	case entities.ScheduleManual:
 		return nil
@@ -22,6 +28,8 @@ func ValidateSchedule(schedule *entities.Schedule) error {
 		return validateIntervalSchedule(schedule.Config)
 	case entities.ScheduleDaily:
 		return validateDailySchedule(schedule.Config)
+	case entities.ScheduleCron:
+		return validateCronSchedule(schedule.Config)
 	default:
 		return errors.Validation("invalid schedule type")
 	}
@@ -98,3 +106,21 @@ func validateDailySchedule(config json.RawMessage) error {
 
 	return nil
 }
+
+func validateCronSchedule(config json.RawMessage) error {
+	var cfg entities.CronSchedule
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return errors.Validation("invalid cron schedule config")
+	}
+
+	expr, err := cron.Parse(cfg.Expression)
+	if err != nil {
+		return errors.Validation(fmt.Sprintf("invalid cron expression: %v", err))
+	}
+
+	if expr.Next(time.Now()).IsZero() {
+		return errors.Validation("cron expression never fires")
+	}
+
+	return nil
+}
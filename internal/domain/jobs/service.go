@@ -3,33 +3,51 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/articles"
+	"github.com/davidmovas/postulator/internal/domain/batches"
 	"github.com/davidmovas/postulator/internal/domain/categories"
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/knowledge"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
 	"github.com/davidmovas/postulator/internal/domain/sites"
 	"github.com/davidmovas/postulator/internal/domain/topics"
+	"github.com/davidmovas/postulator/pkg/cron"
 	"github.com/davidmovas/postulator/pkg/errors"
 	"github.com/davidmovas/postulator/pkg/logger"
 )
 
+const (
+	// maxSchedulePreview caps the fire times returned by a schedule preview
+	maxSchedulePreview = 20
+	// maxMisfireRuns caps the catch-up runs of the missed slots of a job
+	maxMisfireRuns = 24
+	// maxMissedRunsListed caps the missed runs returned for a job
+	maxMissedRunsListed = 200
+	// maxJobTimeoutMinutes caps how long a run of a job may take
+	maxJobTimeoutMinutes = 180
+)
+
 var _ Service = (*service)(nil)
 
 type service struct {
-	scheduler       Scheduler
-	siteService     sites.Service
-	topicService    topics.Service
-	promptService   prompts.Service
-	providerService providers.Service
-	categoryService categories.Service
-	articleService  articles.Service
-	repo            Repository
-	stateRepo       StateRepository
-	logger          *logger.Logger
+	scheduler        Scheduler
+	siteService      sites.Service
+	topicService     topics.Service
+	promptService    prompts.Service
+	providerService  providers.Service
+	categoryService  categories.Service
+	articleService   articles.Service
+	batchService     batches.Service
+	knowledgeService knowledge.Service
+	repo             Repository
+	stateRepo        StateRepository
+	missedRunRepo    MissedRunRepository
+	logger           *logger.Logger
 }
 
 func NewService(
@@ -40,25 +58,36 @@ func NewService(
 	providerService providers.Service,
 	categoryService categories.Service,
 	articleService articles.Service,
+	batchService batches.Service,
+	knowledgeService knowledge.Service,
 	repo Repository,
 	stateRepo StateRepository,
+	missedRunRepo MissedRunRepository,
 	logger *logger.Logger,
 ) Service {
-	return &service{
-		scheduler:       scheduler,
-		siteService:     siteService,
-		topicService:    topicService,
-		promptService:   promptService,
-		providerService: providerService,
-		categoryService: categoryService,
-		articleService:  articleService,
-		repo:            repo,
-		stateRepo:       stateRepo,
-		logger:          logger.WithScope("service").WithScope("jobs"),
-	}
+	s := &service{
+		scheduler:        scheduler,
+		siteService:      siteService,
+		topicService:     topicService,
+		promptService:    promptService,
+		providerService:  providerService,
+		categoryService:  categoryService,
+		articleService:   articleService,
+		batchService:     batchService,
+		knowledgeService: knowledgeService,
+		repo:             repo,
+		stateRepo:        stateRepo,
+		missedRunRepo:    missedRunRepo,
+		logger:           logger.WithScope("service").WithScope("jobs"),
+	}
+
+	batchService.RegisterHandler(entities.BatchSourceJob, s.publishBatchResults)
+	return s
 }
 
 func (s *service) CreateJob(ctx context.Context, job *entities.Job) error {
+	setJobDefaults(job)
+
 	if err := s.validateJob(job); err != nil {
 		return err
 	}
@@ -90,6 +119,13 @@ func (s *service) CreateJob(ctx context.Context, job *entities.Job) error {
 		}
 	}
 
+	if len(job.FallbackProviderIDs) > 0 {
+		if err := s.repo.SetFallbackProviders(ctx, job.ID, job.FallbackProviderIDs); err != nil {
+			s.logger.ErrorWithErr(err, "Failed to set job fallback providers")
+			return err
+		}
+	}
+
 	if job.Schedule != nil && job.Schedule.Type != entities.ScheduleManual {
 		baseTime, withJitter, err := s.scheduler.CalculateNextRun(job, nil)
 		if err != nil {
@@ -172,6 +208,8 @@ func (s *service) ListJobs(ctx context.Context) ([]*entities.Job, error) {
 }
 
 func (s *service) UpdateJob(ctx context.Context, job *entities.Job) error {
+	setJobDefaults(job)
+
 	if err := s.validateJob(job); err != nil {
 		return err
 	}
@@ -201,6 +239,13 @@ func (s *service) UpdateJob(ctx context.Context, job *entities.Job) error {
 		}
 	}
 
+	if job.FallbackProviderIDs != nil {
+		if err := s.repo.SetFallbackProviders(ctx, job.ID, job.FallbackProviderIDs); err != nil {
+			s.logger.ErrorWithErr(err, "Failed to update job fallback providers")
+			return err
+		}
+	}
+
 	if err := s.scheduler.ScheduleJob(ctx, job); err != nil {
 		s.logger.ErrorWithErr(err, "Failed to reschedule job")
 		return err
@@ -285,10 +330,61 @@ func (s *service) ExecuteManually(ctx context.Context, jobID int64) error {
 		return err
 	}
 
-	s.logger.Info("Job executed manually successfully")
+	s.logger.Info("Job queued for manual execution")
+	return nil
+}
+
+func (s *service) CancelExecution(_ context.Context, jobID int64) error {
+	if err := s.scheduler.CancelJob(jobID); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to cancel job execution")
+		return err
+	}
+
+	s.logger.Info("Job execution cancelled")
 	return nil
 }
 
+func (s *service) PreviewSchedule(_ context.Context, schedule *entities.Schedule, count int) ([]time.Time, error) {
+	if err := s.validateScheduleConfig(schedule); err != nil {
+		return nil, err
+	}
+
+	if count <= 0 || count > maxSchedulePreview {
+		count = maxSchedulePreview
+	}
+
+	return s.scheduler.PreviewRuns(schedule, count)
+}
+
+func (s *service) GetMissedRuns(ctx context.Context, jobID int64, limit int) ([]*entities.MissedRun, error) {
+	if _, err := s.repo.GetByID(ctx, jobID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > maxMissedRunsListed {
+		limit = maxMissedRunsListed
+	}
+
+	runs, err := s.missedRunRepo.GetByJobID(ctx, jobID, limit)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get missed runs")
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// setJobDefaults catches up missed slots with a single run and bounds the runs with
+// the default timeout, as jobs did before these could be chosen
+func setJobDefaults(job *entities.Job) {
+	if job.MisfirePolicy == "" {
+		job.MisfirePolicy = entities.MisfireRunOnce
+	}
+	if job.TimeoutMinutes == 0 {
+		job.TimeoutMinutes = entities.DefaultJobTimeoutMinutes
+	}
+}
+
 func (s *service) validateJob(job *entities.Job) error {
 	if strings.TrimSpace(job.Name) == "" {
 		return errors.Validation("Job name is required")
@@ -306,6 +402,21 @@ func (s *service) validateJob(job *entities.Job) error {
 		return errors.Validation("AI Provider ID is required")
 	}
 
+	seenProviders := map[int64]bool{job.AIProviderID: true}
+	for _, providerID := range job.FallbackProviderIDs {
+		if providerID <= 0 {
+			return errors.Validation("Invalid fallback AI Provider ID")
+		}
+		if seenProviders[providerID] {
+			return errors.Validation("Fallback AI Providers must be unique and differ from the primary provider")
+		}
+		seenProviders[providerID] = true
+	}
+
+	if err := job.GenerationParams.Validate(); err != nil {
+		return err
+	}
+
 	if validTopicStrategies := map[entities.TopicStrategy]bool{
 		entities.StrategyUnique:    true,
 		entities.StrategyVariation: true,
@@ -313,6 +424,28 @@ func (s *service) validateJob(job *entities.Job) error {
 		return errors.Validation("Invalid topic strategy")
 	}
 
+	if validMisfirePolicies := map[entities.MisfirePolicy]bool{
+		entities.MisfireSkip:    true,
+		entities.MisfireRunOnce: true,
+		entities.MisfireRunAll:  true,
+	}; !validMisfirePolicies[job.MisfirePolicy] {
+		return errors.Validation("Invalid misfire policy")
+	}
+
+	if job.MisfirePolicy == entities.MisfireRunAll {
+		if job.MisfireMaxRuns < 1 || job.MisfireMaxRuns > maxMisfireRuns {
+			return errors.Validation(fmt.Sprintf("Missed runs to catch up must be between 1 and %d", maxMisfireRuns))
+		}
+		// Catch-up runs fired back to back would hit the site in a burst
+		if job.MisfireSpacingMinutes < 1 || job.MisfireSpacingMinutes > 24*60 {
+			return errors.Validation("Spacing between catch-up runs must be between 1 and 1440 minutes")
+		}
+	}
+
+	if job.TimeoutMinutes < 1 || job.TimeoutMinutes > maxJobTimeoutMinutes {
+		return errors.Validation(fmt.Sprintf("Timeout must be between 1 and %d minutes", maxJobTimeoutMinutes))
+	}
+
 	if validCategoryStrategies := map[entities.CategoryStrategy]bool{
 		entities.CategoryFixed:  true,
 		entities.CategoryRandom: true,
@@ -327,6 +460,7 @@ func (s *service) validateJob(job *entities.Job) error {
 			entities.ScheduleOnce:     true,
 			entities.ScheduleInterval: true,
 			entities.ScheduleDaily:    true,
+			entities.ScheduleCron:     true,
 		}; !validScheduleTypes[job.Schedule.Type] {
 			return errors.Validation("Invalid schedule type")
 		}
@@ -346,13 +480,31 @@ func (s *service) validateDependencies(ctx context.Context, job *entities.Job) e
 		return errors.Validation("Site does not exist")
 	}
 
-	if _, err := s.promptService.GetPrompt(ctx, job.PromptID); err != nil {
+	prompt, err := s.promptService.GetPrompt(ctx, job.PromptID)
+	if err != nil {
 		return errors.Validation("Prompt does not exist")
 	}
 
-	if _, err := s.providerService.GetProvider(ctx, job.AIProviderID); err != nil {
+	// The params are sent merged over the prompt's to every provider the job may use
+	params := prompt.GenerationParams.Merge(job.GenerationParams)
+
+	provider, err := s.providerService.GetProvider(ctx, job.AIProviderID)
+	if err != nil {
 		return errors.Validation("AI Provider does not exist")
 	}
+	if err = params.ValidateFor(provider.Type); err != nil {
+		return err
+	}
+
+	for _, providerID := range job.FallbackProviderIDs {
+		fallback, err := s.providerService.GetProvider(ctx, providerID)
+		if err != nil {
+			return errors.Validation("Fallback AI Provider does not exist")
+		}
+		if err = params.ValidateFor(fallback.Type); err != nil {
+			return err
+		}
+	}
 
 	for _, categoryID := range job.Categories {
 		if _, err := s.categoryService.GetCategory(ctx, categoryID); err != nil {
@@ -374,6 +526,10 @@ func (s *service) validateScheduleConfig(schedule *entities.Schedule) error {
 		return errors.Validation("Schedule is required")
 	}
 
+	if _, err := schedule.Location(); err != nil {
+		return errors.Validation("Invalid time zone " + schedule.Timezone)
+	}
+
 	switch schedule.Type {
 	case entities.ScheduleOnce:
 		var config entities.OnceSchedule
@@ -414,6 +570,15 @@ func (s *service) validateScheduleConfig(schedule *entities.Schedule) error {
 				return errors.Validation("Weekday must be between 0 and 6")
 			}
 		}
+
+	case entities.ScheduleCron:
+		var config entities.CronSchedule
+		if err := json.Unmarshal(schedule.Config, &config); err != nil {
+			return errors.Validation("Invalid cron schedule configuration")
+		}
+		if _, err := cron.Parse(config.Expression); err != nil {
+			return errors.Validation(fmt.Sprintf("Invalid cron expression: %v", err))
+		}
 	}
 
 	return nil
@@ -33,7 +33,7 @@ func (r *stateRepository) Get(ctx context.Context, jobID int64) (*entities.State
 	query, args := dbx.ST.
 		Select(
 			"job_id", "last_run_at", "next_run_at", "next_run_base",
-			"total_executions", "failed_executions", "last_category_index",
+			"total_executions", "failed_executions", "last_category_index", "catch_up_runs",
 		).
 		From("job_state").
 		Where(squirrel.Eq{"job_id": jobID}).
@@ -50,6 +50,7 @@ func (r *stateRepository) Get(ctx context.Context, jobID int64) (*entities.State
 		&state.TotalExecutions,
 		&state.FailedExecutions,
 		&state.LastCategoryIndex,
+		&state.CatchUpRuns,
 	)
 
 	switch {
@@ -77,13 +78,13 @@ func (r *stateRepository) Update(ctx context.Context, state *entities.State) err
 		Insert("job_state").
 		Columns(
 			"job_id", "last_run_at", "next_run_at", "next_run_base",
-			"total_executions", "failed_executions", "last_category_index",
+			"total_executions", "failed_executions", "last_category_index", "catch_up_runs",
 		).
 		Values(
 			state.JobID, state.LastRunAt, state.NextRunAt, state.NextRunBase,
-			state.TotalExecutions, state.FailedExecutions, state.LastCategoryIndex,
+			state.TotalExecutions, state.FailedExecutions, state.LastCategoryIndex, state.CatchUpRuns,
 		).
-		Suffix("ON CONFLICT(job_id) DO UPDATE SET last_run_at = EXCLUDED.last_run_at, next_run_at = EXCLUDED.next_run_at, next_run_base = EXCLUDED.next_run_base, last_category_index = EXCLUDED.last_category_index").
+		Suffix("ON CONFLICT(job_id) DO UPDATE SET last_run_at = EXCLUDED.last_run_at, next_run_at = EXCLUDED.next_run_at, next_run_base = EXCLUDED.next_run_base, last_category_index = EXCLUDED.last_category_index, catch_up_runs = EXCLUDED.catch_up_runs").
 		MustSql()
 
 	_, err := r.db.ExecContext(ctx, query, args...)
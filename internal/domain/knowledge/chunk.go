@@ -0,0 +1,134 @@
+package knowledge
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxChunkChars keeps passages small enough for several to fit into a prompt
+	maxChunkChars = 1200
+	// maxQueryTerms bounds the full-text query built from a title and its keywords
+	maxQueryTerms = 24
+)
+
+// stopWords are left out of search queries, they match nearly every passage
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "by": {}, "for": {},
+	"from": {}, "how": {}, "in": {}, "is": {}, "it": {}, "of": {}, "on": {}, "or": {}, "the": {},
+	"to": {}, "what": {}, "when": {}, "where": {}, "which": {}, "who": {}, "why": {}, "with": {},
+	"your": {}, "you": {}, "our": {}, "we": {}, "best": {}, "guide": {},
+}
+
+// chunkText splits text into passages of at most maxChunkChars. Paragraphs are kept
+// whole where possible and packed together while they fit.
+func chunkText(text string) []string {
+	var pieces []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if len(paragraph) <= maxChunkChars {
+			pieces = append(pieces, paragraph)
+			continue
+		}
+		pieces = append(pieces, splitLong(paragraph)...)
+	}
+
+	var chunks []string
+	var current strings.Builder
+	for _, piece := range pieces {
+		if current.Len() > 0 && current.Len()+2+len(piece) > maxChunkChars {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(piece)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
+
+// splitLong splits a paragraph longer than maxChunkChars on line breaks, and lines
+// that are still too long on word boundaries
+func splitLong(paragraph string) []string {
+	var pieces []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			pieces = append(pieces, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, line := range strings.Split(paragraph, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if len(line) > maxChunkChars {
+			flush()
+			for _, word := range strings.Fields(line) {
+				if current.Len() > 0 && current.Len()+1+len(word) > maxChunkChars {
+					flush()
+				}
+				if current.Len() > 0 {
+					current.WriteString(" ")
+				}
+				current.WriteString(word)
+			}
+			flush()
+			continue
+		}
+
+		if current.Len() > 0 && current.Len()+1+len(line) > maxChunkChars {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	flush()
+
+	return pieces
+}
+
+// matchQuery turns free text into an FTS5 query matching passages with any of its
+// terms, which bm25 then ranks by how many and how rare they are. Empty when the
+// text has no searchable terms.
+func matchQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]struct{})
+	var terms []string
+	for _, word := range words {
+		if len([]rune(word)) < 2 {
+			continue
+		}
+		if _, ok := stopWords[word]; ok {
+			continue
+		}
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		seen[word] = struct{}{}
+
+		terms = append(terms, `"`+word+`"`)
+		if len(terms) == maxQueryTerms {
+			break
+		}
+	}
+
+	return strings.Join(terms, " OR ")
+}
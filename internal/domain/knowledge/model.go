@@ -0,0 +1,43 @@
+package knowledge
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+// DefaultPassageLimit is how many passages are injected into a generation prompt
+const DefaultPassageLimit = 4
+
+type Repository interface {
+	// CreateDocument stores the document with its chunks in order
+	CreateDocument(ctx context.Context, doc *entities.KnowledgeDocument, chunks []string) error
+	GetDocument(ctx context.Context, id int64) (*entities.KnowledgeDocument, error)
+	// ListDocuments returns the documents of the site, its job documents included
+	ListDocuments(ctx context.Context, siteID int64) ([]*entities.KnowledgeDocument, error)
+	DeleteDocument(ctx context.Context, id int64) error
+	// Search returns the chunks matching the full-text query, best match first. Only
+	// site-wide documents are searched when jobID is nil, the job's own as well otherwise.
+	Search(ctx context.Context, siteID int64, jobID *int64, match string, limit int) ([]*Passage, error)
+}
+
+// Passage is a chunk found for a generation, with the document it comes from
+type Passage struct {
+	Chunk        *entities.KnowledgeChunk
+	DocumentName string
+}
+
+type Service interface {
+	// AddDocument extracts the text of a local file, splits it into passages and indexes
+	// them for the site, or only for the job when jobID is set
+	AddDocument(ctx context.Context, siteID int64, jobID *int64, filePath string) (*entities.KnowledgeDocument, error)
+	ListDocuments(ctx context.Context, siteID int64) ([]*entities.KnowledgeDocument, error)
+	DeleteDocument(ctx context.Context, id int64) error
+
+	// FindPassages returns the passages most relevant to the query, best first
+	FindPassages(ctx context.Context, siteID int64, jobID *int64, query string, limit int) ([]*Passage, error)
+
+	// PromptContext renders the passages most relevant to the query as the knowledge
+	// context field of a prompt. Empty when the site has no matching documents.
+	PromptContext(ctx context.Context, siteID int64, jobID *int64, query string) (string, error)
+}
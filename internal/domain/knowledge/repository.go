@@ -0,0 +1,243 @@
+package knowledge
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+
+	"github.com/Masterminds/squirrel"
+)
+
+var _ Repository = (*repository)(nil)
+
+var documentColumns = []string{
+	"id",
+	"site_id",
+	"job_id",
+	"name",
+	"file_type",
+	"size_bytes",
+	"chunk_count",
+	"created_at",
+}
+
+type repository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewRepository(db *database.DB, logger *logger.Logger) Repository {
+	return &repository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("knowledge"),
+	}
+}
+
+func (r *repository) CreateDocument(ctx context.Context, doc *entities.KnowledgeDocument, chunks []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Database(err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query, args := dbx.ST.
+		Insert("knowledge_documents").
+		Columns("site_id", "job_id", "name", "file_type", "size_bytes", "chunk_count", "created_at").
+		Values(doc.SiteID, doc.JobID, doc.Name, doc.FileType, doc.SizeBytes, len(chunks), doc.CreatedAt).
+		MustSql()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	for position, content := range chunks {
+		query, args = dbx.ST.
+			Insert("knowledge_chunks").
+			Columns("document_id", "position", "content").
+			Values(id, position, content).
+			MustSql()
+
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return errors.Database(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Database(err)
+	}
+
+	doc.ID = id
+	doc.ChunkCount = len(chunks)
+	return nil
+}
+
+func (r *repository) GetDocument(ctx context.Context, id int64) (*entities.KnowledgeDocument, error) {
+	query, args := dbx.ST.
+		Select(documentColumns...).
+		From("knowledge_documents").
+		Where(squirrel.Eq{"id": id}).
+		MustSql()
+
+	doc, err := scanDocument(r.db.QueryRowContext(ctx, query, args...))
+	switch {
+	case dbx.IsNoRows(err):
+		return nil, errors.NotFound("knowledge document", id)
+	case err != nil:
+		return nil, errors.Database(err)
+	}
+
+	return doc, nil
+}
+
+func (r *repository) ListDocuments(ctx context.Context, siteID int64) ([]*entities.KnowledgeDocument, error) {
+	query, args := dbx.ST.
+		Select(documentColumns...).
+		From("knowledge_documents").
+		Where(squirrel.Eq{"site_id": siteID}).
+		OrderBy("created_at DESC", "id DESC").
+		MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var docs []*entities.KnowledgeDocument
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, errors.Database(err)
+		}
+		docs = append(docs, doc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return docs, nil
+}
+
+func (r *repository) DeleteDocument(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Database(err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Chunks are deleted one by one rather than by cascade, so the trigger keeps the
+	// full-text index in sync
+	query, args := dbx.ST.
+		Delete("knowledge_chunks").
+		Where(squirrel.Eq{"document_id": id}).
+		MustSql()
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return errors.Database(err)
+	}
+
+	query, args = dbx.ST.
+		Delete("knowledge_documents").
+		Where(squirrel.Eq{"id": id}).
+		MustSql()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("knowledge document", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Database(err)
+	}
+
+	return nil
+}
+
+func (r *repository) Search(ctx context.Context, siteID int64, jobID *int64, match string, limit int) ([]*Passage, error) {
+	scope := squirrel.Or{squirrel.Eq{"d.job_id": nil}}
+	if jobID != nil {
+		scope = append(scope, squirrel.Eq{"d.job_id": *jobID})
+	}
+
+	query, args := dbx.ST.
+		Select("c.id", "c.document_id", "c.position", "c.content", "d.name").
+		From("knowledge_chunks_fts").
+		Join("knowledge_chunks c ON c.id = knowledge_chunks_fts.rowid").
+		Join("knowledge_documents d ON d.id = c.document_id").
+		Where("knowledge_chunks_fts MATCH ?", match).
+		Where(squirrel.Eq{"d.site_id": siteID}).
+		Where(scope).
+		OrderBy("bm25(knowledge_chunks_fts)").
+		Limit(uint64(limit)).
+		MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var passages []*Passage
+	for rows.Next() {
+		var chunk entities.KnowledgeChunk
+		passage := &Passage{Chunk: &chunk}
+		if err = rows.Scan(&chunk.ID, &chunk.DocumentID, &chunk.Position, &chunk.Content, &passage.DocumentName); err != nil {
+			return nil, errors.Database(err)
+		}
+		passages = append(passages, passage)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return passages, nil
+}
+
+func scanDocument(row dbx.RowScanner) (*entities.KnowledgeDocument, error) {
+	var doc entities.KnowledgeDocument
+	err := row.Scan(
+		&doc.ID,
+		&doc.SiteID,
+		&doc.JobID,
+		&doc.Name,
+		&doc.FileType,
+		&doc.SizeBytes,
+		&doc.ChunkCount,
+		&doc.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
@@ -0,0 +1,125 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/sites"
+	"github.com/davidmovas/postulator/internal/infra/documents"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+// maxContextChars bounds the passages injected into a single prompt
+const maxContextChars = 5000
+
+var _ Service = (*service)(nil)
+
+type service struct {
+	repo    Repository
+	siteSvc sites.Service
+	logger  *logger.Logger
+}
+
+func NewService(repo Repository, siteSvc sites.Service, logger *logger.Logger) Service {
+	return &service{
+		repo:    repo,
+		siteSvc: siteSvc,
+		logger: logger.
+			WithScope("service").
+			WithScope("knowledge"),
+	}
+}
+
+func (s *service) AddDocument(ctx context.Context, siteID int64, jobID *int64, filePath string) (*entities.KnowledgeDocument, error) {
+	if _, err := s.siteSvc.GetSite(ctx, siteID); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, errors.Validation(fmt.Sprintf("Cannot read file %s", filepath.Base(filePath)))
+	}
+	if info.Size() > documents.MaxFileSize {
+		return nil, errors.Validation(fmt.Sprintf("Files larger than %d MB are not supported", documents.MaxFileSize>>20))
+	}
+
+	extractor, err := documents.GetExtractor(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := extractor.Extract(filePath)
+	if err != nil {
+		s.logger.ErrorWithErr(err, fmt.Sprintf("Failed to extract text from %s", filePath))
+		return nil, err
+	}
+
+	chunks := chunkText(text)
+	if len(chunks) == 0 {
+		return nil, errors.Validation("No text found in the document")
+	}
+
+	doc := &entities.KnowledgeDocument{
+		SiteID:    siteID,
+		JobID:     jobID,
+		Name:      filepath.Base(filePath),
+		FileType:  strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), "."),
+		SizeBytes: info.Size(),
+		CreatedAt: time.Now(),
+	}
+
+	if err = s.repo.CreateDocument(ctx, doc, chunks); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to store knowledge document")
+		return nil, err
+	}
+
+	s.logger.Infof("Indexed knowledge document %s for site %d in %d passages", doc.Name, siteID, doc.ChunkCount)
+	return doc, nil
+}
+
+func (s *service) ListDocuments(ctx context.Context, siteID int64) ([]*entities.KnowledgeDocument, error) {
+	return s.repo.ListDocuments(ctx, siteID)
+}
+
+func (s *service) DeleteDocument(ctx context.Context, id int64) error {
+	return s.repo.DeleteDocument(ctx, id)
+}
+
+func (s *service) FindPassages(ctx context.Context, siteID int64, jobID *int64, query string, limit int) ([]*Passage, error) {
+	match := matchQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	if limit <= 0 {
+		limit = DefaultPassageLimit
+	}
+
+	return s.repo.Search(ctx, siteID, jobID, match, limit)
+}
+
+func (s *service) PromptContext(ctx context.Context, siteID int64, jobID *int64, query string) (string, error) {
+	passages, err := s.FindPassages(ctx, siteID, jobID, query, DefaultPassageLimit)
+	if err != nil || len(passages) == 0 {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Facts from the client's own documents. Rely on them for product specifications, ")
+	sb.WriteString("prices, service areas and other details, and do not state details that contradict them.\n")
+
+	for _, passage := range passages {
+		if sb.Len()+len(passage.Chunk.Content) > maxContextChars {
+			break
+		}
+		fmt.Fprintf(&sb, "\n[%s]\n%s\n", passage.DocumentName, passage.Chunk.Content)
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
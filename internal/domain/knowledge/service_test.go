@@ -0,0 +1,162 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/deletion"
+	"github.com/davidmovas/postulator/internal/domain/sites"
+	"github.com/davidmovas/postulator/internal/infra/database"
+)
+
+// filler pads each section of the product sheet into a passage of its own
+var filler = strings.Repeat("This section is reviewed by the team every season. ", 16)
+
+var productSheet = strings.Join([]string{
+	"# Product catalog\n\nThe X200 cargo bike carries up to 450 kg and costs $4,999. " + filler,
+	"# Service areas\n\nWe deliver and repair bikes in Denver, Boulder and Fort Collins. " + filler,
+	"# Company\n\nFounded in 2012, the workshop employs twelve mechanics. " + filler,
+}, "\n\n")
+
+type serviceFixture struct {
+	service Service
+	db      *database.DB
+	siteID  int64
+	// jobs counts the jobs created, each with a provider of its own
+	jobs int
+}
+
+// newServiceFixture stores a site in a fresh database
+func newServiceFixture(t *testing.T) *serviceFixture {
+	t.Helper()
+
+	db, log := database.SetupTestEnv(t)
+
+	siteSvc := sites.NewService(nil, nil, sites.NewRepository(db, log), deletion.NewValidator(db), aiusage.NewService(aiusage.NewRepository(db), log), log)
+	return &serviceFixture{
+		service: NewService(NewRepository(db, log), siteSvc, log),
+		db:      db,
+		siteID:  database.SeedTestSite(t, db, "https://example.com"),
+	}
+}
+
+// createJob stores a job of the site with the provider and prompt it references
+func (f *serviceFixture) createJob(t *testing.T) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	f.jobs++
+	providerID := database.SeedTestProvider(t, f.db, fmt.Sprintf("Offline %d", f.jobs))
+
+	var promptID, jobID int64
+	err := f.db.QueryRowContext(ctx,
+		"INSERT INTO prompts (name, system_prompt, user_prompt) VALUES ('Test', '', '') RETURNING id",
+	).Scan(&promptID)
+	if err == nil {
+		err = f.db.QueryRowContext(ctx,
+			"INSERT INTO jobs (name, site_id, prompt_id, ai_provider_id, schedule_type) VALUES ('Test', ?, ?, ?, 'manual') RETURNING id",
+			f.siteID, promptID, providerID,
+		).Scan(&jobID)
+	}
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	return jobID
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestPromptContextFindsRelevantPassages(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	svc, siteID := f.service, f.siteID
+
+	doc, err := svc.AddDocument(ctx, siteID, nil, writeFile(t, "catalog.md", productSheet))
+	if err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	if doc.ChunkCount != 3 || doc.FileType != "md" {
+		t.Fatalf("expected 3 md passages, got %d %s", doc.ChunkCount, doc.FileType)
+	}
+
+	passages, err := svc.FindPassages(ctx, siteID, nil, "Bike repair in Boulder", 0)
+	if err != nil {
+		t.Fatalf("FindPassages: %v", err)
+	}
+	if len(passages) == 0 || !strings.Contains(passages[0].Chunk.Content, "Fort Collins") {
+		t.Fatalf("expected the service areas passage first, got %+v", passages)
+	}
+
+	prompt, err := svc.PromptContext(ctx, siteID, nil, "How much does the X200 cost?")
+	if err != nil {
+		t.Fatalf("PromptContext: %v", err)
+	}
+	if !strings.Contains(prompt, "[catalog.md]") || !strings.Contains(prompt, "$4,999") {
+		t.Errorf("expected the catalog passage in the prompt, got %q", prompt)
+	}
+
+	if prompt, err = svc.PromptContext(ctx, siteID, nil, "Underwater photography"); err != nil || prompt != "" {
+		t.Errorf("expected no context for an unrelated query, got %q, %v", prompt, err)
+	}
+}
+
+func TestJobDocumentsStayWithTheirJob(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	svc, siteID := f.service, f.siteID
+
+	jobID := f.createJob(t)
+	if _, err := svc.AddDocument(ctx, siteID, &jobID, writeFile(t, "promo.txt", "The spring promotion takes 15 percent off every cargo bike.")); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+
+	passages, err := svc.FindPassages(ctx, siteID, &jobID, "spring promotion", 0)
+	if err != nil || len(passages) != 1 {
+		t.Fatalf("expected the job's passage, got %d, %v", len(passages), err)
+	}
+
+	otherJob := f.createJob(t)
+	for _, scope := range []*int64{nil, &otherJob} {
+		if passages, err = svc.FindPassages(ctx, siteID, scope, "spring promotion", 0); err != nil || len(passages) != 0 {
+			t.Errorf("expected no passages outside the job, got %d, %v", len(passages), err)
+		}
+	}
+}
+
+func TestDeleteDocumentRemovesPassages(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	svc, siteID := f.service, f.siteID
+
+	doc, err := svc.AddDocument(ctx, siteID, nil, writeFile(t, "catalog.md", productSheet))
+	if err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+
+	if err = svc.DeleteDocument(ctx, doc.ID); err != nil {
+		t.Fatalf("DeleteDocument: %v", err)
+	}
+
+	passages, err := svc.FindPassages(ctx, siteID, nil, "X200 cargo bike", 0)
+	if err != nil || len(passages) != 0 {
+		t.Errorf("expected no passages after deletion, got %d, %v", len(passages), err)
+	}
+
+	docs, err := svc.ListDocuments(ctx, siteID)
+	if err != nil || len(docs) != 0 {
+		t.Errorf("expected no documents after deletion, got %d, %v", len(docs), err)
+	}
+}
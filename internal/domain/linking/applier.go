@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
@@ -28,6 +29,7 @@ type Applier struct {
 	linkRepo       LinkRepository
 	wpClient       wp.Client
 	aiUsageService aiusage.Service
+	budgetSvc      budgets.Service
 	eventBus       *events.EventBus
 	emitter        *ApplyEventEmitter
 	logger         *logger.Logger
@@ -41,6 +43,7 @@ func NewApplier(
 	linkRepo LinkRepository,
 	wpClient wp.Client,
 	aiUsageService aiusage.Service,
+	budgetSvc budgets.Service,
 	eventBus *events.EventBus,
 	logger *logger.Logger,
 ) *Applier {
@@ -52,6 +55,7 @@ func NewApplier(
 		linkRepo:       linkRepo,
 		wpClient:       wpClient,
 		aiUsageService: aiUsageService,
+		budgetSvc:      budgetSvc,
 		eventBus:       eventBus,
 		emitter:        NewApplyEventEmitter(eventBus),
 		logger:         logger.WithScope("linking.applier"),
@@ -66,28 +70,10 @@ type ApplyConfig struct {
 	LinkIDs    []int64
 }
 
-// calculateConcurrency determines optimal concurrency based on provider's RPM limits
-// Returns a value between 1 and maxConcurrency based on the model's rate limits
+// calculateConcurrency determines the number of workers from the model's concurrency
+// limit. The AI governor enforces that limit across every caller of the same model.
 func (a *Applier) calculateConcurrency(provider *entities.Provider) int {
-	modelInfo := ai.GetModelInfo(provider.Type, provider.Model)
-	if modelInfo == nil {
-		return 1 // Default to sequential if model info not found
-	}
-
-	// Calculate concurrency based on RPM
-	// Assume each request takes ~6 seconds on average (10 requests per minute per worker)
-	// We want to stay safely under the limit, so use 80% of theoretical max
-	concurrency := (modelInfo.RPM * RPMMultiplier) / RPMDivisor
-
-	// Clamp between MinConcurrency and MaxConcurrency
-	if concurrency < MinConcurrency {
-		concurrency = MinConcurrency
-	}
-	if concurrency > MaxConcurrency {
-		concurrency = MaxConcurrency
-	}
-
-	return concurrency
+	return ai.MaxConcurrency(provider.Type, provider.Model)
 }
 
 // sourceNodeWork represents work to be done for a single source node
@@ -118,6 +104,10 @@ func (a *Applier) Apply(ctx context.Context, config ApplyConfig) (*ApplyResult,
 		return nil, fmt.Errorf("provider is not active")
 	}
 
+	if err = a.checkBudget(ctx, config.SiteID, provider.ID); err != nil {
+		return nil, err
+	}
+
 	aiClient, err := ai.CreateClient(provider)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AI client: %w", err)
@@ -210,7 +200,7 @@ func (a *Applier) Apply(ctx context.Context, config ApplyConfig) (*ApplyResult,
 	a.logger.Infof("Using concurrency %d for %d pages (RPM-based)", concurrency, len(workItems))
 
 	// Process work items with concurrency
-	results := a.processWorkItems(ctx, taskID, workItems, aiClient, site, config.SiteID, concurrency, prompt)
+	results := a.processWorkItems(ctx, taskID, workItems, aiClient, site, config.SiteID, provider.ID, concurrency, prompt)
 
 	// Aggregate results
 	for _, r := range results {
@@ -236,6 +226,7 @@ func (a *Applier) processWorkItems(
 	aiClient ai.Client,
 	site *entities.Site,
 	siteID int64,
+	providerID int64,
 	concurrency int,
 	prompt *entities.Prompt,
 ) []sourceNodeResult {
@@ -292,7 +283,7 @@ func (a *Applier) processWorkItems(
 			a.emitter.EmitPageProcessing(ctx, taskID, w.sourceNodeID, w.sourceNode.Title, len(w.links))
 
 			// Process the node
-			appliedInfos, failedCount, err := a.applyLinksToNode(ctx, aiClient, site, w.sourceNode, w.links, siteID, prompt)
+			appliedInfos, failedCount, err := a.applyLinksToNode(ctx, aiClient, site, w.sourceNode, w.links, siteID, providerID, prompt)
 
 			// Store result
 			results[idx] = sourceNodeResult{
@@ -335,12 +326,18 @@ func (a *Applier) applyLinksToNode(
 	sourceNode *entities.SitemapNode,
 	links []*PlannedLink,
 	siteID int64,
+	providerID int64,
 	prompt *entities.Prompt,
 ) ([]*AppliedLinkInfo, int, error) {
 	if sourceNode.WPPageID == nil {
 		return nil, len(links), fmt.Errorf("source node has no WordPress page ID")
 	}
 
+	// Links stay approved so they can be applied once the budget allows it
+	if err := a.checkBudget(ctx, siteID, providerID); err != nil {
+		return nil, 0, err
+	}
+
 	// Get page content from WordPress
 	page, err := a.wpClient.GetPage(ctx, site, *sourceNode.WPPageID)
 	if err != nil {
@@ -429,6 +426,7 @@ func (a *Applier) applyLinksToNode(
 		if logErr := a.aiUsageService.LogFromResult(
 			ctx,
 			siteID,
+			providerID,
 			aiusage.OpLinkInsertion,
 			aiClient,
 			insertResult.Usage,
@@ -569,3 +567,10 @@ func (a *Applier) buildApplyPrompts(
 
 	return sys, usr
 }
+
+func (a *Applier) checkBudget(ctx context.Context, siteID, providerID int64) error {
+	if a.budgetSvc == nil {
+		return nil
+	}
+	return a.budgetSvc.Check(ctx, siteID, providerID)
+}
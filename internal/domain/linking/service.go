@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/domain/embeddings"
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
@@ -38,6 +40,8 @@ func NewService(
 	promptSvc prompts.Service,
 	wpClient wp.Client,
 	aiUsageService aiusage.Service,
+	budgetSvc budgets.Service,
+	embeddingSvc embeddings.Service,
 	eventBus *events.EventBus,
 	logger *logger.Logger,
 ) Service {
@@ -46,8 +50,8 @@ func NewService(
 		planRepo:   NewPlanRepository(db.DB),
 		linkRepo:   linkRepo,
 		sitemapSvc: sitemapSvc,
-		suggester:  NewSuggester(sitemapSvc, providerSvc, promptSvc, linkRepo, aiUsageService, eventBus, logger),
-		applier:    NewApplier(sitemapSvc, sitesSvc, providerSvc, promptSvc, linkRepo, wpClient, aiUsageService, eventBus, logger),
+		suggester:  NewSuggester(sitemapSvc, providerSvc, promptSvc, linkRepo, aiUsageService, budgetSvc, embeddingSvc, eventBus, logger),
+		applier:    NewApplier(sitemapSvc, sitesSvc, providerSvc, promptSvc, linkRepo, wpClient, aiUsageService, budgetSvc, eventBus, logger),
 		eventBus:   eventBus,
 		logger:     logger.WithScope("linking"),
 	}
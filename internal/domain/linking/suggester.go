@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/domain/embeddings"
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
@@ -23,6 +25,8 @@ type Suggester struct {
 	promptSvc      prompts.Service
 	linkRepo       LinkRepository
 	aiUsageService aiusage.Service
+	budgetSvc      budgets.Service
+	embeddingSvc   embeddings.Service
 	eventBus       *events.EventBus
 	emitter        *SuggestEventEmitter
 	logger         *logger.Logger
@@ -34,6 +38,8 @@ func NewSuggester(
 	promptSvc prompts.Service,
 	linkRepo LinkRepository,
 	aiUsageService aiusage.Service,
+	budgetSvc budgets.Service,
+	embeddingSvc embeddings.Service,
 	eventBus *events.EventBus,
 	logger *logger.Logger,
 ) *Suggester {
@@ -43,6 +49,8 @@ func NewSuggester(
 		promptSvc:      promptSvc,
 		linkRepo:       linkRepo,
 		aiUsageService: aiUsageService,
+		budgetSvc:      budgetSvc,
+		embeddingSvc:   embeddingSvc,
 		eventBus:       eventBus,
 		emitter:        NewSuggestEventEmitter(eventBus),
 		logger:         logger.WithScope("linking.suggester"),
@@ -130,7 +138,7 @@ func (s *Suggester) Suggest(ctx context.Context, config SuggestConfig) (*Suggest
 	}
 
 	// Split into batches if too many nodes
-	batches := s.splitIntoBatches(filteredNodes, maxNodesPerBatch)
+	batches := s.buildBatches(ctx, config, provider, filteredNodes)
 	totalBatches := len(batches)
 	totalNodes := len(filteredNodes)
 
@@ -166,6 +174,14 @@ func (s *Suggester) Suggest(ctx context.Context, config SuggestConfig) (*Suggest
 		// Emit progress before processing batch
 		s.emitter.EmitSuggestProgress(ctx, taskID, batchIdx+1, totalBatches, processedNodes, totalNodes, totalLinksCreated, len(batchNodes))
 
+		// Unlike AI failures, an exhausted budget fails every remaining batch as well
+		if s.budgetSvc != nil {
+			if err = s.budgetSvc.Check(ctx, config.SiteID, provider.ID); err != nil {
+				s.emitter.EmitSuggestFailed(ctx, taskID, err.Error())
+				return nil, err
+			}
+		}
+
 		systemPrompt, userPrompt := s.buildPrompts(ctx, config, batchNodes, outgoingCount, incomingCount)
 
 		request := &ai.LinkSuggestionRequest{
@@ -191,6 +207,7 @@ func (s *Suggester) Suggest(ctx context.Context, config SuggestConfig) (*Suggest
 			_ = s.aiUsageService.LogFromResult(
 				ctx,
 				config.SiteID,
+				provider.ID,
 				aiusage.OpLinkSuggestion,
 				aiClient,
 				result.Usage,
@@ -226,6 +243,77 @@ func (s *Suggester) Suggest(ctx context.Context, config SuggestConfig) (*Suggest
 	}, nil
 }
 
+// buildBatches puts related pages into the same batch when the provider can embed text,
+// so the AI only compares pages worth linking together. Otherwise, or when indexing
+// fails, nodes are batched by their position in the tree.
+func (s *Suggester) buildBatches(ctx context.Context, config SuggestConfig, provider *entities.Provider, nodes []*entities.SitemapNode) [][]*entities.SitemapNode {
+	if len(nodes) <= maxNodesPerBatch || s.embeddingSvc == nil || !ai.SupportsEmbeddings(provider.Type) {
+		return s.splitIntoBatches(nodes, maxNodesPerBatch)
+	}
+
+	documents := make([]embeddings.Document, len(nodes))
+	for i, node := range nodes {
+		documents[i] = embeddings.Document{
+			EntityType: entities.EmbeddingSitemapNode,
+			EntityID:   node.ID,
+			Text:       nodeText(node),
+		}
+	}
+
+	index, err := s.embeddingSvc.Index(ctx, config.SiteID, provider.ID, documents)
+	if err != nil {
+		s.logger.Warnf("Failed to index nodes, batching by position: %v", err)
+		return s.splitIntoBatches(nodes, maxNodesPerBatch)
+	}
+
+	return semanticBatches(nodes, index, maxNodesPerBatch)
+}
+
+// semanticBatches fills batches greedily: each batch starts from the first unassigned
+// node in tree order and takes the unassigned nodes most similar to it
+func semanticBatches(nodes []*entities.SitemapNode, index *embeddings.VectorIndex, maxSize int) [][]*entities.SitemapNode {
+	nodeMap := make(map[int64]*entities.SitemapNode, len(nodes))
+	for _, node := range nodes {
+		nodeMap[node.ID] = node
+	}
+
+	assigned := make(map[int64]bool, len(nodes))
+	skip := func(id int64) bool {
+		return assigned[id] || nodeMap[id] == nil
+	}
+
+	var batches [][]*entities.SitemapNode
+	for _, seed := range nodes {
+		if assigned[seed.ID] {
+			continue
+		}
+		assigned[seed.ID] = true
+
+		batch := []*entities.SitemapNode{seed}
+		if vector, ok := index.Vector(seed.ID); ok {
+			for _, match := range index.Nearest(vector, maxSize-1, skip) {
+				assigned[match.EntityID] = true
+				batch = append(batch, nodeMap[match.EntityID])
+			}
+		}
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// nodeText is the text embedded for a node: its title, path, keywords and description
+func nodeText(node *entities.SitemapNode) string {
+	parts := []string{node.Title, "/" + node.Slug}
+	if len(node.Keywords) > 0 {
+		parts = append(parts, strings.Join(node.Keywords, ", "))
+	}
+	if node.Description != nil && *node.Description != "" {
+		parts = append(parts, *node.Description)
+	}
+	return strings.Join(parts, "\n")
+}
+
 // splitIntoBatches divides nodes into batches of maxSize
 func (s *Suggester) splitIntoBatches(nodes []*entities.SitemapNode, maxSize int) [][]*entities.SitemapNode {
 	if len(nodes) <= maxSize {
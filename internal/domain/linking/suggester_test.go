@@ -0,0 +1,212 @@
+package linking
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/deletion"
+	"github.com/davidmovas/postulator/internal/domain/embeddings"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/prompts"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/domain/sitemap"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/internal/infra/events"
+)
+
+type suggesterFixture struct {
+	suggester *Suggester
+	links     LinkRepository
+	config    SuggestConfig
+}
+
+// newSuggesterFixture stores a site, a sitemap with four pages, a mock provider
+// and an empty link plan in a fresh database
+func newSuggesterFixture(t *testing.T) *suggesterFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	db, log := database.SetupTestEnv(t)
+
+	siteID := database.SeedTestSite(t, db, "https://example.com")
+	providerID := database.SeedTestProvider(t, db, "Offline")
+
+	sitemapSvc := sitemap.NewService(
+		sitemap.NewRepository(db, log),
+		sitemap.NewNodeRepository(db, log),
+		sitemap.NewKeywordRepository(db, log),
+		log,
+	)
+
+	sm := &entities.Sitemap{SiteID: siteID, Name: "Main"}
+	if err := sitemapSvc.CreateSitemapWithRoot(ctx, sm, "https://example.com"); err != nil {
+		t.Fatalf("failed to create sitemap: %v", err)
+	}
+
+	var nodeIDs []int64
+	for i := 1; i <= 4; i++ {
+		node := &entities.SitemapNode{
+			SitemapID: sm.ID,
+			Title:     fmt.Sprintf("Page %d", i),
+			Slug:      fmt.Sprintf("page-%d", i),
+			Position:  i,
+		}
+		if err := sitemapSvc.CreateNode(ctx, node); err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+
+	plan := &LinkPlan{
+		SitemapID:  sm.ID,
+		SiteID:     siteID,
+		Name:       "Plan",
+		Status:     PlanStatusDraft,
+		ProviderID: &providerID,
+	}
+	if err := NewPlanRepository(db.DB).Create(ctx, plan); err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+
+	deletionValidator := deletion.NewValidator(db)
+	links := NewLinkRepository(db.DB)
+
+	return &suggesterFixture{
+		suggester: NewSuggester(
+			sitemapSvc,
+			providers.NewService(providers.NewRepository(db, log), providers.NewModelRepository(db, log), providers.NewKeyRepository(db, log), deletionValidator, log),
+			prompts.NewService(prompts.NewRepository(db, log), deletionValidator, log),
+			links,
+			aiusage.NewService(aiusage.NewRepository(db), log),
+			nil,
+			nil,
+			events.NewEventBus(),
+			log,
+		),
+		links: links,
+		config: SuggestConfig{
+			PlanID:     plan.ID,
+			SitemapID:  sm.ID,
+			SiteID:     siteID,
+			ProviderID: providerID,
+			NodeIDs:    nodeIDs,
+		},
+	}
+}
+
+func TestSuggesterCreatesPlannedLinks(t *testing.T) {
+	f := newSuggesterFixture(t)
+	ctx := context.Background()
+
+	f.config.MaxOutgoing = 1
+	result, err := f.suggester.Suggest(ctx, f.config)
+	if err != nil {
+		t.Fatalf("Suggest: %v", err)
+	}
+
+	if result.LinksCreated != 4 {
+		t.Fatalf("expected 4 links, got %d", result.LinksCreated)
+	}
+
+	links, err := f.links.GetByPlanID(ctx, f.config.PlanID)
+	if err != nil {
+		t.Fatalf("GetByPlanID: %v", err)
+	}
+
+	// The synthetic provider links each page to the next one, wrapping around
+	ids := f.config.NodeIDs
+	expected := map[int64]int64{ids[0]: ids[1], ids[1]: ids[2], ids[2]: ids[3], ids[3]: ids[0]}
+	for _, link := range links {
+		if expected[link.SourceNodeID] != link.TargetNodeID {
+			t.Errorf("unexpected link %d -> %d", link.SourceNodeID, link.TargetNodeID)
+		}
+		if link.Status != LinkStatusPlanned || link.Source != LinkSourceAI {
+			t.Errorf("unexpected link status/source: %s/%s", link.Status, link.Source)
+		}
+		if link.AnchorText == nil || *link.AnchorText == "" {
+			t.Error("expected anchor text")
+		}
+	}
+
+	// Suggesting again must not duplicate existing node pairs
+	result, err = f.suggester.Suggest(ctx, f.config)
+	if err != nil {
+		t.Fatalf("Suggest: %v", err)
+	}
+	if result.LinksCreated != 0 {
+		t.Errorf("expected no new links on second run, got %d", result.LinksCreated)
+	}
+}
+
+func TestSuggesterRespectsLinkLimits(t *testing.T) {
+	f := newSuggesterFixture(t)
+	ctx := context.Background()
+
+	f.config.MaxOutgoing = 2
+	f.config.MaxIncoming = 1
+	if _, err := f.suggester.Suggest(ctx, f.config); err != nil {
+		t.Fatalf("Suggest: %v", err)
+	}
+
+	links, err := f.links.GetByPlanID(ctx, f.config.PlanID)
+	if err != nil {
+		t.Fatalf("GetByPlanID: %v", err)
+	}
+
+	outgoing := make(map[int64]int)
+	incoming := make(map[int64]int)
+	for _, link := range links {
+		outgoing[link.SourceNodeID]++
+		incoming[link.TargetNodeID]++
+	}
+
+	for nodeID, count := range outgoing {
+		if count > 2 {
+			t.Errorf("node %d has %d outgoing links, limit is 2", nodeID, count)
+		}
+	}
+	for nodeID, count := range incoming {
+		if count > 1 {
+			t.Errorf("node %d has %d incoming links, limit is 1", nodeID, count)
+		}
+	}
+}
+
+func TestSuggesterRequiresTwoNodes(t *testing.T) {
+	f := newSuggesterFixture(t)
+
+	f.config.NodeIDs = f.config.NodeIDs[:1]
+	if _, err := f.suggester.Suggest(context.Background(), f.config); err == nil {
+		t.Fatal("expected error for a single node")
+	}
+}
+
+func TestSemanticBatchesGroupSimilarNodes(t *testing.T) {
+	vectors := map[int64][]float32{
+		1: {1, 0},
+		2: {0, 1},
+		3: {0.9, 0.1},
+		4: {0.1, 0.9},
+	}
+
+	var nodes []*entities.SitemapNode
+	index := embeddings.NewVectorIndex(entities.EmbeddingSitemapNode)
+	for id := int64(1); id <= 4; id++ {
+		nodes = append(nodes, &entities.SitemapNode{ID: id})
+		index.Add(id, vectors[id])
+	}
+
+	batches := semanticBatches(nodes, index, 2)
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 batches, got %d", len(batches))
+	}
+
+	expected := [][2]int64{{1, 3}, {2, 4}}
+	for i, batch := range batches {
+		if len(batch) != 2 || batch[0].ID != expected[i][0] || batch[1].ID != expected[i][1] {
+			t.Errorf("batch %d: expected nodes %v, got %d nodes starting at %d", i, expected[i], len(batch), batch[0].ID)
+		}
+	}
+}
@@ -5,15 +5,21 @@ import (
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
 	"github.com/davidmovas/postulator/internal/domain/articles"
+	"github.com/davidmovas/postulator/internal/domain/batches"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/categories"
 	"github.com/davidmovas/postulator/internal/domain/deletion"
+	"github.com/davidmovas/postulator/internal/domain/embeddings"
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/healthcheck"
+	"github.com/davidmovas/postulator/internal/domain/images"
 	"github.com/davidmovas/postulator/internal/domain/jobs"
 	"github.com/davidmovas/postulator/internal/domain/jobs/execution"
 	"github.com/davidmovas/postulator/internal/domain/jobs/schedule"
+	"github.com/davidmovas/postulator/internal/domain/knowledge"
 	"github.com/davidmovas/postulator/internal/domain/linking"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
+	"github.com/davidmovas/postulator/internal/domain/providerhealth"
 	"github.com/davidmovas/postulator/internal/domain/providers"
 	"github.com/davidmovas/postulator/internal/domain/proxy"
 	"github.com/davidmovas/postulator/internal/domain/settings"
@@ -48,10 +54,12 @@ var Module = fx.Module("domain",
 		// Jobs
 		jobs.NewRepository,
 		jobs.NewStateRepository,
+		jobs.NewMissedRunRepository,
 		jobs.NewService,
 
 		// Execution
 		execution.NewRepository,
+		execution.NewCheckpointRepository,
 		execution.NewService,
 		execution.NewExecutor,
 		execution.NewExecutionStatsAdapter,
@@ -61,11 +69,24 @@ var Module = fx.Module("domain",
 		prompts.NewService,
 		prompts.NewMigrator,
 
+		// Embeddings (before linking as the suggester batches pages by similarity)
+		embeddings.NewRepository,
+		embeddings.NewService,
+
+		// Images
+		images.NewService,
+
+		// Knowledge base
+		knowledge.NewRepository,
+		knowledge.NewService,
+
 		// Linking
 		linking.NewService,
 
 		// Providers
 		providers.NewRepository,
+		providers.NewModelRepository,
+		providers.NewKeyRepository,
 		providers.NewService,
 
 		// Sites
@@ -82,10 +103,30 @@ var Module = fx.Module("domain",
 		healthcheck.NewService,
 		healthcheck.NewNotifier,
 
+		// Provider health
+		providerhealth.NewRepository,
+		providerhealth.NewNotifier,
+		func(
+			providerSvc providers.Service,
+			aiUsageSvc aiusage.Service,
+			repo providerhealth.Repository,
+			logger *logger.Logger,
+		) providerhealth.Service {
+			return providerhealth.NewService(providerSvc, aiUsageSvc, repo, ai.CreateKeyClient, logger)
+		},
+
 		// AI Usage
 		aiusage.NewRepository,
 		aiusage.NewService,
 
+		// AI Budgets
+		budgets.NewRepository,
+		budgets.NewService,
+
+		// AI Batches
+		batches.NewRepository,
+		batches.NewService,
+
 		// Topics
 		topics.NewRepository,
 		topics.NewUsageRepository,
@@ -119,6 +160,7 @@ var Module = fx.Module("domain",
 				promptSvc prompts.Service,
 				providerSvc providers.Service,
 				aiUsageSvc aiusage.Service,
+				budgetSvc budgets.Service,
 				logger *logger.Logger,
 			) *sitemap.GenerationService {
 				return sitemap.NewGenerationService(
@@ -127,6 +169,7 @@ var Module = fx.Module("domain",
 					promptSvc,
 					providerSvc,
 					aiUsageSvc,
+					budgetSvc,
 					func(provider *entities.Provider) (ai.Client, error) {
 						return ai.CreateClient(provider)
 					},
@@ -148,6 +191,10 @@ var Module = fx.Module("domain",
 				providerSvc providers.Service,
 				linkingSvc linking.Service,
 				aiUsageService aiusage.Service,
+				budgetSvc budgets.Service,
+				batchSvc batches.Service,
+				imageSvc images.Service,
+				knowledgeSvc knowledge.Service,
 				wpClient wp.Client,
 				eventBus *events.EventBus,
 				logger *logger.Logger,
@@ -160,6 +207,10 @@ var Module = fx.Module("domain",
 					providerSvc,
 					linkingSvc,
 					aiUsageService,
+					budgetSvc,
+					batchSvc,
+					imageSvc,
+					knowledgeSvc,
 					wpClient,
 					eventBus,
 					func(provider *entities.Provider) (ai.Client, error) {
@@ -199,6 +250,32 @@ var Module = fx.Module("domain",
 		})
 	}),
 
+	// Provider health Scheduler lifecycle
+	fx.Provide(providerhealth.NewScheduler),
+	fx.Invoke(func(lc fx.Lifecycle, scheduler providerhealth.Scheduler) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return scheduler.Start(ctx)
+			},
+			OnStop: func(ctx context.Context) error {
+				return scheduler.Stop()
+			},
+		})
+	}),
+
+	// Batch poller lifecycle
+	fx.Provide(batches.NewPoller),
+	fx.Invoke(func(lc fx.Lifecycle, poller batches.Poller) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return poller.Start(ctx)
+			},
+			OnStop: func(ctx context.Context) error {
+				return poller.Stop()
+			},
+		})
+	}),
+
 	// Proxy initialization
 	fx.Invoke(func(lc fx.Lifecycle, proxyService proxy.Service) {
 		type initializer interface {
@@ -214,6 +291,15 @@ var Module = fx.Module("domain",
 		})
 	}),
 
+	// Model catalog (user-defined models layered over the built-in ones)
+	fx.Invoke(func(lc fx.Lifecycle, providerService providers.Service) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return providerService.LoadModelCatalog(ctx)
+			},
+		})
+	}),
+
 	// Prompts V2 migration (runs once on startup)
 	fx.Invoke(func(lc fx.Lifecycle, migrator *prompts.Migrator) {
 		lc.Append(fx.Hook{
@@ -90,6 +90,7 @@ func (r *ContextFieldRegistry) registerAllFields() {
 			entities.PromptCategoryPostGen,
 			entities.PromptCategoryPageGen,
 			entities.PromptCategoryLinkApply,
+			entities.PromptCategoryArticleRewrite,
 		},
 		Group: "settings",
 	})
@@ -103,6 +104,19 @@ func (r *ContextFieldRegistry) registerAllFields() {
 		Group:        "settings",
 	})
 
+	r.register(&entities.ContextFieldDefinition{
+		Key:          "knowledge",
+		Label:        "Reference Material",
+		Description:  "Include the most relevant passages of the site's knowledge base documents",
+		Type:         entities.ContextFieldTypeCheckbox,
+		DefaultValue: "true",
+		Categories: []entities.PromptCategory{
+			entities.PromptCategoryPostGen,
+			entities.PromptCategoryPageGen,
+		},
+		Group: "advanced",
+	})
+
 	// =========================================================================
 	// PAGE_GEN Fields
 	// =========================================================================
@@ -269,6 +283,57 @@ func (r *ContextFieldRegistry) registerAllFields() {
 		Categories:   []entities.PromptCategory{entities.PromptCategoryLinkApply},
 		Group:        "content",
 	})
+
+	// =========================================================================
+	// ARTICLE_REWRITE Fields
+	// =========================================================================
+	r.register(&entities.ContextFieldDefinition{
+		Key:          "articleTitle",
+		Label:        "Article Title",
+		Description:  "Include the current article title",
+		Type:         entities.ContextFieldTypeCheckbox,
+		DefaultValue: "true",
+		Required:     true,
+		Categories:   []entities.PromptCategory{entities.PromptCategoryArticleRewrite},
+		Group:        "content",
+	})
+	r.register(&entities.ContextFieldDefinition{
+		Key:          "rewriteInstructions",
+		Label:        "Changes Requested",
+		Description:  "Include what to change (passed via runtime data)",
+		Type:         entities.ContextFieldTypeCheckbox,
+		DefaultValue: "true",
+		Required:     true,
+		Categories:   []entities.PromptCategory{entities.PromptCategoryArticleRewrite},
+		Group:        "content",
+	})
+	r.register(&entities.ContextFieldDefinition{
+		Key:          "articleContent",
+		Label:        "Article Content",
+		Description:  "Include the current article HTML content",
+		Type:         entities.ContextFieldTypeCheckbox,
+		DefaultValue: "true",
+		Required:     true,
+		Categories:   []entities.PromptCategory{entities.PromptCategoryArticleRewrite},
+		Group:        "content",
+	})
+	r.register(&entities.ContextFieldDefinition{
+		Key:          "rewriteTone",
+		Label:        "Content Tone",
+		Description:  "Tone of the revised article, empty keeps the current one",
+		Type:         entities.ContextFieldTypeSelect,
+		DefaultValue: "",
+		Options: []entities.SelectOption{
+			{Value: "", Label: "Keep current"},
+			{Value: "informative", Label: "Informative"},
+			{Value: "persuasive", Label: "Persuasive"},
+			{Value: "educational", Label: "Educational"},
+			{Value: "engaging", Label: "Engaging"},
+			{Value: "authoritative", Label: "Authoritative"},
+		},
+		Categories: []entities.PromptCategory{entities.PromptCategoryArticleRewrite},
+		Group:      "style",
+	})
 }
 
 // register adds a field definition to the registry
@@ -43,6 +43,11 @@ func (r *repository) Create(ctx context.Context, prompt *entities.Prompt) error
 		return errors.Validation("Invalid context config format")
 	}
 
+	generationParamsJSON, err := marshalGenerationParams(prompt.GenerationParams)
+	if err != nil {
+		return errors.Validation("Invalid generation params format")
+	}
+
 	category := prompt.Category
 	if category == "" {
 		category = entities.PromptCategoryPostGen
@@ -56,8 +61,8 @@ func (r *repository) Create(ctx context.Context, prompt *entities.Prompt) error
 
 	query, args := dbx.ST.
 		Insert("prompts").
-		Columns("name", "category", "is_builtin", "system_prompt", "user_prompt", "placeholders", "instructions", "context_config", "version").
-		Values(prompt.Name, category, prompt.IsBuiltin, prompt.SystemPrompt, prompt.UserPrompt, placeholdersJSON, prompt.Instructions, contextConfigJSON, version).
+		Columns("name", "category", "is_builtin", "system_prompt", "user_prompt", "placeholders", "instructions", "context_config", "generation_params", "version").
+		Values(prompt.Name, category, prompt.IsBuiltin, prompt.SystemPrompt, prompt.UserPrompt, placeholdersJSON, prompt.Instructions, contextConfigJSON, generationParamsJSON, version).
 		MustSql()
 
 	result, err := r.db.ExecContext(ctx, query, args...)
@@ -80,7 +85,7 @@ func (r *repository) Create(ctx context.Context, prompt *entities.Prompt) error
 
 func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Prompt, error) {
 	query, args := dbx.ST.
-		Select("id", "name", "category", "is_builtin", "system_prompt", "user_prompt", "placeholders", "instructions", "context_config", "version", "created_at", "updated_at").
+		Select("id", "name", "category", "is_builtin", "system_prompt", "user_prompt", "placeholders", "instructions", "context_config", "generation_params", "version", "created_at", "updated_at").
 		From("prompts").
 		Where(squirrel.Eq{"id": id}).
 		MustSql()
@@ -90,6 +95,7 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Prompt, e
 	var placeholdersJSON sql.NullString
 	var instructions sql.NullString
 	var contextConfigJSON sql.NullString
+	var generationParamsJSON sql.NullString
 	var version sql.NullInt64
 
 	err := r.db.QueryRowContext(ctx, query, args...).Scan(
@@ -102,6 +108,7 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Prompt, e
 		&placeholdersJSON,
 		&instructions,
 		&contextConfigJSON,
+		&generationParamsJSON,
 		&version,
 		&prompt.CreatedAt,
 		&prompt.UpdatedAt,
@@ -118,6 +125,7 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Prompt, e
 	prompt.Placeholders = parsePlaceholders(placeholdersJSON)
 	prompt.Instructions = instructions.String
 	prompt.ContextConfig = parseContextConfig(contextConfigJSON)
+	prompt.GenerationParams = parseGenerationParams(generationParamsJSON)
 	prompt.Version = int(version.Int64)
 
 	return &prompt, nil
@@ -125,7 +133,7 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Prompt, e
 
 func (r *repository) GetAll(ctx context.Context) ([]*entities.Prompt, error) {
 	query, args := dbx.ST.
-		Select("id", "name", "category", "is_builtin", "system_prompt", "user_prompt", "placeholders", "instructions", "context_config", "version", "created_at", "updated_at").
+		Select("id", "name", "category", "is_builtin", "system_prompt", "user_prompt", "placeholders", "instructions", "context_config", "generation_params", "version", "created_at", "updated_at").
 		From("prompts").
 		OrderBy("is_builtin DESC", "created_at DESC").
 		MustSql()
@@ -145,6 +153,7 @@ func (r *repository) GetAll(ctx context.Context) ([]*entities.Prompt, error) {
 		var placeholdersJSON sql.NullString
 		var instructions sql.NullString
 		var contextConfigJSON sql.NullString
+		var generationParamsJSON sql.NullString
 		var version sql.NullInt64
 
 		err = rows.Scan(
@@ -157,6 +166,7 @@ func (r *repository) GetAll(ctx context.Context) ([]*entities.Prompt, error) {
 			&placeholdersJSON,
 			&instructions,
 			&contextConfigJSON,
+			&generationParamsJSON,
 			&version,
 			&prompt.CreatedAt,
 			&prompt.UpdatedAt,
@@ -169,6 +179,7 @@ func (r *repository) GetAll(ctx context.Context) ([]*entities.Prompt, error) {
 		prompt.Placeholders = parsePlaceholders(placeholdersJSON)
 		prompt.Instructions = instructions.String
 		prompt.ContextConfig = parseContextConfig(contextConfigJSON)
+		prompt.GenerationParams = parseGenerationParams(generationParamsJSON)
 		prompt.Version = int(version.Int64)
 
 		prompts = append(prompts, &prompt)
@@ -186,7 +197,7 @@ func (r *repository) GetAll(ctx context.Context) ([]*entities.Prompt, error) {
 
 func (r *repository) GetByCategory(ctx context.Context, category entities.PromptCategory) ([]*entities.Prompt, error) {
 	query, args := dbx.ST.
-		Select("id", "name", "category", "is_builtin", "system_prompt", "user_prompt", "placeholders", "instructions", "context_config", "version", "created_at", "updated_at").
+		Select("id", "name", "category", "is_builtin", "system_prompt", "user_prompt", "placeholders", "instructions", "context_config", "generation_params", "version", "created_at", "updated_at").
 		From("prompts").
 		Where(squirrel.Eq{"category": string(category)}).
 		OrderBy("is_builtin DESC", "created_at DESC").
@@ -207,6 +218,7 @@ func (r *repository) GetByCategory(ctx context.Context, category entities.Prompt
 		var placeholdersJSON sql.NullString
 		var instructions sql.NullString
 		var contextConfigJSON sql.NullString
+		var generationParamsJSON sql.NullString
 		var version sql.NullInt64
 
 		err = rows.Scan(
@@ -219,6 +231,7 @@ func (r *repository) GetByCategory(ctx context.Context, category entities.Prompt
 			&placeholdersJSON,
 			&instructions,
 			&contextConfigJSON,
+			&generationParamsJSON,
 			&version,
 			&prompt.CreatedAt,
 			&prompt.UpdatedAt,
@@ -231,6 +244,7 @@ func (r *repository) GetByCategory(ctx context.Context, category entities.Prompt
 		prompt.Placeholders = parsePlaceholders(placeholdersJSON)
 		prompt.Instructions = instructions.String
 		prompt.ContextConfig = parseContextConfig(contextConfigJSON)
+		prompt.GenerationParams = parseGenerationParams(generationParamsJSON)
 		prompt.Version = int(version.Int64)
 
 		prompts = append(prompts, &prompt)
@@ -257,6 +271,11 @@ func (r *repository) Update(ctx context.Context, prompt *entities.Prompt) error
 		return errors.Validation("Invalid context config format")
 	}
 
+	generationParamsJSON, err := marshalGenerationParams(prompt.GenerationParams)
+	if err != nil {
+		return errors.Validation("Invalid generation params format")
+	}
+
 	category := prompt.Category
 	if category == "" {
 		category = entities.PromptCategoryPostGen
@@ -271,6 +290,7 @@ func (r *repository) Update(ctx context.Context, prompt *entities.Prompt) error
 		Set("placeholders", placeholdersJSON).
 		Set("instructions", prompt.Instructions).
 		Set("context_config", contextConfigJSON).
+		Set("generation_params", generationParamsJSON).
 		Set("version", prompt.Version).
 		Set("updated_at", time.Now()).
 		Where(squirrel.Eq{"id": prompt.ID}).
@@ -359,3 +379,29 @@ func parseContextConfig(configJSON sql.NullString) entities.ContextConfig {
 	}
 	return config
 }
+
+// marshalGenerationParams encodes generation params for storage, empty params are stored as NULL
+func marshalGenerationParams(params *entities.GenerationParams) (any, error) {
+	if params.IsEmpty() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// parseGenerationParams parses JSON generation params from database
+func parseGenerationParams(paramsJSON sql.NullString) *entities.GenerationParams {
+	if !paramsJSON.Valid || strings.TrimSpace(paramsJSON.String) == "" {
+		return nil
+	}
+
+	var params entities.GenerationParams
+	if err := json.Unmarshal([]byte(paramsJSON.String), &params); err != nil {
+		return nil
+	}
+	return &params
+}
@@ -171,6 +171,10 @@ func (s *service) validatePrompt(prompt *entities.Prompt) error {
 		return errors.Validation("Prompt name is required")
 	}
 
+	if err := prompt.GenerationParams.Validate(); err != nil {
+		return err
+	}
+
 	// V2 prompts: require instructions
 	if prompt.IsV2() {
 		if strings.TrimSpace(prompt.Instructions) == "" {
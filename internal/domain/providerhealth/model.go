@@ -0,0 +1,47 @@
+package providerhealth
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+)
+
+// ClientFactory creates a client for the provider bound to a single API key
+type ClientFactory func(provider *entities.Provider, apiKey string) (ai.Client, error)
+
+type Repository interface {
+	SaveCheck(ctx context.Context, check *entities.ProviderHealthCheck) error
+	GetHistoryByProvider(ctx context.Context, providerID int64, limit int) ([]*entities.ProviderHealthCheck, error)
+	// GetLastCheck returns the latest check of the provider's key, nil when it was never checked
+	GetLastCheck(ctx context.Context, providerID int64, keyFingerprint string) (*entities.ProviderHealthCheck, error)
+	// GetLatestChecks returns the latest check of every key of every provider
+	GetLatestChecks(ctx context.Context) ([]*entities.ProviderHealthCheck, error)
+}
+
+// Problem is a provider check that newly found a key or model needing the user's attention
+type Problem struct {
+	Provider *entities.Provider
+	Check    *entities.ProviderHealthCheck
+}
+
+type Service interface {
+	// CheckProvider pings the provider's model once with each of its API keys
+	CheckProvider(ctx context.Context, provider *entities.Provider) ([]*entities.ProviderHealthCheck, error)
+	CheckProviderByID(ctx context.Context, providerID int64) ([]*entities.ProviderHealthCheck, error)
+	// CheckActiveProviders checks every active provider and returns the keys and models
+	// that broke since their previous check
+	CheckActiveProviders(ctx context.Context) ([]*Problem, error)
+	GetProviderHistory(ctx context.Context, providerID int64, limit int) ([]*entities.ProviderHealthCheck, error)
+	GetLatestChecks(ctx context.Context) ([]*entities.ProviderHealthCheck, error)
+}
+
+type Notifier interface {
+	NotifyProblems(ctx context.Context, problems []*Problem, withSound bool) error
+}
+
+type Scheduler interface {
+	Start(ctx context.Context) error
+	Stop() error
+	ApplySettings(ctx context.Context, enabled bool, intervalMinutes int) error
+}
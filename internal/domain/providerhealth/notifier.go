@@ -0,0 +1,99 @@
+package providerhealth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/internal/infra/notification"
+	"github.com/davidmovas/postulator/internal/infra/notifyicon"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+const (
+	problemNotificationTitle = "AI Provider Alert"
+	maxDisplay               = 2
+)
+
+type notifier struct {
+	baseNotifier notification.Notifier
+	logger       *logger.Logger
+}
+
+func NewNotifier(logger *logger.Logger) Notifier {
+	return &notifier{
+		baseNotifier: notification.NewWithConfig("Postulator", notifyicon.Icon()),
+		logger: logger.
+			WithScope("notifier").
+			WithScope("provider_health"),
+	}
+}
+
+func (n *notifier) NotifyProblems(ctx context.Context, problems []*Problem, withSound bool) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	opts := &notification.Options{
+		Title:     problemNotificationTitle,
+		Message:   formatProblems(problems),
+		WithSound: withSound,
+	}
+
+	if err := n.baseNotifier.Notify(ctx, opts); err != nil {
+		n.logger.ErrorWithErr(err, "Failed to send provider problem notification")
+		return err
+	}
+
+	return nil
+}
+
+func formatProblems(problems []*Problem) string {
+	if len(problems) == 1 {
+		return describeProblem(problems[0])
+	}
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("%d Provider Problems\n\n", len(problems)))
+	for i := 0; i < len(problems) && i < maxDisplay; i++ {
+		msg.WriteString(describeProblem(problems[i]))
+		if i < len(problems)-1 && i < maxDisplay-1 {
+			msg.WriteString("\n")
+		}
+	}
+	if len(problems) > maxDisplay {
+		msg.WriteString(fmt.Sprintf("\nand %d more", len(problems)-maxDisplay))
+	}
+	return msg.String()
+}
+
+func describeProblem(problem *Problem) string {
+	name := problem.Provider.Name
+
+	switch problem.Check.Status {
+	case entities.ProviderKeyRevoked:
+		return fmt.Sprintf("%s: %s was rejected", name, keyName(problem))
+	case entities.ProviderOutOfCredit:
+		return fmt.Sprintf("%s: %s is out of credit", name, keyName(problem))
+	case entities.ProviderModelRetired:
+		return fmt.Sprintf("%s: model %s is no longer available", name, problem.Check.Model)
+	default:
+		return fmt.Sprintf("%s: %s", name, problem.Check.Status)
+	}
+}
+
+// keyName names the key checked by its label when it has one
+func keyName(problem *Problem) string {
+	if problem.Check.KeyFingerprint == "" {
+		return "the API key"
+	}
+
+	for _, key := range problem.Provider.Keys {
+		if key.Label != "" && ai.KeyFingerprint(key.APIKey) == problem.Check.KeyFingerprint {
+			return fmt.Sprintf("API key %q", key.Label)
+		}
+	}
+	return "the API key"
+}
@@ -0,0 +1,156 @@
+package providerhealth
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+
+	"github.com/Masterminds/squirrel"
+)
+
+var checkColumns = []string{
+	"id", "provider_id", "key_fingerprint", "model", "checked_at", "status", "latency_ms", "error_message",
+}
+
+type repository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewRepository(db *database.DB, logger *logger.Logger) Repository {
+	return &repository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("provider_health"),
+	}
+}
+
+func (r *repository) SaveCheck(ctx context.Context, check *entities.ProviderHealthCheck) error {
+	query, args := dbx.ST.
+		Insert("provider_health_checks").
+		Columns("provider_id", "key_fingerprint", "model", "checked_at", "status", "latency_ms", "error_message").
+		Values(
+			check.ProviderID,
+			check.KeyFingerprint,
+			check.Model,
+			check.CheckedAt,
+			check.Status,
+			check.LatencyMs,
+			check.ErrorMessage,
+		).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	check.ID = id
+	return nil
+}
+
+func (r *repository) GetHistoryByProvider(ctx context.Context, providerID int64, limit int) ([]*entities.ProviderHealthCheck, error) {
+	query, args := dbx.ST.
+		Select(checkColumns...).
+		From("provider_health_checks").
+		Where(squirrel.Eq{"provider_id": providerID}).
+		OrderBy("checked_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		MustSql()
+
+	return r.queryChecks(ctx, query, args...)
+}
+
+func (r *repository) GetLastCheck(ctx context.Context, providerID int64, keyFingerprint string) (*entities.ProviderHealthCheck, error) {
+	query, args := dbx.ST.
+		Select(checkColumns...).
+		From("provider_health_checks").
+		Where(squirrel.Eq{"provider_id": providerID, "key_fingerprint": keyFingerprint}).
+		OrderBy("id DESC").
+		Limit(1).
+		MustSql()
+
+	check, err := scanCheck(r.db.QueryRowContext(ctx, query, args...))
+	switch {
+	case dbx.IsNoRows(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Database(err)
+	}
+
+	return check, nil
+}
+
+func (r *repository) GetLatestChecks(ctx context.Context) ([]*entities.ProviderHealthCheck, error) {
+	latest := dbx.ST.
+		Select("MAX(id)").
+		From("provider_health_checks").
+		GroupBy("provider_id", "key_fingerprint")
+
+	query, args := dbx.ST.
+		Select(checkColumns...).
+		From("provider_health_checks").
+		Where(squirrel.Expr("id IN (?)", latest)).
+		OrderBy("provider_id", "key_fingerprint").
+		MustSql()
+
+	return r.queryChecks(ctx, query, args...)
+}
+
+func (r *repository) queryChecks(ctx context.Context, query string, args ...any) ([]*entities.ProviderHealthCheck, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var checks []*entities.ProviderHealthCheck
+	for rows.Next() {
+		check, err := scanCheck(rows)
+		if err != nil {
+			return nil, errors.Database(err)
+		}
+		checks = append(checks, check)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return checks, nil
+}
+
+func scanCheck(scanner dbx.RowScanner) (*entities.ProviderHealthCheck, error) {
+	var check entities.ProviderHealthCheck
+	var errorMessage sql.NullString
+
+	err := scanner.Scan(
+		&check.ID,
+		&check.ProviderID,
+		&check.KeyFingerprint,
+		&check.Model,
+		&check.CheckedAt,
+		&check.Status,
+		&check.LatencyMs,
+		&errorMessage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	check.ErrorMessage = errorMessage.String
+	return &check, nil
+}
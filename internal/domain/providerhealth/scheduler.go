@@ -0,0 +1,161 @@
+package providerhealth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	settingsSvc "github.com/davidmovas/postulator/internal/domain/settings"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+// startupDelay lets the app settle before the first check after it starts
+const startupDelay = time.Minute
+
+type scheduler struct {
+	service         Service
+	settingsService settingsSvc.Service
+	notifier        Notifier
+	logger          *logger.Logger
+
+	ticker   *time.Ticker
+	stopChan chan struct{}
+	running  bool
+	ctx      context.Context
+	mu       sync.RWMutex
+}
+
+func NewScheduler(
+	service Service,
+	settingsService settingsSvc.Service,
+	notifier Notifier,
+	logger *logger.Logger,
+) Scheduler {
+	return &scheduler{
+		service:         service,
+		settingsService: settingsService,
+		notifier:        notifier,
+		logger: logger.
+			WithScope("scheduler").
+			WithScope("provider_health"),
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (s *scheduler) Start(ctx context.Context) error {
+	settings, err := s.settingsService.GetProviderHealthSettings(ctx)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get provider health settings")
+		return err
+	}
+
+	// The fx start context ends once the app started, checks run for the app's lifetime
+	return s.ApplySettings(context.Background(), settings.Enabled, settings.IntervalMinutes)
+}
+
+func (s *scheduler) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.logger.Info("Stopping provider health scheduler")
+	s.stopLocked()
+
+	return nil
+}
+
+func (s *scheduler) ApplySettings(ctx context.Context, enabled bool, intervalMinutes int) error {
+	if intervalMinutes <= 0 {
+		intervalMinutes = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ctx != nil && s.ctx == nil {
+		s.ctx = ctx
+	}
+	if s.ctx == nil {
+		s.ctx = context.Background()
+	}
+
+	if !enabled {
+		if s.running {
+			s.logger.Info("Disabling provider health scheduler")
+			s.stopLocked()
+		}
+		return nil
+	}
+
+	interval := time.Duration(intervalMinutes) * time.Minute
+
+	if s.running {
+		s.logger.Infof("Updating provider health interval to: %v", interval)
+		s.ticker.Reset(interval)
+		return nil
+	}
+
+	s.ticker = time.NewTicker(interval)
+	s.stopChan = make(chan struct{})
+	s.running = true
+
+	go s.run(s.ctx, s.ticker, s.stopChan)
+
+	s.logger.Infof("Provider health scheduler started with interval: %v", interval)
+	return nil
+}
+
+func (s *scheduler) stopLocked() {
+	s.running = false
+
+	if s.ticker != nil {
+		s.ticker.Stop()
+		s.ticker = nil
+	}
+
+	close(s.stopChan)
+}
+
+func (s *scheduler) run(ctx context.Context, ticker *time.Ticker, stop chan struct{}) {
+	startup := time.NewTimer(startupDelay)
+	defer startup.Stop()
+
+	for {
+		select {
+		case <-stop:
+			s.logger.Debug("Provider health run loop stopped")
+			return
+
+		case <-startup.C:
+			s.performCheck(ctx)
+
+		case <-ticker.C:
+			s.performCheck(ctx)
+		}
+	}
+}
+
+func (s *scheduler) performCheck(ctx context.Context) {
+	settings, err := s.settingsService.GetProviderHealthSettings(ctx)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get settings during check")
+		return
+	}
+
+	if !settings.Enabled {
+		return
+	}
+
+	problems, err := s.service.CheckActiveProviders(ctx)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to check active providers")
+		return
+	}
+
+	if err = s.notifier.NotifyProblems(ctx, problems, settings.NotifyWithSound); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to send provider problem notification")
+	}
+}
@@ -0,0 +1,211 @@
+package providerhealth
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+// pingTimeout bounds a single key check, a provider slower than that counts as unavailable
+const pingTimeout = 30 * time.Second
+
+var _ Service = (*service)(nil)
+
+type service struct {
+	providerSvc providers.Service
+	aiUsageSvc  aiusage.Service
+	repo        Repository
+	newClient   ClientFactory
+	logger      *logger.Logger
+}
+
+func NewService(
+	providerSvc providers.Service,
+	aiUsageSvc aiusage.Service,
+	repo Repository,
+	newClient ClientFactory,
+	logger *logger.Logger,
+) Service {
+	return &service{
+		providerSvc: providerSvc,
+		aiUsageSvc:  aiUsageSvc,
+		repo:        repo,
+		newClient:   newClient,
+		logger: logger.
+			WithScope("service").
+			WithScope("provider_health"),
+	}
+}
+
+func (s *service) CheckProvider(ctx context.Context, provider *entities.Provider) ([]*entities.ProviderHealthCheck, error) {
+	checks, _, err := s.checkProvider(ctx, provider)
+	return checks, err
+}
+
+func (s *service) CheckProviderByID(ctx context.Context, providerID int64) ([]*entities.ProviderHealthCheck, error) {
+	provider, err := s.providerSvc.GetProvider(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.CheckProvider(ctx, provider)
+}
+
+func (s *service) CheckActiveProviders(ctx context.Context) ([]*Problem, error) {
+	activeProviders, err := s.providerSvc.ListActiveProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []*Problem
+	for _, provider := range activeProviders {
+		_, found, checkErr := s.checkProvider(ctx, provider)
+		if checkErr != nil {
+			s.logger.ErrorWithErr(checkErr, fmt.Sprintf("Health check failed for provider %d (%s)", provider.ID, provider.Name))
+			continue
+		}
+		problems = append(problems, found...)
+	}
+
+	s.logger.Infof("Checked %d providers, %d new problems", len(activeProviders), len(problems))
+	return problems, nil
+}
+
+func (s *service) GetProviderHistory(ctx context.Context, providerID int64, limit int) ([]*entities.ProviderHealthCheck, error) {
+	history, err := s.repo.GetHistoryByProvider(ctx, providerID, limit)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get provider health history")
+		return nil, err
+	}
+	return history, nil
+}
+
+func (s *service) GetLatestChecks(ctx context.Context) ([]*entities.ProviderHealthCheck, error) {
+	checks, err := s.repo.GetLatestChecks(ctx)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get latest provider health checks")
+		return nil, err
+	}
+	return checks, nil
+}
+
+// checkProvider checks and records every key of the provider. The problems are the
+// checks needing action whose key was fine, or broken differently, the time before.
+func (s *service) checkProvider(ctx context.Context, provider *entities.Provider) ([]*entities.ProviderHealthCheck, []*Problem, error) {
+	var checks []*entities.ProviderHealthCheck
+
+	keys := ai.ProviderKeys(provider)
+	switch {
+	case !ai.ValidateModel(provider.Type, provider.Model):
+		// The model left the catalog, requests with it would be rejected before being sent
+		checks = append(checks, s.newCheck(provider, "", entities.ProviderModelRetired,
+			fmt.Sprintf("Model %s is no longer available, choose another model", provider.Model)))
+	case len(keys) == 0 && ai.RequiresAPIKey(provider.Type):
+		checks = append(checks, s.newCheck(provider, "", entities.ProviderKeyRevoked, "No API key configured"))
+	case len(keys) == 0:
+		checks = append(checks, s.pingKey(ctx, provider, ""))
+	default:
+		for _, key := range keys {
+			checks = append(checks, s.pingKey(ctx, provider, key))
+		}
+	}
+
+	var problems []*Problem
+	for _, check := range checks {
+		last, err := s.repo.GetLastCheck(ctx, provider.ID, check.KeyFingerprint)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err = s.repo.SaveCheck(ctx, check); err != nil {
+			s.logger.ErrorWithErr(err, "Failed to save provider health check")
+			return nil, nil, err
+		}
+
+		if check.Status.NeedsAction() && (last == nil || last.Status != check.Status) {
+			problems = append(problems, &Problem{Provider: provider, Check: check})
+		}
+		if check.Status == entities.ProviderHealthy && last != nil && last.Status.NeedsAction() {
+			s.logger.Infof("Provider %s recovered from %s", provider.Name, last.Status)
+		}
+	}
+
+	return checks, problems, nil
+}
+
+// pingKey sends a one-token request with the key, recording its cost like any other call
+func (s *service) pingKey(ctx context.Context, provider *entities.Provider, apiKey string) *entities.ProviderHealthCheck {
+	var fingerprint string
+	if apiKey != "" {
+		fingerprint = ai.KeyFingerprint(apiKey)
+	}
+
+	client, err := s.newClient(provider, apiKey)
+	if err != nil {
+		return s.newCheck(provider, fingerprint, entities.ProviderFailing, err.Error())
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := client.Ping(pingCtx)
+	latency := time.Since(start)
+
+	var usage ai.Usage
+	if result != nil {
+		usage = result.Usage
+	}
+	usage.KeyFingerprint = fingerprint
+	_ = s.aiUsageSvc.LogFromResult(ctx, 0, provider.ID, aiusage.OperationHealthCheck, client, usage, latency.Milliseconds(), err, nil)
+
+	check := s.newCheck(provider, fingerprint, healthOf(err), "")
+	check.LatencyMs = int(latency.Milliseconds())
+	if err != nil {
+		check.ErrorMessage = err.Error()
+	}
+	return check
+}
+
+func (s *service) newCheck(provider *entities.Provider, fingerprint string, status entities.ProviderHealth, message string) *entities.ProviderHealthCheck {
+	return &entities.ProviderHealthCheck{
+		ProviderID:     provider.ID,
+		KeyFingerprint: fingerprint,
+		Model:          provider.Model,
+		CheckedAt:      time.Now(),
+		Status:         status,
+		ErrorMessage:   message,
+	}
+}
+
+// healthOf maps the outcome of a ping onto a health status
+func healthOf(err error) entities.ProviderHealth {
+	if err == nil {
+		return entities.ProviderHealthy
+	}
+
+	switch errors.AICode(err) {
+	case errors.ErrCodeAIAuth:
+		return entities.ProviderKeyRevoked
+	case errors.ErrCodeAIQuotaExceeded:
+		return entities.ProviderOutOfCredit
+	case errors.ErrCodeAIModelNotFound:
+		return entities.ProviderModelRetired
+	case errors.ErrCodeAIRateLimit:
+		return entities.ProviderRateLimited
+	case errors.ErrCodeAIUnavailable:
+		return entities.ProviderUnavailable
+	}
+
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return entities.ProviderUnavailable
+	}
+	return entities.ProviderFailing
+}
@@ -0,0 +1,194 @@
+package providerhealth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/deletion"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+// pingClient answers pings with the error set for its key, nil meaning a healthy key
+type pingClient struct {
+	ai.Client
+	err error
+}
+
+func (c *pingClient) Ping(context.Context) (*ai.PingResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &ai.PingResult{Usage: ai.Usage{InputTokens: 8, OutputTokens: 1, TotalTokens: 9}}, nil
+}
+
+func (c *pingClient) GetProviderName() string { return "openai" }
+
+func (c *pingClient) GetModelName() string { return "gpt-4o-mini" }
+
+type serviceFixture struct {
+	service   Service
+	providers providers.Repository
+	keys      providers.KeyRepository
+	// failures maps API keys onto the error their pings fail with
+	failures map[string]error
+	pings    int
+}
+
+func newServiceFixture(t *testing.T) *serviceFixture {
+	t.Helper()
+
+	db, log := database.SetupTestEnv(t)
+
+	f := &serviceFixture{
+		providers: providers.NewRepository(db, log),
+		keys:      providers.NewKeyRepository(db, log),
+		failures:  make(map[string]error),
+	}
+
+	providerSvc := providers.NewService(f.providers, providers.NewModelRepository(db, log), f.keys, deletion.NewValidator(db), log)
+	factory := func(provider *entities.Provider, apiKey string) (ai.Client, error) {
+		f.pings++
+		return &pingClient{err: f.failures[apiKey]}, nil
+	}
+	f.service = NewService(providerSvc, aiusage.NewService(aiusage.NewRepository(db), log), NewRepository(db, log), factory, log)
+
+	return f
+}
+
+func (f *serviceFixture) createProvider(t *testing.T, name, model string, keys ...string) *entities.Provider {
+	t.Helper()
+	ctx := context.Background()
+
+	provider := &entities.Provider{
+		Name:     name,
+		Type:     entities.TypeOpenAI,
+		APIKey:   keys[0],
+		Model:    model,
+		IsActive: true,
+	}
+	if err := f.providers.Create(ctx, provider); err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	for i, key := range keys[1:] {
+		err := f.keys.Create(ctx, &entities.ProviderKey{
+			ProviderID: provider.ID,
+			Label:      fmt.Sprintf("spare %d", i+1),
+			APIKey:     key,
+			IsActive:   true,
+		})
+		if err != nil {
+			t.Fatalf("failed to create key: %v", err)
+		}
+	}
+
+	return provider
+}
+
+func statuses(checks []*entities.ProviderHealthCheck) map[string]entities.ProviderHealth {
+	result := make(map[string]entities.ProviderHealth)
+	for _, check := range checks {
+		result[check.KeyFingerprint] = check.Status
+	}
+	return result
+}
+
+func TestCheckActiveProvidersReportsNewProblemsOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	f.createProvider(t, "OpenAI", "gpt-4o-mini", "sk-main", "sk-spare")
+
+	f.failures["sk-spare"] = errors.AIAuth("OpenAI", fmt.Errorf("invalid_api_key"))
+
+	problems, err := f.service.CheckActiveProviders(ctx)
+	if err != nil {
+		t.Fatalf("CheckActiveProviders: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Check.Status != entities.ProviderKeyRevoked {
+		t.Fatalf("expected the revoked spare key, got %+v", problems)
+	}
+	if problems[0].Check.KeyFingerprint != ai.KeyFingerprint("sk-spare") {
+		t.Errorf("expected the spare key's fingerprint, got %s", problems[0].Check.KeyFingerprint)
+	}
+	if message := formatProblems(problems); message != `OpenAI: API key "spare 1" was rejected` {
+		t.Errorf("unexpected notification %q", message)
+	}
+
+	if problems, err = f.service.CheckActiveProviders(ctx); err != nil || len(problems) != 0 {
+		t.Fatalf("expected a known problem not to be reported again, got %d, %v", len(problems), err)
+	}
+
+	// The key runs out of credit instead, which needs different action
+	f.failures["sk-spare"] = errors.AIQuotaExceeded("OpenAI", fmt.Errorf("insufficient_quota"))
+	if problems, err = f.service.CheckActiveProviders(ctx); err != nil || len(problems) != 1 || problems[0].Check.Status != entities.ProviderOutOfCredit {
+		t.Fatalf("expected the key out of credit, got %+v, %v", problems, err)
+	}
+
+	latest, err := f.service.GetLatestChecks(ctx)
+	if err != nil {
+		t.Fatalf("GetLatestChecks: %v", err)
+	}
+	got := statuses(latest)
+	if len(got) != 2 || got[ai.KeyFingerprint("sk-main")] != entities.ProviderHealthy || got[ai.KeyFingerprint("sk-spare")] != entities.ProviderOutOfCredit {
+		t.Errorf("unexpected latest checks %v", got)
+	}
+}
+
+func TestCheckProviderClassifiesFailures(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	provider := f.createProvider(t, "OpenAI", "gpt-4o-mini", "sk-limited", "sk-down", "sk-retired")
+
+	f.failures["sk-limited"] = errors.AIRateLimit("OpenAI")
+	f.failures["sk-down"] = errors.AIUnavailable("OpenAI", fmt.Errorf("bad gateway"))
+	f.failures["sk-retired"] = errors.AIModelNotFound("OpenAI", fmt.Errorf("model_not_found"))
+
+	checks, err := f.service.CheckProviderByID(ctx, provider.ID)
+	if err != nil {
+		t.Fatalf("CheckProviderByID: %v", err)
+	}
+
+	expected := map[string]entities.ProviderHealth{
+		ai.KeyFingerprint("sk-limited"): entities.ProviderRateLimited,
+		ai.KeyFingerprint("sk-down"):    entities.ProviderUnavailable,
+		ai.KeyFingerprint("sk-retired"): entities.ProviderModelRetired,
+	}
+	for fingerprint, status := range statuses(checks) {
+		if expected[fingerprint] != status {
+			t.Errorf("key %s: expected %s, got %s", fingerprint, expected[fingerprint], status)
+		}
+	}
+
+	history, err := f.service.GetProviderHistory(ctx, provider.ID, 10)
+	if err != nil || len(history) != 3 {
+		t.Fatalf("expected 3 recorded checks, got %d, %v", len(history), err)
+	}
+	for _, check := range history {
+		if check.ErrorMessage == "" {
+			t.Errorf("expected the error of key %s to be recorded", check.KeyFingerprint)
+		}
+	}
+}
+
+func TestCheckProviderFlagsModelsLeavingTheCatalog(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	f.createProvider(t, "Legacy", "gpt-3.5-turbo-0301", "sk-main")
+
+	problems, err := f.service.CheckActiveProviders(ctx)
+	if err != nil {
+		t.Fatalf("CheckActiveProviders: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Check.Status != entities.ProviderModelRetired {
+		t.Fatalf("expected the retired model, got %+v", problems)
+	}
+	if f.pings != 0 {
+		t.Errorf("expected no request with a model the app no longer knows, got %d", f.pings)
+	}
+}
@@ -0,0 +1,181 @@
+package providers
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+
+	"github.com/Masterminds/squirrel"
+)
+
+var _ KeyRepository = (*keyRepository)(nil)
+
+var keyColumns = []string{
+	"id",
+	"provider_id",
+	"label",
+	"api_key",
+	"is_active",
+	"created_at",
+	"updated_at",
+}
+
+type keyRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewKeyRepository(db *database.DB, logger *logger.Logger) KeyRepository {
+	return &keyRepository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("ai_provider_keys"),
+	}
+}
+
+func (r *keyRepository) Create(ctx context.Context, key *entities.ProviderKey) error {
+	query, args := dbx.ST.
+		Insert("ai_provider_keys").
+		Columns("provider_id", "label", "api_key", "is_active", "created_at", "updated_at").
+		Values(key.ProviderID, key.Label, key.APIKey, key.IsActive, key.CreatedAt, key.UpdatedAt).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	switch {
+	case dbx.IsUniqueViolation(err):
+		return errors.AlreadyExists("API key")
+	case err != nil:
+		return errors.Database(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	key.ID = id
+	return nil
+}
+
+func (r *keyRepository) GetByID(ctx context.Context, id int64) (*entities.ProviderKey, error) {
+	query, args := dbx.ST.
+		Select(keyColumns...).
+		From("ai_provider_keys").
+		Where(squirrel.Eq{"id": id}).
+		MustSql()
+
+	var key entities.ProviderKey
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&key.ID,
+		&key.ProviderID,
+		&key.Label,
+		&key.APIKey,
+		&key.IsActive,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return nil, errors.NotFound("API key", id)
+	case err != nil:
+		return nil, errors.Database(err)
+	}
+
+	return &key, nil
+}
+
+func (r *keyRepository) GetByProvider(ctx context.Context, providerID int64) ([]*entities.ProviderKey, error) {
+	query, args := dbx.ST.
+		Select(keyColumns...).
+		From("ai_provider_keys").
+		Where(squirrel.Eq{"provider_id": providerID}).
+		OrderBy("id ASC").
+		MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var keys []*entities.ProviderKey
+	for rows.Next() {
+		var key entities.ProviderKey
+		err = rows.Scan(
+			&key.ID,
+			&key.ProviderID,
+			&key.Label,
+			&key.APIKey,
+			&key.IsActive,
+			&key.CreatedAt,
+			&key.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.Database(err)
+		}
+		keys = append(keys, &key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return keys, nil
+}
+
+func (r *keyRepository) Update(ctx context.Context, key *entities.ProviderKey) error {
+	query, args := dbx.ST.
+		Update("ai_provider_keys").
+		Set("label", key.Label).
+		Set("is_active", key.IsActive).
+		Set("updated_at", key.UpdatedAt).
+		Where(squirrel.Eq{"id": key.ID}).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("API key", key.ID)
+	}
+
+	return nil
+}
+
+func (r *keyRepository) Delete(ctx context.Context, id int64) error {
+	query, args := dbx.ST.
+		Delete("ai_provider_keys").
+		Where(squirrel.Eq{"id": id}).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("API key", id)
+	}
+
+	return nil
+}
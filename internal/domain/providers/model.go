@@ -15,6 +15,22 @@ type Repository interface {
 	Delete(ctx context.Context, id int64) error
 }
 
+// ModelRepository stores user-defined model catalog entries keyed by provider and model ID
+type ModelRepository interface {
+	GetAll(ctx context.Context) ([]*entities.Model, error)
+	Upsert(ctx context.Context, model *entities.Model) error
+	Delete(ctx context.Context, providerType entities.Type, modelID string) error
+}
+
+// KeyRepository stores the additional API keys of providers
+type KeyRepository interface {
+	Create(ctx context.Context, key *entities.ProviderKey) error
+	GetByID(ctx context.Context, id int64) (*entities.ProviderKey, error)
+	GetByProvider(ctx context.Context, providerID int64) ([]*entities.ProviderKey, error)
+	Update(ctx context.Context, key *entities.ProviderKey) error
+	Delete(ctx context.Context, id int64) error
+}
+
 type Service interface {
 	CreateProvider(ctx context.Context, provider *entities.Provider) error
 	GetProvider(ctx context.Context, id int64) (*entities.Provider, error)
@@ -24,6 +40,20 @@ type Service interface {
 	DeleteProvider(ctx context.Context, id int64) error
 	SetProviderStatus(ctx context.Context, id int64, isActive bool) error
 
+	// AddProviderKey adds an API key to the provider's rotation
+	AddProviderKey(ctx context.Context, key *entities.ProviderKey) error
+	ListProviderKeys(ctx context.Context, providerID int64) ([]*entities.ProviderKey, error)
+	// SetProviderKeyStatus enables or disables a key, enabling it also ends its cool-down
+	SetProviderKeyStatus(ctx context.Context, id int64, isActive bool) error
+	DeleteProviderKey(ctx context.Context, id int64) error
+
 	GetAvailableModels(providerType entities.Type) ([]*entities.Model, error)
 	ValidateModel(providerType entities.Type, model string) error
+
+	// SaveModel adds a model to the catalog or overrides a built-in one
+	SaveModel(ctx context.Context, model *entities.Model) error
+	// DeleteModel removes a user-defined model, restoring the built-in entry if there is one
+	DeleteModel(ctx context.Context, providerType entities.Type, modelID string) error
+	// LoadModelCatalog applies the stored user-defined models to the effective catalog
+	LoadModelCatalog(ctx context.Context) error
 }
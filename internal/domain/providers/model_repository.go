@@ -0,0 +1,150 @@
+package providers
+
+import (
+	"context"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/pkg/dbx"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+
+	"github.com/Masterminds/squirrel"
+)
+
+var _ ModelRepository = (*modelRepository)(nil)
+
+type modelRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewModelRepository(db *database.DB, logger *logger.Logger) ModelRepository {
+	return &modelRepository{
+		db: db,
+		logger: logger.
+			WithScope("repository").
+			WithScope("ai_models"),
+	}
+}
+
+func (r *modelRepository) GetAll(ctx context.Context) ([]*entities.Model, error) {
+	query, args := dbx.ST.
+		Select(
+			"provider",
+			"model_id",
+			"name",
+			"context_window",
+			"max_output_tokens",
+			"input_cost",
+			"output_cost",
+			"rpm",
+			"tpm",
+			"uses_completion_tokens",
+			"is_reasoning_model",
+		).
+		From("ai_models").
+		OrderBy("id ASC").
+		MustSql()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Database(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var models []*entities.Model
+	for rows.Next() {
+		var model entities.Model
+		err = rows.Scan(
+			&model.Provider,
+			&model.ID,
+			&model.Name,
+			&model.ContextWindow,
+			&model.MaxOutputTokens,
+			&model.InputCost,
+			&model.OutputCost,
+			&model.RPM,
+			&model.TPM,
+			&model.UsesCompletionTokens,
+			&model.IsReasoningModel,
+		)
+		if err != nil {
+			return nil, errors.Database(err)
+		}
+		models = append(models, &model)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Database(err)
+	}
+
+	return models, nil
+}
+
+func (r *modelRepository) Upsert(ctx context.Context, model *entities.Model) error {
+	query, args := dbx.ST.
+		Insert("ai_models").
+		Columns(
+			"provider",
+			"model_id",
+			"name",
+			"context_window",
+			"max_output_tokens",
+			"input_cost",
+			"output_cost",
+			"rpm",
+			"tpm",
+			"uses_completion_tokens",
+			"is_reasoning_model",
+			"updated_at",
+		).
+		Values(
+			model.Provider,
+			model.ID,
+			model.Name,
+			model.ContextWindow,
+			model.MaxOutputTokens,
+			model.InputCost,
+			model.OutputCost,
+			model.RPM,
+			model.TPM,
+			model.UsesCompletionTokens,
+			model.IsReasoningModel,
+			time.Now(),
+		).
+		Suffix("ON CONFLICT(provider, model_id) DO UPDATE SET name = EXCLUDED.name, context_window = EXCLUDED.context_window, max_output_tokens = EXCLUDED.max_output_tokens, input_cost = EXCLUDED.input_cost, output_cost = EXCLUDED.output_cost, rpm = EXCLUDED.rpm, tpm = EXCLUDED.tpm, uses_completion_tokens = EXCLUDED.uses_completion_tokens, is_reasoning_model = EXCLUDED.is_reasoning_model, updated_at = EXCLUDED.updated_at").
+		MustSql()
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return errors.Database(err)
+	}
+
+	return nil
+}
+
+func (r *modelRepository) Delete(ctx context.Context, providerType entities.Type, modelID string) error {
+	query, args := dbx.ST.
+		Delete("ai_models").
+		Where(squirrel.Eq{"provider": providerType, "model_id": modelID}).
+		MustSql()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Database(err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("model", modelID)
+	}
+
+	return nil
+}
@@ -32,8 +32,8 @@ func NewRepository(db *database.DB, logger *logger.Logger) Repository {
 func (r *repository) Create(ctx context.Context, provider *entities.Provider) error {
 	query, args := dbx.ST.
 		Insert("ai_providers").
-		Columns("name", "provider", "model", "api_key", "is_active").
-		Values(provider.Name, provider.Type, provider.Model, provider.APIKey, provider.IsActive).
+		Columns("name", "provider", "model", "api_key", "base_url", "embedding_model", "is_active").
+		Values(provider.Name, provider.Type, provider.Model, provider.APIKey, provider.BaseURL, provider.EmbeddingModel, provider.IsActive).
 		MustSql()
 
 	result, err := r.db.ExecContext(ctx, query, args...)
@@ -55,7 +55,7 @@ func (r *repository) Create(ctx context.Context, provider *entities.Provider) er
 
 func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Provider, error) {
 	query, args := dbx.ST.
-		Select("id", "name", "provider", "model", "api_key", "is_active", "created_at", "updated_at").
+		Select("id", "name", "provider", "model", "api_key", "base_url", "embedding_model", "is_active", "created_at", "updated_at").
 		From("ai_providers").
 		Where(squirrel.Eq{"id": id}).
 		MustSql()
@@ -67,6 +67,8 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*entities.Provider,
 		&provider.Type,
 		&provider.Model,
 		&provider.APIKey,
+		&provider.BaseURL,
+		&provider.EmbeddingModel,
 		&provider.IsActive,
 		&provider.CreatedAt,
 		&provider.UpdatedAt,
@@ -90,6 +92,8 @@ func (r *repository) GetAll(ctx context.Context) ([]*entities.Provider, error) {
 			"provider",
 			"model",
 			"api_key",
+			"base_url",
+			"embedding_model",
 			"is_active",
 			"created_at",
 			"updated_at",
@@ -115,6 +119,8 @@ func (r *repository) GetAll(ctx context.Context) ([]*entities.Provider, error) {
 			&provider.Type,
 			&provider.Model,
 			&provider.APIKey,
+			&provider.BaseURL,
+			&provider.EmbeddingModel,
 			&provider.IsActive,
 			&provider.CreatedAt,
 			&provider.UpdatedAt,
@@ -143,6 +149,8 @@ func (r *repository) GetActive(ctx context.Context) ([]*entities.Provider, error
 			"provider",
 			"model",
 			"api_key",
+			"base_url",
+			"embedding_model",
 			"is_active",
 			"created_at",
 			"updated_at",
@@ -169,6 +177,8 @@ func (r *repository) GetActive(ctx context.Context) ([]*entities.Provider, error
 			&provider.Type,
 			&provider.Model,
 			&provider.APIKey,
+			&provider.BaseURL,
+			&provider.EmbeddingModel,
 			&provider.IsActive,
 			&provider.CreatedAt,
 			&provider.UpdatedAt,
@@ -196,6 +206,8 @@ func (r *repository) Update(ctx context.Context, provider *entities.Provider) er
 		Set("provider", provider.Type).
 		Set("model", provider.Model).
 		Set("api_key", provider.APIKey).
+		Set("base_url", provider.BaseURL).
+		Set("embedding_model", provider.EmbeddingModel).
 		Set("is_active", provider.IsActive).
 		Set("updated_at", time.Now()).
 		Where(squirrel.Eq{"id": provider.ID}).
@@ -14,15 +14,27 @@ import (
 
 var _ Service = (*service)(nil)
 
+var validTypes = map[entities.Type]bool{
+	entities.TypeOpenAI:           true,
+	entities.TypeAnthropic:        true,
+	entities.TypeGoogle:           true,
+	entities.TypeOpenAICompatible: true,
+	entities.TypeMock:             true,
+}
+
 type service struct {
 	repo              Repository
+	modelRepo         ModelRepository
+	keyRepo           KeyRepository
 	deletionValidator *deletion.Validator
 	logger            *logger.Logger
 }
 
-func NewService(repo Repository, deletionValidator *deletion.Validator, logger *logger.Logger) Service {
+func NewService(repo Repository, modelRepo ModelRepository, keyRepo KeyRepository, deletionValidator *deletion.Validator, logger *logger.Logger) Service {
 	return &service{
 		repo:              repo,
+		modelRepo:         modelRepo,
+		keyRepo:           keyRepo,
 		deletionValidator: deletionValidator,
 		logger: logger.
 			WithScope("service").
@@ -55,6 +67,10 @@ func (s *service) GetProvider(ctx context.Context, id int64) (*entities.Provider
 		return nil, err
 	}
 
+	if err = s.loadKeys(ctx, provider); err != nil {
+		return nil, err
+	}
+
 	s.logger.Debug("Provider retrieved")
 	return provider, nil
 }
@@ -66,6 +82,10 @@ func (s *service) ListProviders(ctx context.Context) ([]*entities.Provider, erro
 		return nil, err
 	}
 
+	if err = s.loadKeys(ctx, providers...); err != nil {
+		return nil, err
+	}
+
 	s.logger.Debug("Providers listed")
 	return providers, nil
 }
@@ -77,6 +97,10 @@ func (s *service) ListActiveProviders(ctx context.Context) ([]*entities.Provider
 		return nil, err
 	}
 
+	if err = s.loadKeys(ctx, providers...); err != nil {
+		return nil, err
+	}
+
 	s.logger.Debug("Active providers listed")
 	return providers, nil
 }
@@ -158,9 +182,100 @@ func (s *service) SetProviderStatus(ctx context.Context, id int64, isActive bool
 	return nil
 }
 
+func (s *service) AddProviderKey(ctx context.Context, key *entities.ProviderKey) error {
+	key.APIKey = strings.TrimSpace(key.APIKey)
+	key.Label = strings.TrimSpace(key.Label)
+	if key.APIKey == "" {
+		return errors.Validation("API key is required")
+	}
+
+	provider, err := s.repo.GetByID(ctx, key.ProviderID)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get provider for key")
+		return err
+	}
+
+	if !ai.RequiresAPIKey(provider.Type) {
+		return errors.Validation("Provider does not use API keys")
+	}
+	if key.APIKey == provider.APIKey {
+		return errors.AlreadyExists("API key")
+	}
+
+	now := time.Now()
+	key.IsActive = true
+	key.CreatedAt = now
+	key.UpdatedAt = now
+
+	if err = s.keyRepo.Create(ctx, key); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to add provider key")
+		return err
+	}
+
+	s.logger.Infof("API key %s added to provider %d", ai.KeyFingerprint(key.APIKey), provider.ID)
+	return nil
+}
+
+func (s *service) ListProviderKeys(ctx context.Context, providerID int64) ([]*entities.ProviderKey, error) {
+	keys, err := s.keyRepo.GetByProvider(ctx, providerID)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to list provider keys")
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *service) SetProviderKeyStatus(ctx context.Context, id int64, isActive bool) error {
+	key, err := s.keyRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to get provider key for status update")
+		return err
+	}
+
+	key.IsActive = isActive
+	key.UpdatedAt = time.Now()
+
+	if err = s.keyRepo.Update(ctx, key); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to update provider key status")
+		return err
+	}
+
+	if isActive {
+		ai.DefaultKeyPool().Reset(ai.KeyFingerprint(key.APIKey))
+	}
+
+	s.logger.Infof("API key %s of provider %d active: %t", ai.KeyFingerprint(key.APIKey), key.ProviderID, isActive)
+	return nil
+}
+
+func (s *service) DeleteProviderKey(ctx context.Context, id int64) error {
+	if err := s.keyRepo.Delete(ctx, id); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to delete provider key")
+		return err
+	}
+
+	s.logger.Info("Provider key deleted successfully")
+	return nil
+}
+
+// loadKeys attaches the key pool to each provider
+func (s *service) loadKeys(ctx context.Context, providers ...*entities.Provider) error {
+	for _, provider := range providers {
+		keys, err := s.keyRepo.GetByProvider(ctx, provider.ID)
+		if err != nil {
+			s.logger.ErrorWithErr(err, "Failed to get provider keys")
+			return err
+		}
+		provider.Keys = keys
+	}
+
+	return nil
+}
+
 func (s *service) GetAvailableModels(providerType entities.Type) ([]*entities.Model, error) {
 	models := ai.GetAvailableModels(providerType)
-	if len(models) == 0 {
+	if len(models) == 0 && !ai.SupportsCustomModels(providerType) {
 		return nil, errors.Validation("Unsupported provider type")
 	}
 
@@ -169,6 +284,13 @@ func (s *service) GetAvailableModels(providerType entities.Type) ([]*entities.Mo
 }
 
 func (s *service) ValidateModel(providerType entities.Type, model string) error {
+	if ai.SupportsCustomModels(providerType) {
+		if !ai.ValidateModel(providerType, model) {
+			return errors.Validation("Model is required")
+		}
+		return nil
+	}
+
 	availableModels, err := s.GetAvailableModels(providerType)
 	if err != nil {
 		return err
@@ -185,6 +307,78 @@ func (s *service) ValidateModel(providerType entities.Type, model string) error
 	return errors.Validation("Unsupported model for provider type")
 }
 
+func (s *service) SaveModel(ctx context.Context, model *entities.Model) error {
+	if err := validateModel(model); err != nil {
+		return err
+	}
+
+	if err := s.modelRepo.Upsert(ctx, model); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to save model")
+		return err
+	}
+
+	if err := s.LoadModelCatalog(ctx); err != nil {
+		return err
+	}
+
+	s.logger.Infof("Model saved provider: %s model: %s", model.Provider, model.ID)
+	return nil
+}
+
+func (s *service) DeleteModel(ctx context.Context, providerType entities.Type, modelID string) error {
+	if err := s.modelRepo.Delete(ctx, providerType, modelID); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to delete model")
+		return err
+	}
+
+	if err := s.LoadModelCatalog(ctx); err != nil {
+		return err
+	}
+
+	s.logger.Infof("Model deleted provider: %s model: %s", providerType, modelID)
+	return nil
+}
+
+func (s *service) LoadModelCatalog(ctx context.Context) error {
+	models, err := s.modelRepo.GetAll(ctx)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to load model catalog")
+		return err
+	}
+
+	ai.SetUserModels(models)
+
+	s.logger.Debugf("Model catalog loaded user models: %d", len(models))
+	return nil
+}
+
+func validateModel(model *entities.Model) error {
+	model.ID = strings.TrimSpace(model.ID)
+	model.Name = strings.TrimSpace(model.Name)
+
+	if model.ID == "" {
+		return errors.Validation("Model ID is required")
+	}
+	if !validTypes[model.Provider] {
+		return errors.Validation("Unsupported provider type")
+	}
+	if model.Name == "" {
+		model.Name = model.ID
+	}
+
+	if model.ContextWindow < 0 || model.MaxOutputTokens < 0 {
+		return errors.Validation("Token limits must not be negative")
+	}
+	if model.InputCost < 0 || model.OutputCost < 0 {
+		return errors.Validation("Prices must not be negative")
+	}
+	if model.RPM < 0 || model.TPM < 0 {
+		return errors.Validation("Rate limits must not be negative")
+	}
+
+	return nil
+}
+
 func (s *service) validateProvider(provider *entities.Provider) error {
 	if strings.TrimSpace(provider.Name) == "" {
 		return errors.Validation("Provider name is required")
@@ -194,19 +388,25 @@ func (s *service) validateProvider(provider *entities.Provider) error {
 		return errors.Validation("Provider type is required")
 	}
 
-	validTypes := map[entities.Type]bool{
-		entities.TypeOpenAI:    true,
-		entities.TypeAnthropic: true,
-		entities.TypeGoogle:    true,
-	}
-
 	if !validTypes[provider.Type] {
 		return errors.Validation("Unsupported provider type")
 	}
 
+	if provider.Type == entities.TypeOpenAICompatible && strings.TrimSpace(provider.BaseURL) == "" {
+		return errors.Validation("Base URL is required for OpenAI-compatible provider")
+	}
+
+	if provider.Type == entities.TypeMock && provider.Model == ai.MockModelReplay && strings.TrimSpace(provider.BaseURL) == "" {
+		return errors.Validation("Fixtures directory is required for replay mode")
+	}
+
 	if strings.TrimSpace(provider.Model) == "" {
 		return errors.Validation("Model is required")
 	}
+
+	if strings.TrimSpace(provider.EmbeddingModel) != "" && !ai.SupportsEmbeddings(provider.Type) {
+		return errors.Validation("Provider does not support embeddings")
+	}
 	if err := s.ValidateModel(provider.Type, provider.Model); err != nil {
 		return err
 	}
@@ -20,6 +20,10 @@ type Service interface {
 	UpdateProxySettings(ctx context.Context, settings *entities.ProxySettings) error
 	GetDashboardSettings(ctx context.Context) (*entities.DashboardSettings, error)
 	UpdateDashboardSettings(ctx context.Context, settings *entities.DashboardSettings) error
+	GetProviderHealthSettings(ctx context.Context) (*entities.ProviderHealthSettings, error)
+	UpdateProviderHealthSettings(ctx context.Context, settings *entities.ProviderHealthSettings) error
+	GetSchedulerSettings(ctx context.Context) (*entities.SchedulerSettings, error)
+	UpdateSchedulerSettings(ctx context.Context, settings *entities.SchedulerSettings) error
 }
 
 type HealthCheckScheduler interface {
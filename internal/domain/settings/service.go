@@ -150,3 +150,87 @@ func (s *service) UpdateDashboardSettings(ctx context.Context, settings *entitie
 	s.logger.Info("Dashboard settings updated successfully")
 	return nil
 }
+
+func (s *service) GetProviderHealthSettings(ctx context.Context) (*entities.ProviderHealthSettings, error) {
+	value, err := s.repo.Get(ctx, entities.SettingsKeyProviderHealth)
+	if err != nil {
+		var appErr *appErrors.AppError
+		if errors.As(err, &appErr) && appErr.Code == appErrors.ErrCodeNotFound {
+			s.logger.Info("Provider health settings not found, returning defaults")
+			return entities.DefaultProviderHealthSettings(), nil
+		}
+		s.logger.ErrorWithErr(err, "Failed to get provider health settings")
+		return nil, err
+	}
+
+	var settings entities.ProviderHealthSettings
+	if err = json.Unmarshal([]byte(value), &settings); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to unmarshal provider health settings")
+		return nil, appErrors.Internal(err)
+	}
+
+	return &settings, nil
+}
+
+func (s *service) UpdateProviderHealthSettings(ctx context.Context, settings *entities.ProviderHealthSettings) error {
+	if err := settings.Validate(); err != nil {
+		s.logger.ErrorWithErr(err, "Invalid provider health settings")
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to marshal provider health settings")
+		return appErrors.Internal(err)
+	}
+
+	if err = s.repo.Set(ctx, entities.SettingsKeyProviderHealth, string(data)); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to save provider health settings")
+		return err
+	}
+
+	s.logger.Info("Provider health settings updated successfully")
+	return nil
+}
+
+func (s *service) GetSchedulerSettings(ctx context.Context) (*entities.SchedulerSettings, error) {
+	value, err := s.repo.Get(ctx, entities.SettingsKeyScheduler)
+	if err != nil {
+		var appErr *appErrors.AppError
+		if errors.As(err, &appErr) && appErr.Code == appErrors.ErrCodeNotFound {
+			s.logger.Info("Scheduler settings not found, returning defaults")
+			return entities.DefaultSchedulerSettings(), nil
+		}
+		s.logger.ErrorWithErr(err, "Failed to get scheduler settings")
+		return nil, err
+	}
+
+	var settings entities.SchedulerSettings
+	if err = json.Unmarshal([]byte(value), &settings); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to unmarshal scheduler settings")
+		return nil, appErrors.Internal(err)
+	}
+
+	return &settings, nil
+}
+
+func (s *service) UpdateSchedulerSettings(ctx context.Context, settings *entities.SchedulerSettings) error {
+	if err := settings.Validate(); err != nil {
+		s.logger.ErrorWithErr(err, "Invalid scheduler settings")
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		s.logger.ErrorWithErr(err, "Failed to marshal scheduler settings")
+		return appErrors.Internal(err)
+	}
+
+	if err = s.repo.Set(ctx, entities.SettingsKeyScheduler, string(data)); err != nil {
+		s.logger.ErrorWithErr(err, "Failed to save scheduler settings")
+		return err
+	}
+
+	s.logger.Info("Scheduler settings updated successfully")
+	return nil
+}
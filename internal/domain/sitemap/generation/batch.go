@@ -0,0 +1,198 @@
+package generation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/davidmovas/postulator/internal/domain/batches"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/pkg/errors"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+// batchSettings are the options of a sitemap batch needed to publish its pages
+type batchSettings struct {
+	PublishAs PublishAs `json:"publishAs"`
+	// LinkIDs are the approved links embedded in the prompt of each node,
+	// they are marked as applied once the node is published
+	LinkIDs map[int64][]int64 `json:"linkIds,omitempty"`
+}
+
+// batcher generates the pages of a sitemap through the provider's batch API and
+// publishes them once the batch is finished
+type batcher struct {
+	executor *Executor
+	batchSvc batches.Service
+	logger   *logger.Logger
+}
+
+func newBatcher(executor *Executor, batchSvc batches.Service, logger *logger.Logger) *batcher {
+	b := &batcher{
+		executor: executor,
+		batchSvc: batchSvc,
+		logger:   logger.WithScope("page_batcher"),
+	}
+
+	batchSvc.RegisterHandler(entities.BatchSourceSitemap, b.publishResults)
+	return b
+}
+
+// Submit renders the prompt of every node the task would generate and submits them
+// as one batch. Fallback providers and automatic linking don't apply to batches.
+func (b *batcher) Submit(ctx context.Context, config GenerationConfig) (*entities.Batch, error) {
+	nodes, err := b.executor.prepareNodes(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return nil, errors.Validation("No pages to generate")
+	}
+
+	words, err := b.executor.generator.estimateWords(ctx, config.PromptID, config.ContentSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := batchSettings{
+		PublishAs: config.PublishAs,
+		LinkIDs:   make(map[int64][]int64),
+	}
+
+	items := make([]*batches.SubmitItem, 0, len(nodes))
+	for _, taskNode := range nodes {
+		req, err := b.executor.generateRequest(ctx, config, taskNode.NodeID)
+		if err != nil {
+			return nil, err
+		}
+
+		systemPrompt, userPrompt, params, err := b.executor.generator.articleRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, &batches.SubmitItem{
+			EntityID:     taskNode.NodeID,
+			SystemPrompt: systemPrompt,
+			UserPrompt:   userPrompt,
+			Options:      ai.NewGenerateArticleOptions(params),
+			Words:        words,
+		})
+
+		for _, target := range req.LinkTargets {
+			if target.LinkID > 0 {
+				settings.LinkIDs[taskNode.NodeID] = append(settings.LinkIDs[taskNode.NodeID], target.LinkID)
+			}
+		}
+	}
+
+	batch, err := b.batchSvc.Submit(ctx, &batches.SubmitRequest{
+		SiteID:     config.SiteID,
+		ProviderID: config.ProviderID,
+		Source:     entities.BatchSourceSitemap,
+		SourceID:   config.SitemapID,
+		Settings:   settings,
+		Items:      items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, taskNode := range nodes {
+		if err = b.executor.sitemapSvc.UpdateNodeGenerationStatus(ctx, taskNode.NodeID, entities.GenStatusQueued, nil); err != nil {
+			b.logger.ErrorWithErr(err, "Failed to update node status to queued")
+		}
+	}
+
+	return batch, nil
+}
+
+// publishResults publishes the generated pages of a finished batch. Parents are
+// published before their children, so the children are nested under them.
+func (b *batcher) publishResults(ctx context.Context, batch *entities.Batch, results []*batches.ItemResult) {
+	var settings batchSettings
+	if err := json.Unmarshal([]byte(batch.Settings), &settings); err != nil {
+		b.logger.ErrorWithErr(err, fmt.Sprintf("Invalid settings of batch %d, publishing as drafts", batch.ID))
+	}
+
+	type nodeResult struct {
+		node   *entities.SitemapNode
+		result *batches.ItemResult
+	}
+
+	nodeResults := make([]nodeResult, 0, len(results))
+	for _, result := range results {
+		node, err := b.executor.sitemapSvc.GetNode(ctx, result.Item.EntityID)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to get node: %w", err)
+			continue
+		}
+		nodeResults = append(nodeResults, nodeResult{node: node, result: result})
+	}
+
+	sort.Slice(nodeResults, func(i, j int) bool {
+		if nodeResults[i].node.Depth != nodeResults[j].node.Depth {
+			return nodeResults[i].node.Depth < nodeResults[j].node.Depth
+		}
+		return nodeResults[i].node.ID < nodeResults[j].node.ID
+	})
+
+	for _, nr := range nodeResults {
+		if err := b.publishNode(ctx, batch, settings, nr.node, nr.result); err != nil {
+			nr.result.Err = err
+			b.logger.Errorf("Node %d (%s) of batch %d failed: %v", nr.node.ID, nr.node.Title, batch.ID, err)
+		}
+	}
+}
+
+func (b *batcher) publishNode(ctx context.Context, batch *entities.Batch, settings batchSettings, node *entities.SitemapNode, result *batches.ItemResult) error {
+	sitemapSvc := b.executor.sitemapSvc
+
+	if result.Err != nil {
+		errStr := result.Err.Error()
+		_ = sitemapSvc.UpdateNodeGenerationStatus(ctx, node.ID, entities.GenStatusFailed, &errStr)
+		return result.Err
+	}
+
+	if node.GenerationStatus == entities.GenStatusGenerated && node.WPPageID != nil {
+		return fmt.Errorf("page was generated while the batch was running")
+	}
+
+	// The parent is read again as it may have been published from this batch
+	var parentWPPageID *int
+	if node.ParentID != nil {
+		if parent, err := sitemapSvc.GetNode(ctx, *node.ParentID); err == nil {
+			parentWPPageID = parent.WPPageID
+		}
+	}
+
+	if err := sitemapSvc.UpdateNodePublishStatus(ctx, node.ID, entities.PubStatusPublishing, nil); err != nil {
+		b.logger.ErrorWithErr(err, "Failed to update node status to publishing")
+	}
+
+	pubResult, err := b.executor.publisher.Publish(ctx, PublishRequest{
+		Node:           node,
+		Content:        pageContent(result.Article),
+		SiteID:         batch.SiteID,
+		PublishAs:      settings.PublishAs,
+		ParentWPPageID: parentWPPageID,
+	})
+	if err != nil {
+		errStr := err.Error()
+		_ = sitemapSvc.UpdateNodePublishStatus(ctx, node.ID, entities.PubStatusFailed, &errStr)
+		return fmt.Errorf("publish failed: %w", err)
+	}
+
+	result.Item.ArticleID = &pubResult.ArticleID
+
+	var linkTargets []LinkTarget
+	for _, linkID := range settings.LinkIDs[node.ID] {
+		linkTargets = append(linkTargets, LinkTarget{LinkID: linkID})
+	}
+	b.executor.markLinksAsApplied(ctx, linkTargets)
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package generation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+func TestBatchPublishesSitemapPages(t *testing.T) {
+	f := newExecutorFixture(t)
+	ctx := context.Background()
+	providerID := f.createProvider(t, ai.MockModelSynthetic, "")
+
+	batch, err := f.batcher.Submit(ctx, GenerationConfig{
+		SitemapID:  f.sitemapID,
+		SiteID:     f.siteID,
+		ProviderID: providerID,
+		PublishAs:  PublishAsDraft,
+	})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if batch.TotalItems != 4 || batch.Status != entities.BatchStatusSubmitted {
+		t.Fatalf("expected a submitted batch of 4 pages, got %+v", batch)
+	}
+
+	node, err := f.sitemapSvc.GetNode(ctx, f.children[0])
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if node.GenerationStatus != entities.GenStatusQueued {
+		t.Errorf("expected node to be queued for the batch, got %s", node.GenerationStatus)
+	}
+
+	if err = f.batchSvc.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	batch, err = f.batchSvc.GetBatch(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatch: %v", err)
+	}
+	if batch.Status != entities.BatchStatusCompleted || batch.CompletedItems != 4 || batch.FailedItems != 0 {
+		t.Fatalf("expected all pages to complete, got %+v", batch)
+	}
+	if f.wp.Count() != 4 {
+		t.Fatalf("expected 4 published pages, got %d", f.wp.Count())
+	}
+
+	// Parents are published first, so every child is nested under its parent page
+	for i, childID := range f.children {
+		child, _ := f.sitemapSvc.GetNode(ctx, childID)
+		parent, _ := f.sitemapSvc.GetNode(ctx, f.parents[i])
+		if child.WPPageID == nil || parent.WPPageID == nil {
+			t.Fatalf("expected pages for section %d", i+1)
+		}
+		if child.GenerationStatus != entities.GenStatusGenerated {
+			t.Errorf("expected child %d to be generated, got %s", childID, child.GenerationStatus)
+		}
+		if page := f.wp.Page(*child.WPPageID); page.ParentID != *parent.WPPageID {
+			t.Errorf("expected child page under parent %d, got %d", *parent.WPPageID, page.ParentID)
+		}
+	}
+
+	items, err := f.batchSvc.GetBatchItems(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatchItems: %v", err)
+	}
+	for _, item := range items {
+		if item.Status != entities.BatchItemStatusCompleted || item.ArticleID == nil {
+			t.Errorf("expected item %s to be completed with an article, got %+v", item.CustomID, item)
+		}
+	}
+
+	// A finished batch is not published twice
+	if err = f.batchSvc.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if f.wp.Count() != 4 {
+		t.Errorf("expected the batch to be published once, got %d pages", f.wp.Count())
+	}
+}
+
+func TestBatchSubmitRespectsBudget(t *testing.T) {
+	f := newExecutorFixture(t)
+	providerID := f.createProvider(t, ai.MockModelSynthetic, "")
+	f.budget.exhausted.Store(true)
+
+	_, err := f.batcher.Submit(context.Background(), GenerationConfig{
+		SitemapID:  f.sitemapID,
+		SiteID:     f.siteID,
+		ProviderID: providerID,
+	})
+	if !errors.IsBudgetExceeded(err) {
+		t.Fatalf("expected budget exceeded, got %v", err)
+	}
+
+	node, err := f.sitemapSvc.GetNode(context.Background(), f.children[0])
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if node.GenerationStatus == entities.GenStatusQueued {
+		t.Error("expected node not to be queued by a rejected batch")
+	}
+}
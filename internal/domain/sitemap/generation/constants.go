@@ -6,4 +6,10 @@ const (
 	DefaultTaskTimeout    = 4 * time.Hour
 	DefaultMaxConcurrency = 3
 	DraftSlugPrefix       = "draft-%d"
+
+	// Transient provider failures (429, 5xx) are retried on the same provider
+	// before falling back, waiting at least the Retry-After the provider asked for
+	MaxTransientRetries = 3
+	RetryBaseDelay      = 2 * time.Second
+	RetryMaxDelay       = 2 * time.Minute
 )
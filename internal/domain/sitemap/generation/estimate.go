@@ -0,0 +1,80 @@
+package generation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidmovas/postulator/internal/infra/ai"
+)
+
+// Estimate renders the prompt of every node the task would generate and sums the
+// expected token usage and cost on the primary provider. Nothing is sent to the provider.
+func (e *Executor) Estimate(ctx context.Context, config GenerationConfig) (*ai.CostEstimate, error) {
+	nodes, err := e.prepareNodes(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := e.generator.providerSvc.GetProvider(ctx, config.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	words, err := e.generator.estimateWords(ctx, config.PromptID, config.ContentSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := ai.NewCostEstimate(provider.Type, provider.Model)
+	for _, taskNode := range nodes {
+		req, err := e.generateRequest(ctx, config, taskNode.NodeID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = e.generator.Estimate(ctx, req, words, estimate); err != nil {
+			return nil, err
+		}
+	}
+
+	e.logger.Infof("Estimated generation of %d pages: %d input, %d output tokens, $%.2f",
+		estimate.Items, estimate.InputTokens, estimate.OutputTokens, estimate.CostUSD)
+
+	return estimate, nil
+}
+
+// Estimate adds the expected usage of generating the node to the estimate
+func (g *Generator) Estimate(ctx context.Context, req GenerateRequest, words int, estimate *ai.CostEstimate) error {
+	systemPrompt, userPrompt, params, err := g.articleRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	estimate.AddArticle(systemPrompt, userPrompt, words, ai.NewGenerateArticleOptions(params))
+	return nil
+}
+
+// estimateWords resolves the article length the prompts ask for: the content
+// settings first, then the word count configured on the prompt
+func (g *Generator) estimateWords(ctx context.Context, promptID *int64, settings *ContentSettings) (int, error) {
+	if settings != nil {
+		if field, ok := settings.ContextOverrides["wordCount"]; ok && field.Enabled && field.Value != "" {
+			return ai.ParseWordCount(field.Value), nil
+		}
+		if settings.WordCount != "" {
+			return ai.ParseWordCount(settings.WordCount), nil
+		}
+	}
+
+	if promptID != nil && *promptID > 0 {
+		prompt, err := g.promptSvc.GetPrompt(ctx, *promptID)
+		if err != nil {
+			return 0, err
+		}
+		if field, ok := prompt.ContextConfig["wordCount"]; ok && field.Enabled && field.Value != "" {
+			return ai.ParseWordCount(field.Value), nil
+		}
+	}
+
+	return ai.ParseWordCount(DefaultWordCount), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/davidmovas/postulator/internal/infra/ai"
 	"github.com/davidmovas/postulator/internal/infra/events"
 )
 
@@ -18,6 +19,7 @@ const (
 
 	EventNodeQueued     events.EventType = "pagegeneration.node.queued"
 	EventNodeGenerating events.EventType = "pagegeneration.node.generating"
+	EventNodeProgress   events.EventType = "pagegeneration.node.progress"
 	EventNodeGenerated  events.EventType = "pagegeneration.node.generated"
 	EventNodePublishing events.EventType = "pagegeneration.node.publishing"
 	EventNodeCompleted  events.EventType = "pagegeneration.node.completed"
@@ -54,6 +56,7 @@ type TaskPausedEvent struct {
 	TaskID         string
 	ProcessedNodes int
 	TotalNodes     int
+	Reason         string // Empty when paused by the user
 }
 
 type TaskResumedEvent struct {
@@ -94,6 +97,14 @@ type NodeGeneratingEvent struct {
 	Title  string
 }
 
+type NodeProgressEvent struct {
+	TaskID       string
+	NodeID       int64
+	Chars        int
+	Tokens       int
+	PartialTitle string
+}
+
 type NodeGeneratedEvent struct {
 	TaskID     string
 	NodeID     int64
@@ -158,11 +169,12 @@ func (e *EventEmitter) EmitTaskProgress(ctx context.Context, taskID string, proc
 	}))
 }
 
-func (e *EventEmitter) EmitTaskPaused(ctx context.Context, taskID string, processed, total int) {
+func (e *EventEmitter) EmitTaskPaused(ctx context.Context, taskID string, processed, total int, reason string) {
 	e.eventBus.Publish(ctx, events.NewEvent(EventTaskPaused, TaskPausedEvent{
 		TaskID:         taskID,
 		ProcessedNodes: processed,
 		TotalNodes:     total,
+		Reason:         reason,
 	}))
 }
 
@@ -216,6 +228,16 @@ func (e *EventEmitter) EmitNodeGenerating(ctx context.Context, taskID string, no
 	}))
 }
 
+func (e *EventEmitter) EmitNodeProgress(ctx context.Context, taskID string, nodeID int64, progress ai.StreamProgress) {
+	e.eventBus.Publish(ctx, events.NewEvent(EventNodeProgress, NodeProgressEvent{
+		TaskID:       taskID,
+		NodeID:       nodeID,
+		Chars:        progress.Chars,
+		Tokens:       progress.Tokens,
+		PartialTitle: progress.PartialTitle,
+	}))
+}
+
 func (e *EventEmitter) EmitNodeGenerated(ctx context.Context, taskID string, nodeID int64, title string, tokensUsed int, startTime time.Time) {
 	e.eventBus.Publish(ctx, events.NewEvent(EventNodeGenerated, NodeGeneratedEvent{
 		TaskID:     taskID,
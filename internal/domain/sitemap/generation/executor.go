@@ -10,7 +10,9 @@ import (
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/linking"
 	"github.com/davidmovas/postulator/internal/domain/sitemap"
+	"github.com/davidmovas/postulator/internal/infra/ai"
 	"github.com/davidmovas/postulator/internal/infra/events"
+	"github.com/davidmovas/postulator/pkg/errors"
 	"github.com/davidmovas/postulator/pkg/logger"
 	"github.com/google/uuid"
 )
@@ -216,7 +218,8 @@ func (e *Executor) runTask(ctx context.Context, task *Task, config GenerationCon
 		select {
 		case <-ctx.Done():
 			task.SetStatus(TaskStatusCancelled)
-			e.emitter.EmitTaskCancelled(ctx, task.ID, task.ProcessedNodes, task.TotalNodes)
+			processed, _, total := task.GetProgress()
+			e.emitter.EmitTaskCancelled(ctx, task.ID, processed, total)
 			return
 		default:
 		}
@@ -225,16 +228,30 @@ func (e *Executor) runTask(ctx context.Context, task *Task, config GenerationCon
 		select {
 		case <-pauseCh:
 			task.SetStatus(TaskStatusPaused)
-			e.emitter.EmitTaskPaused(ctx, task.ID, task.ProcessedNodes, task.TotalNodes)
+			processed, _, total := task.GetProgress()
+			e.emitter.EmitTaskPaused(ctx, task.ID, processed, total, "")
 			<-pauseCh
 			task.SetStatus(TaskStatusRunning)
-			remaining := task.TotalNodes - task.ProcessedNodes
-			e.emitter.EmitTaskResumed(ctx, task.ID, remaining)
+			e.emitter.EmitTaskResumed(ctx, task.ID, total-processed)
 		default:
 		}
 
-		// Process nodes at this depth level in parallel
-		e.processNodesParallel(ctx, task, nodes, config, &wpPageIDMap, maxConcurrency)
+		// Process nodes at this depth level in parallel. Nodes stopped by an exhausted
+		// AI budget are retried once the user resumes the task.
+		for pending := nodes; len(pending) > 0; {
+			var budgetErr error
+			pending, budgetErr = e.processNodesParallel(ctx, task, pending, config, &wpPageIDMap, maxConcurrency)
+			if len(pending) == 0 || ctx.Err() != nil {
+				break
+			}
+
+			if !e.waitForBudget(ctx, task, pauseCh, budgetErr) {
+				task.SetStatus(TaskStatusCancelled)
+				processed, _, total := task.GetProgress()
+				e.emitter.EmitTaskCancelled(ctx, task.ID, processed, total)
+				return
+			}
+		}
 		e.logger.Infof("Completed processing depth %d", depth)
 	}
 
@@ -249,33 +266,55 @@ func (e *Executor) runTask(ctx context.Context, task *Task, config GenerationCon
 
 	task.Complete()
 
-	e.emitter.EmitTaskCompleted(ctx, task.ID,
-		task.ProcessedNodes, task.FailedNodes, task.SkippedNodes, task.TotalNodes, task.StartedAt)
+	processed, failed, skipped, total := task.GetCounts()
+	e.emitter.EmitTaskCompleted(ctx, task.ID, processed, failed, skipped, total, task.StartedAt)
 
 	e.logger.Infof("Task %s completed: %d/%d nodes processed, %d failed",
-		task.ID, task.ProcessedNodes, task.TotalNodes, task.FailedNodes)
+		task.ID, processed, total, failed)
 }
 
-func (e *Executor) processNodesParallel(ctx context.Context, task *Task, nodes []*TaskNode, config GenerationConfig, wpPageIDMap *sync.Map, maxConcurrency int) {
+// processNodesParallel processes the nodes of one depth level. Once a node hits an
+// exhausted AI budget no further nodes are started; the nodes left unprocessed are
+// returned together with the budget error.
+func (e *Executor) processNodesParallel(ctx context.Context, task *Task, nodes []*TaskNode, config GenerationConfig, wpPageIDMap *sync.Map, maxConcurrency int) ([]*TaskNode, error) {
 	if len(nodes) == 0 {
-		return
+		return nil, nil
 	}
 
 	// Semaphore to limit concurrency
 	sem := make(chan struct{}, maxConcurrency)
 	var wg sync.WaitGroup
 
-	for _, node := range nodes {
+	var (
+		mu        sync.Mutex
+		budgetErr error
+		deferred  []*TaskNode
+	)
+
+	for i, node := range nodes {
 		// Check for cancellation
 		select {
 		case <-ctx.Done():
-			return
+			return nil, nil
 		default:
 		}
 
-		wg.Add(1)
 		sem <- struct{}{} // acquire semaphore
 
+		mu.Lock()
+		stopped := budgetErr != nil
+		if stopped {
+			deferred = append(deferred, nodes[i:]...)
+		}
+		mu.Unlock()
+
+		if stopped {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+
 		go func(n *TaskNode) {
 			defer wg.Done()
 			defer func() { <-sem }() // release semaphore
@@ -294,6 +333,16 @@ func (e *Executor) processNodesParallel(ctx context.Context, task *Task, nodes [
 			}
 
 			err := e.processNode(ctx, task, n, config)
+			if errors.IsBudgetExceeded(err) {
+				n.SetStatus(NodeStatusPending)
+				e.logger.Warnf("Node %d (%s) deferred: %v", n.NodeID, n.Title, err)
+				mu.Lock()
+				budgetErr = err
+				deferred = append(deferred, n)
+				mu.Unlock()
+				return
+			}
+
 			if err != nil {
 				errMsg := err.Error()
 				n.MarkFailed(errMsg)
@@ -305,13 +354,51 @@ func (e *Executor) processNodesParallel(ctx context.Context, task *Task, nodes [
 			}
 
 			task.IncrementProcessed()
-			e.emitter.EmitTaskProgress(ctx, task.ID,
-				task.ProcessedNodes, task.TotalNodes, task.FailedNodes, task.SkippedNodes,
+			processed, failed, skipped, total := task.GetCounts()
+			e.emitter.EmitTaskProgress(ctx, task.ID, processed, total, failed, skipped,
 				&NodeInfo{NodeID: n.NodeID, Title: n.Title, Path: n.Path})
 		}(node)
 	}
 
 	wg.Wait()
+
+	// Keep the depth order stable for the retry after resume
+	sort.Slice(deferred, func(i, j int) bool {
+		return deferred[i].NodeID < deferred[j].NodeID
+	})
+
+	return deferred, budgetErr
+}
+
+// waitForBudget pauses the task after an AI budget was exhausted and blocks until the
+// user resumes it. It returns false if the task was cancelled while paused.
+func (e *Executor) waitForBudget(ctx context.Context, task *Task, pauseCh chan struct{}, budgetErr error) bool {
+	// A manual pause requested while the depth was finishing is superseded by this one
+	select {
+	case <-pauseCh:
+	default:
+	}
+
+	reason := "AI budget exceeded"
+	if budgetErr != nil {
+		reason = budgetErr.Error()
+	}
+
+	task.SetStatus(TaskStatusPaused)
+	e.logger.Warnf("Task %s paused: %s", task.ID, reason)
+	processed, _, total := task.GetProgress()
+	e.emitter.EmitTaskPaused(ctx, task.ID, processed, total, reason)
+
+	select {
+	case <-pauseCh:
+	case <-ctx.Done():
+		return false
+	}
+
+	task.SetStatus(TaskStatusRunning)
+	processed, _, total = task.GetProgress()
+	e.emitter.EmitTaskResumed(ctx, task.ID, total-processed)
+	return true
 }
 
 func (e *Executor) processNode(ctx context.Context, task *Task, taskNode *TaskNode, config GenerationConfig) error {
@@ -343,9 +430,7 @@ func (e *Executor) processNode(ctx context.Context, task *Task, taskNode *TaskNo
 	// Fetch link targets if IncludeLinks is enabled OR if AutoLinkMode is "before"
 	// (in "before" mode, links were auto-approved before generation started)
 	var linkTargets []LinkTarget
-	shouldIncludeLinks := config.ContentSettings != nil &&
-		(config.ContentSettings.IncludeLinks || config.ContentSettings.AutoLinkMode == AutoLinkModeBefore)
-	if shouldIncludeLinks {
+	if includeLinks(config) {
 		linkTargets = e.getApprovedLinkTargets(ctx, task.SitemapID, task.SiteID, node.ID)
 		if len(linkTargets) > 0 {
 			e.logger.Infof("Node %d: including %d approved link targets in generation", node.ID, len(linkTargets))
@@ -354,21 +439,34 @@ func (e *Executor) processNode(ctx context.Context, task *Task, taskNode *TaskNo
 
 	genStartTime := time.Now()
 	genResult, err := e.generator.Generate(ctx, GenerateRequest{
-		Node:            node,
-		Ancestors:       ancestors,
-		SiteID:          task.SiteID,
-		ProviderID:      config.ProviderID,
-		PromptID:        config.PromptID,
-		Placeholders:    config.Placeholders,
-		ContentSettings: config.ContentSettings,
-		LinkTargets:     linkTargets,
+		Node:                node,
+		Ancestors:           ancestors,
+		SiteID:              task.SiteID,
+		ProviderID:          config.ProviderID,
+		FallbackProviderIDs: config.FallbackProviderIDs,
+		PromptID:            config.PromptID,
+		Placeholders:        config.Placeholders,
+		ContentSettings:     config.ContentSettings,
+		LinkTargets:         linkTargets,
+		OnProgress: func(progress ai.StreamProgress) {
+			e.emitter.EmitNodeProgress(ctx, task.ID, taskNode.NodeID, progress)
+		},
 	})
 	if err != nil {
+		if errors.IsBudgetExceeded(err) {
+			_ = e.sitemapSvc.UpdateNodeGenerationStatus(ctx, node.ID, entities.GenStatusNone, nil)
+			return err
+		}
 		errStr := err.Error()
 		_ = e.sitemapSvc.UpdateNodeGenerationStatus(ctx, node.ID, entities.GenStatusFailed, &errStr)
 		return fmt.Errorf("generation failed: %w", err)
 	}
 
+	if genResult.ProviderID != config.ProviderID {
+		e.logger.Infof("Node %d: content generated by fallback provider %s/%s",
+			node.ID, genResult.ProviderName, genResult.ModelName)
+	}
+
 	tokensUsed := genResult.Content.InputTokens + genResult.Content.OutputTokens
 	e.emitter.EmitNodeGenerated(ctx, task.ID, taskNode.NodeID, taskNode.Title, tokensUsed, genStartTime)
 
@@ -385,6 +483,8 @@ func (e *Executor) processNode(ctx context.Context, task *Task, taskNode *TaskNo
 		SiteID:         task.SiteID,
 		PublishAs:      task.PublishAs,
 		ParentWPPageID: taskNode.ParentWPPageID,
+		FeaturedImage:  genResult.FeaturedImage,
+		ProviderID:     genResult.ProviderID,
 	})
 	if err != nil {
 		errStr := err.Error()
@@ -404,6 +504,42 @@ func (e *Executor) processNode(ctx context.Context, task *Task, taskNode *TaskNo
 	return nil
 }
 
+// generateRequest loads the node with its ancestors and approved link targets for
+// generation outside of a running task
+func (e *Executor) generateRequest(ctx context.Context, config GenerationConfig, nodeID int64) (GenerateRequest, error) {
+	node, err := e.sitemapSvc.GetNode(ctx, nodeID)
+	if err != nil {
+		return GenerateRequest{}, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	ancestors, err := e.getAncestors(ctx, node)
+	if err != nil {
+		e.logger.ErrorWithErr(err, "Failed to get ancestors")
+	}
+
+	var linkTargets []LinkTarget
+	if includeLinks(config) {
+		linkTargets = e.getApprovedLinkTargets(ctx, config.SitemapID, config.SiteID, node.ID)
+	}
+
+	return GenerateRequest{
+		Node:            node,
+		Ancestors:       ancestors,
+		SiteID:          config.SiteID,
+		ProviderID:      config.ProviderID,
+		PromptID:        config.PromptID,
+		Placeholders:    config.Placeholders,
+		ContentSettings: config.ContentSettings,
+		LinkTargets:     linkTargets,
+	}, nil
+}
+
+// includeLinks reports whether approved link targets are passed to the generation prompt
+func includeLinks(config GenerationConfig) bool {
+	return config.ContentSettings != nil &&
+		(config.ContentSettings.IncludeLinks || config.ContentSettings.AutoLinkMode == AutoLinkModeBefore)
+}
+
 func (e *Executor) getAncestors(ctx context.Context, node *entities.SitemapNode) ([]*entities.SitemapNode, error) {
 	var ancestors []*entities.SitemapNode
 	currentID := node.ParentID
@@ -0,0 +1,422 @@
+package generation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/articles"
+	"github.com/davidmovas/postulator/internal/domain/batches"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/domain/deletion"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/images"
+	"github.com/davidmovas/postulator/internal/domain/linking"
+	"github.com/davidmovas/postulator/internal/domain/prompts"
+	"github.com/davidmovas/postulator/internal/domain/providers"
+	"github.com/davidmovas/postulator/internal/domain/sitemap"
+	"github.com/davidmovas/postulator/internal/domain/sites"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/internal/infra/database"
+	"github.com/davidmovas/postulator/internal/infra/events"
+	"github.com/davidmovas/postulator/internal/infra/wp"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+// fakeWP keeps created pages and uploaded media in memory; only the methods
+// used by the publisher are implemented
+type fakeWP struct {
+	wp.Client
+
+	mu     sync.Mutex
+	nextID int
+	pages  map[int]*wp.WPPage
+	media  map[int]*wp.MediaResult
+}
+
+func newFakeWP() *fakeWP {
+	return &fakeWP{nextID: 100, pages: make(map[int]*wp.WPPage), media: make(map[int]*wp.MediaResult)}
+}
+
+func (f *fakeWP) UploadMedia(_ context.Context, s *entities.Site, filename string, data []byte, altText string) (*wp.MediaResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty media file")
+	}
+
+	f.nextID++
+	media := &wp.MediaResult{ID: f.nextID, SourceURL: s.URL + "/media/" + filename, AltText: altText}
+	f.media[media.ID] = media
+	return media, nil
+}
+
+func (f *fakeWP) Media(mediaID int) *wp.MediaResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.media[mediaID]
+}
+
+func (f *fakeWP) CreatePage(_ context.Context, s *entities.Site, page *wp.WPPage, _ *wp.PageCreateOptions) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	cp := *page
+	cp.ID = f.nextID
+	cp.Link = s.URL + "/" + page.Slug
+	f.pages[cp.ID] = &cp
+	return cp.ID, nil
+}
+
+func (f *fakeWP) GetPage(_ context.Context, _ *entities.Site, pageID int) (*wp.WPPage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	page, ok := f.pages[pageID]
+	if !ok {
+		return nil, fmt.Errorf("page %d not found", pageID)
+	}
+	cp := *page
+	return &cp, nil
+}
+
+func (f *fakeWP) Page(pageID int) *wp.WPPage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pages[pageID]
+}
+
+func (f *fakeWP) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pages)
+}
+
+// switchableBudget reports the budget as exceeded while exhausted is set
+type switchableBudget struct {
+	budgets.Service
+	exhausted atomic.Bool
+}
+
+func (b *switchableBudget) Check(context.Context, int64, int64) error {
+	if b.exhausted.Load() {
+		return errors.BudgetExceeded("daily", 5, 5)
+	}
+	return nil
+}
+
+func (b *switchableBudget) CheckCost(ctx context.Context, siteID, providerID int64, _ float64) error {
+	return b.Check(ctx, siteID, providerID)
+}
+
+type executorFixture struct {
+	executor   *Executor
+	batcher    *batcher
+	batchSvc   batches.Service
+	budget     *switchableBudget
+	sitemapSvc sitemap.Service
+	articles   articles.Repository
+	providers  providers.Repository
+	wp         *fakeWP
+	siteID     int64
+	sitemapID  int64
+	parents    []int64
+	children   []int64
+}
+
+// newExecutorFixture stores a site and a sitemap with two sections of one
+// page each, wiring the real generator and publisher against a fake WordPress
+func newExecutorFixture(t *testing.T) *executorFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	db, log := database.SetupTestEnv(t)
+
+	f := &executorFixture{
+		articles:  articles.NewRepository(db, log),
+		providers: providers.NewRepository(db, log),
+		budget:    &switchableBudget{},
+		wp:        newFakeWP(),
+	}
+
+	deletionValidator := deletion.NewValidator(db)
+	aiUsageSvc := aiusage.NewService(aiusage.NewRepository(db), log)
+	eventBus := events.NewEventBus()
+
+	siteSvc := sites.NewService(f.wp, nil, sites.NewRepository(db, log), deletionValidator, aiUsageSvc, log)
+	f.siteID = database.SeedTestSite(t, db, "https://example.com")
+
+	f.sitemapSvc = sitemap.NewService(
+		sitemap.NewRepository(db, log),
+		sitemap.NewNodeRepository(db, log),
+		sitemap.NewKeywordRepository(db, log),
+		log,
+	)
+
+	sm := &entities.Sitemap{SiteID: f.siteID, Name: "Main"}
+	if err := f.sitemapSvc.CreateSitemapWithRoot(ctx, sm, "https://example.com"); err != nil {
+		t.Fatalf("failed to create sitemap: %v", err)
+	}
+	f.sitemapID = sm.ID
+
+	createNode := func(parentID *int64, title string, position int) int64 {
+		node := &entities.SitemapNode{
+			SitemapID: sm.ID,
+			ParentID:  parentID,
+			Title:     title,
+			Slug:      strings.ToLower(strings.ReplaceAll(title, " ", "-")),
+			Position:  position,
+		}
+		if err := f.sitemapSvc.CreateNode(ctx, node); err != nil {
+			t.Fatalf("failed to create node %q: %v", title, err)
+		}
+		return node.ID
+	}
+
+	for i := 1; i <= 2; i++ {
+		parentID := createNode(nil, fmt.Sprintf("Section %d", i), i)
+		f.parents = append(f.parents, parentID)
+		f.children = append(f.children, createNode(&parentID, fmt.Sprintf("Section %d page", i), 1))
+	}
+
+	providerSvc := providers.NewService(f.providers, providers.NewModelRepository(db, log), providers.NewKeyRepository(db, log), deletionValidator, log)
+	promptSvc := prompts.NewService(prompts.NewRepository(db, log), deletionValidator, log)
+	articleSvc := articles.NewService(f.articles, siteSvc, providerSvc, promptSvc, nil, f.budget, aiUsageSvc, f.sitemapSvc, f.wp, log)
+
+	f.executor = NewExecutor(
+		f.sitemapSvc,
+		linking.NewService(db, f.sitemapSvc, siteSvc, providerSvc, promptSvc, f.wp, aiUsageSvc, f.budget, nil, eventBus, log),
+		NewGenerator(f.sitemapSvc, promptSvc, providerSvc, ai.CreateClient, aiUsageSvc, f.budget, nil, log),
+		NewPublisher(f.sitemapSvc, articleSvc, siteSvc, images.NewService(providerSvc, aiUsageSvc, f.budget, f.wp, log), f.wp, log),
+		eventBus,
+		log,
+	)
+
+	f.batchSvc = batches.NewService(batches.NewRepository(db, log), providerSvc, aiUsageSvc, f.budget, log)
+	f.batcher = newBatcher(f.executor, f.batchSvc, log)
+
+	return f
+}
+
+func (f *executorFixture) createProvider(t *testing.T, model, fixturesDir string) int64 {
+	t.Helper()
+
+	provider := &entities.Provider{
+		Name:     "Offline " + model,
+		Type:     entities.TypeMock,
+		Model:    model,
+		BaseURL:  fixturesDir,
+		IsActive: true,
+	}
+	if err := f.providers.Create(context.Background(), provider); err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	return provider.ID
+}
+
+func (f *executorFixture) run(t *testing.T, config GenerationConfig) *Task {
+	t.Helper()
+
+	config.SitemapID = f.sitemapID
+	config.SiteID = f.siteID
+	if config.PublishAs == "" {
+		config.PublishAs = PublishAsDraft
+	}
+
+	task, err := f.executor.Start(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	waitWhile(t, task, TaskStatusRunning)
+	return task
+}
+
+func waitWhile(t *testing.T, task *Task, statuses ...TaskStatus) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for slices.Contains(statuses, task.GetStatus()) {
+		if time.Now().After(deadline) {
+			t.Fatal("task did not finish in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestExecutorPublishesSitemapPages(t *testing.T) {
+	f := newExecutorFixture(t)
+	ctx := context.Background()
+
+	task := f.run(t, GenerationConfig{
+		ProviderID: f.createProvider(t, ai.MockModelSynthetic, ""),
+	})
+
+	if task.GetStatus() != TaskStatusCompleted {
+		t.Fatalf("expected completed task, got %s", task.GetStatus())
+	}
+	processed, failed, total := task.GetProgress()
+	if total != 4 || processed != 4 || failed != 0 {
+		t.Fatalf("expected 4/4 processed without failures, got %d/%d (%d failed)", processed, total, failed)
+	}
+	if f.wp.Count() != 4 {
+		t.Fatalf("expected 4 WordPress pages, got %d", f.wp.Count())
+	}
+
+	for i, childID := range f.children {
+		parent, err := f.sitemapSvc.GetNode(ctx, f.parents[i])
+		if err != nil {
+			t.Fatalf("GetNode: %v", err)
+		}
+		child, err := f.sitemapSvc.GetNode(ctx, childID)
+		if err != nil {
+			t.Fatalf("GetNode: %v", err)
+		}
+
+		if child.GenerationStatus != entities.GenStatusGenerated || child.WPPageID == nil || parent.WPPageID == nil {
+			t.Fatalf("expected node %d and its parent to be published", child.ID)
+		}
+
+		// Sections are processed before their pages, so each page is nested under its section
+		page := f.wp.Page(*child.WPPageID)
+		if page.ParentID != *parent.WPPageID {
+			t.Errorf("expected page %d to have parent %d, got %d", page.ID, *parent.WPPageID, page.ParentID)
+		}
+		if page.Content == "" || page.Status != "draft" {
+			t.Errorf("unexpected page %d: status=%q, empty content=%v", page.ID, page.Status, page.Content == "")
+		}
+	}
+}
+
+func TestExecutorPublishesFeaturedImages(t *testing.T) {
+	f := newExecutorFixture(t)
+	ctx := context.Background()
+
+	featuredImage := true
+	task := f.run(t, GenerationConfig{
+		NodeIDs:    f.parents[:1],
+		ProviderID: f.createProvider(t, ai.MockModelSynthetic, ""),
+		ContentSettings: &ContentSettings{
+			GenerationParams: &entities.GenerationParams{FeaturedImage: &featuredImage},
+		},
+	})
+
+	if task.GetStatus() != TaskStatusCompleted {
+		t.Fatalf("expected completed task, got %s", task.GetStatus())
+	}
+
+	node, err := f.sitemapSvc.GetNode(ctx, f.parents[0])
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+
+	page := f.wp.Page(*node.WPPageID)
+	media := f.wp.Media(page.FeaturedMedia)
+	if media == nil {
+		t.Fatalf("expected page %d to have an uploaded featured image", page.ID)
+	}
+	if media.AltText != page.Title || !strings.HasSuffix(media.SourceURL, ".png") {
+		t.Errorf("unexpected featured image: %+v", media)
+	}
+
+	article, err := f.articles.GetByID(ctx, *node.ArticleID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if article.FeaturedMediaID == nil || *article.FeaturedMediaID != media.ID {
+		t.Errorf("expected article to reference media %d, got %v", media.ID, article.FeaturedMediaID)
+	}
+}
+
+func TestExecutorFallsBackToNextProvider(t *testing.T) {
+	f := newExecutorFixture(t)
+
+	// An empty fixtures directory makes every replay request fail
+	task := f.run(t, GenerationConfig{
+		NodeIDs:             f.children[:1],
+		ProviderID:          f.createProvider(t, ai.MockModelReplay, t.TempDir()),
+		FallbackProviderIDs: []int64{f.createProvider(t, ai.MockModelSynthetic, "")},
+	})
+
+	// The selected page brings its ungenerated section along
+	processed, failed, total := task.GetProgress()
+	if total != 2 || processed != 2 || failed != 0 {
+		t.Fatalf("expected 2/2 processed without failures, got %d/%d (%d failed)", processed, total, failed)
+	}
+	if f.wp.Count() != 2 {
+		t.Errorf("expected 2 WordPress pages, got %d", f.wp.Count())
+	}
+}
+
+func TestExecutorFailsNodesWhenProviderFails(t *testing.T) {
+	f := newExecutorFixture(t)
+	ctx := context.Background()
+
+	task := f.run(t, GenerationConfig{
+		ProviderID: f.createProvider(t, ai.MockModelReplay, t.TempDir()),
+	})
+
+	if task.GetStatus() != TaskStatusFailed {
+		t.Fatalf("expected failed task, got %s", task.GetStatus())
+	}
+	if f.wp.Count() != 0 {
+		t.Errorf("expected no WordPress pages, got %d", f.wp.Count())
+	}
+
+	node, err := f.sitemapSvc.GetNode(ctx, f.parents[0])
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if node.GenerationStatus != entities.GenStatusFailed || node.LastError == nil {
+		t.Errorf("expected node to record the generation failure, got %s", node.GenerationStatus)
+	}
+}
+
+func TestExecutorPausesWhenBudgetExceeded(t *testing.T) {
+	f := newExecutorFixture(t)
+	ctx := context.Background()
+
+	f.budget.exhausted.Store(true)
+	task := f.run(t, GenerationConfig{
+		ProviderID: f.createProvider(t, ai.MockModelSynthetic, ""),
+	})
+
+	if task.GetStatus() != TaskStatusPaused {
+		t.Fatalf("expected paused task, got %s", task.GetStatus())
+	}
+	processed, failed, _ := task.GetProgress()
+	if processed != 0 || failed != 0 || f.wp.Count() != 0 {
+		t.Fatalf("expected nothing processed, got processed=%d failed=%d pages=%d", processed, failed, f.wp.Count())
+	}
+
+	node, err := f.sitemapSvc.GetNode(ctx, f.parents[0])
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if node.GenerationStatus != entities.GenStatusNone {
+		t.Errorf("expected node to stay ungenerated, got %s", node.GenerationStatus)
+	}
+
+	// Raising the limit and resuming picks up where the task stopped
+	f.budget.exhausted.Store(false)
+	if err = f.executor.Resume(task.ID); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	waitWhile(t, task, TaskStatusPaused, TaskStatusRunning)
+
+	if task.GetStatus() != TaskStatusCompleted {
+		t.Fatalf("expected completed task, got %s", task.GetStatus())
+	}
+	processed, failed, total := task.GetProgress()
+	if total != 4 || processed != 4 || failed != 0 || f.wp.Count() != 4 {
+		t.Fatalf("expected 4/4 processed without failures, got %d/%d (%d failed), pages=%d", processed, total, failed, f.wp.Count())
+	}
+}
@@ -2,34 +2,30 @@ package generation
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/knowledge"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
 	"github.com/davidmovas/postulator/internal/domain/sitemap"
 	"github.com/davidmovas/postulator/internal/infra/ai"
+	"github.com/davidmovas/postulator/pkg/errors"
 	"github.com/davidmovas/postulator/pkg/logger"
 )
 
-type GeneratedContent struct {
-	Title           string `json:"title"`
-	Content         string `json:"content"`
-	Excerpt         string `json:"excerpt"`
-	MetaDescription string `json:"meta_description"`
-}
-
 type Generator struct {
 	sitemapSvc      sitemap.Service
 	promptSvc       prompts.Service
 	providerSvc     providers.Service
 	aiClientFactory func(provider *entities.Provider) (ai.Client, error)
-	rateLimiter     *RateLimiter
 	aiUsageService  aiusage.Service
+	budgetSvc       budgets.Service
+	knowledgeSvc    knowledge.Service
 	logger          *logger.Logger
 }
 
@@ -38,8 +34,9 @@ func NewGenerator(
 	promptSvc prompts.Service,
 	providerSvc providers.Service,
 	aiClientFactory func(provider *entities.Provider) (ai.Client, error),
-	rateLimiter *RateLimiter,
 	aiUsageService aiusage.Service,
+	budgetSvc budgets.Service,
+	knowledgeSvc knowledge.Service,
 	logger *logger.Logger,
 ) *Generator {
 	return &Generator{
@@ -47,8 +44,9 @@ func NewGenerator(
 		promptSvc:       promptSvc,
 		providerSvc:     providerSvc,
 		aiClientFactory: aiClientFactory,
-		rateLimiter:     rateLimiter,
 		aiUsageService:  aiUsageService,
+		budgetSvc:       budgetSvc,
+		knowledgeSvc:    knowledgeSvc,
 		logger:          logger.WithScope("page_generator"),
 	}
 }
@@ -62,41 +60,136 @@ type GenerateRequest struct {
 	Placeholders    map[string]string
 	ContentSettings *ContentSettings
 	LinkTargets     []LinkTarget // Approved outgoing links for this node
+	// FallbackProviderIDs are tried in order when ProviderID fails (optional)
+	FallbackProviderIDs []int64
+	// OnProgress receives streaming progress while the content is generated (optional)
+	OnProgress ai.StreamProgressFunc
 }
 
 type GenerateResult struct {
 	Content      *PageContent
 	DurationMs   int64
+	ProviderID   int64
 	ProviderName string
 	ModelName    string
+	// FeaturedImage is set when the prompt or content settings ask for a featured image
+	FeaturedImage bool
 }
 
 func (g *Generator) Generate(ctx context.Context, req GenerateRequest) (*GenerateResult, error) {
+	systemPrompt, userPrompt, params, err := g.articleRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	opts := ai.NewGenerateArticleOptions(params)
+
+	// The same rendered prompt is replayed on each provider of the chain
+	// until one of them produces the content
+	chain := append([]int64{req.ProviderID}, req.FallbackProviderIDs...)
+
+	var lastErr error
+	for attempt, providerID := range chain {
+		if attempt > 0 {
+			g.logger.Warnf("Node %d: falling back to provider %d after: %v", req.Node.ID, providerID, lastErr)
+		}
+
+		var (
+			result   *GenerateResult
+			fallback bool
+		)
+		result, fallback, lastErr = g.generateWithRetry(ctx, req, providerID, attempt, systemPrompt, userPrompt, opts)
+		if lastErr == nil {
+			result.FeaturedImage = params.WantsFeaturedImage()
+			return result, nil
+		}
+
+		if !fallback || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// generateWithRetry runs generateWithProvider, reacting to classified provider faults:
+// rate limits and outages are retried with backoff, a context overflow is retried
+// with a smaller output budget. Quota, auth and content filter faults go straight
+// to the next provider.
+func (g *Generator) generateWithRetry(
+	ctx context.Context,
+	req GenerateRequest,
+	providerID int64,
+	attempt int,
+	systemPrompt, userPrompt string,
+	opts *ai.GenerateArticleOptions,
+) (*GenerateResult, bool, error) {
+	for retries := 0; ; {
+		result, fallback, err := g.generateWithProvider(ctx, req, providerID, attempt, systemPrompt, userPrompt, opts)
+		if err == nil || ctx.Err() != nil {
+			return result, fallback, err
+		}
+
+		switch {
+		case errors.IsAIContextLength(err):
+			shrunk, ok := ai.ShrinkOutput(opts)
+			if !ok {
+				return result, fallback, err
+			}
+			opts = shrunk
+			g.logger.Warnf("Node %d: retrying with %d max output tokens after context overflow", req.Node.ID, *opts.MaxOutputTokens)
+
+		case errors.IsAIRetryable(err) && retries < MaxTransientRetries:
+			delay := min(max(RetryBaseDelay<<retries, errors.RetryAfter(err)), RetryMaxDelay)
+			retries++
+			g.logger.Warnf("Node %d: provider %d unavailable, retry %d/%d in %v: %v",
+				req.Node.ID, providerID, retries, MaxTransientRetries, delay, err)
+
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, false, ctx.Err()
+			}
+
+		default:
+			return result, fallback, err
+		}
+	}
+}
+
+// generateWithProvider runs a single generation attempt on the given provider.
+// The returned flag tells whether the failure may be retried on the next provider.
+func (g *Generator) generateWithProvider(
+	ctx context.Context,
+	req GenerateRequest,
+	providerID int64,
+	attempt int,
+	systemPrompt, userPrompt string,
+	opts *ai.GenerateArticleOptions,
+) (*GenerateResult, bool, error) {
 	startTime := time.Now()
 
-	provider, err := g.providerSvc.GetProvider(ctx, req.ProviderID)
+	provider, err := g.providerSvc.GetProvider(ctx, providerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get provider: %w", err)
+		return nil, true, fmt.Errorf("failed to get provider: %w", err)
 	}
 
+	// Calls are rate limited by the governed client, shared with jobs and linking
 	aiClient, err := g.aiClientFactory(provider)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create AI client: %w", err)
+		return nil, true, fmt.Errorf("failed to create AI client: %w", err)
 	}
 
-	if err := g.rateLimiter.Acquire(ctx, aiClient.GetProviderName(), aiClient.GetModelName()); err != nil {
-		return nil, fmt.Errorf("rate limit error: %w", err)
-	}
-
-	systemPrompt, userPrompt, err := g.buildPrompts(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build prompts: %w", err)
+	// An exhausted provider budget may still leave room on the next provider
+	if g.budgetSvc != nil {
+		if err = g.budgetSvc.Check(ctx, req.SiteID, providerID); err != nil {
+			return nil, errors.IsBudgetExceeded(err), err
+		}
 	}
 
 	g.logger.Infof("Generating content for node %d (%s) with provider %s/%s, links=%d",
 		req.Node.ID, req.Node.Title, aiClient.GetProviderName(), aiClient.GetModelName(), len(req.LinkTargets))
 
-	articleResult, err := aiClient.GenerateArticle(ctx, systemPrompt, userPrompt, nil)
+	articleResult, err := ai.ComposeArticle(ctx, aiClient, systemPrompt, userPrompt, opts, req.OnProgress)
 	durationMs := time.Since(startTime).Milliseconds()
 
 	// Log AI usage regardless of success/failure
@@ -108,32 +201,27 @@ func (g *Generator) Generate(ctx context.Context, req GenerateRequest) (*Generat
 		_ = g.aiUsageService.LogFromResult(
 			ctx,
 			req.SiteID,
+			provider.ID,
 			aiusage.OperationPageGeneration,
 			aiClient,
 			usage,
 			durationMs,
 			err,
 			map[string]interface{}{
-				"node_id":    req.Node.ID,
-				"node_title": req.Node.Title,
-				"node_path":  req.Node.Path,
+				"node_id":     req.Node.ID,
+				"node_title":  req.Node.Title,
+				"node_path":   req.Node.Path,
+				"provider_id": provider.ID,
+				"attempt":     attempt + 1,
 			},
 		)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("AI generation failed: %w", err)
+		return nil, errors.IsAI(err), fmt.Errorf("AI generation failed: %w", err)
 	}
 
-	content := &PageContent{
-		Title:           articleResult.Title,
-		Content:         articleResult.Content,
-		Excerpt:         articleResult.Excerpt,
-		MetaDescription: extractMetaDescription(articleResult),
-		InputTokens:     articleResult.Usage.InputTokens,
-		OutputTokens:    articleResult.Usage.OutputTokens,
-		CostUSD:         articleResult.Usage.CostUSD,
-	}
+	content := pageContent(articleResult)
 
 	g.logger.Infof("Generated content for node %d in %dms (tokens: %d)",
 		req.Node.ID, durationMs, articleResult.Usage.TotalTokens)
@@ -141,12 +229,30 @@ func (g *Generator) Generate(ctx context.Context, req GenerateRequest) (*Generat
 	return &GenerateResult{
 		Content:      content,
 		DurationMs:   durationMs,
+		ProviderID:   provider.ID,
 		ProviderName: aiClient.GetProviderName(),
 		ModelName:    aiClient.GetModelName(),
-	}, nil
+	}, false, nil
+}
+
+// articleRequest renders the prompts of the node and resolves the generation params
+func (g *Generator) articleRequest(ctx context.Context, req GenerateRequest) (string, string, *entities.GenerationParams, error) {
+	systemPrompt, userPrompt, params, err := g.buildPrompts(ctx, req)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to build prompts: %w", err)
+	}
+
+	// Content settings override the prompt defaults field by field
+	if req.ContentSettings != nil {
+		params = params.Merge(req.ContentSettings.GenerationParams)
+	}
+
+	return systemPrompt, userPrompt, params, nil
 }
 
-func (g *Generator) buildPrompts(ctx context.Context, req GenerateRequest) (string, string, error) {
+// buildPrompts renders the system and user prompts, and returns the generation
+// params of the selected prompt (nil for the built-in default prompt)
+func (g *Generator) buildPrompts(ctx context.Context, req GenerateRequest) (string, string, *entities.GenerationParams, error) {
 	nodeCtx := NodeContext{
 		Title:       req.Node.Title,
 		Path:        req.Node.Path,
@@ -164,6 +270,29 @@ func (g *Generator) buildPrompts(ctx context.Context, req GenerateRequest) (stri
 		nodeCtx.CustomInstructions = req.ContentSettings.CustomInstructions
 	}
 
+	var prompt *entities.Prompt
+	if req.PromptID != nil && *req.PromptID > 0 {
+		var err error
+		if prompt, err = g.promptSvc.GetPrompt(ctx, *req.PromptID); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	// Build context config overrides from ContentSettings
+	var overrides entities.ContextConfig
+	if req.ContentSettings != nil {
+		overrides = contentSettingsToOverrides(req.ContentSettings)
+	}
+
+	if g.knowledgeSvc != nil && g.knowledgeEnabled(prompt, overrides) {
+		query := req.Node.Title + " " + strings.Join(req.Node.Keywords, " ")
+		knowledgeCtx, err := g.knowledgeSvc.PromptContext(ctx, req.SiteID, nil, query)
+		if err != nil {
+			g.logger.ErrorWithErr(err, fmt.Sprintf("Node %d: failed to find knowledge base passages", req.Node.ID))
+		}
+		nodeCtx.Knowledge = knowledgeCtx
+	}
+
 	for _, ancestor := range req.Ancestors {
 		nodeCtx.Hierarchy = append(nodeCtx.Hierarchy, HierarchyNode{
 			Title: ancestor.Title,
@@ -180,24 +309,34 @@ func (g *Generator) buildPrompts(ctx context.Context, req GenerateRequest) (stri
 		}
 	}
 
-	if req.PromptID != nil && *req.PromptID > 0 {
-		prompt, err := g.promptSvc.GetPrompt(ctx, *req.PromptID)
-		if err != nil {
-			return "", "", err
-		}
-
-		// Build context config overrides from ContentSettings
-		var overrides entities.ContextConfig
-		if req.ContentSettings != nil {
-			overrides = contentSettingsToOverrides(req.ContentSettings)
-		}
-
-		return g.promptSvc.RenderPromptWithOverrides(ctx, prompt, runtimeData, overrides)
+	if prompt != nil {
+		system, user, err := g.promptSvc.RenderPromptWithOverrides(ctx, prompt, runtimeData, overrides)
+		return system, user, prompt.GenerationParams, err
 	}
 
 	renderer := NewDefaultPromptRenderer()
 	system, user := renderer.Render(runtimeData)
-	return system, user, nil
+	return system, user, nil, nil
+}
+
+// knowledgeEnabled reports whether the prompt takes the passages of the knowledge base.
+// Like the prompt builder, it follows the knowledge field of the prompt's context config,
+// or of the category defaults for the built-in prompt, unless the overrides set it.
+// Legacy prompts also take them when their template has the placeholder.
+func (g *Generator) knowledgeEnabled(prompt *entities.Prompt, overrides entities.ContextConfig) bool {
+	if field, ok := overrides["knowledge"]; ok {
+		return field.Enabled
+	}
+
+	if prompt == nil {
+		return g.promptSvc.GetDefaultContextConfig(entities.PromptCategoryPageGen)["knowledge"].Enabled
+	}
+
+	if !prompt.IsV2() && strings.Contains(prompt.SystemPrompt+prompt.UserPrompt, "{{knowledge}}") {
+		return true
+	}
+
+	return prompt.ContextConfig["knowledge"].Enabled
 }
 
 // contentSettingsToOverrides converts ContentSettings to ContextConfig overrides
@@ -228,6 +367,18 @@ func contentSettingsToOverrides(settings *ContentSettings) entities.ContextConfi
 	return overrides
 }
 
+func pageContent(result *ai.ArticleResult) *PageContent {
+	return &PageContent{
+		Title:           result.Title,
+		Content:         result.Content,
+		Excerpt:         result.Excerpt,
+		MetaDescription: extractMetaDescription(result),
+		InputTokens:     result.Usage.InputTokens,
+		OutputTokens:    result.Usage.OutputTokens,
+		CostUSD:         result.Usage.CostUSD,
+	}
+}
+
 func extractMetaDescription(result *ai.ArticleResult) string {
 	if result.Excerpt != "" && len(result.Excerpt) <= 160 {
 		return result.Excerpt
@@ -249,24 +400,3 @@ func extractMetaDescription(result *ai.ArticleResult) string {
 	}
 	return content
 }
-
-func ParseJSONContent(raw string) (*GeneratedContent, error) {
-	raw = strings.TrimSpace(raw)
-
-	if strings.HasPrefix(raw, "```json") {
-		raw = strings.TrimPrefix(raw, "```json")
-		raw = strings.TrimSuffix(raw, "```")
-		raw = strings.TrimSpace(raw)
-	} else if strings.HasPrefix(raw, "```") {
-		raw = strings.TrimPrefix(raw, "```")
-		raw = strings.TrimSuffix(raw, "```")
-		raw = strings.TrimSpace(raw)
-	}
-
-	var content GeneratedContent
-	if err := json.Unmarshal([]byte(raw), &content); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON content: %w", err)
-	}
-
-	return &content, nil
-}
@@ -0,0 +1,45 @@
+package generation
+
+import (
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/config"
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/prompts"
+	"github.com/davidmovas/postulator/pkg/logger"
+)
+
+func TestKnowledgeEnabled(t *testing.T) {
+	log, err := logger.NewForTest(&config.Config{LogLevel: "error", LogDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	g := &Generator{promptSvc: prompts.NewService(nil, nil, log)}
+
+	enabled := entities.ContextConfig{"knowledge": {Enabled: true}}
+	disabled := entities.ContextConfig{"knowledge": {Enabled: false}}
+
+	tests := []struct {
+		name      string
+		prompt    *entities.Prompt
+		overrides entities.ContextConfig
+		want      bool
+	}{
+		{name: "built-in prompt", want: true},
+		{name: "built-in prompt turned off", overrides: disabled, want: false},
+		{name: "prompt with the field on", prompt: &entities.Prompt{Version: 2, ContextConfig: enabled}, want: true},
+		{name: "prompt with the field off", prompt: &entities.Prompt{Version: 2, ContextConfig: disabled}, want: false},
+		{name: "prompt without the field", prompt: &entities.Prompt{Version: 2}, want: false},
+		{name: "override turns it on", prompt: &entities.Prompt{Version: 2, ContextConfig: disabled}, overrides: enabled, want: true},
+		{name: "legacy prompt with the placeholder", prompt: &entities.Prompt{Version: 1, UserPrompt: "Sources: {{knowledge}}"}, want: true},
+		{name: "legacy prompt without the placeholder", prompt: &entities.Prompt{Version: 1, UserPrompt: "{{user_instructions}}"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.knowledgeEnabled(tt.prompt, tt.overrides); got != tt.want {
+				t.Errorf("knowledgeEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -142,6 +142,8 @@ type ContentSettings struct {
 	MaxOutgoingLinks        int          `json:"maxOutgoingLinks"`                  // Max outgoing links per page (0 = no limit)
 	// Context overrides from UI - allows enabling/disabling specific context fields
 	ContextOverrides entities.ContextConfig `json:"contextOverrides,omitempty"`
+	// Sampling overrides on top of the prompt's generation params
+	GenerationParams *entities.GenerationParams `json:"generationParams,omitempty"`
 }
 
 // LinkTarget represents a target page for internal linking during content generation
@@ -154,15 +156,17 @@ type LinkTarget struct {
 }
 
 type GenerationConfig struct {
-	SitemapID       int64
-	SiteID          int64
-	NodeIDs         []int64
-	ProviderID      int64
-	PromptID        *int64
-	PublishAs       PublishAs
-	Placeholders    map[string]string
-	MaxConcurrency  int
-	ContentSettings *ContentSettings
+	SitemapID  int64
+	SiteID     int64
+	NodeIDs    []int64
+	ProviderID int64
+	// FallbackProviderIDs are tried in order when ProviderID fails to generate a page
+	FallbackProviderIDs []int64
+	PromptID            *int64
+	PublishAs           PublishAs
+	Placeholders        map[string]string
+	MaxConcurrency      int
+	ContentSettings     *ContentSettings
 }
 
 func (t *Task) IncrementProcessed() {
@@ -207,6 +211,14 @@ func (t *Task) GetProgress() (processed, failed, total int) {
 	return t.ProcessedNodes, t.FailedNodes, t.TotalNodes
 }
 
+// GetCounts returns a consistent snapshot of the node counters, which the node
+// goroutines keep updating
+func (t *Task) GetCounts() (processed, failed, skipped, total int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.ProcessedNodes, t.FailedNodes, t.SkippedNodes, t.TotalNodes
+}
+
 func (t *Task) Complete() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
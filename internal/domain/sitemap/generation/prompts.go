@@ -14,6 +14,9 @@ const (
 	DefaultPageUserPrompt   = `{{user_instructions}}`
 )
 
+// DefaultWordCount is the article length asked for when the content settings set none
+const DefaultWordCount = "500"
+
 var DefaultPagePlaceholders = []string{
 	"title",
 	"path",
@@ -26,6 +29,7 @@ var DefaultPagePlaceholders = []string{
 	"content_tone",
 	"custom_instructions",
 	"internal_links",
+	"knowledge",
 	"system_instructions",
 	"user_instructions",
 }
@@ -84,6 +88,7 @@ type NodeContext struct {
 	ContentTone        string
 	CustomInstructions string
 	LinkTargets        []LinkTarget // Internal links to include in content
+	Knowledge          string       // Passages of the site's knowledge base relevant to the page
 }
 
 type HierarchyNode struct {
@@ -117,7 +122,7 @@ func BuildPlaceholders(ctx NodeContext) map[string]string {
 
 	wordCount := ctx.WordCount
 	if wordCount == "" {
-		wordCount = "500"
+		wordCount = DefaultWordCount
 	}
 
 	writingStyle := ctx.WritingStyle
@@ -137,7 +142,7 @@ func BuildPlaceholders(ctx NodeContext) map[string]string {
 	systemInstructions := buildSystemInstructions(language, wordCount, writingStyle, contentTone, ctx.CustomInstructions, len(ctx.LinkTargets) > 0)
 
 	// Build user instructions from node data
-	userInstructions := buildUserInstructions(ctx.Title, ctx.Path, keywords, hierarchy, ctx.Context, internalLinks)
+	userInstructions := buildUserInstructions(ctx.Title, ctx.Path, keywords, hierarchy, ctx.Context, internalLinks, ctx.Knowledge)
 
 	return map[string]string{
 		// Registry-matching keys (camelCase) for v2 prompt builder
@@ -151,6 +156,7 @@ func BuildPlaceholders(ctx NodeContext) map[string]string {
 		"language":           language,
 		"internalLinks":      internalLinks,
 		"customInstructions": ctx.CustomInstructions,
+		"knowledge":          ctx.Knowledge,
 		// Legacy keys (snake_case) for v1 prompt compatibility
 		"title":               ctx.Title,
 		"word_count":          wordCount,
@@ -189,7 +195,7 @@ func buildSystemInstructions(language, wordCount, writingStyle, contentTone, cus
 	return sb.String()
 }
 
-func buildUserInstructions(title, path, keywords, hierarchy, context, internalLinks string) string {
+func buildUserInstructions(title, path, keywords, hierarchy, context, internalLinks, knowledge string) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("Create content for page: %s\n", title))
@@ -211,6 +217,10 @@ func buildUserInstructions(title, path, keywords, hierarchy, context, internalLi
 		sb.WriteString(fmt.Sprintf("\n%s", internalLinks))
 	}
 
+	if knowledge != "" {
+		sb.WriteString(fmt.Sprintf("\nReference material:\n%s\n", knowledge))
+	}
+
 	return sb.String()
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/davidmovas/postulator/internal/domain/articles"
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/images"
 	"github.com/davidmovas/postulator/internal/domain/sitemap"
 	"github.com/davidmovas/postulator/internal/domain/sites"
 	"github.com/davidmovas/postulator/internal/infra/wp"
@@ -23,6 +24,7 @@ type Publisher struct {
 	sitemapSvc sitemap.Service
 	articleSvc articles.Service
 	siteSvc    sites.Service
+	imageSvc   images.Service
 	wpClient   wp.Client
 	logger     *logger.Logger
 }
@@ -31,6 +33,7 @@ func NewPublisher(
 	sitemapSvc sitemap.Service,
 	articleSvc articles.Service,
 	siteSvc sites.Service,
+	imageSvc images.Service,
 	wpClient wp.Client,
 	logger *logger.Logger,
 ) *Publisher {
@@ -38,6 +41,7 @@ func NewPublisher(
 		sitemapSvc: sitemapSvc,
 		articleSvc: articleSvc,
 		siteSvc:    siteSvc,
+		imageSvc:   imageSvc,
 		wpClient:   wpClient,
 		logger:     logger.WithScope("page_publisher"),
 	}
@@ -49,6 +53,9 @@ type PublishRequest struct {
 	SiteID         int64
 	PublishAs      PublishAs
 	ParentWPPageID *int
+	// FeaturedImage generates a featured image for the page with ProviderID before publishing
+	FeaturedImage bool
+	ProviderID    int64
 }
 
 func (p *Publisher) Publish(ctx context.Context, req PublishRequest) (*PublishResult, error) {
@@ -71,6 +78,14 @@ func (p *Publisher) Publish(ctx context.Context, req PublishRequest) (*PublishRe
 		wpPage.ParentID = *req.ParentWPPageID
 	}
 
+	var featuredMedia *wp.MediaResult
+	if req.FeaturedImage {
+		featuredMedia = p.featuredImage(ctx, site, req)
+	}
+	if featuredMedia != nil {
+		wpPage.FeaturedMedia = featuredMedia.ID
+	}
+
 	p.logger.Debugf("Creating WP page for node %d: %s", req.Node.ID, req.Node.Title)
 
 	wpPageID, err := p.wpClient.CreatePage(ctx, site, wpPage, &wp.PageCreateOptions{
@@ -125,6 +140,11 @@ func (p *Publisher) Publish(ctx context.Context, req PublishRequest) (*PublishRe
 		article.ParentPageID = req.ParentWPPageID
 	}
 
+	if featuredMedia != nil {
+		article.FeaturedMediaID = &featuredMedia.ID
+		article.FeaturedMediaURL = &featuredMedia.SourceURL
+	}
+
 	if err := p.articleSvc.CreateArticle(ctx, article); err != nil {
 		p.logger.ErrorWithErr(err, "Failed to save article record, but WP page was created")
 	}
@@ -142,6 +162,21 @@ func (p *Publisher) Publish(ctx context.Context, req PublishRequest) (*PublishRe
 	}, nil
 }
 
+// featuredImage generates and uploads the featured image of the page. The page is
+// published without one when that fails.
+func (p *Publisher) featuredImage(ctx context.Context, site *entities.Site, req PublishRequest) *wp.MediaResult {
+	if p.imageSvc == nil {
+		return nil
+	}
+
+	media, err := p.imageSvc.CreateFeaturedImage(ctx, site, req.ProviderID, req.Content.Title, req.Content.Excerpt)
+	if err != nil {
+		p.logger.ErrorWithErr(err, fmt.Sprintf("Publishing node %d without featured image", req.Node.ID))
+		return nil
+	}
+	return media
+}
+
 func (p *Publisher) updateNodeAfterPublish(
 	ctx context.Context,
 	node *entities.SitemapNode,
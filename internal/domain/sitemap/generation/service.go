@@ -5,7 +5,11 @@ import (
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
 	"github.com/davidmovas/postulator/internal/domain/articles"
+	"github.com/davidmovas/postulator/internal/domain/batches"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/domain/images"
+	"github.com/davidmovas/postulator/internal/domain/knowledge"
 	"github.com/davidmovas/postulator/internal/domain/linking"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
@@ -19,6 +23,12 @@ import (
 
 type Service interface {
 	StartGeneration(ctx context.Context, config GenerationConfig) (*Task, error)
+	// EstimateGeneration returns the expected token usage and cost of a generation
+	// task with the given config, for confirmation before it is started
+	EstimateGeneration(ctx context.Context, config GenerationConfig) (*ai.CostEstimate, error)
+	// SubmitBatch submits the pages of the config to the provider's batch API at a
+	// discounted price. They are published once the batch finishes, within 24 hours.
+	SubmitBatch(ctx context.Context, config GenerationConfig) (*entities.Batch, error)
 	PauseGeneration(taskID string) error
 	ResumeGeneration(taskID string) error
 	CancelGeneration(taskID string) error
@@ -28,9 +38,9 @@ type Service interface {
 }
 
 type serviceImpl struct {
-	executor    *Executor
-	rateLimiter *RateLimiter
-	logger      *logger.Logger
+	executor *Executor
+	batcher  *batcher
+	logger   *logger.Logger
 }
 
 func NewService(
@@ -41,21 +51,25 @@ func NewService(
 	providerSvc providers.Service,
 	linkingSvc linking.Service,
 	aiUsageService aiusage.Service,
+	budgetSvc budgets.Service,
+	batchSvc batches.Service,
+	imageSvc images.Service,
+	knowledgeSvc knowledge.Service,
 	wpClient wp.Client,
 	eventBus *events.EventBus,
 	aiClientFactory func(provider *entities.Provider) (ai.Client, error),
 	logger *logger.Logger,
 ) Service {
 	log := logger.WithScope("page_generation")
-	rateLimiter := NewRateLimiter()
 
 	generator := NewGenerator(
 		sitemapSvc,
 		promptSvc,
 		providerSvc,
 		aiClientFactory,
-		rateLimiter,
 		aiUsageService,
+		budgetSvc,
+		knowledgeSvc,
 		log,
 	)
 
@@ -63,6 +77,7 @@ func NewService(
 		sitemapSvc,
 		articleSvc,
 		siteSvc,
+		imageSvc,
 		wpClient,
 		log,
 	)
@@ -77,9 +92,9 @@ func NewService(
 	)
 
 	return &serviceImpl{
-		executor:    executor,
-		rateLimiter: rateLimiter,
-		logger:      log,
+		executor: executor,
+		batcher:  newBatcher(executor, batchSvc, log),
+		logger:   log,
 	}
 }
 
@@ -90,6 +105,9 @@ func (s *serviceImpl) StartGeneration(ctx context.Context, config GenerationConf
 	if config.PublishAs == "" {
 		config.PublishAs = PublishAsDraft
 	}
+	if err := s.validateParams(ctx, config); err != nil {
+		return nil, err
+	}
 
 	s.logger.Infof("Starting page generation: sitemapID=%d, nodes=%d, provider=%d",
 		config.SitemapID, len(config.NodeIDs), config.ProviderID)
@@ -97,6 +115,61 @@ func (s *serviceImpl) StartGeneration(ctx context.Context, config GenerationConf
 	return s.executor.Start(ctx, config)
 }
 
+func (s *serviceImpl) EstimateGeneration(ctx context.Context, config GenerationConfig) (*ai.CostEstimate, error) {
+	if err := s.validateParams(ctx, config); err != nil {
+		return nil, err
+	}
+
+	return s.executor.Estimate(ctx, config)
+}
+
+func (s *serviceImpl) SubmitBatch(ctx context.Context, config GenerationConfig) (*entities.Batch, error) {
+	if config.PublishAs == "" {
+		config.PublishAs = PublishAsDraft
+	}
+	if err := s.validateParams(ctx, config); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Submitting page generation batch: sitemapID=%d, nodes=%d, provider=%d",
+		config.SitemapID, len(config.NodeIDs), config.ProviderID)
+
+	return s.batcher.Submit(ctx, config)
+}
+
+// validateParams checks the generation params, merged over the prompt's as they are
+// sent, against every provider the pages may be generated with
+func (s *serviceImpl) validateParams(ctx context.Context, config GenerationConfig) error {
+	generator := s.executor.generator
+
+	var params *entities.GenerationParams
+	if config.PromptID != nil && *config.PromptID > 0 {
+		prompt, err := generator.promptSvc.GetPrompt(ctx, *config.PromptID)
+		if err != nil {
+			return err
+		}
+		params = prompt.GenerationParams
+	}
+	if config.ContentSettings != nil {
+		params = params.Merge(config.ContentSettings.GenerationParams)
+	}
+	if params.IsEmpty() {
+		return nil
+	}
+
+	for _, providerID := range append([]int64{config.ProviderID}, config.FallbackProviderIDs...) {
+		provider, err := generator.providerSvc.GetProvider(ctx, providerID)
+		if err != nil {
+			return err
+		}
+		if err = params.ValidateFor(provider.Type); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *serviceImpl) PauseGeneration(taskID string) error {
 	return s.executor.Pause(taskID)
 }
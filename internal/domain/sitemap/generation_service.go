@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/prompts"
 	"github.com/davidmovas/postulator/internal/domain/providers"
@@ -70,6 +71,7 @@ type GenerationService struct {
 	promptSvc       prompts.Service
 	providerSvc     providers.Service
 	aiUsageSvc      aiusage.Service
+	budgetSvc       budgets.Service
 	aiClientFactory func(provider *entities.Provider) (ai.Client, error)
 	logger          *logger.Logger
 }
@@ -81,6 +83,7 @@ func NewGenerationService(
 	promptSvc prompts.Service,
 	providerSvc providers.Service,
 	aiUsageSvc aiusage.Service,
+	budgetSvc budgets.Service,
 	aiClientFactory func(provider *entities.Provider) (ai.Client, error),
 	logger *logger.Logger,
 ) *GenerationService {
@@ -90,6 +93,7 @@ func NewGenerationService(
 		promptSvc:       promptSvc,
 		providerSvc:     providerSvc,
 		aiUsageSvc:      aiUsageSvc,
+		budgetSvc:       budgetSvc,
 		aiClientFactory: aiClientFactory,
 		logger:          logger.WithScope("generation_service"),
 	}
@@ -131,6 +135,12 @@ func (s *GenerationService) GenerateStructureWithTracking(ctx context.Context, i
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
 
+	if s.budgetSvc != nil {
+		if err = s.budgetSvc.Check(ctx, input.SiteID, provider.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create AI client
 	aiClient, err := s.aiClientFactory(provider)
 	if err != nil {
@@ -161,6 +171,7 @@ func (s *GenerationService) GenerateStructureWithTracking(ctx context.Context, i
 		_ = s.aiUsageSvc.LogFromResult(
 			ctx,
 			input.SiteID,
+			provider.ID,
 			aiusage.OperationSitemapGeneration,
 			aiClient,
 			usage,
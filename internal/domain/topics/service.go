@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/davidmovas/postulator/internal/domain/budgets"
 	"github.com/davidmovas/postulator/internal/domain/deletion"
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/internal/domain/providers"
@@ -19,6 +20,7 @@ var _ Service = (*service)(nil)
 
 type service struct {
 	providerService   providers.Service
+	budgetService     budgets.Service
 	repo              Repository
 	siteTopicRepo     SiteTopicRepository
 	usageRepo         UsageRepository
@@ -29,6 +31,7 @@ type service struct {
 
 func NewService(
 	providerService providers.Service,
+	budgetService budgets.Service,
 	repo Repository,
 	siteTopicRepo SiteTopicRepository,
 	usageRepo UsageRepository,
@@ -38,6 +41,7 @@ func NewService(
 ) Service {
 	return &service{
 		providerService:   providerService,
+		budgetService:     budgetService,
 		repo:              repo,
 		siteTopicRepo:     siteTopicRepo,
 		usageRepo:         usageRepo,
@@ -302,6 +306,11 @@ func (s *service) GenerateVariations(ctx context.Context, providerID, topicID in
 		return nil, err
 	}
 
+	// Variations are not tied to a site, only global and provider budgets apply
+	if err = s.budgetService.Check(ctx, 0, provider.ID); err != nil {
+		return nil, err
+	}
+
 	titles, err := client.GenerateTopicVariations(ctx, reference.Title, count)
 	if err != nil {
 		s.logger.ErrorWithErr(err, "Failed to generate topic variations")
@@ -388,6 +397,10 @@ func (s *service) GetOrGenerateVariation(ctx context.Context, providerID, siteID
 		}
 	}
 
+	if err = s.budgetService.Check(ctx, siteID, provider.ID); err != nil {
+		return nil, err
+	}
+
 	newVariation, err := client.GenerateTopicVariations(ctx, originalTopic.Title, 1)
 	if err != nil {
 		s.logger.ErrorWithErr(err, "Failed to generate topic variation")
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/davidmovas/postulator/internal/domain/aiusage"
+	"github.com/davidmovas/postulator/internal/infra/ai"
 )
 
 // AIUsageSummary represents aggregated AI usage statistics
@@ -137,20 +138,21 @@ type AIUsageLogsResult struct {
 
 // AIUsageLog represents a single AI usage log entry
 type AIUsageLog struct {
-	ID            int64   `json:"id"`
-	SiteID        int64   `json:"siteId"`
-	OperationType string  `json:"operationType"`
-	ProviderName  string  `json:"providerName"`
-	ModelName     string  `json:"modelName"`
-	InputTokens   int     `json:"inputTokens"`
-	OutputTokens  int     `json:"outputTokens"`
-	TotalTokens   int     `json:"totalTokens"`
-	CostUSD       float64 `json:"costUsd"`
-	DurationMs    int64   `json:"durationMs"`
-	Success       bool    `json:"success"`
-	ErrorMessage  string  `json:"errorMessage,omitempty"`
-	Metadata      string  `json:"metadata,omitempty"`
-	CreatedAt     string  `json:"createdAt"`
+	ID             int64   `json:"id"`
+	SiteID         int64   `json:"siteId"`
+	OperationType  string  `json:"operationType"`
+	ProviderName   string  `json:"providerName"`
+	ModelName      string  `json:"modelName"`
+	KeyFingerprint string  `json:"keyFingerprint,omitempty"`
+	InputTokens    int     `json:"inputTokens"`
+	OutputTokens   int     `json:"outputTokens"`
+	TotalTokens    int     `json:"totalTokens"`
+	CostUSD        float64 `json:"costUsd"`
+	DurationMs     int64   `json:"durationMs"`
+	Success        bool    `json:"success"`
+	ErrorMessage   string  `json:"errorMessage,omitempty"`
+	Metadata       string  `json:"metadata,omitempty"`
+	CreatedAt      string  `json:"createdAt"`
 }
 
 func metadataToString(m map[string]interface{}) string {
@@ -168,20 +170,21 @@ func NewAIUsageLogList(entities []aiusage.UsageLog) []AIUsageLog {
 	result := make([]AIUsageLog, len(entities))
 	for i, e := range entities {
 		result[i] = AIUsageLog{
-			ID:            e.ID,
-			SiteID:        e.SiteID,
-			OperationType: string(e.OperationType),
-			ProviderName:  e.ProviderName,
-			ModelName:     e.ModelName,
-			InputTokens:   e.InputTokens,
-			OutputTokens:  e.OutputTokens,
-			TotalTokens:   e.TotalTokens,
-			CostUSD:       e.CostUSD,
-			DurationMs:    e.DurationMs,
-			Success:       e.Success,
-			ErrorMessage:  e.ErrorMessage,
-			Metadata:      metadataToString(e.Metadata),
-			CreatedAt:     TimeToString(e.CreatedAt),
+			ID:             e.ID,
+			SiteID:         e.SiteID,
+			OperationType:  string(e.OperationType),
+			ProviderName:   e.ProviderName,
+			ModelName:      e.ModelName,
+			KeyFingerprint: e.KeyFingerprint,
+			InputTokens:    e.InputTokens,
+			OutputTokens:   e.OutputTokens,
+			TotalTokens:    e.TotalTokens,
+			CostUSD:        e.CostUSD,
+			DurationMs:     e.DurationMs,
+			Success:        e.Success,
+			ErrorMessage:   e.ErrorMessage,
+			Metadata:       metadataToString(e.Metadata),
+			CreatedAt:      TimeToString(e.CreatedAt),
 		}
 	}
 	return result
@@ -191,21 +194,50 @@ func NewAIUsageLogPtrList(entities []aiusage.UsageLog) []*AIUsageLog {
 	result := make([]*AIUsageLog, len(entities))
 	for i, e := range entities {
 		result[i] = &AIUsageLog{
-			ID:            e.ID,
-			SiteID:        e.SiteID,
-			OperationType: string(e.OperationType),
-			ProviderName:  e.ProviderName,
-			ModelName:     e.ModelName,
-			InputTokens:   e.InputTokens,
-			OutputTokens:  e.OutputTokens,
-			TotalTokens:   e.TotalTokens,
-			CostUSD:       e.CostUSD,
-			DurationMs:    e.DurationMs,
-			Success:       e.Success,
-			ErrorMessage:  e.ErrorMessage,
-			Metadata:      metadataToString(e.Metadata),
-			CreatedAt:     TimeToString(e.CreatedAt),
+			ID:             e.ID,
+			SiteID:         e.SiteID,
+			OperationType:  string(e.OperationType),
+			ProviderName:   e.ProviderName,
+			ModelName:      e.ModelName,
+			KeyFingerprint: e.KeyFingerprint,
+			InputTokens:    e.InputTokens,
+			OutputTokens:   e.OutputTokens,
+			TotalTokens:    e.TotalTokens,
+			CostUSD:        e.CostUSD,
+			DurationMs:     e.DurationMs,
+			Success:        e.Success,
+			ErrorMessage:   e.ErrorMessage,
+			Metadata:       metadataToString(e.Metadata),
+			CreatedAt:      TimeToString(e.CreatedAt),
 		}
 	}
 	return result
 }
+
+// CostEstimate represents the expected usage of a generation before it is started
+type CostEstimate struct {
+	Items        int     `json:"items"`
+	Calls        int     `json:"calls"`
+	InputTokens  int     `json:"inputTokens"`
+	OutputTokens int     `json:"outputTokens"`
+	CostUSD      float64 `json:"costUsd"`
+	Priced       bool    `json:"priced"`
+	ProviderType string  `json:"providerType"`
+	Model        string  `json:"model"`
+}
+
+func NewCostEstimate(entity *ai.CostEstimate) *CostEstimate {
+	if entity == nil {
+		return nil
+	}
+	return &CostEstimate{
+		Items:        entity.Items,
+		Calls:        entity.Calls,
+		InputTokens:  entity.InputTokens,
+		OutputTokens: entity.OutputTokens,
+		CostUSD:      entity.CostUSD,
+		Priced:       entity.Priced,
+		ProviderType: string(entity.ProviderType),
+		Model:        entity.Model,
+	}
+}
@@ -34,6 +34,10 @@ type Article struct {
 	FeaturedMediaURL *string `json:"featuredMediaUrl"`
 	MetaDescription  *string `json:"metaDescription"`
 	Author           *int    `json:"author"`
+
+	// Translation fields
+	Language         string  `json:"language"`
+	TranslationGroup *string `json:"translationGroup"`
 }
 
 func NewArticle(entity *entities.Article) *Article {
@@ -97,6 +101,8 @@ func (d *Article) ToEntity() (*entities.Article, error) {
 		FeaturedMediaURL: d.FeaturedMediaURL,
 		MetaDescription:  d.MetaDescription,
 		Author:           d.Author,
+		Language:         d.Language,
+		TranslationGroup: d.TranslationGroup,
 	}, nil
 }
 
@@ -127,6 +133,10 @@ func (d *Article) FromEntity(entity *entities.Article) *Article {
 	d.MetaDescription = entity.MetaDescription
 	d.Author = entity.Author
 
+	// Translation fields
+	d.Language = entity.Language
+	d.TranslationGroup = entity.TranslationGroup
+
 	if entity.PublishedAt != nil {
 		publishedAt := TimeToString(*entity.PublishedAt)
 		d.PublishedAt = &publishedAt
@@ -227,3 +237,23 @@ type GenerateContentResult struct {
 	MetaDescription string `json:"metaDescription"`
 	TopicID         *int64 `json:"topicId"`
 }
+
+// RewriteArticleInput represents input for revising an existing article with AI
+type RewriteArticleInput struct {
+	ArticleID         int64             `json:"articleId"`
+	ProviderID        int64             `json:"providerId"`
+	PromptID          int64             `json:"promptId"`
+	Instructions      string            `json:"instructions"`
+	PlaceholderValues map[string]string `json:"placeholderValues"`
+}
+
+// TranslateArticleInput represents input for translating an article or a sitemap node's article
+type TranslateArticleInput struct {
+	ArticleID         int64             `json:"articleId"`
+	SitemapNodeID     *int64            `json:"sitemapNodeId"`
+	ProviderID        int64             `json:"providerId"`
+	PromptID          int64             `json:"promptId"`
+	SourceLanguage    string            `json:"sourceLanguage"`
+	Languages         []string          `json:"languages"`
+	PlaceholderValues map[string]string `json:"placeholderValues"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import "github.com/davidmovas/postulator/internal/domain/entities"
+
+type Batch struct {
+	ID             int64   `json:"id"`
+	SiteID         int64   `json:"siteId"`
+	ProviderID     int64   `json:"providerId"`
+	ExternalID     string  `json:"externalId"`
+	Source         string  `json:"source"`
+	SourceID       int64   `json:"sourceId"`
+	Status         string  `json:"status"`
+	TotalItems     int     `json:"totalItems"`
+	CompletedItems int     `json:"completedItems"`
+	FailedItems    int     `json:"failedItems"`
+	Error          *string `json:"error,omitempty"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+	CompletedAt    *string `json:"completedAt,omitempty"`
+}
+
+func NewBatch(entity *entities.Batch) *Batch {
+	b := &Batch{}
+	return b.FromEntity(entity)
+}
+
+func (d *Batch) FromEntity(entity *entities.Batch) *Batch {
+	d.ID = entity.ID
+	d.SiteID = entity.SiteID
+	d.ProviderID = entity.ProviderID
+	d.ExternalID = entity.ExternalID
+	d.Source = string(entity.Source)
+	d.SourceID = entity.SourceID
+	d.Status = string(entity.Status)
+	d.TotalItems = entity.TotalItems
+	d.CompletedItems = entity.CompletedItems
+	d.FailedItems = entity.FailedItems
+	d.Error = entity.Error
+	d.CreatedAt = TimeToString(entity.CreatedAt)
+	d.UpdatedAt = TimeToString(entity.UpdatedAt)
+	if entity.CompletedAt != nil {
+		completedAt := TimeToString(*entity.CompletedAt)
+		d.CompletedAt = &completedAt
+	}
+	return d
+}
+
+type BatchItem struct {
+	ID        int64   `json:"id"`
+	BatchID   int64   `json:"batchId"`
+	EntityID  int64   `json:"entityId"`
+	Status    string  `json:"status"`
+	ArticleID *int64  `json:"articleId,omitempty"`
+	Error     *string `json:"error,omitempty"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+func NewBatchItem(entity *entities.BatchItem) *BatchItem {
+	return &BatchItem{
+		ID:        entity.ID,
+		BatchID:   entity.BatchID,
+		EntityID:  entity.EntityID,
+		Status:    string(entity.Status),
+		ArticleID: entity.ArticleID,
+		Error:     entity.Error,
+		UpdatedAt: TimeToString(entity.UpdatedAt),
+	}
+}
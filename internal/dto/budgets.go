@@ -0,0 +1,74 @@
+package dto
+
+import "github.com/davidmovas/postulator/internal/domain/entities"
+
+type Budget struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	Scope      string  `json:"scope"`
+	SiteID     *int64  `json:"siteId,omitempty"`
+	ProviderID *int64  `json:"providerId,omitempty"`
+	Period     string  `json:"period"`
+	LimitUSD   float64 `json:"limitUsd"`
+	IsActive   bool    `json:"isActive"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+func NewBudget(entity *entities.Budget) *Budget {
+	b := &Budget{}
+	return b.FromEntity(entity)
+}
+
+func (d *Budget) ToEntity() (*entities.Budget, error) {
+	createdAt, err := StringToTime(d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt, err := StringToTime(d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.Budget{
+		ID:         d.ID,
+		Name:       d.Name,
+		Scope:      entities.BudgetScope(d.Scope),
+		SiteID:     d.SiteID,
+		ProviderID: d.ProviderID,
+		Period:     entities.BudgetPeriod(d.Period),
+		LimitUSD:   d.LimitUSD,
+		IsActive:   d.IsActive,
+		CreatedAt:  createdAt,
+		UpdatedAt:  updatedAt,
+	}, nil
+}
+
+func (d *Budget) FromEntity(entity *entities.Budget) *Budget {
+	d.ID = entity.ID
+	d.Name = entity.Name
+	d.Scope = string(entity.Scope)
+	d.SiteID = entity.SiteID
+	d.ProviderID = entity.ProviderID
+	d.Period = string(entity.Period)
+	d.LimitUSD = entity.LimitUSD
+	d.IsActive = entity.IsActive
+	d.CreatedAt = TimeToString(entity.CreatedAt)
+	d.UpdatedAt = TimeToString(entity.UpdatedAt)
+	return d
+}
+
+type BudgetStatus struct {
+	Budget   *Budget `json:"budget"`
+	SpentUSD float64 `json:"spentUsd"`
+	Exceeded bool    `json:"exceeded"`
+}
+
+func NewBudgetStatus(entity *entities.BudgetStatus) *BudgetStatus {
+	return &BudgetStatus{
+		Budget:   NewBudget(entity.Budget),
+		SpentUSD: entity.SpentUSD,
+		Exceeded: entity.Exceeded,
+	}
+}
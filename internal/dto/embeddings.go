@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/davidmovas/postulator/internal/domain/embeddings"
+
+type DuplicateTopics struct {
+	First      *Topic  `json:"first"`
+	Second     *Topic  `json:"second"`
+	Similarity float64 `json:"similarity"`
+}
+
+func NewDuplicateTopics(entity *embeddings.DuplicateTopics) *DuplicateTopics {
+	return &DuplicateTopics{
+		First:      NewTopic(entity.First),
+		Second:     NewTopic(entity.Second),
+		Similarity: entity.Similarity,
+	}
+}
+
+type RelatedArticle struct {
+	Article    *Article `json:"article"`
+	Similarity float64  `json:"similarity"`
+}
+
+func NewRelatedArticle(entity *embeddings.RelatedArticle) *RelatedArticle {
+	return &RelatedArticle{
+		Article:    NewArticle(entity.Article),
+		Similarity: entity.Similarity,
+	}
+}
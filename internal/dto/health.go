@@ -57,3 +57,37 @@ func SitesToDTO(items []*entities.Site) []*Site {
 	}
 	return res
 }
+
+type ProviderHealthCheck struct {
+	ID             int64  `json:"id"`
+	ProviderID     int64  `json:"providerId"`
+	KeyFingerprint string `json:"keyFingerprint,omitempty"`
+	Model          string `json:"model"`
+	CheckedAt      string `json:"checkedAt"`
+	Status         string `json:"status"`
+	NeedsAction    bool   `json:"needsAction"`
+	LatencyMs      int    `json:"latencyMs"`
+	ErrorMessage   string `json:"errorMessage,omitempty"`
+}
+
+func NewProviderHealthCheck(e *entities.ProviderHealthCheck) *ProviderHealthCheck {
+	return &ProviderHealthCheck{
+		ID:             e.ID,
+		ProviderID:     e.ProviderID,
+		KeyFingerprint: e.KeyFingerprint,
+		Model:          e.Model,
+		CheckedAt:      TimeToString(e.CheckedAt),
+		Status:         string(e.Status),
+		NeedsAction:    e.Status.NeedsAction(),
+		LatencyMs:      e.LatencyMs,
+		ErrorMessage:   e.ErrorMessage,
+	}
+}
+
+func NewProviderHealthCheckList(items []*entities.ProviderHealthCheck) []*ProviderHealthCheck {
+	res := make([]*ProviderHealthCheck, 0, len(items))
+	for _, item := range items {
+		res = append(res, NewProviderHealthCheck(item))
+	}
+	return res
+}
@@ -8,24 +8,31 @@ import (
 )
 
 type Job struct {
-	ID                 int64             `json:"id"`
-	Name               string            `json:"name"`
-	SiteID             int64             `json:"siteId"`
-	PromptID           int64             `json:"promptId"`
-	AIProviderID       int64             `json:"aiProviderId"`
-	PlaceholdersValues map[string]string `json:"placeholdersValues"`
-	TopicStrategy      string            `json:"topicStrategy"`
-	CategoryStrategy   string            `json:"categoryStrategy"`
-	RequiresValidation bool              `json:"requiresValidation"`
-	JitterEnabled      bool              `json:"jitterEnabled"`
-	JitterMinutes      int               `json:"jitterMinutes"`
-	Status             string            `json:"status"`
-	CreatedAt          string            `json:"createdAt"`
-	UpdatedAt          string            `json:"updatedAt"`
-	Schedule           *Schedule         `json:"schedule"`
-	State              *State            `json:"state"`
-	Categories         []int64           `json:"categories"`
-	Topics             []int64           `json:"topics"`
+	ID                  int64             `json:"id"`
+	Name                string            `json:"name"`
+	SiteID              int64             `json:"siteId"`
+	PromptID            int64             `json:"promptId"`
+	AIProviderID        int64             `json:"aiProviderId"`
+	PlaceholdersValues  map[string]string `json:"placeholdersValues"`
+	TopicStrategy       string            `json:"topicStrategy"`
+	CategoryStrategy    string            `json:"categoryStrategy"`
+	RequiresValidation  bool              `json:"requiresValidation"`
+	JitterEnabled       bool              `json:"jitterEnabled"`
+	JitterMinutes       int               `json:"jitterMinutes"`
+	Status              string            `json:"status"`
+	CreatedAt           string            `json:"createdAt"`
+	UpdatedAt           string            `json:"updatedAt"`
+	Schedule            *Schedule         `json:"schedule"`
+	State               *State            `json:"state"`
+	Categories          []int64           `json:"categories"`
+	Topics              []int64           `json:"topics"`
+	FallbackProviderIDs []int64           `json:"fallbackProviderIds"`
+	GenerationParams    *GenerationParams `json:"generationParams,omitempty"`
+	// MisfirePolicy is skip, run_once or run_all
+	MisfirePolicy         string `json:"misfirePolicy"`
+	MisfireMaxRuns        int    `json:"misfireMaxRuns"`
+	MisfireSpacingMinutes int    `json:"misfireSpacingMinutes"`
+	TimeoutMinutes        int    `json:"timeoutMinutes"`
 }
 
 func NewJob(entity *entities.Job) *Job {
@@ -58,24 +65,30 @@ func (d *Job) ToEntity() (*entities.Job, error) {
 	}
 
 	return &entities.Job{
-		ID:                 d.ID,
-		Name:               d.Name,
-		SiteID:             d.SiteID,
-		PromptID:           d.PromptID,
-		AIProviderID:       d.AIProviderID,
-		PlaceholdersValues: d.PlaceholdersValues,
-		TopicStrategy:      entities.TopicStrategy(d.TopicStrategy),
-		CategoryStrategy:   entities.CategoryStrategy(d.CategoryStrategy),
-		RequiresValidation: d.RequiresValidation,
-		JitterEnabled:      d.JitterEnabled,
-		JitterMinutes:      d.JitterMinutes,
-		Status:             entities.JobStatus(d.Status),
-		CreatedAt:          createdAt,
-		UpdatedAt:          updatedAt,
-		Schedule:           schedule,
-		State:              state,
-		Categories:         d.Categories,
-		Topics:             d.Topics,
+		ID:                    d.ID,
+		Name:                  d.Name,
+		SiteID:                d.SiteID,
+		PromptID:              d.PromptID,
+		AIProviderID:          d.AIProviderID,
+		PlaceholdersValues:    d.PlaceholdersValues,
+		TopicStrategy:         entities.TopicStrategy(d.TopicStrategy),
+		CategoryStrategy:      entities.CategoryStrategy(d.CategoryStrategy),
+		RequiresValidation:    d.RequiresValidation,
+		JitterEnabled:         d.JitterEnabled,
+		JitterMinutes:         d.JitterMinutes,
+		Status:                entities.JobStatus(d.Status),
+		CreatedAt:             createdAt,
+		UpdatedAt:             updatedAt,
+		Schedule:              schedule,
+		State:                 state,
+		Categories:            d.Categories,
+		Topics:                d.Topics,
+		FallbackProviderIDs:   d.FallbackProviderIDs,
+		GenerationParams:      d.GenerationParams.ToEntity(),
+		MisfirePolicy:         entities.MisfirePolicy(d.MisfirePolicy),
+		MisfireMaxRuns:        d.MisfireMaxRuns,
+		MisfireSpacingMinutes: d.MisfireSpacingMinutes,
+		TimeoutMinutes:        d.TimeoutMinutes,
 	}, nil
 }
 
@@ -95,15 +108,22 @@ func (d *Job) FromEntity(entity *entities.Job) *Job {
 	d.CreatedAt = TimeToString(entity.CreatedAt)
 	d.UpdatedAt = TimeToString(entity.UpdatedAt)
 	d.Schedule = NewSchedule(entity.Schedule)
-	d.State = NewState(entity.State)
+	d.State = NewState(entity.State).withTimezone(entity.State, entity.Schedule)
 	d.Categories = entity.Categories
 	d.Topics = entity.Topics
+	d.FallbackProviderIDs = entity.FallbackProviderIDs
+	d.GenerationParams = NewGenerationParams(entity.GenerationParams)
+	d.MisfirePolicy = string(entity.MisfirePolicy)
+	d.MisfireMaxRuns = entity.MisfireMaxRuns
+	d.MisfireSpacingMinutes = entity.MisfireSpacingMinutes
+	d.TimeoutMinutes = entity.TimeoutMinutes
 	return d
 }
 
 type Schedule struct {
-	Type   string `json:"type"`
-	Config any    `json:"config"`
+	Type     string `json:"type"`
+	Config   any    `json:"config"`
+	Timezone string `json:"timezone"`
 }
 
 type OnceSchedule struct {
@@ -122,6 +142,10 @@ type DailySchedule struct {
 	Weekdays []int `json:"weekdays"`
 }
 
+type CronSchedule struct {
+	Expression string `json:"expression"`
+}
+
 func NewSchedule(entity *entities.Schedule) *Schedule {
 	if entity == nil {
 		return nil
@@ -142,8 +166,9 @@ func (d *Schedule) ToEntity() (*entities.Schedule, error) {
 	}
 
 	return &entities.Schedule{
-		Type:   entities.ScheduleType(d.Type),
-		Config: configJSON,
+		Type:     entities.ScheduleType(d.Type),
+		Config:   configJSON,
+		Timezone: d.Timezone,
 	}, nil
 }
 
@@ -153,6 +178,7 @@ func (d *Schedule) FromEntity(entity *entities.Schedule) *Schedule {
 	}
 
 	d.Type = string(entity.Type)
+	d.Timezone = entity.Timezone
 
 	if len(entity.Config) > 0 {
 		switch entities.ScheduleType(d.Type) {
@@ -171,6 +197,11 @@ func (d *Schedule) FromEntity(entity *entities.Schedule) *Schedule {
 			if err := json.Unmarshal(entity.Config, &config); err == nil {
 				d.Config = config
 			}
+		case entities.ScheduleCron:
+			var config CronSchedule
+			if err := json.Unmarshal(entity.Config, &config); err == nil {
+				d.Config = config
+			}
 		case entities.ScheduleManual:
 			d.Config = nil
 		}
@@ -180,13 +211,18 @@ func (d *Schedule) FromEntity(entity *entities.Schedule) *Schedule {
 }
 
 type State struct {
-	JobID             int64   `json:"jobId"`
-	LastRunAt         *string `json:"lastRunAt"`
-	NextRunAt         *string `json:"nextRunAt"`
-	NextRunBase       *string `json:"nextRunBase"`
+	JobID       int64   `json:"jobId"`
+	LastRunAt   *string `json:"lastRunAt"`
+	NextRunAt   *string `json:"nextRunAt"`
+	NextRunBase *string `json:"nextRunBase"`
+	// Timezone and NextRunInZone tell the wall clock time of the next run in the
+	// schedule's time zone, NextRunAt being the local time
+	Timezone          string  `json:"timezone"`
+	NextRunInZone     *string `json:"nextRunInZone"`
 	TotalExecutions   int     `json:"totalExecutions"`
 	FailedExecutions  int     `json:"failedExecutions"`
 	LastCategoryIndex int     `json:"lastCategoryIndex"`
+	CatchUpRuns       int     `json:"catchUpRuns"`
 }
 
 func NewState(entity *entities.State) *State {
@@ -223,6 +259,7 @@ func (d *State) ToEntity() *entities.State {
 		TotalExecutions:   d.TotalExecutions,
 		FailedExecutions:  d.FailedExecutions,
 		LastCategoryIndex: d.LastCategoryIndex,
+		CatchUpRuns:       d.CatchUpRuns,
 	}
 }
 
@@ -231,6 +268,7 @@ func (d *State) FromEntity(entity *entities.State) *State {
 	d.TotalExecutions = entity.TotalExecutions
 	d.FailedExecutions = entity.FailedExecutions
 	d.LastCategoryIndex = entity.LastCategoryIndex
+	d.CatchUpRuns = entity.CatchUpRuns
 
 	if entity.LastRunAt != nil {
 		lastRunAt := TimeToString(*entity.LastRunAt)
@@ -255,3 +293,42 @@ func (d *State) FromEntity(entity *entities.State) *State {
 
 	return d
 }
+
+func (d *State) withTimezone(state *entities.State, schedule *entities.Schedule) *State {
+	if d == nil || schedule == nil {
+		return d
+	}
+
+	loc, err := schedule.Location()
+	if err != nil {
+		return d
+	}
+	d.Timezone = loc.String()
+
+	if state.NextRunAt != nil && !state.NextRunAt.IsZero() {
+		nextRunInZone := TimeToString(state.NextRunAt.In(loc))
+		d.NextRunInZone = &nextRunInZone
+	}
+
+	return d
+}
+
+type MissedRun struct {
+	ID          int64  `json:"id"`
+	JobID       int64  `json:"jobId"`
+	ScheduledAt string `json:"scheduledAt"`
+	Policy      string `json:"policy"`
+	Outcome     string `json:"outcome"`
+	DetectedAt  string `json:"detectedAt"`
+}
+
+func NewMissedRun(entity *entities.MissedRun) *MissedRun {
+	return &MissedRun{
+		ID:          entity.ID,
+		JobID:       entity.JobID,
+		ScheduledAt: TimeToString(entity.ScheduledAt),
+		Policy:      string(entity.Policy),
+		Outcome:     string(entity.Outcome),
+		DetectedAt:  TimeToString(entity.DetectedAt),
+	}
+}
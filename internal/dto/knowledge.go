@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/davidmovas/postulator/internal/domain/entities"
+
+type KnowledgeDocument struct {
+	ID         int64  `json:"id"`
+	SiteID     int64  `json:"siteId"`
+	JobID      *int64 `json:"jobId,omitempty"`
+	Name       string `json:"name"`
+	FileType   string `json:"fileType"`
+	SizeBytes  int64  `json:"sizeBytes"`
+	ChunkCount int    `json:"chunkCount"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+func NewKnowledgeDocument(entity *entities.KnowledgeDocument) *KnowledgeDocument {
+	return &KnowledgeDocument{
+		ID:         entity.ID,
+		SiteID:     entity.SiteID,
+		JobID:      entity.JobID,
+		Name:       entity.Name,
+		FileType:   entity.FileType,
+		SizeBytes:  entity.SizeBytes,
+		ChunkCount: entity.ChunkCount,
+		CreatedAt:  TimeToString(entity.CreatedAt),
+	}
+}
@@ -58,6 +58,51 @@ func NewContextFieldDefinition(def *entities.ContextFieldDefinition) *ContextFie
 	}
 }
 
+// GenerationParams holds optional sampling settings, nil fields keep the provider defaults.
+// Mode is "single" (default) or "outline" for outline-then-sections generation.
+// FeaturedImage generates a featured image for the article.
+type GenerationParams struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
+	Seed            *int64   `json:"seed,omitempty"`
+	Stop            []string `json:"stop,omitempty"`
+	Mode            string   `json:"mode,omitempty"`
+	FeaturedImage   *bool    `json:"featuredImage,omitempty"`
+}
+
+func NewGenerationParams(entity *entities.GenerationParams) *GenerationParams {
+	if entity == nil {
+		return nil
+	}
+
+	return &GenerationParams{
+		Temperature:     entity.Temperature,
+		TopP:            entity.TopP,
+		MaxOutputTokens: entity.MaxOutputTokens,
+		Seed:            entity.Seed,
+		Stop:            entity.Stop,
+		Mode:            string(entity.Mode),
+		FeaturedImage:   entity.FeaturedImage,
+	}
+}
+
+func (d *GenerationParams) ToEntity() *entities.GenerationParams {
+	if d == nil {
+		return nil
+	}
+
+	return &entities.GenerationParams{
+		Temperature:     d.Temperature,
+		TopP:            d.TopP,
+		MaxOutputTokens: d.MaxOutputTokens,
+		Seed:            d.Seed,
+		Stop:            d.Stop,
+		Mode:            entities.GenerationMode(d.Mode),
+		FeaturedImage:   d.FeaturedImage,
+	}
+}
+
 // Prompt represents a prompt DTO with support for both v1 and v2 formats
 type Prompt struct {
 	ID            int64         `json:"id"`
@@ -67,6 +112,8 @@ type Prompt struct {
 	Version       int           `json:"version"`
 	Instructions  string        `json:"instructions,omitempty"`
 	ContextConfig ContextConfig `json:"contextConfig,omitempty"`
+	// GenerationParams are the default sampling settings for this prompt (optional)
+	GenerationParams *GenerationParams `json:"generationParams,omitempty"`
 	// Legacy fields (v1 format)
 	SystemPrompt string   `json:"systemPrompt,omitempty"`
 	UserPrompt   string   `json:"userPrompt,omitempty"`
@@ -104,18 +151,19 @@ func (d *Prompt) ToEntity() (*entities.Prompt, error) {
 	}
 
 	return &entities.Prompt{
-		ID:            d.ID,
-		Name:          d.Name,
-		Category:      entities.PromptCategory(d.Category),
-		IsBuiltin:     d.IsBuiltin,
-		Version:       d.Version,
-		Instructions:  d.Instructions,
-		ContextConfig: contextConfig,
-		SystemPrompt:  d.SystemPrompt,
-		UserPrompt:    d.UserPrompt,
-		Placeholders:  d.Placeholders,
-		CreatedAt:     createdAt,
-		UpdatedAt:     updatedAt,
+		ID:               d.ID,
+		Name:             d.Name,
+		Category:         entities.PromptCategory(d.Category),
+		IsBuiltin:        d.IsBuiltin,
+		Version:          d.Version,
+		Instructions:     d.Instructions,
+		ContextConfig:    contextConfig,
+		GenerationParams: d.GenerationParams.ToEntity(),
+		SystemPrompt:     d.SystemPrompt,
+		UserPrompt:       d.UserPrompt,
+		Placeholders:     d.Placeholders,
+		CreatedAt:        createdAt,
+		UpdatedAt:        updatedAt,
 	}, nil
 }
 
@@ -129,6 +177,7 @@ func (d *Prompt) FromEntity(entity *entities.Prompt) *Prompt {
 	d.SystemPrompt = entity.SystemPrompt
 	d.UserPrompt = entity.UserPrompt
 	d.Placeholders = entity.Placeholders
+	d.GenerationParams = NewGenerationParams(entity.GenerationParams)
 	d.CreatedAt = TimeToString(entity.CreatedAt)
 	d.UpdatedAt = TimeToString(entity.UpdatedAt)
 
@@ -1,16 +1,24 @@
 package dto
 
-import "github.com/davidmovas/postulator/internal/domain/entities"
+import (
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/internal/infra/ai"
+)
 
 type Provider struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	Type      string `json:"type"`
-	APIKey    string `json:"apiKey"`
-	Model     string `json:"model"`
-	IsActive  bool   `json:"isActive"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	APIKey         string `json:"apiKey"`
+	Model          string `json:"model"`
+	BaseURL        string `json:"baseUrl"`
+	EmbeddingModel string `json:"embeddingModel"`
+	// KeyStats describe the provider's own API key, Keys the additional keys of its pool
+	KeyStats  *KeyStats      `json:"keyStats,omitempty"`
+	Keys      []*ProviderKey `json:"keys,omitempty"`
+	IsActive  bool           `json:"isActive"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
 }
 
 func NewProvider(entity *entities.Provider) *Provider {
@@ -30,14 +38,16 @@ func (d *Provider) ToEntity() (*entities.Provider, error) {
 	}
 
 	return &entities.Provider{
-		ID:        d.ID,
-		Name:      d.Name,
-		Type:      entities.Type(d.Type),
-		APIKey:    d.APIKey,
-		Model:     d.Model,
-		IsActive:  d.IsActive,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+		ID:             d.ID,
+		Name:           d.Name,
+		Type:           entities.Type(d.Type),
+		APIKey:         d.APIKey,
+		Model:          d.Model,
+		BaseURL:        d.BaseURL,
+		EmbeddingModel: d.EmbeddingModel,
+		IsActive:       d.IsActive,
+		CreatedAt:      createdAt,
+		UpdatedAt:      updatedAt,
 	}, nil
 }
 
@@ -47,7 +57,84 @@ func (d *Provider) FromEntity(entity *entities.Provider) *Provider {
 	d.Type = string(entity.Type)
 	d.APIKey = entity.APIKey
 	d.Model = entity.Model
+	d.BaseURL = entity.BaseURL
+	d.EmbeddingModel = entity.EmbeddingModel
+	if entity.APIKey != "" {
+		d.KeyStats = NewKeyStats(entity.APIKey)
+	}
+	d.Keys = nil
+	for _, key := range entity.Keys {
+		d.Keys = append(d.Keys, NewProviderKey(key))
+	}
+	d.IsActive = entity.IsActive
+	d.CreatedAt = TimeToString(entity.CreatedAt)
+	d.UpdatedAt = TimeToString(entity.UpdatedAt)
+	return d
+}
+
+// KeyStats are the usage counters and cool-down state of an API key since the app started
+type KeyStats struct {
+	Fingerprint   string  `json:"fingerprint"`
+	Requests      int64   `json:"requests"`
+	Failures      int64   `json:"failures"`
+	LastUsedAt    *string `json:"lastUsedAt,omitempty"`
+	CooldownUntil *string `json:"cooldownUntil,omitempty"`
+	LastError     string  `json:"lastError,omitempty"`
+}
+
+func NewKeyStats(apiKey string) *KeyStats {
+	stats := ai.DefaultKeyPool().Stats(ai.KeyFingerprint(apiKey))
+
+	d := &KeyStats{
+		Fingerprint: stats.Fingerprint,
+		Requests:    stats.Requests,
+		Failures:    stats.Failures,
+		LastError:   stats.LastError,
+	}
+	if stats.LastUsedAt != nil {
+		lastUsedAt := TimeToString(*stats.LastUsedAt)
+		d.LastUsedAt = &lastUsedAt
+	}
+	if stats.CooldownUntil != nil {
+		cooldownUntil := TimeToString(*stats.CooldownUntil)
+		d.CooldownUntil = &cooldownUntil
+	}
+	return d
+}
+
+type ProviderKey struct {
+	ID         int64     `json:"id"`
+	ProviderID int64     `json:"providerId"`
+	Label      string    `json:"label"`
+	APIKey     string    `json:"apiKey"`
+	IsActive   bool      `json:"isActive"`
+	Stats      *KeyStats `json:"stats,omitempty"`
+	CreatedAt  string    `json:"createdAt"`
+	UpdatedAt  string    `json:"updatedAt"`
+}
+
+func NewProviderKey(entity *entities.ProviderKey) *ProviderKey {
+	k := &ProviderKey{}
+	return k.FromEntity(entity)
+}
+
+func (d *ProviderKey) ToEntity() *entities.ProviderKey {
+	return &entities.ProviderKey{
+		ID:         d.ID,
+		ProviderID: d.ProviderID,
+		Label:      d.Label,
+		APIKey:     d.APIKey,
+		IsActive:   d.IsActive,
+	}
+}
+
+func (d *ProviderKey) FromEntity(entity *entities.ProviderKey) *ProviderKey {
+	d.ID = entity.ID
+	d.ProviderID = entity.ProviderID
+	d.Label = entity.Label
+	d.APIKey = entity.APIKey
 	d.IsActive = entity.IsActive
+	d.Stats = NewKeyStats(entity.APIKey)
 	d.CreatedAt = TimeToString(entity.CreatedAt)
 	d.UpdatedAt = TimeToString(entity.UpdatedAt)
 	return d
@@ -64,6 +151,9 @@ type Model struct {
 	RPM                  int     `json:"rpm"`
 	TPM                  int     `json:"tpm"`
 	UsesCompletionTokens bool    `json:"usesCompletionTokens"`
+	IsReasoningModel     bool    `json:"isReasoningModel"`
+	IsBuiltin            bool    `json:"isBuiltin"`
+	IsUserDefined        bool    `json:"isUserDefined"`
 }
 
 func NewModel(entity *entities.Model) *Model {
@@ -83,6 +173,7 @@ func (d *Model) ToEntity() *entities.Model {
 		RPM:                  d.RPM,
 		TPM:                  d.TPM,
 		UsesCompletionTokens: d.UsesCompletionTokens,
+		IsReasoningModel:     d.IsReasoningModel,
 	}
 }
 
@@ -97,5 +188,8 @@ func (d *Model) FromEntity(entity *entities.Model) *Model {
 	d.RPM = entity.RPM
 	d.TPM = entity.TPM
 	d.UsesCompletionTokens = entity.UsesCompletionTokens
+	d.IsReasoningModel = entity.IsReasoningModel
+	d.IsBuiltin = entity.IsBuiltin
+	d.IsUserDefined = entity.IsUserDefined
 	return d
 }
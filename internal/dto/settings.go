@@ -40,6 +40,47 @@ func (s *HealthCheckSettings) FromEntity(e *entities.HealthCheckSettings) *Healt
 	return s
 }
 
+type ProviderHealthSettings struct {
+	Enabled         bool `json:"enabled"`
+	IntervalMinutes int  `json:"interval_minutes"`
+	NotifyWithSound bool `json:"notify_with_sound"`
+}
+
+func NewProviderHealthSettings(e *entities.ProviderHealthSettings) *ProviderHealthSettings {
+	return &ProviderHealthSettings{
+		Enabled:         e.Enabled,
+		IntervalMinutes: e.IntervalMinutes,
+		NotifyWithSound: e.NotifyWithSound,
+	}
+}
+
+func (s *ProviderHealthSettings) ToEntity() *entities.ProviderHealthSettings {
+	return &entities.ProviderHealthSettings{
+		Enabled:         s.Enabled,
+		IntervalMinutes: s.IntervalMinutes,
+		NotifyWithSound: s.NotifyWithSound,
+	}
+}
+
+type SchedulerSettings struct {
+	MaxConcurrentJobs    int `json:"max_concurrent_jobs"`
+	MaxConcurrentPerSite int `json:"max_concurrent_per_site"`
+}
+
+func NewSchedulerSettings(e *entities.SchedulerSettings) *SchedulerSettings {
+	return &SchedulerSettings{
+		MaxConcurrentJobs:    e.MaxConcurrentJobs,
+		MaxConcurrentPerSite: e.MaxConcurrentPerSite,
+	}
+}
+
+func (s *SchedulerSettings) ToEntity() *entities.SchedulerSettings {
+	return &entities.SchedulerSettings{
+		MaxConcurrentJobs:    s.MaxConcurrentJobs,
+		MaxConcurrentPerSite: s.MaxConcurrentPerSite,
+	}
+}
+
 type ProxyNode struct {
 	ID       string `json:"id"`
 	Type     string `json:"type"`
@@ -514,6 +514,8 @@ type ContentSettingsDTO struct {
 	MaxOutgoingLinks        int    `json:"maxOutgoingLinks,omitempty"`        // Max outgoing links per page
 	// Context overrides from UI - allows enabling/disabling specific context fields
 	ContextOverrides map[string]ContextFieldValue `json:"contextOverrides,omitempty"`
+	// Sampling overrides on top of the prompt's generation params
+	GenerationParams *GenerationParams `json:"generationParams,omitempty"`
 }
 
 // LinkTargetDTO represents a target page for internal linking during generation
@@ -525,14 +527,15 @@ type LinkTargetDTO struct {
 }
 
 type StartPageGenerationRequest struct {
-	SitemapID       int64               `json:"sitemapId"`
-	NodeIDs         []int64             `json:"nodeIds,omitempty"`
-	ProviderID      int64               `json:"providerId"`
-	PromptID        *int64              `json:"promptId,omitempty"`
-	PublishAs       string              `json:"publishAs"`
-	Placeholders    map[string]string   `json:"placeholders,omitempty"`
-	MaxConcurrency  int                 `json:"maxConcurrency,omitempty"`
-	ContentSettings *ContentSettingsDTO `json:"contentSettings,omitempty"`
+	SitemapID           int64               `json:"sitemapId"`
+	NodeIDs             []int64             `json:"nodeIds,omitempty"`
+	ProviderID          int64               `json:"providerId"`
+	FallbackProviderIDs []int64             `json:"fallbackProviderIds,omitempty"`
+	PromptID            *int64              `json:"promptId,omitempty"`
+	PublishAs           string              `json:"publishAs"`
+	Placeholders        map[string]string   `json:"placeholders,omitempty"`
+	MaxConcurrency      int                 `json:"maxConcurrency,omitempty"`
+	ContentSettings     *ContentSettingsDTO `json:"contentSettings,omitempty"`
 }
 
 type GenerationTaskResponse struct {
@@ -263,3 +263,64 @@ func (h *ArticlesHandler) GenerateContent(input *dto.GenerateContentInput) *dto.
 		TopicID:         result.TopicID,
 	})
 }
+
+func (h *ArticlesHandler) RewriteArticle(input *dto.RewriteArticleInput) *dto.Response[*dto.GenerateContentResult] {
+	domainInput := &articles.RewriteArticleInput{
+		ArticleID:         input.ArticleID,
+		ProviderID:        input.ProviderID,
+		PromptID:          input.PromptID,
+		Instructions:      input.Instructions,
+		PlaceholderValues: input.PlaceholderValues,
+	}
+
+	result, err := h.service.RewriteArticle(ctx.LongCtx(), domainInput)
+	if err != nil {
+		return fail[*dto.GenerateContentResult](err)
+	}
+
+	return ok(&dto.GenerateContentResult{
+		Title:           result.Title,
+		Content:         result.Content,
+		Excerpt:         result.Excerpt,
+		MetaDescription: result.MetaDescription,
+		TopicID:         result.TopicID,
+	})
+}
+
+func (h *ArticlesHandler) TranslateArticle(input *dto.TranslateArticleInput) *dto.Response[[]*dto.Article] {
+	domainInput := &articles.TranslateArticleInput{
+		ArticleID:         input.ArticleID,
+		SitemapNodeID:     input.SitemapNodeID,
+		ProviderID:        input.ProviderID,
+		PromptID:          input.PromptID,
+		SourceLanguage:    input.SourceLanguage,
+		Languages:         input.Languages,
+		PlaceholderValues: input.PlaceholderValues,
+	}
+
+	translations, err := h.service.TranslateArticle(ctx.LongCtx(), domainInput)
+	if err != nil {
+		return fail[[]*dto.Article](err)
+	}
+
+	var dtoArticles []*dto.Article
+	for _, article := range translations {
+		dtoArticles = append(dtoArticles, dto.NewArticle(article))
+	}
+
+	return ok(dtoArticles)
+}
+
+func (h *ArticlesHandler) ListTranslations(id int64) *dto.Response[[]*dto.Article] {
+	translations, err := h.service.ListTranslations(ctx.FastCtx(), id)
+	if err != nil {
+		return fail[[]*dto.Article](err)
+	}
+
+	var dtoArticles []*dto.Article
+	for _, article := range translations {
+		dtoArticles = append(dtoArticles, dto.NewArticle(article))
+	}
+
+	return ok(dtoArticles)
+}
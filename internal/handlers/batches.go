@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"github.com/davidmovas/postulator/internal/domain/batches"
+	"github.com/davidmovas/postulator/internal/dto"
+	"github.com/davidmovas/postulator/pkg/ctx"
+)
+
+type BatchesHandler struct {
+	service batches.Service
+}
+
+func NewBatchesHandler(service batches.Service) *BatchesHandler {
+	return &BatchesHandler{
+		service: service,
+	}
+}
+
+func (h *BatchesHandler) ListBatches(siteID int64) *dto.Response[[]*dto.Batch] {
+	listBatches, err := h.service.ListBatches(ctx.FastCtx(), siteID)
+	if err != nil {
+		return fail[[]*dto.Batch](err)
+	}
+
+	var dtoBatches []*dto.Batch
+	for _, batch := range listBatches {
+		dtoBatches = append(dtoBatches, dto.NewBatch(batch))
+	}
+
+	return ok(dtoBatches)
+}
+
+func (h *BatchesHandler) GetBatch(id int64) *dto.Response[*dto.Batch] {
+	batch, err := h.service.GetBatch(ctx.FastCtx(), id)
+	if err != nil {
+		return fail[*dto.Batch](err)
+	}
+
+	return ok(dto.NewBatch(batch))
+}
+
+func (h *BatchesHandler) GetBatchItems(id int64) *dto.Response[[]*dto.BatchItem] {
+	items, err := h.service.GetBatchItems(ctx.FastCtx(), id)
+	if err != nil {
+		return fail[[]*dto.BatchItem](err)
+	}
+
+	var dtoItems []*dto.BatchItem
+	for _, item := range items {
+		dtoItems = append(dtoItems, dto.NewBatchItem(item))
+	}
+
+	return ok(dtoItems)
+}
+
+func (h *BatchesHandler) CancelBatch(id int64) *dto.Response[string] {
+	if err := h.service.CancelBatch(ctx.MediumCtx(), id); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("Batch cancellation requested")
+}
+
+// PollBatches checks the submitted batches now instead of waiting for the next poll
+func (h *BatchesHandler) PollBatches() *dto.Response[string] {
+	if err := h.service.Poll(ctx.LongCtx()); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("Batches checked")
+}
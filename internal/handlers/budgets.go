@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/davidmovas/postulator/internal/domain/budgets"
+	"github.com/davidmovas/postulator/internal/dto"
+	"github.com/davidmovas/postulator/pkg/ctx"
+)
+
+type BudgetsHandler struct {
+	service budgets.Service
+}
+
+func NewBudgetsHandler(service budgets.Service) *BudgetsHandler {
+	return &BudgetsHandler{
+		service: service,
+	}
+}
+
+func (h *BudgetsHandler) CreateBudget(budget *dto.Budget) *dto.Response[*dto.Budget] {
+	entity, err := budget.ToEntity()
+	if err != nil {
+		return fail[*dto.Budget](err)
+	}
+
+	if err = h.service.CreateBudget(ctx.FastCtx(), entity); err != nil {
+		return fail[*dto.Budget](err)
+	}
+
+	return ok(dto.NewBudget(entity))
+}
+
+func (h *BudgetsHandler) GetBudget(id int64) *dto.Response[*dto.Budget] {
+	budget, err := h.service.GetBudget(ctx.FastCtx(), id)
+	if err != nil {
+		return fail[*dto.Budget](err)
+	}
+
+	return ok(dto.NewBudget(budget))
+}
+
+func (h *BudgetsHandler) ListBudgets() *dto.Response[[]*dto.Budget] {
+	listBudgets, err := h.service.ListBudgets(ctx.FastCtx())
+	if err != nil {
+		return fail[[]*dto.Budget](err)
+	}
+
+	var dtoBudgets []*dto.Budget
+	for _, budget := range listBudgets {
+		dtoBudgets = append(dtoBudgets, dto.NewBudget(budget))
+	}
+
+	return ok(dtoBudgets)
+}
+
+func (h *BudgetsHandler) UpdateBudget(budget *dto.Budget) *dto.Response[string] {
+	entity, err := budget.ToEntity()
+	if err != nil {
+		return fail[string](err)
+	}
+
+	if err = h.service.UpdateBudget(ctx.FastCtx(), entity); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("Budget updated successfully")
+}
+
+func (h *BudgetsHandler) DeleteBudget(id int64) *dto.Response[string] {
+	if err := h.service.DeleteBudget(ctx.FastCtx(), id); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("Budget deleted successfully")
+}
+
+func (h *BudgetsHandler) GetBudgetStatuses() *dto.Response[[]*dto.BudgetStatus] {
+	statuses, err := h.service.GetBudgetStatuses(ctx.FastCtx())
+	if err != nil {
+		return fail[[]*dto.BudgetStatus](err)
+	}
+
+	var dtoStatuses []*dto.BudgetStatus
+	for _, status := range statuses {
+		dtoStatuses = append(dtoStatuses, dto.NewBudgetStatus(status))
+	}
+
+	return ok(dtoStatuses)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"github.com/davidmovas/postulator/internal/domain/embeddings"
+	"github.com/davidmovas/postulator/internal/dto"
+	"github.com/davidmovas/postulator/pkg/ctx"
+)
+
+type EmbeddingsHandler struct {
+	service embeddings.Service
+}
+
+func NewEmbeddingsHandler(service embeddings.Service) *EmbeddingsHandler {
+	return &EmbeddingsHandler{
+		service: service,
+	}
+}
+
+func (h *EmbeddingsHandler) FindDuplicateTopics(siteID, providerID int64, threshold float64) *dto.Response[[]*dto.DuplicateTopics] {
+	duplicates, err := h.service.FindDuplicateTopics(ctx.LongCtx(), siteID, providerID, threshold)
+	if err != nil {
+		return fail[[]*dto.DuplicateTopics](err)
+	}
+
+	var result []*dto.DuplicateTopics
+	for _, pair := range duplicates {
+		result = append(result, dto.NewDuplicateTopics(pair))
+	}
+
+	return ok(result)
+}
+
+func (h *EmbeddingsHandler) FindRelatedArticles(providerID, articleID int64, limit int) *dto.Response[[]*dto.RelatedArticle] {
+	related, err := h.service.FindRelatedArticles(ctx.LongCtx(), providerID, articleID, limit)
+	if err != nil {
+		return fail[[]*dto.RelatedArticle](err)
+	}
+
+	var result []*dto.RelatedArticle
+	for _, article := range related {
+		result = append(result, dto.NewRelatedArticle(article))
+	}
+
+	return ok(result)
+}
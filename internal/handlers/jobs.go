@@ -94,5 +94,64 @@ func (h *JobsHandler) ExecuteManually(jobID int64) *dto.Response[string] {
 		return fail[string](err)
 	}
 
-	return ok("Job execution completed")
+	return ok("Job execution started")
+}
+
+func (h *JobsHandler) CancelExecution(jobID int64) *dto.Response[string] {
+	if err := h.service.CancelExecution(ctx.FastCtx(), jobID); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("Job execution cancelled")
+}
+
+func (h *JobsHandler) EstimateJob(jobID int64) *dto.Response[*dto.CostEstimate] {
+	estimate, err := h.service.EstimateJob(ctx.MediumCtx(), jobID)
+	if err != nil {
+		return fail[*dto.CostEstimate](err)
+	}
+
+	return ok(dto.NewCostEstimate(estimate))
+}
+
+func (h *JobsHandler) BackfillJob(jobID int64) *dto.Response[*dto.Batch] {
+	batch, err := h.service.BackfillJob(ctx.LongCtx(), jobID)
+	if err != nil {
+		return fail[*dto.Batch](err)
+	}
+
+	return ok(dto.NewBatch(batch))
+}
+
+func (h *JobsHandler) PreviewSchedule(schedule *dto.Schedule, count int) *dto.Response[[]string] {
+	entity, err := schedule.ToEntity()
+	if err != nil {
+		return fail[[]string](err)
+	}
+
+	runs, err := h.service.PreviewSchedule(ctx.FastCtx(), entity, count)
+	if err != nil {
+		return fail[[]string](err)
+	}
+
+	times := make([]string, 0, len(runs))
+	for _, run := range runs {
+		times = append(times, dto.TimeToString(run))
+	}
+
+	return ok(times)
+}
+
+func (h *JobsHandler) GetMissedRuns(jobID int64, limit int) *dto.Response[[]*dto.MissedRun] {
+	runs, err := h.service.GetMissedRuns(ctx.FastCtx(), jobID, limit)
+	if err != nil {
+		return fail[[]*dto.MissedRun](err)
+	}
+
+	dtoRuns := make([]*dto.MissedRun, 0, len(runs))
+	for _, run := range runs {
+		dtoRuns = append(dtoRuns, dto.NewMissedRun(run))
+	}
+
+	return ok(dtoRuns)
 }
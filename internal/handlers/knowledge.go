@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/davidmovas/postulator/internal/domain/knowledge"
+	"github.com/davidmovas/postulator/internal/dto"
+	"github.com/davidmovas/postulator/pkg/ctx"
+)
+
+type KnowledgeHandler struct {
+	service knowledge.Service
+}
+
+func NewKnowledgeHandler(service knowledge.Service) *KnowledgeHandler {
+	return &KnowledgeHandler{
+		service: service,
+	}
+}
+
+// AddKnowledgeDocument indexes a local file for the site. A jobID of 0 makes the
+// document available to every job and sitemap of the site.
+func (h *KnowledgeHandler) AddKnowledgeDocument(siteID, jobID int64, filePath string) *dto.Response[*dto.KnowledgeDocument] {
+	var jobScope *int64
+	if jobID > 0 {
+		jobScope = &jobID
+	}
+
+	doc, err := h.service.AddDocument(ctx.LongCtx(), siteID, jobScope, filePath)
+	if err != nil {
+		return fail[*dto.KnowledgeDocument](err)
+	}
+
+	return ok(dto.NewKnowledgeDocument(doc))
+}
+
+func (h *KnowledgeHandler) ListKnowledgeDocuments(siteID int64) *dto.Response[[]*dto.KnowledgeDocument] {
+	docs, err := h.service.ListDocuments(ctx.FastCtx(), siteID)
+	if err != nil {
+		return fail[[]*dto.KnowledgeDocument](err)
+	}
+
+	result := make([]*dto.KnowledgeDocument, 0, len(docs))
+	for _, doc := range docs {
+		result = append(result, dto.NewKnowledgeDocument(doc))
+	}
+
+	return ok(result)
+}
+
+func (h *KnowledgeHandler) DeleteKnowledgeDocument(id int64) *dto.Response[string] {
+	if err := h.service.DeleteDocument(ctx.FastCtx(), id); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("Document deleted successfully")
+}
@@ -22,5 +22,10 @@ var Module = fx.Module("handlers",
 		NewSitemapsHandler,
 		NewAIUsageHandler,
 		NewLinkingHandler,
+		NewBudgetsHandler,
+		NewEmbeddingsHandler,
+		NewBatchesHandler,
+		NewKnowledgeHandler,
+		NewProviderHealthHandler,
 	),
 )
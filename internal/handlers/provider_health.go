@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/davidmovas/postulator/internal/domain/providerhealth"
+	"github.com/davidmovas/postulator/internal/dto"
+	"github.com/davidmovas/postulator/pkg/ctx"
+)
+
+type ProviderHealthHandler struct {
+	service providerhealth.Service
+}
+
+func NewProviderHealthHandler(service providerhealth.Service) *ProviderHealthHandler {
+	return &ProviderHealthHandler{service: service}
+}
+
+func (h *ProviderHealthHandler) CheckProviderHealth(providerID int64) *dto.Response[[]*dto.ProviderHealthCheck] {
+	checks, err := h.service.CheckProviderByID(ctx.LongCtx(), providerID)
+	if err != nil {
+		return fail[[]*dto.ProviderHealthCheck](err)
+	}
+	return ok(dto.NewProviderHealthCheckList(checks))
+}
+
+// GetProviderHealthStatus returns the latest check of every key of every provider
+func (h *ProviderHealthHandler) GetProviderHealthStatus() *dto.Response[[]*dto.ProviderHealthCheck] {
+	checks, err := h.service.GetLatestChecks(ctx.FastCtx())
+	if err != nil {
+		return fail[[]*dto.ProviderHealthCheck](err)
+	}
+	return ok(dto.NewProviderHealthCheckList(checks))
+}
+
+func (h *ProviderHealthHandler) GetProviderHealthHistory(providerID int64, limit int) *dto.Response[[]*dto.ProviderHealthCheck] {
+	checks, err := h.service.GetProviderHistory(ctx.FastCtx(), providerID, limit)
+	if err != nil {
+		return fail[[]*dto.ProviderHealthCheck](err)
+	}
+	return ok(dto.NewProviderHealthCheckList(checks))
+}
@@ -96,6 +96,45 @@ func (h *ProvidersHandler) SetProviderStatus(id int64, isActive bool) *dto.Respo
 	return ok("Provider status updated successfully")
 }
 
+func (h *ProvidersHandler) AddProviderKey(key *dto.ProviderKey) *dto.Response[*dto.ProviderKey] {
+	entity := key.ToEntity()
+	if err := h.service.AddProviderKey(ctx.FastCtx(), entity); err != nil {
+		return fail[*dto.ProviderKey](err)
+	}
+
+	return ok(dto.NewProviderKey(entity))
+}
+
+func (h *ProvidersHandler) ListProviderKeys(providerID int64) *dto.Response[[]*dto.ProviderKey] {
+	keys, err := h.service.ListProviderKeys(ctx.FastCtx(), providerID)
+	if err != nil {
+		return fail[[]*dto.ProviderKey](err)
+	}
+
+	var dtoKeys []*dto.ProviderKey
+	for _, key := range keys {
+		dtoKeys = append(dtoKeys, dto.NewProviderKey(key))
+	}
+
+	return ok(dtoKeys)
+}
+
+func (h *ProvidersHandler) SetProviderKeyStatus(id int64, isActive bool) *dto.Response[string] {
+	if err := h.service.SetProviderKeyStatus(ctx.FastCtx(), id, isActive); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("API key status updated successfully")
+}
+
+func (h *ProvidersHandler) DeleteProviderKey(id int64) *dto.Response[string] {
+	if err := h.service.DeleteProviderKey(ctx.FastCtx(), id); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("API key deleted successfully")
+}
+
 func (h *ProvidersHandler) GetAvailableModels(providerType string) *dto.Response[[]*dto.Model] {
 	models, err := h.service.GetAvailableModels(entities.Type(providerType))
 	if err != nil {
@@ -117,3 +156,19 @@ func (h *ProvidersHandler) ValidateModel(providerType, model string) *dto.Respon
 
 	return ok("Model is valid")
 }
+
+func (h *ProvidersHandler) SaveModel(model *dto.Model) *dto.Response[string] {
+	if err := h.service.SaveModel(ctx.FastCtx(), model.ToEntity()); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("Model saved successfully")
+}
+
+func (h *ProvidersHandler) DeleteModel(providerType, modelID string) *dto.Response[string] {
+	if err := h.service.DeleteModel(ctx.FastCtx(), entities.Type(providerType), modelID); err != nil {
+		return fail[string](err)
+	}
+
+	return ok("Model deleted successfully")
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"github.com/davidmovas/postulator/internal/domain/healthcheck"
+	"github.com/davidmovas/postulator/internal/domain/jobs"
+	"github.com/davidmovas/postulator/internal/domain/providerhealth"
 	"github.com/davidmovas/postulator/internal/domain/settings"
 	"github.com/davidmovas/postulator/internal/dto"
 	"github.com/davidmovas/postulator/internal/version"
@@ -9,14 +11,23 @@ import (
 )
 
 type SettingsHandler struct {
-	service   settings.Service
-	scheduler healthcheck.Scheduler
+	service                 settings.Service
+	scheduler               healthcheck.Scheduler
+	providerHealthScheduler providerhealth.Scheduler
+	jobScheduler            jobs.Scheduler
 }
 
-func NewSettingsHandler(service settings.Service, scheduler healthcheck.Scheduler) *SettingsHandler {
+func NewSettingsHandler(
+	service settings.Service,
+	scheduler healthcheck.Scheduler,
+	providerHealthScheduler providerhealth.Scheduler,
+	jobScheduler jobs.Scheduler,
+) *SettingsHandler {
 	return &SettingsHandler{
-		service:   service,
-		scheduler: scheduler,
+		service:                 service,
+		scheduler:               scheduler,
+		providerHealthScheduler: providerHealthScheduler,
+		jobScheduler:            jobScheduler,
 	}
 }
 
@@ -44,6 +55,48 @@ func (h *SettingsHandler) UpdateHealthCheckSettings(settings *dto.HealthCheckSet
 	return ok("Settings updated successfully")
 }
 
+func (h *SettingsHandler) GetProviderHealthSettings() *dto.Response[*dto.ProviderHealthSettings] {
+	s, err := h.service.GetProviderHealthSettings(ctx.FastCtx())
+	if err != nil {
+		return fail[*dto.ProviderHealthSettings](err)
+	}
+
+	return ok(dto.NewProviderHealthSettings(s))
+}
+
+func (h *SettingsHandler) UpdateProviderHealthSettings(settings *dto.ProviderHealthSettings) *dto.Response[string] {
+	entity := settings.ToEntity()
+
+	if err := h.service.UpdateProviderHealthSettings(ctx.FastCtx(), entity); err != nil {
+		return fail[string](err)
+	}
+
+	_ = h.providerHealthScheduler.ApplySettings(ctx.FastCtx(), entity.Enabled, entity.IntervalMinutes)
+
+	return ok("Provider health settings updated successfully")
+}
+
+func (h *SettingsHandler) GetSchedulerSettings() *dto.Response[*dto.SchedulerSettings] {
+	s, err := h.service.GetSchedulerSettings(ctx.FastCtx())
+	if err != nil {
+		return fail[*dto.SchedulerSettings](err)
+	}
+
+	return ok(dto.NewSchedulerSettings(s))
+}
+
+func (h *SettingsHandler) UpdateSchedulerSettings(settings *dto.SchedulerSettings) *dto.Response[string] {
+	entity := settings.ToEntity()
+
+	if err := h.service.UpdateSchedulerSettings(ctx.FastCtx(), entity); err != nil {
+		return fail[string](err)
+	}
+
+	h.jobScheduler.ApplyLimits(entity.MaxConcurrentJobs, entity.MaxConcurrentPerSite)
+
+	return ok("Scheduler settings updated successfully")
+}
+
 func (h *SettingsHandler) GetAppVersion() *dto.Response[*dto.AppVersion] {
 	info := version.GetInfo()
 	return ok(&dto.AppVersion{
@@ -870,20 +870,63 @@ func (h *SitemapsHandler) CancelSitemapGeneration() *dto.Response[string] {
 // =========================================================================
 
 func (h *SitemapsHandler) StartPageGeneration(req *dto.StartPageGenerationRequest) *dto.Response[*dto.GenerationTaskResponse] {
-	sm, err := h.service.GetSitemap(ctx.FastCtx(), req.SitemapID)
+	config, err := h.pageGenerationConfig(req)
+	if err != nil {
+		return fail[*dto.GenerationTaskResponse](err)
+	}
+
+	task, err := h.pageGenerationService.StartGeneration(context.Background(), config)
 	if err != nil {
 		return fail[*dto.GenerationTaskResponse](err)
 	}
 
+	return ok(h.taskToDTO(task))
+}
+
+func (h *SitemapsHandler) EstimatePageGeneration(req *dto.StartPageGenerationRequest) *dto.Response[*dto.CostEstimate] {
+	config, err := h.pageGenerationConfig(req)
+	if err != nil {
+		return fail[*dto.CostEstimate](err)
+	}
+
+	estimate, err := h.pageGenerationService.EstimateGeneration(ctx.MediumCtx(), config)
+	if err != nil {
+		return fail[*dto.CostEstimate](err)
+	}
+
+	return ok(dto.NewCostEstimate(estimate))
+}
+
+func (h *SitemapsHandler) SubmitPageGenerationBatch(req *dto.StartPageGenerationRequest) *dto.Response[*dto.Batch] {
+	config, err := h.pageGenerationConfig(req)
+	if err != nil {
+		return fail[*dto.Batch](err)
+	}
+
+	batch, err := h.pageGenerationService.SubmitBatch(ctx.LongCtx(), config)
+	if err != nil {
+		return fail[*dto.Batch](err)
+	}
+
+	return ok(dto.NewBatch(batch))
+}
+
+func (h *SitemapsHandler) pageGenerationConfig(req *dto.StartPageGenerationRequest) (generation.GenerationConfig, error) {
+	sm, err := h.service.GetSitemap(ctx.FastCtx(), req.SitemapID)
+	if err != nil {
+		return generation.GenerationConfig{}, err
+	}
+
 	config := generation.GenerationConfig{
-		SitemapID:      req.SitemapID,
-		SiteID:         sm.SiteID,
-		NodeIDs:        req.NodeIDs,
-		ProviderID:     req.ProviderID,
-		PromptID:       req.PromptID,
-		PublishAs:      generation.PublishAs(req.PublishAs),
-		Placeholders:   req.Placeholders,
-		MaxConcurrency: req.MaxConcurrency,
+		SitemapID:           req.SitemapID,
+		SiteID:              sm.SiteID,
+		NodeIDs:             req.NodeIDs,
+		ProviderID:          req.ProviderID,
+		FallbackProviderIDs: req.FallbackProviderIDs,
+		PromptID:            req.PromptID,
+		PublishAs:           generation.PublishAs(req.PublishAs),
+		Placeholders:        req.Placeholders,
+		MaxConcurrency:      req.MaxConcurrency,
 	}
 
 	// Map content settings from DTO to domain model
@@ -901,15 +944,11 @@ func (h *SitemapsHandler) StartPageGeneration(req *dto.StartPageGenerationReques
 			MaxIncomingLinks:        req.ContentSettings.MaxIncomingLinks,
 			MaxOutgoingLinks:        req.ContentSettings.MaxOutgoingLinks,
 			ContextOverrides:        convertContextOverrides(req.ContentSettings.ContextOverrides),
+			GenerationParams:        req.ContentSettings.GenerationParams.ToEntity(),
 		}
 	}
 
-	task, err := h.pageGenerationService.StartGeneration(context.Background(), config)
-	if err != nil {
-		return fail[*dto.GenerationTaskResponse](err)
-	}
-
-	return ok(h.taskToDTO(task))
+	return config, nil
 }
 
 func (h *SitemapsHandler) PausePageGeneration(taskID string) *dto.Response[string] {
@@ -961,14 +1000,15 @@ func (h *SitemapsHandler) GetDefaultPagePrompt() *dto.Response[*dto.DefaultPromp
 }
 
 func (h *SitemapsHandler) taskToDTO(task *generation.Task) *dto.GenerationTaskResponse {
+	processed, failed, skipped, total := task.GetCounts()
 	resp := &dto.GenerationTaskResponse{
 		ID:             task.ID,
 		SitemapID:      task.SitemapID,
 		SiteID:         task.SiteID,
-		TotalNodes:     task.TotalNodes,
-		ProcessedNodes: task.ProcessedNodes,
-		FailedNodes:    task.FailedNodes,
-		SkippedNodes:   task.SkippedNodes,
+		TotalNodes:     total,
+		ProcessedNodes: processed,
+		FailedNodes:    failed,
+		SkippedNodes:   skipped,
 		Status:         string(task.Status),
 		StartedAt:      dto.TimeToString(task.StartedAt),
 		Error:          task.Error,
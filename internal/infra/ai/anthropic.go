@@ -2,9 +2,7 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
@@ -30,8 +28,9 @@ func (c *AnthropicClient) GetModelName() string {
 }
 
 type AnthropicConfig struct {
-	APIKey string
-	Model  string
+	APIKey  string
+	Model   string
+	BaseURL string // Optional, defaults to the Anthropic API
 }
 
 func NewAnthropicClient(cfg AnthropicConfig) (*AnthropicClient, error) {
@@ -43,9 +42,15 @@ func NewAnthropicClient(cfg AnthropicConfig) (*AnthropicClient, error) {
 		cfg.Model = "claude-3-5-sonnet-20241022"
 	}
 
-	client := anthropic.NewClient(
+	opts := []option.RequestOption{
 		option.WithAPIKey(cfg.APIKey),
-	)
+	}
+
+	if cfg.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(cfg.BaseURL))
+	}
+
+	client := anthropic.NewClient(opts...)
 
 	return &AnthropicClient{
 		client: &client,
@@ -54,6 +59,48 @@ func NewAnthropicClient(cfg AnthropicConfig) (*AnthropicClient, error) {
 }
 
 func (c *AnthropicClient) GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error) {
+	params := c.articleParams(systemPrompt, userPrompt, opts)
+	message, err := c.client.Messages.New(ctx, params)
+	if err != nil {
+		return nil, classifyError(anthropicProviderName, fmt.Errorf("API error: %w", err))
+	}
+
+	return c.articleResult(ctx, message, c.usage(message), params.MaxTokens)
+}
+
+func (c *AnthropicClient) GenerateArticleStream(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error) {
+	params := c.articleParams(systemPrompt, userPrompt, opts)
+	stream := c.client.Messages.NewStreaming(ctx, params)
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	tracker := newStreamTracker(onProgress)
+	message := anthropic.Message{}
+	for stream.Next() {
+		event := stream.Current()
+		if err := message.Accumulate(event); err != nil {
+			return nil, classifyError(anthropicProviderName, fmt.Errorf("stream error: %w", err))
+		}
+
+		if delta, ok := event.AsAny().(anthropic.ContentBlockDeltaEvent); ok {
+			if text, ok := delta.Delta.AsAny().(anthropic.TextDelta); ok {
+				tracker.Write(text.Text)
+			}
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, classifyError(anthropicProviderName, fmt.Errorf("stream error: %w", err))
+	}
+
+	tracker.Flush()
+
+	return c.articleResult(ctx, &message, c.usage(&message), params.MaxTokens)
+}
+
+// articleParams builds the messages request for article generation
+func (c *AnthropicClient) articleParams(systemPrompt, userPrompt string, opts *GenerateArticleOptions) anthropic.MessageNewParams {
 	// Create JSON schema instructions for the response
 	jsonInstructions := `
 You must respond with a valid JSON object in the following format:
@@ -68,9 +115,15 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 	fullSystemPrompt := systemPrompt + "\n\n" + jsonInstructions
 
 	// 4096 is plenty for 800-1500 words of content
-	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
+	maxTokens := opts.maxOutputTokens(4096)
+	modelInfo := GetModelInfo(entities.TypeAnthropic, c.model)
+	if modelInfo != nil && modelInfo.MaxOutputTokens > 0 {
+		maxTokens = min(maxTokens, modelInfo.MaxOutputTokens)
+	}
+
+	params := anthropic.MessageNewParams{
 		Model:     anthropic.Model(c.model),
-		MaxTokens: 4096,
+		MaxTokens: int64(maxTokens),
 		System: []anthropic.TextBlockParam{
 			{
 				Type: "text",
@@ -80,61 +133,99 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 		Messages: []anthropic.MessageParam{
 			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
 		},
-	})
+		StopSequences: opts.stop(),
+	}
+
+	// Sampling is left to the API defaults unless explicitly configured
+	if modelInfo == nil || !modelInfo.IsReasoningModel {
+		if opts != nil && opts.Temperature != nil {
+			// The API rejects temperatures above 1, params saved before they were
+			// validated per provider may still carry a higher one
+			params.Temperature = anthropic.Float(min(*opts.Temperature, 1))
+		}
+		if topP := opts.topP(); topP != nil {
+			params.TopP = anthropic.Float(*topP)
+		}
+	}
+
+	return params
+}
+
+// articleResult parses the article of the message. usage is what the message is
+// charged, the repair calls a malformed response needs are added at the regular price.
+func (c *AnthropicClient) articleResult(ctx context.Context, message *anthropic.Message, usage Usage, maxTokens int64) (*ArticleResult, error) {
+	responseText, err := messageText(message)
 	if err != nil {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("API error: %w", err))
+		return nil, err
 	}
 
-	if len(message.Content) == 0 {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no response from API"))
+	article, repairUsage, err := parseStructured[ArticleContent](ctx, responseText, c.repairer(maxTokens))
+	if err != nil {
+		return nil, errors.AI(anthropicProviderName, err)
 	}
 
-	// Extract text content
-	var responseText string
-	for _, block := range message.Content {
-		if block.Type == "text" {
-			responseText = block.Text
-			break
+	usage = usage.add(repairUsage)
+
+	return &ArticleResult{
+		Title:      article.Title,
+		Excerpt:    article.Excerpt,
+		Content:    article.Content,
+		TokensUsed: usage.TotalTokens,
+		Cost:       usage.CostUSD,
+		Usage:      usage,
+	}, nil
+}
+
+// repairer returns a repairFunc that asks the same model to fix a malformed response
+func (c *AnthropicClient) repairer(maxTokens int64) repairFunc {
+	return func(ctx context.Context, systemPrompt, userPrompt string) (string, Usage, error) {
+		message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
+			Model:     anthropic.Model(c.model),
+			MaxTokens: maxTokens,
+			System: []anthropic.TextBlockParam{
+				{
+					Type: "text",
+					Text: systemPrompt,
+				},
+			},
+			Messages: []anthropic.MessageParam{
+				anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
+			},
+		})
+		if err != nil {
+			return "", Usage{}, classifyError(anthropicProviderName, fmt.Errorf("API error: %w", err))
 		}
+
+		responseText, err := messageText(message)
+		return responseText, c.usage(message), err
 	}
+}
 
-	if responseText == "" {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no text content in response"))
+// messageText returns the first text block of a message
+func messageText(message *anthropic.Message) (string, error) {
+	if len(message.Content) == 0 {
+		return "", errors.AI(anthropicProviderName, fmt.Errorf("no response from API"))
 	}
 
-	// Parse JSON from response
-	var article ArticleContent
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &article); err != nil {
-		// Try sanitizing the JSON to fix invalid escape sequences
-		sanitized := sanitizeJSON(jsonStr)
-		if err2 := json.Unmarshal([]byte(sanitized), &article); err2 != nil {
-			return nil, errors.AI(anthropicProviderName, fmt.Errorf("failed to parse response: %w, raw: %s", err, responseText[:min(200, len(responseText))]))
+	for _, block := range message.Content {
+		if block.Type == "text" && block.Text != "" {
+			return block.Text, nil
 		}
 	}
 
-	if article.Title == "" || article.Content == "" {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("empty title or content in response"))
-	}
+	return "", errors.AI(anthropicProviderName, fmt.Errorf("no text content in response"))
+}
 
+func (c *AnthropicClient) usage(message *anthropic.Message) Usage {
 	inputTokens := int(message.Usage.InputTokens)
 	outputTokens := int(message.Usage.OutputTokens)
-	totalTokens := inputTokens + outputTokens
-	cost := CalculateCost(entities.TypeAnthropic, c.model, inputTokens, outputTokens)
 
-	return &ArticleResult{
-		Title:      article.Title,
-		Excerpt:    article.Excerpt,
-		Content:    article.Content,
-		TokensUsed: totalTokens,
-		Cost:       cost,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
-	}, nil
+	return Usage{
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		TotalTokens:  inputTokens + outputTokens,
+		CostUSD:      CalculateCost(entities.TypeAnthropic, c.model, inputTokens, outputTokens),
+	}
 }
 
 func (c *AnthropicClient) GenerateTopicVariations(ctx context.Context, topic string, amount int) ([]string, error) {
@@ -164,103 +255,56 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 		},
 	})
 	if err != nil {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(anthropicProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	if len(message.Content) == 0 {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no response from API"))
-	}
-
-	var responseText string
-	for _, block := range message.Content {
-		if block.Type == "text" {
-			responseText = block.Text
-			break
-		}
-	}
-
-	if responseText == "" {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no text content in response"))
-	}
-
-	var result TopicVariations
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("failed to parse variations: %w", err))
-	}
-
-	if len(result.Variations) == 0 {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no variations generated"))
-	}
-
-	if len(result.Variations) > amount {
-		result.Variations = result.Variations[:amount]
+	responseText, err := messageText(message)
+	if err != nil {
+		return nil, err
 	}
 
-	cleanedVariations := make([]string, len(result.Variations))
-	for i, variation := range result.Variations {
-		cleanedVariations[i] = cleanQuotes(variation)
+	result, _, err := parseStructured[TopicVariations](ctx, responseText, c.repairer(1024))
+	if err != nil {
+		return nil, errors.AI(anthropicProviderName, err)
 	}
 
-	return cleanedVariations, nil
+	return topicVariations(result, amount), nil
 }
 
-// extractJSON attempts to extract a JSON object from a string that may contain other text
-func extractJSON(s string) string {
-	s = strings.TrimSpace(s)
+func (c *AnthropicClient) GenerateOutline(ctx context.Context, systemPrompt, userPrompt string) (*OutlineResult, error) {
+	const outlineTokens = 4096
 
-	// Try to find JSON object boundaries
-	start := strings.Index(s, "{")
-	end := strings.LastIndex(s, "}")
-
-	if start != -1 && end != -1 && end > start {
-		return s[start : end+1]
+	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     anthropic.Model(c.model),
+		MaxTokens: outlineTokens,
+		System: []anthropic.TextBlockParam{
+			{
+				Type: "text",
+				Text: systemPrompt + "\n\n" + outlineJSONInstructions,
+			},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
+		},
+	})
+	if err != nil {
+		return nil, classifyError(anthropicProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	return s
-}
-
-// sanitizeJSON fixes common invalid escape sequences in JSON strings
-// This helps handle cases where AI generates content with invalid escapes like "\ " or "\x"
-func sanitizeJSON(s string) string {
-	// Fix invalid escape sequences by finding backslash not followed by valid escape chars
-	// Valid JSON escapes: " \ / b f n r t u
-	result := strings.Builder{}
-	result.Grow(len(s))
-
-	for i := 0; i < len(s); i++ {
-		if s[i] == '\\' && i+1 < len(s) {
-			next := s[i+1]
-			// Check if it's a valid escape sequence
-			switch next {
-			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
-				result.WriteByte(s[i])
-			case 'u':
-				// Unicode escape - check if followed by 4 hex digits
-				if i+5 < len(s) {
-					result.WriteByte(s[i])
-				} else {
-					// Invalid unicode escape, skip the backslash
-					continue
-				}
-			default:
-				// Invalid escape - skip the backslash
-				continue
-			}
-		} else {
-			result.WriteByte(s[i])
-		}
+	responseText, err := messageText(message)
+	if err != nil {
+		return nil, err
 	}
 
-	return result.String()
-}
-
-// min returns the minimum of two integers
-func min(a, b int) int {
-	if a < b {
-		return a
+	result, repairUsage, err := parseStructured[ArticleOutline](ctx, responseText, c.repairer(outlineTokens))
+	if err != nil {
+		return nil, errors.AI(anthropicProviderName, err)
 	}
-	return b
+
+	return &OutlineResult{
+		Outline: *result,
+		Usage:   c.usage(message).add(repairUsage),
+	}, nil
 }
 
 func (c *AnthropicClient) GenerateSitemapStructure(ctx context.Context, systemPrompt, userPrompt string) (*SitemapStructureResult, error) {
@@ -302,50 +346,26 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 		},
 	})
 	if err != nil {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(anthropicProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	if len(message.Content) == 0 {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no response from API"))
-	}
-
-	var responseText string
-	for _, block := range message.Content {
-		if block.Type == "text" {
-			responseText = block.Text
-			break
-		}
-	}
-
-	if responseText == "" {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no text content in response"))
+	responseText, err := messageText(message)
+	if err != nil {
+		return nil, err
 	}
 
-	var result SitemapStructureSchema
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("failed to parse response: %w, raw: %s", err, responseText[:min(200, len(responseText))]))
+	result, repairUsage, err := parseStructured[SitemapStructureSchema](ctx, responseText, c.repairer(8192))
+	if err != nil {
+		return nil, errors.AI(anthropicProviderName, err)
 	}
 
-	if len(result.Nodes) == 0 {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no nodes generated"))
-	}
-
-	inputTokens := int(message.Usage.InputTokens)
-	outputTokens := int(message.Usage.OutputTokens)
-	totalTokens := inputTokens + outputTokens
-	cost := CalculateCost(entities.TypeAnthropic, c.model, inputTokens, outputTokens)
+	usage := c.usage(message).add(repairUsage)
 
 	return &SitemapStructureResult{
 		Nodes:      result.Nodes,
-		TokensUsed: totalTokens,
-		Cost:       cost,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
+		TokensUsed: usage.TotalTokens,
+		Cost:       usage.CostUSD,
+		Usage:      usage,
 	}, nil
 }
 
@@ -392,48 +412,23 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 		},
 	})
 	if err != nil {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(anthropicProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	if len(message.Content) == 0 {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no response from API"))
-	}
-
-	var responseText string
-	for _, block := range message.Content {
-		if block.Type == "text" {
-			responseText = block.Text
-			break
-		}
-	}
-
-	if responseText == "" {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no text content in response"))
+	responseText, err := messageText(message)
+	if err != nil {
+		return nil, err
 	}
 
-	var result LinkSuggestionSchema
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		sanitized := sanitizeJSON(jsonStr)
-		if err2 := json.Unmarshal([]byte(sanitized), &result); err2 != nil {
-			return nil, errors.AI(anthropicProviderName, fmt.Errorf("failed to parse response: %w", err))
-		}
+	result, repairUsage, err := parseStructured[LinkSuggestionSchema](ctx, responseText, c.repairer(4096))
+	if err != nil {
+		return nil, errors.AI(anthropicProviderName, err)
 	}
 
-	inputTokens := int(message.Usage.InputTokens)
-	outputTokens := int(message.Usage.OutputTokens)
-	totalTokens := inputTokens + outputTokens
-	cost := CalculateCost(entities.TypeAnthropic, c.model, inputTokens, outputTokens)
-
 	return &LinkSuggestionResult{
 		Links:       result.Links,
 		Explanation: result.Explanation,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
+		Usage:       c.usage(message).add(repairUsage),
 	}, nil
 }
 
@@ -482,53 +477,56 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 		},
 	})
 	if err != nil {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(anthropicProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	if len(message.Content) == 0 {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no response from API"))
-	}
-
-	var responseText string
-	for _, block := range message.Content {
-		if block.Type == "text" {
-			responseText = block.Text
-			break
-		}
-	}
-
-	if responseText == "" {
-		return nil, errors.AI(anthropicProviderName, fmt.Errorf("no text content in response"))
+	responseText, err := messageText(message)
+	if err != nil {
+		return nil, err
 	}
 
-	var result InsertLinksContentSchema
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		sanitized := sanitizeJSON(jsonStr)
-		if err2 := json.Unmarshal([]byte(sanitized), &result); err2 != nil {
-			preview := jsonStr
-			if len(preview) > 300 {
-				preview = preview[:300] + "..."
-			}
-			return nil, errors.AI(anthropicProviderName, fmt.Errorf("failed to parse response: %w, raw: %s", err, preview))
-		}
+	result, repairUsage, err := parseStructured[InsertLinksContentSchema](ctx, responseText, c.repairer(16384))
+	if err != nil {
+		return nil, errors.AI(anthropicProviderName, err)
 	}
 
-	inputTokens := int(message.Usage.InputTokens)
-	outputTokens := int(message.Usage.OutputTokens)
-	totalTokens := inputTokens + outputTokens
-	cost := CalculateCost(entities.TypeAnthropic, c.model, inputTokens, outputTokens)
+	usage := c.usage(message).add(repairUsage)
 
-	fmt.Printf("[Anthropic] InsertLinks success: linksApplied=%d, cost=$%.4f\n", result.LinksApplied, cost)
+	fmt.Printf("[Anthropic] InsertLinks success: linksApplied=%d, cost=$%.4f\n", result.LinksApplied, usage.CostUSD)
 
 	return &InsertLinksResult{
 		Content:      result.Content,
 		LinksApplied: result.LinksApplied,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
+		Usage:        usage,
 	}, nil
 }
+
+func (c *AnthropicClient) RewriteArticle(ctx context.Context, request *RewriteArticleRequest) (*ArticleResult, error) {
+	systemPrompt, userPrompt := rewritePrompts(request)
+	return c.GenerateArticle(ctx, systemPrompt, userPrompt, rewriteOptions(request))
+}
+
+// Embed is not available, Anthropic has no embeddings API
+func (c *AnthropicClient) Embed(ctx context.Context, texts []string) (*EmbeddingResult, error) {
+	return nil, errors.Validation("Anthropic does not provide embeddings, use an OpenAI, Google or OpenAI-compatible provider")
+}
+
+func (c *AnthropicClient) Ping(ctx context.Context) (*PingResult, error) {
+	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     anthropic.Model(c.model),
+		MaxTokens: pingOutputTokens,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(pingPrompt)),
+		},
+	})
+	if err != nil {
+		return nil, classifyError(anthropicProviderName, fmt.Errorf("API error: %w", err))
+	}
+
+	return &PingResult{Usage: c.usage(message)}, nil
+}
+
+// GenerateImage is not available, Anthropic has no image generation API
+func (c *AnthropicClient) GenerateImage(ctx context.Context, request *ImageRequest) (*ImageResult, error) {
+	return nil, errors.Validation("Anthropic does not generate images, use an OpenAI provider")
+}
@@ -0,0 +1,24 @@
+package ai
+
+import "testing"
+
+func TestAnthropicArticleParamsCapTemperature(t *testing.T) {
+	client, err := NewAnthropicClient(AnthropicConfig{APIKey: "test", Model: "claude-3-5-sonnet-20241022"})
+	if err != nil {
+		t.Fatalf("NewAnthropicClient: %v", err)
+	}
+
+	opts := testArticleOptions()
+	temperature := 1.5
+	opts.Temperature = &temperature
+
+	params := client.articleParams("system", "user", opts)
+	if params.Temperature.Value != 1 {
+		t.Errorf("expected the temperature capped at 1, got %v", params.Temperature.Value)
+	}
+
+	params = client.articleParams("system", "user", testArticleOptions())
+	if params.Temperature.Value != 0.2 {
+		t.Errorf("expected temperature 0.2 to be kept, got %v", params.Temperature.Value)
+	}
+}
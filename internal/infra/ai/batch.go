@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+// BatchDiscount is the share of the regular price charged for batched requests
+const BatchDiscount = 0.5
+
+// BatchRequest is one article generation of a batch. CustomID identifies its
+// result and must be unique within the batch (letters, digits, - and _ only).
+type BatchRequest struct {
+	CustomID     string
+	SystemPrompt string
+	UserPrompt   string
+	Options      *GenerateArticleOptions
+}
+
+type BatchStatus string
+
+const (
+	// BatchStatusPending means the provider is still working on the batch
+	BatchStatusPending BatchStatus = "pending"
+	// BatchStatusCompleted means every request was processed, results are available
+	BatchStatusCompleted BatchStatus = "completed"
+	// BatchStatusExpired means the completion window elapsed, finished requests still have results
+	BatchStatusExpired BatchStatus = "expired"
+	// BatchStatusCancelled means the batch was cancelled, finished requests still have results
+	BatchStatusCancelled BatchStatus = "cancelled"
+	// BatchStatusFailed means the batch was rejected as a whole, there are no results
+	BatchStatusFailed BatchStatus = "failed"
+)
+
+// Finished reports whether the provider stopped working on the batch
+func (s BatchStatus) Finished() bool {
+	return s != BatchStatusPending
+}
+
+// BatchState is the progress of a batch as reported by the provider
+type BatchState struct {
+	Status    BatchStatus
+	Total     int
+	Succeeded int
+	Failed    int
+	Error     string // Reason of a failed batch
+}
+
+// BatchItemResult is the outcome of one request of a batch. Usage of the
+// batched request is already discounted by BatchDiscount.
+type BatchItemResult struct {
+	CustomID string
+	Article  *ArticleResult
+	Err      error
+}
+
+// BatchClient is implemented by clients of providers with an asynchronous batch
+// API. Batches trade latency (up to 24 hours) for a discounted price.
+type BatchClient interface {
+	SubmitArticleBatch(ctx context.Context, requests []BatchRequest) (string, error)
+	GetBatch(ctx context.Context, batchID string) (*BatchState, error)
+	// GetBatchResults returns the results of a finished batch, in no particular order
+	GetBatchResults(ctx context.Context, batchID string) ([]*BatchItemResult, error)
+	CancelBatch(ctx context.Context, batchID string) error
+}
+
+// SupportsBatch reports whether the provider has a batch API
+func SupportsBatch(providerType entities.Type) bool {
+	switch providerType {
+	// Anthropic Message Batches wait for the Anthropic provider, which is disabled
+	case entities.TypeOpenAI, entities.TypeMock:
+		return true
+	default:
+		return false
+	}
+}
+
+// AsBatchClient returns the batch API of a client, unwrapping governed and
+// recording clients. Batches are queued by the provider, so they are not governed.
+// keyFingerprint selects the API key of a provider with several, empty for a new
+// batch. The fingerprint of the key the batch API uses is returned, it is empty
+// for keyless providers. ok is false when the client has no batch API or the key
+// is no longer set on the provider.
+func AsBatchClient(client Client, keyFingerprint string) (batchClient BatchClient, fingerprint string, ok bool) {
+	for {
+		if batchClient, ok = client.(BatchClient); ok {
+			return batchClient, keyFingerprint, true
+		}
+
+		if rotating, isRotating := client.(*RotatingClient); isRotating {
+			key, found := rotating.batchKey(keyFingerprint)
+			if !found {
+				return nil, "", false
+			}
+			client, keyFingerprint = key.client, key.fingerprint
+			continue
+		}
+
+		wrapper, isWrapper := client.(interface{ Unwrap() Client })
+		if !isWrapper {
+			return nil, "", false
+		}
+		client = wrapper.Unwrap()
+	}
+}
+
+// batchUsage applies the batch price to the usage of a batched request. Follow-up
+// calls made outside the batch, such as repairs, are charged the regular price.
+func batchUsage(usage Usage) Usage {
+	usage.CostUSD *= BatchDiscount
+	return usage
+}
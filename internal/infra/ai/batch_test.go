@@ -0,0 +1,317 @@
+package ai
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+const testArticleJSON = `{"title":"Batched title","excerpt":"Batched excerpt","content":"<p>Batched content</p>"}`
+
+func testBatchRequests() []BatchRequest {
+	return []BatchRequest{
+		{CustomID: "node-1", SystemPrompt: "system", UserPrompt: "first"},
+		{CustomID: "node-2", SystemPrompt: "system", UserPrompt: "second"},
+	}
+}
+
+// newOpenAIBatchServer stands in for the files and batches endpoints of the OpenAI API.
+// The first request of the uploaded batch succeeds with content, the others are reported
+// in the error file. Repairs of malformed content get testArticleJSON back.
+func newOpenAIBatchServer(t *testing.T, content string) *httptest.Server {
+	t.Helper()
+
+	var customIDs []string
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /files", func(w http.ResponseWriter, r *http.Request) {
+		if purpose := r.FormValue("purpose"); purpose != "batch" {
+			t.Errorf("expected purpose batch, got %q", purpose)
+		}
+
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("read uploaded file: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var line struct {
+				CustomID string `json:"custom_id"`
+				Method   string `json:"method"`
+				URL      string `json:"url"`
+				Body     struct {
+					Model    string            `json:"model"`
+					Messages []json.RawMessage `json:"messages"`
+				} `json:"body"`
+			}
+			if err = json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Errorf("malformed input line: %v", err)
+				continue
+			}
+			if line.Method != "POST" || line.URL != "/v1/chat/completions" || line.Body.Model != "gpt-4o-mini" || len(line.Body.Messages) != 2 {
+				t.Errorf("unexpected input line: %s", scanner.Text())
+			}
+			customIDs = append(customIDs, line.CustomID)
+		}
+
+		_, _ = fmt.Fprint(w, `{"id":"file-in","object":"file","bytes":1,"created_at":0,"filename":"batch.jsonl","purpose":"batch","status":"processed"}`)
+	})
+
+	mux.HandleFunc("POST /batches", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			InputFileID string `json:"input_file_id"`
+			Endpoint    string `json:"endpoint"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.InputFileID != "file-in" || body.Endpoint != "/v1/chat/completions" {
+			t.Errorf("unexpected batch request: %+v", body)
+		}
+
+		_, _ = fmt.Fprint(w, `{"id":"batch-1","object":"batch","endpoint":"/v1/chat/completions","input_file_id":"file-in","completion_window":"24h","created_at":0,"status":"validating"}`)
+	})
+
+	mux.HandleFunc("GET /batches/batch-1", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"id":"batch-1","object":"batch","endpoint":"/v1/chat/completions","input_file_id":"file-in","completion_window":"24h","created_at":0,"status":"completed","output_file_id":"file-out","error_file_id":"file-err","request_counts":{"total":%d,"completed":1,"failed":%d}}`,
+			len(customIDs), len(customIDs)-1)
+	})
+
+	mux.HandleFunc("GET /files/file-out/content", func(w http.ResponseWriter, _ *http.Request) {
+		completion := map[string]any{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"created": 0,
+			"model":   "gpt-4o-mini",
+			"choices": []map[string]any{{
+				"index":         0,
+				"finish_reason": "stop",
+				"message":       map[string]any{"role": "assistant", "content": content},
+			}},
+			"usage": map[string]any{"prompt_tokens": 1000, "completion_tokens": 2000, "total_tokens": 3000},
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"custom_id": customIDs[0],
+			"response":  map[string]any{"status_code": 200, "body": completion},
+		})
+	})
+
+	mux.HandleFunc("GET /files/file-err/content", func(w http.ResponseWriter, _ *http.Request) {
+		for _, customID := range customIDs[1:] {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"custom_id": customID,
+				"response":  map[string]any{"status_code": 400, "body": map[string]any{"error": map[string]any{"message": "bad request"}}},
+			})
+		}
+	})
+
+	mux.HandleFunc("POST /chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"id":"chatcmpl-2","object":"chat.completion","created":0,"model":"gpt-4o-mini","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":%q}}],"usage":{"prompt_tokens":100,"completion_tokens":200,"total_tokens":300}}`,
+			testArticleJSON)
+	})
+
+	server := httptest.NewServer(jsonResponses(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenAIBatchRoundTrip(t *testing.T) {
+	server := newOpenAIBatchServer(t, testArticleJSON)
+
+	client, err := NewOpenAIClient(Config{APIKey: "test", Model: "gpt-4o-mini", BaseURL: server.URL + "/", ProviderType: entities.TypeOpenAI})
+	if err != nil {
+		t.Fatalf("NewOpenAIClient: %v", err)
+	}
+
+	batchID, err := client.SubmitArticleBatch(t.Context(), testBatchRequests())
+	if err != nil {
+		t.Fatalf("SubmitArticleBatch: %v", err)
+	}
+	if batchID != "batch-1" {
+		t.Fatalf("expected batch-1, got %q", batchID)
+	}
+
+	state, err := client.GetBatch(t.Context(), batchID)
+	if err != nil {
+		t.Fatalf("GetBatch: %v", err)
+	}
+	if state.Status != BatchStatusCompleted || state.Total != 2 || state.Succeeded != 1 || state.Failed != 1 {
+		t.Errorf("unexpected state: %+v", state)
+	}
+
+	results, err := client.GetBatchResults(t.Context(), batchID)
+	if err != nil {
+		t.Fatalf("GetBatchResults: %v", err)
+	}
+	assertBatchResults(t, results, CalculateCost(entities.TypeOpenAI, "gpt-4o-mini", 1000, 2000))
+}
+
+func TestOpenAIBatchRepairIsChargedRegularPrice(t *testing.T) {
+	server := newOpenAIBatchServer(t, "Here is the article you asked for")
+
+	client, err := NewOpenAIClient(Config{APIKey: "test", Model: "gpt-4o-mini", BaseURL: server.URL + "/", ProviderType: entities.TypeOpenAI})
+	if err != nil {
+		t.Fatalf("NewOpenAIClient: %v", err)
+	}
+
+	batchID, err := client.SubmitArticleBatch(t.Context(), testBatchRequests())
+	if err != nil {
+		t.Fatalf("SubmitArticleBatch: %v", err)
+	}
+
+	results, err := client.GetBatchResults(t.Context(), batchID)
+	if err != nil {
+		t.Fatalf("GetBatchResults: %v", err)
+	}
+
+	var repaired *BatchItemResult
+	for _, result := range results {
+		if result.CustomID == "node-1" {
+			repaired = result
+		}
+	}
+	if repaired == nil || repaired.Err != nil || repaired.Article == nil {
+		t.Fatalf("expected node-1 to be repaired, got %+v", repaired)
+	}
+	if repaired.Article.Title != "Batched title" {
+		t.Errorf("unexpected article: %+v", repaired.Article)
+	}
+
+	// Only the batched line is discounted, the repair is a regular call
+	want := CalculateCost(entities.TypeOpenAI, "gpt-4o-mini", 1000, 2000)*BatchDiscount +
+		CalculateCost(entities.TypeOpenAI, "gpt-4o-mini", 100, 200)
+	if math.Abs(repaired.Article.Usage.CostUSD-want) > 1e-9 {
+		t.Errorf("expected cost %v, got %v", want, repaired.Article.Usage.CostUSD)
+	}
+	if repaired.Article.Cost != repaired.Article.Usage.CostUSD {
+		t.Errorf("expected article cost %v to match its usage", repaired.Article.Cost)
+	}
+}
+
+func TestMockBatchRoundTrip(t *testing.T) {
+	client, err := NewMockClient(MockConfig{Model: MockModelSynthetic})
+	if err != nil {
+		t.Fatalf("NewMockClient: %v", err)
+	}
+
+	batchID, err := client.SubmitArticleBatch(t.Context(), testBatchRequests())
+	if err != nil {
+		t.Fatalf("SubmitArticleBatch: %v", err)
+	}
+
+	results, err := client.GetBatchResults(t.Context(), batchID)
+	if err != nil {
+		t.Fatalf("GetBatchResults: %v", err)
+	}
+	if len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("expected two successful results, got %+v", results)
+	}
+
+	if err = client.CancelBatch(t.Context(), batchID); err != nil {
+		t.Fatalf("CancelBatch: %v", err)
+	}
+	state, err := client.GetBatch(t.Context(), batchID)
+	if err != nil {
+		t.Fatalf("GetBatch: %v", err)
+	}
+	if state.Status != BatchStatusCancelled {
+		t.Errorf("expected cancelled batch, got %s", state.Status)
+	}
+}
+
+func TestAsBatchClientUnwrapsGovernedClient(t *testing.T) {
+	mock, err := NewMockClient(MockConfig{Model: MockModelSynthetic})
+	if err != nil {
+		t.Fatalf("NewMockClient: %v", err)
+	}
+
+	governed := NewGovernedClient(mock, NewGovernor())
+	if _, _, ok := AsBatchClient(governed, ""); !ok {
+		t.Error("expected the governed mock client to expose its batch API")
+	}
+
+	if _, _, ok := AsBatchClient(&noBatchClient{}, ""); ok {
+		t.Error("expected a client without batch API to be rejected")
+	}
+}
+
+func TestAsBatchClientKeepsTheBatchKey(t *testing.T) {
+	pool := NewKeyPool()
+	first := newScriptedClient(t, nil)
+	second := newScriptedClient(t, nil)
+
+	rotating := NewRotatingClient(pool)
+	rotating.AddKey("sk-first", first)
+	rotating.AddKey("sk-second", second)
+	governed := NewGovernedClient(rotating, NewGovernor())
+
+	pool.record(KeyFingerprint("sk-first"), errors.AIRateLimitAfter("openai", time.Minute, fmt.Errorf("429")), time.Now())
+
+	// A new batch skips the benched key
+	batchClient, fingerprint, ok := AsBatchClient(governed, "")
+	if !ok || batchClient != BatchClient(second) || fingerprint != KeyFingerprint("sk-second") {
+		t.Fatalf("expected the second key for a new batch, got %s", fingerprint)
+	}
+
+	// A submitted batch is read with its own key, benched or not
+	batchClient, fingerprint, ok = AsBatchClient(governed, KeyFingerprint("sk-first"))
+	if !ok || batchClient != BatchClient(first) || fingerprint != KeyFingerprint("sk-first") {
+		t.Fatalf("expected the first key for its batch, got %s", fingerprint)
+	}
+
+	if _, _, ok = AsBatchClient(governed, KeyFingerprint("sk-removed")); ok {
+		t.Error("expected a batch of a removed key to be rejected")
+	}
+}
+
+type noBatchClient struct{ Client }
+
+// jsonResponses defaults the content type of the stand-in responses to JSON, the SDKs refuse anything else
+func jsonResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func assertBatchResults(t *testing.T, results []*BatchItemResult, fullCost float64) {
+	t.Helper()
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	byID := make(map[string]*BatchItemResult, len(results))
+	for _, result := range results {
+		byID[result.CustomID] = result
+	}
+
+	succeeded := byID["node-1"]
+	if succeeded == nil || succeeded.Err != nil || succeeded.Article == nil {
+		t.Fatalf("expected node-1 to succeed, got %+v", succeeded)
+	}
+	if succeeded.Article.Title != "Batched title" || succeeded.Article.Content != "<p>Batched content</p>" {
+		t.Errorf("unexpected article: %+v", succeeded.Article)
+	}
+	if succeeded.Article.Usage.InputTokens != 1000 || succeeded.Article.Usage.OutputTokens != 2000 {
+		t.Errorf("unexpected usage: %+v", succeeded.Article.Usage)
+	}
+	if want := fullCost * BatchDiscount; math.Abs(succeeded.Article.Usage.CostUSD-want) > 1e-9 {
+		t.Errorf("expected discounted cost %v, got %v", want, succeeded.Article.Usage.CostUSD)
+	}
+
+	failed := byID["node-2"]
+	if failed == nil || failed.Err == nil || !strings.Contains(failed.Err.Error(), "bad request") {
+		t.Errorf("expected node-2 to fail with bad request, got %+v", failed)
+	}
+}
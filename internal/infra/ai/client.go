@@ -2,6 +2,8 @@ package ai
 
 import (
 	"context"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
 )
 
 // Usage contains token usage and cost metrics for an AI operation
@@ -11,23 +13,159 @@ type Usage struct {
 	TotalTokens  int     // Total tokens used
 	CostUSD      float64 // Estimated cost in USD
 	DurationMs   int64   // Operation duration in milliseconds
+	// KeyFingerprint identifies the API key the call was made with, empty for keyless providers
+	KeyFingerprint string
 }
 
-// GenerateArticleOptions contains optional parameters for article generation
+// GenerateArticleOptions contains optional sampling parameters for article generation.
+// Nil fields keep the client defaults. Reasoning models ignore temperature, top-p
+// and stop sequences, and seed is only sent to OpenAI-compatible APIs.
 type GenerateArticleOptions struct {
-	// Reserved for future options
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
+	Seed            *int64   `json:"seed,omitempty"`
+	Stop            []string `json:"stop,omitempty"`
+	// Mode is read by ComposeArticle, clients ignore it
+	Mode entities.GenerationMode `json:"mode,omitempty"`
+}
+
+// NewGenerateArticleOptions converts stored generation params into client options
+func NewGenerateArticleOptions(params *entities.GenerationParams) *GenerateArticleOptions {
+	if params.IsEmpty() {
+		return nil
+	}
+
+	return &GenerateArticleOptions{
+		Temperature:     params.Temperature,
+		TopP:            params.TopP,
+		MaxOutputTokens: params.MaxOutputTokens,
+		Seed:            params.Seed,
+		Stop:            params.Stop,
+		Mode:            params.Mode,
+	}
+}
+
+func (o *GenerateArticleOptions) mode() entities.GenerationMode {
+	if o == nil || o.Mode == "" {
+		return entities.GenerationModeSingle
+	}
+	return o.Mode
 }
 
+const (
+	// defaultArticleOutputTokens is the output budget of an article when none is set
+	defaultArticleOutputTokens = 8192
+	// minShrunkOutputTokens is the smallest output budget ShrinkOutput goes down to
+	minShrunkOutputTokens = 1024
+)
+
+// ShrinkOutput returns a copy of opts with half the output token budget, so a request
+// that overflowed the model's context window can be retried in a smaller one.
+// It returns false once the budget can't shrink any further.
+func ShrinkOutput(opts *GenerateArticleOptions) (*GenerateArticleOptions, bool) {
+	current := opts.maxOutputTokens(defaultArticleOutputTokens)
+	if current <= minShrunkOutputTokens {
+		return opts, false
+	}
+
+	var shrunk GenerateArticleOptions
+	if opts != nil {
+		shrunk = *opts
+	}
+	next := max(current/2, minShrunkOutputTokens)
+	shrunk.MaxOutputTokens = &next
+
+	return &shrunk, true
+}
+
+func (o *GenerateArticleOptions) temperature(def float64) float64 {
+	if o == nil || o.Temperature == nil {
+		return def
+	}
+	return *o.Temperature
+}
+
+func (o *GenerateArticleOptions) topP() *float64 {
+	if o == nil {
+		return nil
+	}
+	return o.TopP
+}
+
+func (o *GenerateArticleOptions) maxOutputTokens(def int) int {
+	if o == nil || o.MaxOutputTokens == nil {
+		return def
+	}
+	return *o.MaxOutputTokens
+}
+
+func (o *GenerateArticleOptions) seed() *int64 {
+	if o == nil {
+		return nil
+	}
+	return o.Seed
+}
+
+func (o *GenerateArticleOptions) stop() []string {
+	if o == nil {
+		return nil
+	}
+	return o.Stop
+}
+
+// StreamProgress reports incremental output received while an article is streamed
+type StreamProgress struct {
+	Chars        int    // Characters of raw model output received so far
+	Tokens       int    // Estimated output tokens received so far
+	PartialTitle string // Title decoded from the partial response, empty until available
+}
+
+// StreamProgressFunc is called periodically while a streamed generation is in progress
+type StreamProgressFunc func(progress StreamProgress)
+
 type Client interface {
 	GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error)
+	// GenerateArticleStream behaves like GenerateArticle but streams the response,
+	// reporting progress through onProgress. Cancelling ctx aborts the stream.
+	GenerateArticleStream(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error)
+	// GenerateOutline plans an article: title, excerpt and its sections with target
+	// length and keywords. ComposeArticle then generates each section on its own.
+	GenerateOutline(ctx context.Context, systemPrompt, userPrompt string) (*OutlineResult, error)
 	GenerateTopicVariations(ctx context.Context, topic string, amount int) ([]string, error)
 	GenerateSitemapStructure(ctx context.Context, systemPrompt, userPrompt string) (*SitemapStructureResult, error)
 	GenerateLinkSuggestions(ctx context.Context, request *LinkSuggestionRequest) (*LinkSuggestionResult, error)
 	InsertLinks(ctx context.Context, request *InsertLinksRequest) (*InsertLinksResult, error)
+	// RewriteArticle revises an existing article following the request instructions,
+	// e.g. updating facts, expanding a section or changing the tone
+	RewriteArticle(ctx context.Context, request *RewriteArticleRequest) (*ArticleResult, error)
+	// Embed returns one vector per text for semantic search and deduplication.
+	// Providers without an embeddings API return a validation error.
+	Embed(ctx context.Context, texts []string) (*EmbeddingResult, error)
+	// GenerateImage creates an image from a text prompt, e.g. a featured image.
+	// Providers without an image API return a validation error.
+	GenerateImage(ctx context.Context, request *ImageRequest) (*ImageResult, error)
+	// Ping sends the smallest possible request to the model, verifying the API key,
+	// the account's credit and that the model is still served
+	Ping(ctx context.Context) (*PingResult, error)
 	GetProviderName() string
 	GetModelName() string
 }
 
+const (
+	// pingPrompt is the user message of a Ping request
+	pingPrompt = "ping"
+	// pingOutputTokens caps the answer to a Ping. Reasoning models get a little more,
+	// a cap they hit while thinking still proves the request was served.
+	pingOutputTokens          = 1
+	pingReasoningOutputTokens = 16
+)
+
+// PingResult contains the usage of a Ping request
+type PingResult struct {
+	Usage Usage
+}
+
 type ArticleResult struct {
 	Title      string
 	Excerpt    string
@@ -37,6 +175,27 @@ type ArticleResult struct {
 	Usage      Usage   // Detailed usage metrics
 }
 
+// OutlineSection is one planned section of a long-form article
+type OutlineSection struct {
+	Heading     string   `json:"heading" jsonschema_description:"Section heading, used as the H2 of the section"`
+	Summary     string   `json:"summary" jsonschema_description:"What the section covers, one or two sentences"`
+	TargetWords int      `json:"targetWords" jsonschema_description:"Target length of the section in words"`
+	Keywords    []string `json:"keywords" jsonschema_description:"Keywords to use naturally in the section"`
+}
+
+// ArticleOutline is the JSON schema of an outline response
+type ArticleOutline struct {
+	Title    string           `json:"title" jsonschema_description:"Article title"`
+	Excerpt  string           `json:"excerpt" jsonschema_description:"Brief summary for previews"`
+	Sections []OutlineSection `json:"sections" jsonschema_description:"Sections of the article in reading order"`
+}
+
+// OutlineResult contains the result of outline generation
+type OutlineResult struct {
+	Outline ArticleOutline
+	Usage   Usage
+}
+
 // SitemapGeneratedNode represents a node generated by AI for sitemap structure
 type SitemapGeneratedNode struct {
 	Title    string                 `json:"title" jsonschema_description:"Page title, should be descriptive and SEO-friendly"`
@@ -125,3 +284,14 @@ type InsertLinksResult struct {
 	LinksApplied int    // Number of links successfully inserted
 	Usage        Usage  // Token usage metrics
 }
+
+// RewriteArticleRequest contains an existing article and the instructions to revise it
+type RewriteArticleRequest struct {
+	Title        string                  // Current title of the article
+	Content      string                  // Existing HTML content
+	Instructions string                  // What to change, e.g. "update for 2026" or "expand section X"
+	Language     string                  // Content language
+	SystemPrompt string                  // Optional: custom system prompt (uses default if empty)
+	UserPrompt   string                  // Optional: custom user prompt (uses default if empty)
+	Options      *GenerateArticleOptions // Optional: sampling parameters
+}
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"math"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+// maxEmbeddingBatch is the number of texts sent in a single embeddings request
+const maxEmbeddingBatch = 64
+
+// mockEmbeddingDimensions is the vector size produced by the mock client
+const mockEmbeddingDimensions = 256
+
+// defaultEmbeddingModels are used when a provider has no embedding model set.
+// OpenAI-compatible servers have no common default and fall back to the chat model.
+var defaultEmbeddingModels = map[entities.Type]string{
+	entities.TypeOpenAI: "text-embedding-3-small",
+	entities.TypeGoogle: "text-embedding-004",
+	entities.TypeMock:   MockModelSynthetic,
+}
+
+// embeddingCosts is the price per 1M input tokens of the known embedding models
+var embeddingCosts = map[string]float64{
+	"text-embedding-3-small": 0.02,
+	"text-embedding-3-large": 0.13,
+	"text-embedding-ada-002": 0.10,
+}
+
+// EmbeddingResult contains one vector per embedded text, in the request order
+type EmbeddingResult struct {
+	Vectors [][]float32
+	Model   string // Embedding model that produced the vectors
+	Usage   Usage
+}
+
+// SupportsEmbeddings reports whether the provider can embed text
+func SupportsEmbeddings(providerType entities.Type) bool {
+	switch providerType {
+	case entities.TypeOpenAI, entities.TypeGoogle, entities.TypeOpenAICompatible, entities.TypeMock:
+		return true
+	default:
+		return false
+	}
+}
+
+// EmbeddingModel returns the embedding model used for the provider
+func EmbeddingModel(provider *entities.Provider) string {
+	if model := strings.TrimSpace(provider.EmbeddingModel); model != "" {
+		return model
+	}
+	if model, ok := defaultEmbeddingModels[provider.Type]; ok {
+		return model
+	}
+	return provider.Model
+}
+
+func embeddingCost(model string, inputTokens int) float64 {
+	return (float64(inputTokens) / 1_000_000) * embeddingCosts[model]
+}
+
+func embeddingBatches(texts []string) [][]string {
+	var batches [][]string
+	for start := 0; start < len(texts); start += maxEmbeddingBatch {
+		batches = append(batches, texts[start:min(start+maxEmbeddingBatch, len(texts))])
+	}
+	return batches
+}
+
+// CosineSimilarity returns the cosine of the angle between two vectors,
+// 0 when their sizes differ or one of them is empty
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// syntheticEmbedding hashes the words of text into a fixed size vector,
+// so texts sharing words end up close to each other
+func syntheticEmbedding(text string) []float32 {
+	vector := make([]float32, mockEmbeddingDimensions)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	})
+	for _, word := range words {
+		rng := mockRand("embedding", word)
+		vector[rng.IntN(len(vector))] += 1
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] = float32(float64(vector[i]) / norm)
+		}
+	}
+
+	return vector
+}
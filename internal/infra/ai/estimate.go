@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+const (
+	// DefaultEstimateWords is the article length assumed when no word count is set
+	DefaultEstimateWords = 1000
+
+	// Expected output of an article: ~1.5 tokens per word of HTML plus the title,
+	// excerpt and JSON wrapper around the content
+	estimateTokensPerWord       = 1.5
+	estimateResponseTokens      = 200
+	estimateWordsPerSection     = 300
+	estimateOutlineOutputTokens = 400
+	estimateSectionPromptTokens = 150
+)
+
+// CostEstimate is the expected token usage and price of generating a number of articles
+type CostEstimate struct {
+	Items        int
+	Calls        int
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+	// Priced is false when the model is missing from the pricing catalog, CostUSD is 0 then
+	Priced       bool
+	ProviderType entities.Type
+	Model        string
+}
+
+// NewCostEstimate creates an empty estimate for the given model
+func NewCostEstimate(providerType entities.Type, model string) *CostEstimate {
+	return &CostEstimate{
+		ProviderType: providerType,
+		Model:        model,
+		Priced:       GetModelInfo(providerType, model) != nil,
+	}
+}
+
+// EstimateTokens approximates the token count of a text for any provider
+func EstimateTokens(text string) int {
+	return estimateTokens(text)
+}
+
+// ParseWordCount reads a word count setting such as "1000" or "800-1200".
+// Ranges resolve to their upper bound so estimates err on the expensive side.
+// Returns DefaultEstimateWords when the value is empty or unreadable.
+func ParseWordCount(value string) int {
+	words := 0
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r < '0' || r > '9' }) {
+		if n, err := strconv.Atoi(part); err == nil && n > words {
+			words = n
+		}
+	}
+
+	if words <= 0 {
+		return DefaultEstimateWords
+	}
+	return words
+}
+
+// AddArticle adds the generation of one article from the rendered prompts. The output
+// follows the word count, capped by MaxOutputTokens in single mode. Outline mode adds
+// the outline call and resends the prompt with every section.
+func (e *CostEstimate) AddArticle(systemPrompt, userPrompt string, words int, opts *GenerateArticleOptions) {
+	if words <= 0 {
+		words = DefaultEstimateWords
+	}
+
+	promptTokens := estimateTokens(systemPrompt) + estimateTokens(userPrompt)
+	contentTokens := int(math.Ceil(float64(words) * estimateTokensPerWord))
+
+	var calls, inputTokens, outputTokens int
+	if opts.mode() == entities.GenerationModeOutline {
+		sections := max(1, int(math.Ceil(float64(words)/estimateWordsPerSection)))
+		calls = sections + 1
+		inputTokens = promptTokens*calls + sections*estimateSectionPromptTokens
+		outputTokens = estimateOutlineOutputTokens + contentTokens + sections*estimateResponseTokens
+	} else {
+		calls = 1
+		inputTokens = promptTokens
+		outputTokens = contentTokens + estimateResponseTokens
+		if opts != nil && opts.MaxOutputTokens != nil && *opts.MaxOutputTokens < outputTokens {
+			outputTokens = *opts.MaxOutputTokens
+		}
+	}
+
+	e.Items++
+	e.Calls += calls
+	e.InputTokens += inputTokens
+	e.OutputTokens += outputTokens
+	e.CostUSD += CalculateCost(e.ProviderType, e.Model, inputTokens, outputTokens)
+}
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+func TestParseWordCount(t *testing.T) {
+	cases := map[string]int{
+		"1000":          1000,
+		"800-1200":      1200,
+		" 600 – 900 ":   900,
+		"":              DefaultEstimateWords,
+		"a few hundred": DefaultEstimateWords,
+	}
+
+	for value, expected := range cases {
+		if words := ParseWordCount(value); words != expected {
+			t.Errorf("ParseWordCount(%q) = %d, expected %d", value, words, expected)
+		}
+	}
+}
+
+func TestCostEstimateAddArticle(t *testing.T) {
+	estimate := NewCostEstimate(entities.TypeOpenAI, "gpt-4o-mini")
+	if !estimate.Priced {
+		t.Fatal("expected catalog model to be priced")
+	}
+
+	estimate.AddArticle("You are a writer.", "Write about hiking.", 1000, nil)
+	estimate.AddArticle("You are a writer.", "Write about hiking.", 1000, nil)
+
+	if estimate.Items != 2 || estimate.Calls != 2 {
+		t.Fatalf("expected 2 items in 2 calls, got %d items in %d calls", estimate.Items, estimate.Calls)
+	}
+	if estimate.OutputTokens != 2*(1500+estimateResponseTokens) {
+		t.Errorf("unexpected output tokens: %d", estimate.OutputTokens)
+	}
+	expectedCost := CalculateCost(entities.TypeOpenAI, "gpt-4o-mini", estimate.InputTokens, estimate.OutputTokens)
+	if diff := estimate.CostUSD - expectedCost; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected cost %v, got %v", expectedCost, estimate.CostUSD)
+	}
+
+	// The output budget caps a single call
+	limit := 500
+	capped := NewCostEstimate(entities.TypeOpenAI, "gpt-4o-mini")
+	capped.AddArticle("", "Write about hiking.", 1000, &GenerateArticleOptions{MaxOutputTokens: &limit})
+	if capped.OutputTokens != limit {
+		t.Errorf("expected output capped at %d, got %d", limit, capped.OutputTokens)
+	}
+
+	// Outline mode resends the prompt for every section
+	outline := NewCostEstimate(entities.TypeOpenAI, "gpt-4o-mini")
+	outline.AddArticle("", "Write about hiking.", 1200, &GenerateArticleOptions{Mode: entities.GenerationModeOutline})
+	if outline.Calls != 5 {
+		t.Errorf("expected outline and 4 section calls, got %d", outline.Calls)
+	}
+
+	unknown := NewCostEstimate(entities.TypeOpenAICompatible, "local-model")
+	unknown.AddArticle("", "Write about hiking.", 1000, nil)
+	if unknown.Priced || unknown.CostUSD != 0 || unknown.OutputTokens == 0 {
+		t.Errorf("expected tokens without price for an unknown model, got %+v", unknown)
+	}
+}
@@ -2,12 +2,35 @@ package ai
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/pkg/errors"
 )
 
+// CreateClient creates a client for the provider. Every call made through it is
+// governed by the process-wide rate limiter and concurrency governor, and recorded
+// as fixtures while a recording directory is set.
 func CreateClient(provider *entities.Provider) (Client, error) {
+	client, err := newKeyedClient(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replayed calls are fixtures already
+	if dir := recordingDir(); dir != "" && provider.Type != entities.TypeMock {
+		if client, err = NewRecordingClient(client, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	governed := NewGovernedClient(client, defaultGovernor)
+	governed.embeddingModel = EmbeddingModel(provider)
+	return governed, nil
+}
+
+// newKeyedClient creates a client rotating over the API keys of the provider
+func newKeyedClient(provider *entities.Provider) (Client, error) {
 	if provider == nil {
 		return nil, errors.Validation("provider is required")
 	}
@@ -16,10 +39,33 @@ func CreateClient(provider *entities.Provider) (Client, error) {
 		return nil, errors.Validation("provider is not active")
 	}
 
-	if provider.APIKey == "" {
-		return nil, errors.Validation("API key is required")
+	keys := ProviderKeys(provider)
+	if len(keys) == 0 {
+		if RequiresAPIKey(provider.Type) {
+			return nil, errors.Validation("API key is required")
+		}
+		return newClient(provider, "")
+	}
+
+	rotating := NewRotatingClient(defaultKeyPool)
+	for _, key := range keys {
+		client, err := newClient(provider, key)
+		if err != nil {
+			return nil, err
+		}
+		rotating.AddKey(key, client)
 	}
 
+	return rotating, nil
+}
+
+// CreateKeyClient creates an ungoverned client for the provider bound to one API key,
+// e.g. to check that key on its own. An empty key is used by keyless providers.
+func CreateKeyClient(provider *entities.Provider, apiKey string) (Client, error) {
+	return newClient(provider, apiKey)
+}
+
+func newClient(provider *entities.Provider, apiKey string) (Client, error) {
 	if !ValidateModel(provider.Type, provider.Model) {
 		return nil, errors.Validation(fmt.Sprintf("invalid model %s for provider %s", provider.Model, provider.Type))
 	}
@@ -27,20 +73,39 @@ func CreateClient(provider *entities.Provider) (Client, error) {
 	switch provider.Type {
 	case entities.TypeOpenAI:
 		return NewOpenAIClient(Config{
-			APIKey: provider.APIKey,
-			Model:  provider.Model,
+			APIKey:         apiKey,
+			Model:          provider.Model,
+			EmbeddingModel: EmbeddingModel(provider),
+		})
+
+	case entities.TypeOpenAICompatible:
+		if strings.TrimSpace(provider.BaseURL) == "" {
+			return nil, errors.Validation("base URL is required for OpenAI-compatible provider")
+		}
+		return NewOpenAIClient(Config{
+			APIKey:         apiKey,
+			Model:          provider.Model,
+			BaseURL:        strings.TrimSpace(provider.BaseURL),
+			ProviderType:   entities.TypeOpenAICompatible,
+			EmbeddingModel: EmbeddingModel(provider),
+		})
+
+	case entities.TypeMock:
+		return NewMockClient(MockConfig{
+			Model:       provider.Model,
+			FixturesDir: strings.TrimSpace(provider.BaseURL),
 		})
 
 	// NOTE: Anthropic and Google providers are commented out for now
 	// case entities.TypeAnthropic:
 	// 	return NewAnthropicClient(AnthropicConfig{
-	// 		APIKey: provider.APIKey,
+	// 		APIKey: apiKey,
 	// 		Model:  provider.Model,
 	// 	})
 
 	// case entities.TypeGoogle:
 	// 	return NewGoogleClient(GoogleConfig{
-	// 		APIKey: provider.APIKey,
+	// 		APIKey: apiKey,
 	// 		Model:  provider.Model,
 	// 	})
 
@@ -50,20 +115,25 @@ func CreateClient(provider *entities.Provider) (Client, error) {
 }
 
 func GetProviderModels() map[entities.Type][]*entities.Model {
-	cp := make(map[entities.Type][]*entities.Model)
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
 
-	for provider, models := range availableModels {
+	cp := make(map[entities.Type][]*entities.Model, len(catalog.models))
+	for provider, models := range catalog.models {
 		cpModels := make([]*entities.Model, len(models))
 		copy(cpModels, models)
 
 		cp[provider] = cpModels
 	}
 
-	return availableModels
+	return cp
 }
 
 func GetAvailableModels(providerType entities.Type) []*entities.Model {
-	models, exists := availableModels[providerType]
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	models, exists := catalog.models[providerType]
 	if !exists {
 		return nil
 	}
@@ -73,7 +143,21 @@ func GetAvailableModels(providerType entities.Type) []*entities.Model {
 	return result
 }
 
+// SupportsCustomModels reports whether the provider accepts model IDs outside the built-in catalog.
+func SupportsCustomModels(providerType entities.Type) bool {
+	return providerType == entities.TypeOpenAICompatible
+}
+
+// RequiresAPIKey reports whether the provider cannot be used without an API key.
+func RequiresAPIKey(providerType entities.Type) bool {
+	return providerType != entities.TypeOpenAICompatible && providerType != entities.TypeMock
+}
+
 func ValidateModel(providerType entities.Type, model string) bool {
+	if SupportsCustomModels(providerType) {
+		return strings.TrimSpace(model) != ""
+	}
+
 	models := GetAvailableModels(providerType)
 	for _, m := range models {
 		if m.ID == model {
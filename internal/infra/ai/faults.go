@@ -0,0 +1,162 @@
+package ai
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/davidmovas/postulator/pkg/errors"
+	openaiSDK "github.com/openai/openai-go/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// providerFault is the transport-level view of a provider SDK error
+type providerFault struct {
+	status     int    // HTTP status code, 0 when unknown
+	code       string // provider error code or type, lowercased
+	message    string // lowercased error text
+	retryAfter time.Duration
+}
+
+// classifyError wraps a provider SDK error into a typed AI error, so callers can
+// tell a 429 from an invalid key or a context overflow
+func classifyError(provider string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	f := inspectFault(err)
+
+	switch {
+	case f.status == http.StatusUnauthorized || f.status == http.StatusForbidden ||
+		f.hasCode("invalid_api_key", "authentication_error", "permission_error"):
+		return errors.AIAuth(provider, err)
+
+	case f.hasCode("insufficient_quota", "billing_error") ||
+		f.status == http.StatusPaymentRequired ||
+		(f.status == http.StatusTooManyRequests && f.mentions("quota", "billing", "credit balance")):
+		return errors.AIQuotaExceeded(provider, err)
+
+	case f.hasCode("model_not_found", "not_found_error") ||
+		(f.status == http.StatusNotFound && f.mentions("model")) ||
+		f.mentions("model has been deprecated", "model has been retired", "model is not supported"):
+		return errors.AIModelNotFound(provider, err)
+
+	case f.status == http.StatusTooManyRequests || f.hasCode("rate_limit_exceeded", "rate_limit_error"):
+		return errors.AIRateLimitAfter(provider, f.retryAfter, err)
+
+	case f.hasCode("context_length_exceeded", "string_above_max_length") ||
+		f.status == http.StatusRequestEntityTooLarge ||
+		f.mentions("context length", "context window", "maximum context", "prompt is too long", "too many tokens", "exceeds the maximum number of tokens"):
+		return errors.AIContextLength(provider, err)
+
+	case f.hasCode("content_filter", "content_policy_violation") || f.mentions("safety system", "content management policy"):
+		return errors.AIContentFilter(provider, err)
+
+	case f.status >= http.StatusInternalServerError || f.hasCode("overloaded_error", "server_error", "api_error"):
+		return errors.AIUnavailable(provider, err)
+	}
+
+	return errors.AI(provider, err)
+}
+
+func inspectFault(err error) providerFault {
+	f := providerFault{message: strings.ToLower(err.Error())}
+
+	var openaiErr *openaiSDK.Error
+	var anthropicErr *anthropic.Error
+	var googleErr *googleapi.Error
+
+	switch {
+	case stderrors.As(err, &openaiErr):
+		f.status = openaiErr.StatusCode
+		f.code = strings.ToLower(openaiErr.Code + " " + openaiErr.Type)
+		f.retryAfter = retryAfterHeader(openaiErr.Response)
+
+	case stderrors.As(err, &anthropicErr):
+		f.status = anthropicErr.StatusCode
+		f.code = strings.ToLower(anthropicErr.RawJSON())
+		f.retryAfter = retryAfterHeader(anthropicErr.Response)
+
+	case stderrors.As(err, &googleErr):
+		f.status = googleErr.Code
+		if googleErr.Header != nil {
+			f.retryAfter = parseRetryAfter(googleErr.Header)
+		}
+
+	default:
+		// Gemini reports failures as gRPC statuses
+		if s, ok := status.FromError(err); ok && s.Code() != codes.OK && s.Code() != codes.Unknown {
+			f.status = grpcHTTPStatus(s.Code())
+		}
+	}
+
+	return f
+}
+
+func (f providerFault) hasCode(want ...string) bool {
+	for _, c := range want {
+		if strings.Contains(f.code, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f providerFault) mentions(phrases ...string) bool {
+	for _, p := range phrases {
+		if strings.Contains(f.message, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func grpcHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unavailable, codes.Internal, codes.DeadlineExceeded:
+		return http.StatusServiceUnavailable
+	default:
+		return 0
+	}
+}
+
+func retryAfterHeader(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	return parseRetryAfter(resp.Header)
+}
+
+// parseRetryAfter reads the retry delay from the response headers. OpenAI sends
+// a millisecond precise retry-after-ms next to the standard Retry-After.
+func parseRetryAfter(header http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
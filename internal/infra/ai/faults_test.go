@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+	openaiSDK "github.com/openai/openai-go/v3"
+)
+
+func newOpenAIError(status int, code string, header http.Header) error {
+	req, _ := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", nil)
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return fmt.Errorf("API error: %w", &openaiSDK.Error{
+		Code:       code,
+		StatusCode: status,
+		Request:    req,
+		Response:   &http.Response{StatusCode: status, Header: header},
+	})
+}
+
+func TestClassifyErrorMapsProviderFaults(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want errors.ErrorCode
+	}{
+		{"invalid key", newOpenAIError(http.StatusUnauthorized, "invalid_api_key", nil), errors.ErrCodeAIAuth},
+		{"rate limited", newOpenAIError(http.StatusTooManyRequests, "rate_limit_exceeded", nil), errors.ErrCodeAIRateLimit},
+		{"quota", newOpenAIError(http.StatusTooManyRequests, "insufficient_quota", nil), errors.ErrCodeAIQuotaExceeded},
+		{"context overflow", newOpenAIError(http.StatusBadRequest, "context_length_exceeded", nil), errors.ErrCodeAIContextLength},
+		{"content filter", newOpenAIError(http.StatusBadRequest, "content_policy_violation", nil), errors.ErrCodeAIContentFilter},
+		{"server error", newOpenAIError(http.StatusBadGateway, "", nil), errors.ErrCodeAIUnavailable},
+		{"retired model", newOpenAIError(http.StatusNotFound, "model_not_found", nil), errors.ErrCodeAIModelNotFound},
+		{"bad request", newOpenAIError(http.StatusBadRequest, "invalid_value", nil), errors.ErrCodeAI},
+		{"transport", fmt.Errorf("API error: connection reset"), errors.ErrCodeAI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(providerName, tt.err)
+			if got := errors.AICode(err); got != tt.want {
+				t.Errorf("expected %s, got %s (%v)", tt.want, got, err)
+			}
+			if !errors.IsAI(err) {
+				t.Errorf("expected classified error to still count as an AI error")
+			}
+		})
+	}
+}
+
+func TestClassifyErrorReadsRetryAfter(t *testing.T) {
+	err := classifyError(providerName, newOpenAIError(http.StatusTooManyRequests, "", http.Header{"Retry-After": {"7"}}))
+	if got := errors.RetryAfter(err); got != 7*time.Second {
+		t.Errorf("expected 7s retry-after, got %v", got)
+	}
+	if !errors.IsAIRetryable(err) {
+		t.Error("expected rate limit to be retryable")
+	}
+
+	err = classifyError(providerName, newOpenAIError(http.StatusTooManyRequests, "", http.Header{"Retry-After-Ms": {"1500"}}))
+	if got := errors.RetryAfter(err); got != 1500*time.Millisecond {
+		t.Errorf("expected 1.5s retry-after, got %v", got)
+	}
+
+	err = classifyError(providerName, newOpenAIError(http.StatusUnauthorized, "", nil))
+	if errors.IsAIRetryable(err) {
+		t.Error("expected invalid credentials not to be retryable")
+	}
+}
+
+func TestShrinkOutputHalvesBudget(t *testing.T) {
+	opts, ok := ShrinkOutput(nil)
+	if !ok || *opts.MaxOutputTokens != defaultArticleOutputTokens/2 {
+		t.Fatalf("expected default budget to be halved, got %v", opts)
+	}
+
+	for ok {
+		opts, ok = ShrinkOutput(opts)
+	}
+	if *opts.MaxOutputTokens != minShrunkOutputTokens {
+		t.Errorf("expected budget to stop at %d, got %d", minShrunkOutputTokens, *opts.MaxOutputTokens)
+	}
+}
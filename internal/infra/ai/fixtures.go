@@ -0,0 +1,285 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+// Fixture operations, used as file name prefixes inside a fixtures directory
+const (
+	fixtureOpArticle          = "article"
+	fixtureOpOutline          = "outline"
+	fixtureOpTopicVariations  = "topic_variations"
+	fixtureOpSitemapStructure = "sitemap_structure"
+	fixtureOpLinkSuggestions  = "link_suggestions"
+	fixtureOpInsertLinks      = "insert_links"
+	fixtureOpRewrite          = "rewrite"
+	fixtureOpEmbed            = "embed"
+	fixtureOpImage            = "image"
+)
+
+// fixture is a single recorded request/response pair stored as JSON on disk
+type fixture struct {
+	Operation string          `json:"operation"`
+	Provider  string          `json:"provider"`
+	Model     string          `json:"model"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+type articleRequest struct {
+	SystemPrompt string                  `json:"systemPrompt"`
+	UserPrompt   string                  `json:"userPrompt"`
+	Options      *GenerateArticleOptions `json:"options,omitempty"`
+}
+
+func newArticleRequest(systemPrompt, userPrompt string, opts *GenerateArticleOptions) articleRequest {
+	return articleRequest{SystemPrompt: systemPrompt, UserPrompt: userPrompt, Options: opts}
+}
+
+type outlineRequest struct {
+	SystemPrompt string `json:"systemPrompt"`
+	UserPrompt   string `json:"userPrompt"`
+}
+
+type topicVariationsRequest struct {
+	Topic  string `json:"topic"`
+	Amount int    `json:"amount"`
+}
+
+type sitemapStructureRequest struct {
+	SystemPrompt string `json:"systemPrompt"`
+	UserPrompt   string `json:"userPrompt"`
+}
+
+type embedRequest struct {
+	Texts []string `json:"texts"`
+}
+
+// fixtureStore reads and writes fixtures keyed by a hash of the request,
+// so identical requests always resolve to the same file
+type fixtureStore struct {
+	dir string
+}
+
+func newFixtureStore(dir string) (*fixtureStore, error) {
+	if dir == "" {
+		return nil, errors.Validation("fixtures directory is required")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Internal(fmt.Errorf("failed to create fixtures directory: %w", err))
+	}
+
+	return &fixtureStore{dir: dir}, nil
+}
+
+func (s *fixtureStore) path(operation string, request json.RawMessage) string {
+	sum := sha256.Sum256(append([]byte(operation+":"), request...))
+	return filepath.Join(s.dir, operation+"-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load decodes the recorded response for the request into response.
+// A recorded provider failure is returned as an AI error.
+func (s *fixtureStore) Load(operation string, request, response any) error {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return errors.Internal(err)
+	}
+
+	path := s.path(operation, requestJSON)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return errors.AI(mockProviderName, fmt.Errorf("no recorded fixture for %s request (%s)", operation, filepath.Base(path)))
+	}
+	if err != nil {
+		return errors.Internal(err)
+	}
+
+	var f fixture
+	if err = json.Unmarshal(data, &f); err != nil {
+		return errors.Internal(fmt.Errorf("invalid fixture %s: %w", filepath.Base(path), err))
+	}
+
+	if f.Error != "" {
+		return errors.AI(mockProviderName, fmt.Errorf("recorded failure: %s", f.Error))
+	}
+
+	if err = json.Unmarshal(f.Response, response); err != nil {
+		return errors.Internal(fmt.Errorf("invalid fixture %s: %w", filepath.Base(path), err))
+	}
+
+	return nil
+}
+
+// Save records the request together with either its response or its error
+func (s *fixtureStore) Save(operation, provider, model string, request, response any, callErr error) error {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return errors.Internal(err)
+	}
+
+	f := fixture{
+		Operation: operation,
+		Provider:  provider,
+		Model:     model,
+		Request:   requestJSON,
+	}
+
+	if callErr != nil {
+		f.Error = callErr.Error()
+	} else {
+		if f.Response, err = json.Marshal(response); err != nil {
+			return errors.Internal(err)
+		}
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return errors.Internal(err)
+	}
+
+	if err = os.WriteFile(s.path(operation, requestJSON), data, 0o644); err != nil {
+		return errors.Internal(fmt.Errorf("failed to write fixture: %w", err))
+	}
+
+	return nil
+}
+
+// recording holds the directory calls to real providers are captured into, empty
+// while recording is off
+var recording struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// SetRecordingDir makes clients created afterwards capture every call into dir,
+// producing fixtures for the mock replay mode. An empty dir turns recording off.
+func SetRecordingDir(dir string) {
+	recording.mu.Lock()
+	recording.dir = dir
+	recording.mu.Unlock()
+}
+
+func recordingDir() string {
+	recording.mu.RLock()
+	defer recording.mu.RUnlock()
+	return recording.dir
+}
+
+var _ Client = (*RecordingClient)(nil)
+
+// RecordingClient wraps a real client and captures every request/response pair
+// into a fixtures directory that a replay mock client can later serve offline
+type RecordingClient struct {
+	inner    Client
+	fixtures *fixtureStore
+}
+
+func NewRecordingClient(inner Client, dir string) (*RecordingClient, error) {
+	if inner == nil {
+		return nil, errors.Validation("client to record is required")
+	}
+
+	fixtures, err := newFixtureStore(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordingClient{
+		inner:    inner,
+		fixtures: fixtures,
+	}, nil
+}
+
+func (c *RecordingClient) GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error) {
+	result, err := c.inner.GenerateArticle(ctx, systemPrompt, userPrompt, opts)
+	return record(ctx, c, fixtureOpArticle, newArticleRequest(systemPrompt, userPrompt, opts), result, err)
+}
+
+func (c *RecordingClient) GenerateArticleStream(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error) {
+	result, err := c.inner.GenerateArticleStream(ctx, systemPrompt, userPrompt, opts, onProgress)
+	return record(ctx, c, fixtureOpArticle, newArticleRequest(systemPrompt, userPrompt, opts), result, err)
+}
+
+func (c *RecordingClient) GenerateOutline(ctx context.Context, systemPrompt, userPrompt string) (*OutlineResult, error) {
+	result, err := c.inner.GenerateOutline(ctx, systemPrompt, userPrompt)
+	return record(ctx, c, fixtureOpOutline, outlineRequest{SystemPrompt: systemPrompt, UserPrompt: userPrompt}, result, err)
+}
+
+func (c *RecordingClient) GenerateTopicVariations(ctx context.Context, topic string, amount int) ([]string, error) {
+	result, err := c.inner.GenerateTopicVariations(ctx, topic, amount)
+	return record(ctx, c, fixtureOpTopicVariations, topicVariationsRequest{Topic: topic, Amount: amount}, result, err)
+}
+
+func (c *RecordingClient) GenerateSitemapStructure(ctx context.Context, systemPrompt, userPrompt string) (*SitemapStructureResult, error) {
+	result, err := c.inner.GenerateSitemapStructure(ctx, systemPrompt, userPrompt)
+	return record(ctx, c, fixtureOpSitemapStructure, sitemapStructureRequest{SystemPrompt: systemPrompt, UserPrompt: userPrompt}, result, err)
+}
+
+func (c *RecordingClient) GenerateLinkSuggestions(ctx context.Context, request *LinkSuggestionRequest) (*LinkSuggestionResult, error) {
+	result, err := c.inner.GenerateLinkSuggestions(ctx, request)
+	return record(ctx, c, fixtureOpLinkSuggestions, request, result, err)
+}
+
+func (c *RecordingClient) InsertLinks(ctx context.Context, request *InsertLinksRequest) (*InsertLinksResult, error) {
+	result, err := c.inner.InsertLinks(ctx, request)
+	return record(ctx, c, fixtureOpInsertLinks, request, result, err)
+}
+
+func (c *RecordingClient) RewriteArticle(ctx context.Context, request *RewriteArticleRequest) (*ArticleResult, error) {
+	result, err := c.inner.RewriteArticle(ctx, request)
+	return record(ctx, c, fixtureOpRewrite, request, result, err)
+}
+
+func (c *RecordingClient) Embed(ctx context.Context, texts []string) (*EmbeddingResult, error) {
+	result, err := c.inner.Embed(ctx, texts)
+	return record(ctx, c, fixtureOpEmbed, embedRequest{Texts: texts}, result, err)
+}
+
+func (c *RecordingClient) GenerateImage(ctx context.Context, request *ImageRequest) (*ImageResult, error) {
+	result, err := c.inner.GenerateImage(ctx, request)
+	return record(ctx, c, fixtureOpImage, request, result, err)
+}
+
+// Ping is not recorded, a replayed provider is always reachable
+func (c *RecordingClient) Ping(ctx context.Context) (*PingResult, error) {
+	return c.inner.Ping(ctx)
+}
+
+func (c *RecordingClient) GetProviderName() string {
+	return c.inner.GetProviderName()
+}
+
+func (c *RecordingClient) GetModelName() string {
+	return c.inner.GetModelName()
+}
+
+// Unwrap returns the recorded client. Batches go to it directly and are not recorded.
+func (c *RecordingClient) Unwrap() Client {
+	return c.inner
+}
+
+// record stores the call outcome and passes it through unchanged. Cancelled
+// calls are not recorded, they say nothing about the provider's behaviour.
+func record[T any](ctx context.Context, c *RecordingClient, operation string, request any, result T, callErr error) (T, error) {
+	if callErr != nil && ctx.Err() != nil {
+		return result, callErr
+	}
+
+	if err := c.fixtures.Save(operation, c.inner.GetProviderName(), c.inner.GetModelName(), request, result, callErr); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return result, callErr
+}
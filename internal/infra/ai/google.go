@@ -2,12 +2,13 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/davidmovas/postulator/internal/domain/entities"
 	"github.com/davidmovas/postulator/pkg/errors"
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -16,8 +17,9 @@ const googleProviderName = "Google"
 var _ Client = (*GoogleClient)(nil)
 
 type GoogleClient struct {
-	client *genai.Client
-	model  string
+	client         *genai.Client
+	model          string
+	embeddingModel string
 }
 
 func (c *GoogleClient) GetProviderName() string {
@@ -29,8 +31,9 @@ func (c *GoogleClient) GetModelName() string {
 }
 
 type GoogleConfig struct {
-	APIKey string
-	Model  string
+	APIKey         string
+	Model          string
+	EmbeddingModel string
 }
 
 func NewGoogleClient(cfg GoogleConfig) (*GoogleClient, error) {
@@ -42,6 +45,10 @@ func NewGoogleClient(cfg GoogleConfig) (*GoogleClient, error) {
 		cfg.Model = "gemini-1.5-flash"
 	}
 
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = defaultEmbeddingModels[entities.TypeGoogle]
+	}
+
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
 	if err != nil {
@@ -49,18 +56,85 @@ func NewGoogleClient(cfg GoogleConfig) (*GoogleClient, error) {
 	}
 
 	return &GoogleClient{
-		client: client,
-		model:  cfg.Model,
+		client:         client,
+		model:          cfg.Model,
+		embeddingModel: cfg.EmbeddingModel,
 	}, nil
 }
 
 func (c *GoogleClient) GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error) {
+	model := c.articleModel(systemPrompt, opts)
+
+	// Generate response
+	resp, err := model.GenerateContent(ctx, genai.Text(userPrompt))
+	if err != nil {
+		return nil, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
+	}
+
+	text, err := responseText(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.articleResult(ctx, text, resp.UsageMetadata, *model.MaxOutputTokens)
+}
+
+func (c *GoogleClient) GenerateArticleStream(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error) {
+	model := c.articleModel(systemPrompt, opts)
+
+	tracker := newStreamTracker(onProgress)
+	var usage *genai.UsageMetadata
+
+	iter := model.GenerateContentStream(ctx, genai.Text(userPrompt))
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, classifyError(googleProviderName, fmt.Errorf("stream error: %w", err))
+		}
+
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
+
+		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+			continue
+		}
+
+		for _, part := range resp.Candidates[0].Content.Parts {
+			if text, ok := part.(genai.Text); ok {
+				tracker.Write(string(text))
+			}
+		}
+	}
+
+	tracker.Flush()
+
+	return c.articleResult(ctx, tracker.String(), usage, *model.MaxOutputTokens)
+}
+
+// articleModel configures a generative model for article generation
+func (c *GoogleClient) articleModel(systemPrompt string, opts *GenerateArticleOptions) *genai.GenerativeModel {
 	model := c.client.GenerativeModel(c.model)
 
 	// Configure the model
 	// 4096 is plenty for 800-1500 words of content
-	model.SetTemperature(0.7)
-	model.SetMaxOutputTokens(4096)
+	maxTokens := opts.maxOutputTokens(4096)
+	modelInfo := GetModelInfo(entities.TypeGoogle, c.model)
+	if modelInfo != nil && modelInfo.MaxOutputTokens > 0 {
+		maxTokens = min(maxTokens, modelInfo.MaxOutputTokens)
+	}
+	model.SetMaxOutputTokens(int32(maxTokens))
+
+	if modelInfo == nil || !modelInfo.IsReasoningModel {
+		model.SetTemperature(float32(opts.temperature(0.7)))
+		if topP := opts.topP(); topP != nil {
+			model.SetTopP(float32(*topP))
+		}
+		model.StopSequences = opts.stop()
+	}
 
 	// Set system instruction
 	jsonInstructions := `
@@ -77,67 +151,82 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 		Parts: []genai.Part{genai.Text(systemPrompt + "\n\n" + jsonInstructions)},
 	}
 
-	// Generate response
-	resp, err := model.GenerateContent(ctx, genai.Text(userPrompt))
-	if err != nil {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("API error: %w", err))
+	return model
+}
+
+// articleResult parses the response text into an ArticleResult
+func (c *GoogleClient) articleResult(ctx context.Context, responseText string, metadata *genai.UsageMetadata, maxTokens int32) (*ArticleResult, error) {
+	if responseText == "" {
+		return nil, errors.AI(googleProviderName, fmt.Errorf("no text content in response"))
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no response from API"))
+	article, repairUsage, err := parseStructured[ArticleContent](ctx, responseText, c.repairer(maxTokens))
+	if err != nil {
+		return nil, errors.AI(googleProviderName, err)
 	}
 
-	// Extract text from response
-	var responseText string
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText = string(text)
-			break
+	usage := c.usage(metadata).add(repairUsage)
+
+	return &ArticleResult{
+		Title:      article.Title,
+		Excerpt:    article.Excerpt,
+		Content:    article.Content,
+		TokensUsed: usage.TotalTokens,
+		Cost:       usage.CostUSD,
+		Usage:      usage,
+	}, nil
+}
+
+// repairer returns a repairFunc that asks the same model to fix a malformed response
+func (c *GoogleClient) repairer(maxTokens int32) repairFunc {
+	return func(ctx context.Context, systemPrompt, userPrompt string) (string, Usage, error) {
+		model := c.client.GenerativeModel(c.model)
+		model.SetTemperature(0)
+		model.SetMaxOutputTokens(maxTokens)
+		model.ResponseMIMEType = "application/json"
+		model.SystemInstruction = &genai.Content{
+			Parts: []genai.Part{genai.Text(systemPrompt)},
 		}
+
+		resp, err := model.GenerateContent(ctx, genai.Text(userPrompt))
+		if err != nil {
+			return "", Usage{}, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
+		}
+
+		text, err := responseText(resp)
+		return text, c.usage(resp.UsageMetadata), err
 	}
+}
 
-	if responseText == "" {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no text content in response"))
+// responseText returns the first text part of the first candidate
+func responseText(resp *genai.GenerateContentResponse) (string, error) {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", errors.AI(googleProviderName, fmt.Errorf("no response from API"))
 	}
 
-	// Parse JSON from response
-	var article ArticleContent
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &article); err != nil {
-		// Try sanitizing the JSON to fix invalid escape sequences
-		sanitized := sanitizeJSON(jsonStr)
-		if err2 := json.Unmarshal([]byte(sanitized), &article); err2 != nil {
-			return nil, errors.AI(googleProviderName, fmt.Errorf("failed to parse response: %w, raw: %s", err, responseText[:min(200, len(responseText))]))
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok && text != "" {
+			return string(text), nil
 		}
 	}
 
-	if article.Title == "" || article.Content == "" {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("empty title or content in response"))
-	}
+	return "", errors.AI(googleProviderName, fmt.Errorf("no text content in response"))
+}
 
-	// Calculate tokens and cost
+func (c *GoogleClient) usage(metadata *genai.UsageMetadata) Usage {
 	inputTokens := 0
 	outputTokens := 0
-	if resp.UsageMetadata != nil {
-		inputTokens = int(resp.UsageMetadata.PromptTokenCount)
-		outputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+	if metadata != nil {
+		inputTokens = int(metadata.PromptTokenCount)
+		outputTokens = int(metadata.CandidatesTokenCount)
 	}
-	totalTokens := inputTokens + outputTokens
-	cost := CalculateCost(entities.TypeGoogle, c.model, inputTokens, outputTokens)
 
-	return &ArticleResult{
-		Title:      article.Title,
-		Excerpt:    article.Excerpt,
-		Content:    article.Content,
-		TokensUsed: totalTokens,
-		Cost:       cost,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
-	}, nil
+	return Usage{
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		TotalTokens:  inputTokens + outputTokens,
+		CostUSD:      CalculateCost(entities.TypeGoogle, c.model, inputTokens, outputTokens),
+	}
 }
 
 func (c *GoogleClient) GenerateTopicVariations(ctx context.Context, topic string, amount int) ([]string, error) {
@@ -165,53 +254,59 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 
 	resp, err := model.GenerateContent(ctx, genai.Text(userPrompt))
 	if err != nil {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no response from API"))
+	text, err := responseText(resp)
+	if err != nil {
+		return nil, err
 	}
 
-	var responseText string
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText = string(text)
-			break
-		}
+	result, _, err := parseStructured[TopicVariations](ctx, text, c.repairer(1024))
+	if err != nil {
+		return nil, errors.AI(googleProviderName, err)
 	}
 
-	if responseText == "" {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no text content in response"))
-	}
+	return topicVariations(result, amount), nil
+}
 
-	var result TopicVariations
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("failed to parse variations: %w", err))
+// Close closes the Google AI client
+func (c *GoogleClient) Close() error {
+	if c.client != nil {
+		return c.client.Close()
 	}
+	return nil
+}
 
-	if len(result.Variations) == 0 {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no variations generated"))
-	}
+func (c *GoogleClient) GenerateOutline(ctx context.Context, systemPrompt, userPrompt string) (*OutlineResult, error) {
+	const outlineTokens = 4096
 
-	if len(result.Variations) > amount {
-		result.Variations = result.Variations[:amount]
+	model := c.client.GenerativeModel(c.model)
+	model.SetTemperature(0.5)
+	model.SetMaxOutputTokens(outlineTokens)
+	model.SystemInstruction = &genai.Content{
+		Parts: []genai.Part{genai.Text(systemPrompt + "\n\n" + outlineJSONInstructions)},
 	}
 
-	cleanedVariations := make([]string, len(result.Variations))
-	for i, variation := range result.Variations {
-		cleanedVariations[i] = cleanQuotes(variation)
+	resp, err := model.GenerateContent(ctx, genai.Text(userPrompt))
+	if err != nil {
+		return nil, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	return cleanedVariations, nil
-}
+	text, err := responseText(resp)
+	if err != nil {
+		return nil, err
+	}
 
-// Close closes the Google AI client
-func (c *GoogleClient) Close() error {
-	if c.client != nil {
-		return c.client.Close()
+	result, repairUsage, err := parseStructured[ArticleOutline](ctx, text, c.repairer(outlineTokens))
+	if err != nil {
+		return nil, errors.AI(googleProviderName, err)
 	}
-	return nil
+
+	return &OutlineResult{
+		Outline: *result,
+		Usage:   c.usage(resp.UsageMetadata).add(repairUsage),
+	}, nil
 }
 
 func (c *GoogleClient) GenerateSitemapStructure(ctx context.Context, systemPrompt, userPrompt string) (*SitemapStructureResult, error) {
@@ -249,55 +344,26 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 
 	resp, err := model.GenerateContent(ctx, genai.Text(userPrompt))
 	if err != nil {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("API error: %w", err))
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no response from API"))
-	}
-
-	var responseText string
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText = string(text)
-			break
-		}
+		return nil, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	if responseText == "" {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no text content in response"))
+	text, err := responseText(resp)
+	if err != nil {
+		return nil, err
 	}
 
-	var result SitemapStructureSchema
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("failed to parse response: %w, raw: %s", err, responseText[:min(200, len(responseText))]))
+	result, repairUsage, err := parseStructured[SitemapStructureSchema](ctx, text, c.repairer(8192))
+	if err != nil {
+		return nil, errors.AI(googleProviderName, err)
 	}
 
-	if len(result.Nodes) == 0 {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no nodes generated"))
-	}
-
-	// Calculate tokens and cost
-	inputTokens := 0
-	outputTokens := 0
-	if resp.UsageMetadata != nil {
-		inputTokens = int(resp.UsageMetadata.PromptTokenCount)
-		outputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
-	}
-	totalTokens := inputTokens + outputTokens
-	cost := CalculateCost(entities.TypeGoogle, c.model, inputTokens, outputTokens)
+	usage := c.usage(resp.UsageMetadata).add(repairUsage)
 
 	return &SitemapStructureResult{
 		Nodes:      result.Nodes,
-		TokensUsed: totalTokens,
-		Cost:       cost,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
+		TokensUsed: usage.TotalTokens,
+		Cost:       usage.CostUSD,
+		Usage:      usage,
 	}, nil
 }
 
@@ -341,52 +407,23 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 
 	resp, err := model.GenerateContent(ctx, genai.Text(userPrompt))
 	if err != nil {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("API error: %w", err))
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no response from API"))
+		return nil, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	var responseText string
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText = string(text)
-			break
-		}
-	}
-
-	if responseText == "" {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no text content in response"))
-	}
-
-	var result LinkSuggestionSchema
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		sanitized := sanitizeJSON(jsonStr)
-		if err2 := json.Unmarshal([]byte(sanitized), &result); err2 != nil {
-			return nil, errors.AI(googleProviderName, fmt.Errorf("failed to parse response: %w", err))
-		}
+	text, err := responseText(resp)
+	if err != nil {
+		return nil, err
 	}
 
-	inputTokens := 0
-	outputTokens := 0
-	if resp.UsageMetadata != nil {
-		inputTokens = int(resp.UsageMetadata.PromptTokenCount)
-		outputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+	result, repairUsage, err := parseStructured[LinkSuggestionSchema](ctx, text, c.repairer(4096))
+	if err != nil {
+		return nil, errors.AI(googleProviderName, err)
 	}
-	totalTokens := inputTokens + outputTokens
-	cost := CalculateCost(entities.TypeGoogle, c.model, inputTokens, outputTokens)
 
 	return &LinkSuggestionResult{
 		Links:       result.Links,
 		Explanation: result.Explanation,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
+		Usage:       c.usage(resp.UsageMetadata).add(repairUsage),
 	}, nil
 }
 
@@ -432,57 +469,80 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 
 	resp, err := model.GenerateContent(ctx, genai.Text(userPrompt))
 	if err != nil {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no response from API"))
+	text, err := responseText(resp)
+	if err != nil {
+		return nil, err
 	}
 
-	var responseText string
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			responseText = string(text)
-			break
-		}
+	result, repairUsage, err := parseStructured[InsertLinksContentSchema](ctx, text, c.repairer(16384))
+	if err != nil {
+		return nil, errors.AI(googleProviderName, err)
 	}
 
-	if responseText == "" {
-		return nil, errors.AI(googleProviderName, fmt.Errorf("no text content in response"))
-	}
+	usage := c.usage(resp.UsageMetadata).add(repairUsage)
 
-	var result InsertLinksContentSchema
-	jsonStr := extractJSON(responseText)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		sanitized := sanitizeJSON(jsonStr)
-		if err2 := json.Unmarshal([]byte(sanitized), &result); err2 != nil {
-			preview := jsonStr
-			if len(preview) > 300 {
-				preview = preview[:300] + "..."
-			}
-			return nil, errors.AI(googleProviderName, fmt.Errorf("failed to parse response: %w, raw: %s", err, preview))
+	fmt.Printf("[Google] InsertLinks success: linksApplied=%d, cost=$%.4f\n", result.LinksApplied, usage.CostUSD)
+
+	return &InsertLinksResult{
+		Content:      result.Content,
+		LinksApplied: result.LinksApplied,
+		Usage:        usage,
+	}, nil
+}
+
+func (c *GoogleClient) RewriteArticle(ctx context.Context, request *RewriteArticleRequest) (*ArticleResult, error) {
+	systemPrompt, userPrompt := rewritePrompts(request)
+	return c.GenerateArticle(ctx, systemPrompt, userPrompt, rewriteOptions(request))
+}
+
+func (c *GoogleClient) Embed(ctx context.Context, texts []string) (*EmbeddingResult, error) {
+	model := c.client.EmbeddingModel(c.embeddingModel)
+	result := &EmbeddingResult{Model: c.embeddingModel}
+
+	for _, batch := range embeddingBatches(texts) {
+		request := model.NewBatch()
+		for _, text := range batch {
+			request.AddContent(genai.Text(text))
+		}
+
+		resp, err := model.BatchEmbedContents(ctx, request)
+		if err != nil {
+			return nil, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
+		}
+
+		if len(resp.Embeddings) != len(batch) {
+			return nil, errors.AI(googleProviderName, fmt.Errorf("expected %d embeddings, got %d", len(batch), len(resp.Embeddings)))
 		}
+
+		for _, embedding := range resp.Embeddings {
+			result.Vectors = append(result.Vectors, embedding.Values)
+		}
+
+		// The embeddings API reports no usage
+		inputTokens := estimateTokens(strings.Join(batch, "\n"))
+		result.Usage.InputTokens += inputTokens
+		result.Usage.TotalTokens += inputTokens
 	}
 
-	inputTokens := 0
-	outputTokens := 0
-	if resp.UsageMetadata != nil {
-		inputTokens = int(resp.UsageMetadata.PromptTokenCount)
-		outputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+	return result, nil
+}
+
+func (c *GoogleClient) Ping(ctx context.Context) (*PingResult, error) {
+	model := c.client.GenerativeModel(c.model)
+	model.SetMaxOutputTokens(pingOutputTokens)
+
+	resp, err := model.GenerateContent(ctx, genai.Text(pingPrompt))
+	if err != nil {
+		return nil, classifyError(googleProviderName, fmt.Errorf("API error: %w", err))
 	}
-	totalTokens := inputTokens + outputTokens
-	cost := CalculateCost(entities.TypeGoogle, c.model, inputTokens, outputTokens)
 
-	fmt.Printf("[Google] InsertLinks success: linksApplied=%d, cost=$%.4f\n", result.LinksApplied, cost)
+	return &PingResult{Usage: c.usage(resp.UsageMetadata)}, nil
+}
 
-	return &InsertLinksResult{
-		Content:      result.Content,
-		LinksApplied: result.LinksApplied,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
-	}, nil
+// GenerateImage is not available through the Gemini API client in use
+func (c *GoogleClient) GenerateImage(ctx context.Context, request *ImageRequest) (*ImageResult, error) {
+	return nil, errors.Validation("Google does not generate images, use an OpenAI provider")
 }
@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+var _ Client = (*GovernedClient)(nil)
+
+// GovernedClient wraps a client so every call goes through a Governor first.
+// Calls are weighted by their estimated input tokens and reconciled with the
+// reported usage once they complete.
+type GovernedClient struct {
+	inner    Client
+	governor *Governor
+	// embeddingModel and imageModel key the lanes of embedding and image calls, which
+	// are limited apart from the chat model
+	embeddingModel string
+	imageModel     string
+}
+
+func NewGovernedClient(inner Client, governor *Governor) *GovernedClient {
+	providerType := entities.Type(inner.GetProviderName())
+
+	c := &GovernedClient{
+		inner:          inner,
+		governor:       governor,
+		embeddingModel: defaultEmbeddingModels[providerType],
+		imageModel:     defaultImageModels[providerType],
+	}
+	if c.embeddingModel == "" {
+		c.embeddingModel = inner.GetModelName()
+	}
+	if c.imageModel == "" {
+		c.imageModel = inner.GetModelName()
+	}
+	return c
+}
+
+func (c *GovernedClient) GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error) {
+	return govern(ctx, c, estimateTokens(systemPrompt+userPrompt), func() (*ArticleResult, error) {
+		return c.inner.GenerateArticle(ctx, systemPrompt, userPrompt, opts)
+	}, func(r *ArticleResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) GenerateArticleStream(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error) {
+	return govern(ctx, c, estimateTokens(systemPrompt+userPrompt), func() (*ArticleResult, error) {
+		return c.inner.GenerateArticleStream(ctx, systemPrompt, userPrompt, opts, onProgress)
+	}, func(r *ArticleResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) GenerateOutline(ctx context.Context, systemPrompt, userPrompt string) (*OutlineResult, error) {
+	return govern(ctx, c, estimateTokens(systemPrompt+userPrompt), func() (*OutlineResult, error) {
+		return c.inner.GenerateOutline(ctx, systemPrompt, userPrompt)
+	}, func(r *OutlineResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) GenerateTopicVariations(ctx context.Context, topic string, amount int) ([]string, error) {
+	estimated := estimateTokens(topic)
+	return govern(ctx, c, estimated, func() ([]string, error) {
+		return c.inner.GenerateTopicVariations(ctx, topic, amount)
+	}, func(r []string) int { return estimated + estimateTokens(strings.Join(r, "\n")) })
+}
+
+func (c *GovernedClient) GenerateSitemapStructure(ctx context.Context, systemPrompt, userPrompt string) (*SitemapStructureResult, error) {
+	return govern(ctx, c, estimateTokens(systemPrompt+userPrompt), func() (*SitemapStructureResult, error) {
+		return c.inner.GenerateSitemapStructure(ctx, systemPrompt, userPrompt)
+	}, func(r *SitemapStructureResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) GenerateLinkSuggestions(ctx context.Context, request *LinkSuggestionRequest) (*LinkSuggestionResult, error) {
+	estimated := estimateTokens(request.SystemPrompt + request.UserPrompt)
+	for _, node := range request.Nodes {
+		estimated += estimateTokens(node.Title + node.Path + node.Content)
+	}
+
+	return govern(ctx, c, estimated, func() (*LinkSuggestionResult, error) {
+		return c.inner.GenerateLinkSuggestions(ctx, request)
+	}, func(r *LinkSuggestionResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) InsertLinks(ctx context.Context, request *InsertLinksRequest) (*InsertLinksResult, error) {
+	estimated := estimateTokens(request.SystemPrompt + request.UserPrompt + request.Content)
+
+	return govern(ctx, c, estimated, func() (*InsertLinksResult, error) {
+		return c.inner.InsertLinks(ctx, request)
+	}, func(r *InsertLinksResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) RewriteArticle(ctx context.Context, request *RewriteArticleRequest) (*ArticleResult, error) {
+	estimated := estimateTokens(request.SystemPrompt + request.UserPrompt + request.Instructions + request.Content)
+
+	return govern(ctx, c, estimated, func() (*ArticleResult, error) {
+		return c.inner.RewriteArticle(ctx, request)
+	}, func(r *ArticleResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) Embed(ctx context.Context, texts []string) (*EmbeddingResult, error) {
+	return governModel(ctx, c, c.embeddingModel, estimateTokens(strings.Join(texts, "\n")), func() (*EmbeddingResult, error) {
+		return c.inner.Embed(ctx, texts)
+	}, func(r *EmbeddingResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) GenerateImage(ctx context.Context, request *ImageRequest) (*ImageResult, error) {
+	return governModel(ctx, c, c.imageModel, estimateTokens(request.Prompt), func() (*ImageResult, error) {
+		return c.inner.GenerateImage(ctx, request)
+	}, func(r *ImageResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) Ping(ctx context.Context) (*PingResult, error) {
+	return govern(ctx, c, estimateTokens(pingPrompt)+pingOutputTokens, func() (*PingResult, error) {
+		return c.inner.Ping(ctx)
+	}, func(r *PingResult) int { return r.Usage.TotalTokens })
+}
+
+func (c *GovernedClient) GetProviderName() string {
+	return c.inner.GetProviderName()
+}
+
+func (c *GovernedClient) GetModelName() string {
+	return c.inner.GetModelName()
+}
+
+// Unwrap returns the governed client
+func (c *GovernedClient) Unwrap() Client {
+	return c.inner
+}
+
+// govern runs call under a governor permit, releasing it with the tokens the
+// call actually used (or the estimate when the call failed without a result)
+func govern[T any](ctx context.Context, c *GovernedClient, estimatedTokens int, call func() (T, error), usedTokens func(T) int) (T, error) {
+	return governModel(ctx, c, c.inner.GetModelName(), estimatedTokens, call, usedTokens)
+}
+
+// governModel is govern in the lane of the given model of the provider
+func governModel[T any](ctx context.Context, c *GovernedClient, model string, estimatedTokens int, call func() (T, error), usedTokens func(T) int) (T, error) {
+	permit, err := c.governor.Acquire(ctx, entities.Type(c.inner.GetProviderName()), model, estimatedTokens)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	result, err := call()
+
+	used := estimatedTokens
+	if err == nil {
+		used = usedTokens(result)
+	}
+	permit.Release(used)
+
+	return result, err
+}
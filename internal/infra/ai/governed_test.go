@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+// laneClient stands in for a provider client, answering embedding and image calls
+type laneClient struct{ Client }
+
+func (laneClient) GetProviderName() string { return string(entities.TypeOpenAI) }
+
+func (laneClient) GetModelName() string { return "gpt-4o-mini" }
+
+func (laneClient) Embed(context.Context, []string) (*EmbeddingResult, error) {
+	return &EmbeddingResult{}, nil
+}
+
+func (laneClient) GenerateImage(context.Context, *ImageRequest) (*ImageResult, error) {
+	return &ImageResult{}, nil
+}
+
+func TestGovernedClientKeepsEmbeddingsAndImagesOutOfChatLane(t *testing.T) {
+	g := NewGovernor()
+	g.SetLimits(entities.TypeOpenAI, "gpt-4o-mini", Limits{RequestsPerMin: 6000, BurstSize: 10, MaxConcurrent: 1})
+
+	// A long chat call holds the only slot of the chat model
+	chat, err := acquireWithin(g, "gpt-4o-mini", 0, time.Second)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer chat.Release(0)
+
+	client := NewGovernedClient(laneClient{}, g)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err = client.Embed(ctx, []string{"text"}); err != nil {
+		t.Errorf("expected embeddings to run in their own lane: %v", err)
+	}
+	if _, err = client.GenerateImage(ctx, &ImageRequest{Prompt: "a lake"}); err != nil {
+		t.Errorf("expected images to run in their own lane: %v", err)
+	}
+}
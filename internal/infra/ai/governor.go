@@ -0,0 +1,212 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"golang.org/x/time/rate"
+)
+
+// Limits are the per provider+model limits enforced by the Governor
+type Limits struct {
+	RequestsPerMin int
+	TokensPerMin   int // 0 disables token-weighted limiting
+	BurstSize      int
+	MaxConcurrent  int
+}
+
+const (
+	// Concurrency is derived from RPM: assume ~6 seconds per request (10 requests
+	// per minute per worker) and stay at 80% of the theoretical max
+	rpmConcurrencyMultiplier = 8 // RPM * 0.8 / 10 = RPM * 8 / 100
+	rpmConcurrencyDivisor    = 100
+	minConcurrency           = 1
+	maxConcurrency           = 10
+
+	maxBurstSize = 20
+)
+
+// Default fallback limits for providers (used when the model or its limits are unknown)
+var defaultProviderLimits = map[entities.Type]Limits{
+	entities.TypeOpenAI:    {RequestsPerMin: 60, BurstSize: 10, MaxConcurrent: 4},
+	entities.TypeAnthropic: {RequestsPerMin: 50, BurstSize: 5, MaxConcurrent: 4},
+	entities.TypeGoogle:    {RequestsPerMin: 60, BurstSize: 10, MaxConcurrent: 4},
+}
+
+var fallbackLimits = Limits{RequestsPerMin: 30, BurstSize: 3, MaxConcurrent: 2}
+
+// ModelLimits returns the limits for a model from the model catalog
+func ModelLimits(providerType entities.Type, modelID string) Limits {
+	model := GetModelInfo(providerType, modelID)
+	if model == nil || model.RPM <= 0 {
+		if limits, ok := defaultProviderLimits[providerType]; ok {
+			return limits
+		}
+		return fallbackLimits
+	}
+
+	// Burst size is 1/6 of RPM (allows 10-second bursts), capped to avoid overwhelming the API
+	burstSize := clamp(model.RPM/6, 1, maxBurstSize)
+
+	return Limits{
+		RequestsPerMin: model.RPM,
+		TokensPerMin:   max(model.TPM, 0),
+		BurstSize:      burstSize,
+		MaxConcurrent:  clamp(model.RPM*rpmConcurrencyMultiplier/rpmConcurrencyDivisor, minConcurrency, maxConcurrency),
+	}
+}
+
+// MaxConcurrency returns how many calls to a model may be in flight at once
+func MaxConcurrency(providerType entities.Type, modelID string) int {
+	return ModelLimits(providerType, modelID).MaxConcurrent
+}
+
+func clamp(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
+
+// Governor enforces RPM, token-weighted TPM and concurrency limits per provider+model,
+// shared by every client in the process so concurrent jobs, sitemap tasks and
+// linking runs draw from the same budget instead of tripping 429s on each other.
+//
+// Lanes are not split per API key: the keys of a provider are a failover pool that
+// takes over when a key is rate limited or out of quota, not extra throughput. Calls
+// go to the first available key, so one lane sized for one key matches the traffic.
+// A pool of keys doesn't raise the limits, the RPM and TPM of the model do.
+type Governor struct {
+	lanes sync.Map // key: "provider:model" -> *lane
+	mu    sync.Mutex
+}
+
+// lane holds the limiters of one provider+model. It remembers the limits it was
+// built with, so it can be rebuilt when the model catalog changes. Lanes set with
+// SetLimits are kept as is.
+type lane struct {
+	limits   Limits
+	manual   bool
+	requests *rate.Limiter
+	tokens   *rate.Limiter
+	slots    chan struct{}
+}
+
+func newLane(limits Limits, manual bool) *lane {
+	limits.RequestsPerMin = max(limits.RequestsPerMin, 1)
+	limits.BurstSize = max(limits.BurstSize, 1)
+	limits.MaxConcurrent = max(limits.MaxConcurrent, 1)
+
+	l := &lane{
+		limits:   limits,
+		manual:   manual,
+		requests: rate.NewLimiter(rate.Every(time.Minute/time.Duration(limits.RequestsPerMin)), limits.BurstSize),
+		slots:    make(chan struct{}, limits.MaxConcurrent),
+	}
+	if limits.TokensPerMin > 0 {
+		// Allow up to one minute worth of tokens at once
+		l.tokens = rate.NewLimiter(rate.Limit(float64(limits.TokensPerMin)/60), limits.TokensPerMin)
+	}
+	return l
+}
+
+func (l *lane) matches(limits Limits) bool {
+	return l.manual || l.limits == limits
+}
+
+// tokenCost clamps a token count to what the token limiter can grant at once
+func (l *lane) tokenCost(tokens int) int {
+	return clamp(tokens, 0, l.tokens.Burst())
+}
+
+var defaultGovernor = NewGovernor()
+
+func NewGovernor() *Governor {
+	return &Governor{}
+}
+
+// DefaultGovernor returns the process-wide governor used by clients from CreateClient
+func DefaultGovernor() *Governor {
+	return defaultGovernor
+}
+
+// getLaneKey creates a unique key for provider+model combination
+func getLaneKey(providerType entities.Type, model string) string {
+	return string(providerType) + ":" + model
+}
+
+func (g *Governor) getLane(providerType entities.Type, model string) *lane {
+	key := getLaneKey(providerType, model)
+	limits := ModelLimits(providerType, model)
+
+	if l, ok := g.lanes.Load(key); ok && l.(*lane).matches(limits) {
+		return l.(*lane)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Double-check after acquiring lock
+	if l, ok := g.lanes.Load(key); ok && l.(*lane).matches(limits) {
+		return l.(*lane)
+	}
+
+	l := newLane(limits, false)
+	g.lanes.Store(key, l)
+	return l
+}
+
+// SetLimits manually sets limits for a provider+model combination
+func (g *Governor) SetLimits(providerType entities.Type, model string, limits Limits) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.lanes.Store(getLaneKey(providerType, model), newLane(limits, true))
+}
+
+// Permit is a granted call slot. It must be released once the call completes.
+type Permit struct {
+	lane     *lane
+	reserved int
+	once     sync.Once
+}
+
+// Acquire waits until a call with the estimated token count may start: a free
+// concurrency slot, a request token and, if the model has a TPM limit, enough tokens.
+func (g *Governor) Acquire(ctx context.Context, providerType entities.Type, model string, estimatedTokens int) (*Permit, error) {
+	l := g.getLane(providerType, model)
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	permit := &Permit{lane: l}
+
+	if err := l.requests.Wait(ctx); err != nil {
+		permit.Release(0)
+		return nil, err
+	}
+
+	if l.tokens != nil {
+		permit.reserved = l.tokenCost(estimatedTokens)
+		if err := l.tokens.WaitN(ctx, permit.reserved); err != nil {
+			permit.reserved = 0
+			permit.Release(0)
+			return nil, err
+		}
+	}
+
+	return permit, nil
+}
+
+// Release frees the concurrency slot. Tokens used beyond the estimate are charged
+// to the TPM limiter, delaying later calls instead of this one.
+func (p *Permit) Release(usedTokens int) {
+	p.once.Do(func() {
+		if p.lane.tokens != nil && usedTokens > p.reserved {
+			p.lane.tokens.ReserveN(time.Now(), p.lane.tokenCost(usedTokens-p.reserved))
+		}
+		<-p.lane.slots
+	})
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+func acquireWithin(g *Governor, model string, tokens int, timeout time.Duration) (*Permit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return g.Acquire(ctx, entities.TypeOpenAI, model, tokens)
+}
+
+func TestGovernorLimitsConcurrency(t *testing.T) {
+	g := NewGovernor()
+	g.SetLimits(entities.TypeOpenAI, "test", Limits{RequestsPerMin: 6000, BurstSize: 10, MaxConcurrent: 1})
+
+	first, err := acquireWithin(g, "test", 0, time.Second)
+	if err != nil {
+		t.Fatalf("expected first call to be admitted: %v", err)
+	}
+
+	if _, err = acquireWithin(g, "test", 0, 50*time.Millisecond); err == nil {
+		t.Fatal("expected second call to wait for the free slot")
+	}
+
+	first.Release(0)
+	first.Release(0) // releasing twice must not free a second slot
+
+	second, err := acquireWithin(g, "test", 0, time.Second)
+	if err != nil {
+		t.Fatalf("expected call to be admitted after release: %v", err)
+	}
+	if _, err = acquireWithin(g, "test", 0, 50*time.Millisecond); err == nil {
+		t.Fatal("expected double release to keep the concurrency limit")
+	}
+	second.Release(0)
+}
+
+func TestGovernorChargesTokens(t *testing.T) {
+	g := NewGovernor()
+	g.SetLimits(entities.TypeOpenAI, "test", Limits{RequestsPerMin: 6000, TokensPerMin: 600, BurstSize: 10, MaxConcurrent: 5})
+
+	permit, err := acquireWithin(g, "test", 100, time.Second)
+	if err != nil {
+		t.Fatalf("expected call within token budget to be admitted: %v", err)
+	}
+
+	// The call used far more than estimated, the excess must delay later calls
+	permit.Release(600)
+
+	if _, err = acquireWithin(g, "test", 100, 100*time.Millisecond); err == nil {
+		t.Fatal("expected call to wait after the token budget was exceeded")
+	}
+}
+
+func TestModelLimitsFollowCatalog(t *testing.T) {
+	t.Cleanup(func() { SetUserModels(nil) })
+
+	if limits := ModelLimits(entities.TypeOpenAICompatible, "unknown"); limits != fallbackLimits {
+		t.Errorf("expected fallback limits for unknown model, got %+v", limits)
+	}
+
+	SetUserModels([]*entities.Model{
+		{ID: "slow", Name: "Slow", Provider: entities.TypeOpenAICompatible, RPM: 12, TPM: 1000},
+	})
+
+	limits := ModelLimits(entities.TypeOpenAICompatible, "slow")
+	if limits.RequestsPerMin != 12 || limits.TokensPerMin != 1000 || limits.BurstSize != 2 || limits.MaxConcurrent != 1 {
+		t.Errorf("unexpected limits from catalog: %+v", limits)
+	}
+}
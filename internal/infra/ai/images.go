@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+const (
+	// DefaultImageSize is a landscape format suited to featured images
+	DefaultImageSize = "1536x1024"
+
+	// maxImagePromptSummary keeps the article summary in image prompts short
+	maxImagePromptSummary = 600
+
+	// mockImageWidth and mockImageHeight are the dimensions of the stub images
+	mockImageWidth  = 384
+	mockImageHeight = 256
+)
+
+// defaultImageModels are the image models used for providers that can generate images
+var defaultImageModels = map[entities.Type]string{
+	entities.TypeOpenAI: "gpt-image-1",
+	entities.TypeMock:   MockModelSynthetic,
+}
+
+// imageCosts is the price per 1M input and output tokens of the known image models
+var imageCosts = map[string]struct{ input, output float64 }{
+	"gpt-image-1": {input: 5, output: 40},
+}
+
+// ImageRequest describes an image to generate
+type ImageRequest struct {
+	Prompt string `json:"prompt"`
+	// Size is WIDTHxHEIGHT, empty uses DefaultImageSize
+	Size string `json:"size,omitempty"`
+}
+
+// ImageResult is a generated image ready for upload
+type ImageResult struct {
+	Data     []byte
+	MimeType string
+	Model    string // Image model that produced the image
+	Usage    Usage
+}
+
+// Extension returns the file extension matching the image's MIME type
+func (r *ImageResult) Extension() string {
+	switch r.MimeType {
+	case "image/jpeg":
+		return "jpg"
+	case "image/webp":
+		return "webp"
+	default:
+		return "png"
+	}
+}
+
+// SupportsImages reports whether the provider can generate images
+func SupportsImages(providerType entities.Type) bool {
+	_, ok := defaultImageModels[providerType]
+	return ok
+}
+
+// FeaturedImagePrompt builds the prompt of a featured image from the article's title
+// and summary. Images with text in them rarely render well, so the prompt rules it out.
+func FeaturedImagePrompt(title, summary string) string {
+	summary = strings.TrimSpace(summary)
+	if len(summary) > maxImagePromptSummary {
+		summary = strings.TrimSpace(strings.ToValidUTF8(summary[:maxImagePromptSummary], "")) + "..."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Create a featured image for a blog article. ")
+	sb.WriteString("Use a clean, modern editorial style without any text, letters, logos or watermarks.\n\n")
+	fmt.Fprintf(&sb, "Article title: %s\n", strings.TrimSpace(title))
+	if summary != "" {
+		fmt.Fprintf(&sb, "Article summary: %s\n", summary)
+	}
+	return sb.String()
+}
+
+func imageCost(model string, usage Usage) float64 {
+	costs := imageCosts[model]
+	return (float64(usage.InputTokens)/1_000_000)*costs.input + (float64(usage.OutputTokens)/1_000_000)*costs.output
+}
+
+// stubImage renders a deterministic two-color gradient for the prompt, standing in
+// for a generated image when working offline
+func stubImage(prompt string) ([]byte, error) {
+	r := mockRand("image", prompt)
+	from := color.RGBA{R: uint8(r.IntN(256)), G: uint8(r.IntN(256)), B: uint8(r.IntN(256)), A: 255}
+	to := color.RGBA{R: uint8(r.IntN(256)), G: uint8(r.IntN(256)), B: uint8(r.IntN(256)), A: 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, mockImageWidth, mockImageHeight))
+	for x := 0; x < mockImageWidth; x++ {
+		t := float64(x) / float64(mockImageWidth-1)
+		c := color.RGBA{
+			R: blend(from.R, to.R, t),
+			G: blend(from.G, to.G, t),
+			B: blend(from.B, to.B, t),
+			A: 255,
+		}
+		for y := 0; y < mockImageHeight; y++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func blend(from, to uint8, t float64) uint8 {
+	return uint8(float64(from) + (float64(to)-float64(from))*t)
+}
@@ -0,0 +1,21 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFeaturedImagePromptTruncatesOnRuneBoundary(t *testing.T) {
+	// Two-byte runes put the cut in the middle of one
+	summary := "a" + strings.Repeat("é", maxImagePromptSummary)
+
+	prompt := FeaturedImagePrompt("Title", summary)
+
+	if !utf8.ValidString(prompt) {
+		t.Fatal("expected the prompt to stay valid UTF-8")
+	}
+	if !strings.Contains(prompt, "...") {
+		t.Error("expected the summary to be marked as truncated")
+	}
+}
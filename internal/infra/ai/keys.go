@@ -0,0 +1,346 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+const (
+	// rateLimitCooldown benches a rate limited key when the provider did not say for how long
+	rateLimitCooldown = time.Minute
+	// quotaCooldown benches a key whose quota or billing is exhausted, which rarely recovers soon
+	quotaCooldown = time.Hour
+)
+
+// KeyFingerprint identifies an API key in logs and usage records without revealing it
+func KeyFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// ProviderKeys returns the API keys of the provider in rotation order: the provider's
+// own key first, then the active keys of its pool. Duplicates are dropped.
+func ProviderKeys(provider *entities.Provider) []string {
+	seen := make(map[string]struct{})
+	var keys []string
+
+	add := func(key string) {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	add(provider.APIKey)
+	for _, key := range provider.Keys {
+		if key.IsActive {
+			add(key.APIKey)
+		}
+	}
+
+	return keys
+}
+
+// KeyStats are the usage counters and cool-down state of an API key since the app started
+type KeyStats struct {
+	Fingerprint   string
+	Requests      int64
+	Failures      int64
+	LastUsedAt    *time.Time
+	CooldownUntil *time.Time
+	LastError     string
+}
+
+// KeyPool tracks every API key used by the process. Clients are created per operation,
+// so the state lives here to survive them.
+type KeyPool struct {
+	mu    sync.Mutex
+	stats map[string]*KeyStats
+}
+
+var defaultKeyPool = NewKeyPool()
+
+func NewKeyPool() *KeyPool {
+	return &KeyPool{stats: make(map[string]*KeyStats)}
+}
+
+// DefaultKeyPool returns the process-wide key pool used by clients from CreateClient
+func DefaultKeyPool() *KeyPool {
+	return defaultKeyPool
+}
+
+// Stats returns a snapshot of the key's state
+func (p *KeyPool) Stats(fingerprint string) KeyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s, ok := p.stats[fingerprint]; ok {
+		return *s
+	}
+	return KeyStats{Fingerprint: fingerprint}
+}
+
+// Reset ends the cool-down of the key, e.g. once the user topped up its quota
+func (p *KeyPool) Reset(fingerprint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s, ok := p.stats[fingerprint]; ok {
+		s.CooldownUntil = nil
+	}
+}
+
+// coolingDown reports how long the key stays benched, zero when it is available
+func (p *KeyPool) coolingDown(fingerprint string, now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.stats[fingerprint]
+	if !ok || s.CooldownUntil == nil || !s.CooldownUntil.After(now) {
+		return 0
+	}
+	return s.CooldownUntil.Sub(now)
+}
+
+// record counts a call made with the key and benches it on a rate limit or quota error
+func (p *KeyPool) record(fingerprint string, err error, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.stats[fingerprint]
+	if !ok {
+		s = &KeyStats{Fingerprint: fingerprint}
+		p.stats[fingerprint] = s
+	}
+
+	s.Requests++
+	s.LastUsedAt = &now
+	if err == nil {
+		s.CooldownUntil = nil
+		return
+	}
+
+	s.Failures++
+	s.LastError = err.Error()
+
+	if cooldown := keyCooldown(err); cooldown > 0 {
+		until := now.Add(cooldown)
+		s.CooldownUntil = &until
+	}
+}
+
+// keyCooldown returns how long a key is benched after err, zero when another key
+// would not do better
+func keyCooldown(err error) time.Duration {
+	switch errors.AICode(err) {
+	case errors.ErrCodeAIRateLimit:
+		if retryAfter := errors.RetryAfter(err); retryAfter > 0 {
+			return retryAfter
+		}
+		return rateLimitCooldown
+	case errors.ErrCodeAIQuotaExceeded:
+		return quotaCooldown
+	default:
+		return 0
+	}
+}
+
+// UsedKey returns the fingerprint of the API key a call was made with, from its usage
+// or, for a failed call, from its error. Empty when the client has no keys.
+func UsedKey(usage Usage, err error) string {
+	if usage.KeyFingerprint != "" {
+		return usage.KeyFingerprint
+	}
+
+	var ae *errors.AppError
+	if stderrors.As(err, &ae) {
+		fingerprint, _ := ae.Context["key_fingerprint"].(string)
+		return fingerprint
+	}
+	return ""
+}
+
+var _ Client = (*RotatingClient)(nil)
+
+type keyClient struct {
+	fingerprint string
+	client      Client
+}
+
+// RotatingClient fails the calls of a provider over between its API keys. Calls go to
+// the first available key, a key hitting a rate limit or its quota is benched for a
+// while and the call is retried with the next one. The keys share the governor lane
+// of the provider and model, they don't add throughput.
+type RotatingClient struct {
+	keys []keyClient
+	pool *KeyPool
+}
+
+func NewRotatingClient(pool *KeyPool) *RotatingClient {
+	return &RotatingClient{pool: pool}
+}
+
+// AddKey appends a client bound to apiKey to the rotation
+func (c *RotatingClient) AddKey(apiKey string, client Client) {
+	c.keys = append(c.keys, keyClient{fingerprint: KeyFingerprint(apiKey), client: client})
+}
+
+func (c *RotatingClient) GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error) {
+	return rotate(ctx, c, func(client Client) (*ArticleResult, error) {
+		return client.GenerateArticle(ctx, systemPrompt, userPrompt, opts)
+	}, func(r *ArticleResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) GenerateArticleStream(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error) {
+	return rotate(ctx, c, func(client Client) (*ArticleResult, error) {
+		return client.GenerateArticleStream(ctx, systemPrompt, userPrompt, opts, onProgress)
+	}, func(r *ArticleResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) GenerateOutline(ctx context.Context, systemPrompt, userPrompt string) (*OutlineResult, error) {
+	return rotate(ctx, c, func(client Client) (*OutlineResult, error) {
+		return client.GenerateOutline(ctx, systemPrompt, userPrompt)
+	}, func(r *OutlineResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) GenerateTopicVariations(ctx context.Context, topic string, amount int) ([]string, error) {
+	return rotate(ctx, c, func(client Client) ([]string, error) {
+		return client.GenerateTopicVariations(ctx, topic, amount)
+	}, func([]string) *Usage { return nil })
+}
+
+func (c *RotatingClient) GenerateSitemapStructure(ctx context.Context, systemPrompt, userPrompt string) (*SitemapStructureResult, error) {
+	return rotate(ctx, c, func(client Client) (*SitemapStructureResult, error) {
+		return client.GenerateSitemapStructure(ctx, systemPrompt, userPrompt)
+	}, func(r *SitemapStructureResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) GenerateLinkSuggestions(ctx context.Context, request *LinkSuggestionRequest) (*LinkSuggestionResult, error) {
+	return rotate(ctx, c, func(client Client) (*LinkSuggestionResult, error) {
+		return client.GenerateLinkSuggestions(ctx, request)
+	}, func(r *LinkSuggestionResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) InsertLinks(ctx context.Context, request *InsertLinksRequest) (*InsertLinksResult, error) {
+	return rotate(ctx, c, func(client Client) (*InsertLinksResult, error) {
+		return client.InsertLinks(ctx, request)
+	}, func(r *InsertLinksResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) RewriteArticle(ctx context.Context, request *RewriteArticleRequest) (*ArticleResult, error) {
+	return rotate(ctx, c, func(client Client) (*ArticleResult, error) {
+		return client.RewriteArticle(ctx, request)
+	}, func(r *ArticleResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) Embed(ctx context.Context, texts []string) (*EmbeddingResult, error) {
+	return rotate(ctx, c, func(client Client) (*EmbeddingResult, error) {
+		return client.Embed(ctx, texts)
+	}, func(r *EmbeddingResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) GenerateImage(ctx context.Context, request *ImageRequest) (*ImageResult, error) {
+	return rotate(ctx, c, func(client Client) (*ImageResult, error) {
+		return client.GenerateImage(ctx, request)
+	}, func(r *ImageResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) Ping(ctx context.Context) (*PingResult, error) {
+	return rotate(ctx, c, func(client Client) (*PingResult, error) {
+		return client.Ping(ctx)
+	}, func(r *PingResult) *Usage { return &r.Usage })
+}
+
+func (c *RotatingClient) GetProviderName() string {
+	return c.keys[0].client.GetProviderName()
+}
+
+func (c *RotatingClient) GetModelName() string {
+	return c.keys[0].client.GetModelName()
+}
+
+// batchKey returns the key a batch is sent with. Batches can only be read with the
+// key that submitted them, so they never rotate: an empty fingerprint picks the first
+// key that is not cooling down for a new batch, a batch is polled with its own key.
+func (c *RotatingClient) batchKey(fingerprint string) (keyClient, bool) {
+	if fingerprint != "" {
+		for _, key := range c.keys {
+			if key.fingerprint == fingerprint {
+				return key, true
+			}
+		}
+		return keyClient{}, false
+	}
+
+	now := time.Now()
+	for _, key := range c.keys {
+		if c.pool.coolingDown(key.fingerprint, now) == 0 {
+			return key, true
+		}
+	}
+	return c.keys[0], true
+}
+
+// rotate runs call with the available keys in order until one succeeds or fails with
+// an error another key would not avoid. The key used is recorded in the result's usage
+// or in the error's context.
+func rotate[T any](ctx context.Context, c *RotatingClient, call func(Client) (T, error), usage func(T) *Usage) (T, error) {
+	var zero T
+	var lastErr error
+	var wait time.Duration
+
+	for _, key := range c.keys {
+		if remaining := c.pool.coolingDown(key.fingerprint, time.Now()); remaining > 0 {
+			if wait == 0 || remaining < wait {
+				wait = remaining
+			}
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+
+		result, err := call(key.client)
+		c.pool.record(key.fingerprint, err, time.Now())
+
+		if err == nil {
+			if u := usage(result); u != nil {
+				u.KeyFingerprint = key.fingerprint
+			}
+			return result, nil
+		}
+
+		var ae *errors.AppError
+		if stderrors.As(err, &ae) {
+			ae.WithContext("key_fingerprint", key.fingerprint)
+		}
+
+		if keyCooldown(err) == 0 {
+			return result, err
+		}
+		lastErr = err
+	}
+
+	if lastErr != nil {
+		return zero, lastErr
+	}
+
+	// Every key is benched, the caller may retry once the first one recovers
+	return zero, errors.AIRateLimitAfter(c.GetProviderName(), wait,
+		fmt.Errorf("all %d API keys are cooling down", len(c.keys)))
+}
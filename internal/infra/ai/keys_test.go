@@ -0,0 +1,139 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+// scriptedClient fails its article calls with err, a nil err succeeds
+type scriptedClient struct {
+	*MockClient
+	err   error
+	calls int
+}
+
+func (c *scriptedClient) GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.MockClient.GenerateArticle(ctx, systemPrompt, userPrompt, opts)
+}
+
+func newScriptedClient(t *testing.T, err error) *scriptedClient {
+	t.Helper()
+
+	mock, mockErr := NewMockClient(MockConfig{Model: MockModelSynthetic})
+	if mockErr != nil {
+		t.Fatalf("NewMockClient: %v", mockErr)
+	}
+	return &scriptedClient{MockClient: mock, err: err}
+}
+
+func TestRotatingClientSkipsRateLimitedKey(t *testing.T) {
+	pool := NewKeyPool()
+	limited := newScriptedClient(t, errors.AIRateLimitAfter("openai", 30*time.Second, fmt.Errorf("429")))
+	healthy := newScriptedClient(t, nil)
+
+	client := NewRotatingClient(pool)
+	client.AddKey("key-1", limited)
+	client.AddKey("key-2", healthy)
+
+	result, err := client.GenerateArticle(context.Background(), "system", "user", nil)
+	if err != nil {
+		t.Fatalf("GenerateArticle: %v", err)
+	}
+	if result.Usage.KeyFingerprint != KeyFingerprint("key-2") {
+		t.Errorf("expected usage of the second key, got %q", result.Usage.KeyFingerprint)
+	}
+
+	stats := pool.Stats(KeyFingerprint("key-1"))
+	if stats.Requests != 1 || stats.Failures != 1 || stats.CooldownUntil == nil {
+		t.Fatalf("expected the first key to cool down, got %+v", stats)
+	}
+
+	// The benched key is not tried again until its cool-down ends
+	if _, err = client.GenerateArticle(context.Background(), "system", "user", nil); err != nil {
+		t.Fatalf("GenerateArticle: %v", err)
+	}
+	if limited.calls != 1 || healthy.calls != 2 {
+		t.Errorf("expected calls 1/2, got %d/%d", limited.calls, healthy.calls)
+	}
+
+	pool.Reset(KeyFingerprint("key-1"))
+	limited.err = nil
+	if result, err = client.GenerateArticle(context.Background(), "system", "user", nil); err != nil {
+		t.Fatalf("GenerateArticle: %v", err)
+	}
+	if result.Usage.KeyFingerprint != KeyFingerprint("key-1") {
+		t.Errorf("expected the first key back in rotation, got %q", result.Usage.KeyFingerprint)
+	}
+}
+
+func TestRotatingClientStopsOnOtherErrors(t *testing.T) {
+	filtered := newScriptedClient(t, errors.AIContentFilter("openai", fmt.Errorf("filtered")))
+	healthy := newScriptedClient(t, nil)
+
+	client := NewRotatingClient(NewKeyPool())
+	client.AddKey("key-1", filtered)
+	client.AddKey("key-2", healthy)
+
+	_, err := client.GenerateArticle(context.Background(), "system", "user", nil)
+	if errors.AICode(err) != errors.ErrCodeAIContentFilter {
+		t.Fatalf("expected content filter error, got %v", err)
+	}
+	if healthy.calls != 0 {
+		t.Error("expected a content filter error not to rotate keys")
+	}
+	if UsedKey(Usage{}, err) != KeyFingerprint("key-1") {
+		t.Errorf("expected the failing key on the error, got %q", UsedKey(Usage{}, err))
+	}
+}
+
+func TestRotatingClientAllKeysCoolingDown(t *testing.T) {
+	pool := NewKeyPool()
+	first := newScriptedClient(t, errors.AIQuotaExceeded("openai", fmt.Errorf("quota")))
+	second := newScriptedClient(t, errors.AIRateLimitAfter("openai", 20*time.Second, fmt.Errorf("429")))
+
+	client := NewRotatingClient(pool)
+	client.AddKey("key-1", first)
+	client.AddKey("key-2", second)
+
+	if _, err := client.GenerateArticle(context.Background(), "system", "user", nil); errors.AICode(err) != errors.ErrCodeAIRateLimit {
+		t.Fatalf("expected the last rate limit error, got %v", err)
+	}
+
+	_, err := client.GenerateArticle(context.Background(), "system", "user", nil)
+	if !errors.IsAIRetryable(err) {
+		t.Fatalf("expected a retryable error, got %v", err)
+	}
+	if wait := errors.RetryAfter(err); wait <= 0 || wait > 20*time.Second {
+		t.Errorf("expected to wait for the second key, got %s", wait)
+	}
+	if first.calls != 1 || second.calls != 1 {
+		t.Errorf("expected no calls while benched, got %d/%d", first.calls, second.calls)
+	}
+}
+
+func TestProviderKeysOrder(t *testing.T) {
+	provider := &entities.Provider{
+		APIKey: "primary",
+		Keys: []*entities.ProviderKey{
+			{APIKey: "second", IsActive: true},
+			{APIKey: "disabled", IsActive: false},
+			{APIKey: "primary", IsActive: true},
+			{APIKey: "third", IsActive: true},
+		},
+	}
+
+	keys := ProviderKeys(provider)
+	expected := []string{"primary", "second", "third"}
+	if fmt.Sprint(keys) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+}
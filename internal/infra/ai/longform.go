@@ -0,0 +1,145 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+const (
+	// Output budget of a section: ~1.5 tokens per word plus room for HTML and the JSON wrapper
+	sectionTokensPerWord   = 2
+	sectionOverheadTokens  = 512
+	minSectionOutputTokens = 1024
+)
+
+// outlineJSONInstructions describe the outline format to providers without structured outputs
+const outlineJSONInstructions = `
+You must respond with a valid JSON object in the following format:
+{
+  "title": "Article title",
+  "excerpt": "Brief summary for previews",
+  "sections": [
+    {
+      "heading": "Section heading",
+      "summary": "What the section covers",
+      "targetWords": 400,
+      "keywords": ["keyword1", "keyword2"]
+    }
+  ]
+}
+
+Do not include any text before or after the JSON object. Only output the JSON.`
+
+// outlineInstructions turn the article request into a planning request
+const outlineInstructions = `
+---
+Do not write the article yet. Plan it as an outline instead:
+- Split it into sections in reading order, the first one introducing the topic and the last one concluding it.
+- Give every section a heading, a short summary of what it covers, its target length in words and the keywords it should use.
+- The target lengths must add up to the total length requested above.
+- Also provide the final article title and a brief excerpt.`
+
+// ComposeArticle generates an article in the mode selected by opts. Single mode is one
+// streamed GenerateArticleStream call. Outline mode first asks for an outline, then
+// generates every section with its own call and stitches them into the final HTML,
+// so long articles are not capped by the model's output limit.
+func ComposeArticle(ctx context.Context, client Client, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error) {
+	if opts.mode() != entities.GenerationModeOutline {
+		return client.GenerateArticleStream(ctx, systemPrompt, userPrompt, opts, onProgress)
+	}
+
+	outline, err := client.GenerateOutline(ctx, systemPrompt, userPrompt+"\n"+outlineInstructions)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := outline.Usage
+	progress := StreamProgress{PartialTitle: outline.Outline.Title}
+
+	var content strings.Builder
+	for i, section := range outline.Outline.Sections {
+		// Progress of a section is reported on top of the sections already done
+		var streamed StreamProgress
+		sectionProgress := func(p StreamProgress) {
+			streamed = p
+			if onProgress != nil {
+				onProgress(StreamProgress{
+					Chars:        progress.Chars + p.Chars,
+					Tokens:       progress.Tokens + p.Tokens,
+					PartialTitle: outline.Outline.Title,
+				})
+			}
+		}
+
+		result, err := client.GenerateArticleStream(ctx, systemPrompt, buildSectionPrompt(userPrompt, &outline.Outline, i), sectionOptions(opts, section), sectionProgress)
+		if err != nil {
+			return nil, fmt.Errorf("section %d/%d (%s): %w", i+1, len(outline.Outline.Sections), section.Heading, err)
+		}
+
+		usage = usage.add(result.Usage)
+		progress.Chars += max(streamed.Chars, len(result.Content))
+		progress.Tokens += max(streamed.Tokens, estimateTokens(result.Content))
+
+		content.WriteString("<h2>" + html.EscapeString(section.Heading) + "</h2>\n")
+		content.WriteString(strings.TrimSpace(result.Content))
+		content.WriteString("\n")
+	}
+
+	return &ArticleResult{
+		Title:      outline.Outline.Title,
+		Excerpt:    outline.Outline.Excerpt,
+		Content:    content.String(),
+		TokensUsed: usage.TotalTokens,
+		Cost:       usage.CostUSD,
+		Usage:      usage,
+	}, nil
+}
+
+// sectionOptions sizes the output budget to the section. An explicit MaxOutputTokens
+// applies per section and is kept when smaller.
+func sectionOptions(opts *GenerateArticleOptions, section OutlineSection) *GenerateArticleOptions {
+	var sectionOpts GenerateArticleOptions
+	if opts != nil {
+		sectionOpts = *opts
+	}
+
+	budget := max(section.TargetWords*sectionTokensPerWord+sectionOverheadTokens, minSectionOutputTokens)
+	if opts != nil && opts.MaxOutputTokens != nil && *opts.MaxOutputTokens < budget {
+		budget = *opts.MaxOutputTokens
+	}
+	sectionOpts.MaxOutputTokens = &budget
+
+	return &sectionOpts
+}
+
+// buildSectionPrompt asks for one section of the outline, keeping the original
+// request and the whole outline as context so sections don't overlap
+func buildSectionPrompt(userPrompt string, outline *ArticleOutline, index int) string {
+	section := outline.Sections[index]
+
+	var b strings.Builder
+	b.WriteString(userPrompt)
+	b.WriteString("\n\n---\n")
+	fmt.Fprintf(&b, "The article %q is written section by section following this outline:\n", outline.Title)
+	for i, s := range outline.Sections {
+		fmt.Fprintf(&b, "%d. %s (~%d words)\n", i+1, s.Heading, s.TargetWords)
+	}
+
+	fmt.Fprintf(&b, "\nWrite ONLY section %d: %q.\n", index+1, section.Heading)
+	if section.Summary != "" {
+		fmt.Fprintf(&b, "It covers: %s\n", section.Summary)
+	}
+	fmt.Fprintf(&b, "Target length: about %d words.\n", section.TargetWords)
+	if len(section.Keywords) > 0 {
+		fmt.Fprintf(&b, "Use these keywords naturally: %s\n", strings.Join(section.Keywords, ", "))
+	}
+	b.WriteString("\nPut the section body in the content field as HTML, without the section heading (it is added automatically). " +
+		"Do not repeat what other sections cover and do not add an introduction or conclusion for the whole article unless this section is one. " +
+		"Use the section heading as the title and one sentence summarizing the section as the excerpt.")
+
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+func TestComposeArticleOutlineMode(t *testing.T) {
+	ctx := context.Background()
+	client := newSyntheticClient(t)
+
+	userPrompt := "Best hiking trails\nWrite 3000 words"
+
+	// The same outline request ComposeArticle makes
+	outline, err := client.GenerateOutline(ctx, "system", userPrompt+"\n"+outlineInstructions)
+	if err != nil {
+		t.Fatalf("GenerateOutline: %v", err)
+	}
+
+	maxTokens := 700
+	opts := &GenerateArticleOptions{Mode: entities.GenerationModeOutline, MaxOutputTokens: &maxTokens}
+
+	var lastTokens int
+	result, err := ComposeArticle(ctx, client, "system", userPrompt, opts, func(p StreamProgress) {
+		if p.Tokens < lastTokens {
+			t.Errorf("expected progress to grow across sections, went from %d to %d", lastTokens, p.Tokens)
+		}
+		lastTokens = p.Tokens
+	})
+	if err != nil {
+		t.Fatalf("ComposeArticle: %v", err)
+	}
+
+	if result.Title != outline.Outline.Title {
+		t.Errorf("expected outline title %q, got %q", outline.Outline.Title, result.Title)
+	}
+	for _, section := range outline.Outline.Sections {
+		if !strings.Contains(result.Content, "<h2>"+section.Heading+"</h2>") {
+			t.Errorf("expected content to contain section %q", section.Heading)
+		}
+	}
+	if result.Usage.TotalTokens <= outline.Usage.TotalTokens {
+		t.Errorf("expected usage to include the section calls, got %d", result.Usage.TotalTokens)
+	}
+	if lastTokens == 0 {
+		t.Error("expected progress to be reported")
+	}
+}
+
+func TestComposeArticleSingleModeIsOneCall(t *testing.T) {
+	ctx := context.Background()
+	client := newSyntheticClient(t)
+
+	direct, err := client.GenerateArticle(ctx, "system", "Best hiking trails", nil)
+	if err != nil {
+		t.Fatalf("GenerateArticle: %v", err)
+	}
+
+	composed, err := ComposeArticle(ctx, client, "system", "Best hiking trails", nil, nil)
+	if err != nil {
+		t.Fatalf("ComposeArticle: %v", err)
+	}
+
+	if composed.Content != direct.Content {
+		t.Error("expected single mode to return the article unchanged")
+	}
+}
+
+func TestSectionOptionsSizeBudget(t *testing.T) {
+	section := OutlineSection{Heading: "Intro", TargetWords: 1000}
+
+	if got := *sectionOptions(nil, section).MaxOutputTokens; got != 1000*sectionTokensPerWord+sectionOverheadTokens {
+		t.Errorf("expected budget sized to the section, got %d", got)
+	}
+
+	limit := 800
+	if got := *sectionOptions(&GenerateArticleOptions{MaxOutputTokens: &limit}, section).MaxOutputTokens; got != limit {
+		t.Errorf("expected smaller explicit limit to be kept, got %d", got)
+	}
+}
@@ -0,0 +1,502 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+const mockProviderName = string(entities.TypeMock)
+
+// Mock models select how the mock client produces its output
+const (
+	// MockModelSynthetic generates deterministic content derived from the request
+	MockModelSynthetic = "synthetic"
+	// MockModelReplay serves responses previously captured by a RecordingClient
+	MockModelReplay = "replay"
+)
+
+// mockStreamChunkSize is the number of bytes emitted per simulated stream delta
+const mockStreamChunkSize = 64
+
+var mockWords = []string{
+	"content", "strategy", "practical", "guide", "simple", "approach", "results", "quality",
+	"process", "planning", "example", "common", "mistakes", "benefits", "overview", "steps",
+	"modern", "essential", "tools", "tips", "growth", "value", "details", "insight",
+	"reliable", "method", "clear", "audience", "search", "structure", "useful", "focus",
+}
+
+var mockVariationSuffixes = []string{
+	"A Practical Guide", "Common Mistakes to Avoid", "Tips for Beginners",
+	"Everything You Need to Know", "Best Practices", "A Step-by-Step Overview",
+	"Frequently Asked Questions", "Key Benefits Explained",
+}
+
+type MockConfig struct {
+	Model       string
+	FixturesDir string // Required in replay mode
+}
+
+var _ Client = (*MockClient)(nil)
+
+// MockClient is an offline client. In synthetic mode the output is a pure
+// function of the request, so repeated runs produce identical results.
+type MockClient struct {
+	model    string
+	fixtures *fixtureStore
+}
+
+func NewMockClient(cfg MockConfig) (*MockClient, error) {
+	client := &MockClient{model: cfg.Model}
+
+	switch cfg.Model {
+	case MockModelSynthetic:
+	case MockModelReplay:
+		fixtures, err := newFixtureStore(cfg.FixturesDir)
+		if err != nil {
+			return nil, err
+		}
+		client.fixtures = fixtures
+	default:
+		return nil, errors.Validation(fmt.Sprintf("unsupported mock model: %s", cfg.Model))
+	}
+
+	return client, nil
+}
+
+func (c *MockClient) GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error) {
+	return c.GenerateArticleStream(ctx, systemPrompt, userPrompt, opts, nil)
+}
+
+func (c *MockClient) GenerateArticleStream(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	var result *ArticleResult
+	if c.fixtures != nil {
+		result = &ArticleResult{}
+		if err := c.fixtures.Load(fixtureOpArticle, newArticleRequest(systemPrompt, userPrompt, opts), result); err != nil {
+			return nil, err
+		}
+	} else {
+		result = syntheticArticle(systemPrompt, userPrompt, opts.seed())
+	}
+
+	// Replay the article as a JSON stream so progress consumers see realistic deltas
+	raw, err := json.Marshal(map[string]string{
+		"title":   result.Title,
+		"excerpt": result.Excerpt,
+		"content": result.Content,
+	})
+	if err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	tracker := newStreamTracker(onProgress)
+	for start := 0; start < len(raw); start += mockStreamChunkSize {
+		if err = ctx.Err(); err != nil {
+			return nil, errors.AI(mockProviderName, fmt.Errorf("stream error: %w", err))
+		}
+		tracker.Write(string(raw[start:min(start+mockStreamChunkSize, len(raw))]))
+	}
+	tracker.Flush()
+
+	return result, nil
+}
+
+func (c *MockClient) GenerateOutline(ctx context.Context, systemPrompt, userPrompt string) (*OutlineResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	if c.fixtures != nil {
+		result := &OutlineResult{}
+		if err := c.fixtures.Load(fixtureOpOutline, outlineRequest{SystemPrompt: systemPrompt, UserPrompt: userPrompt}, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	rng := mockRand(fixtureOpOutline, systemPrompt, userPrompt)
+
+	title := syntheticTitle(userPrompt)
+	if title == "" {
+		title = mockTitleCase(mockPhrase(rng, 5))
+	}
+
+	outline := ArticleOutline{
+		Title:   title,
+		Excerpt: mockSentence(rng),
+	}
+	for i := 0; i < 4; i++ {
+		outline.Sections = append(outline.Sections, OutlineSection{
+			Heading:     mockTitleCase(mockPhrase(rng, 4)),
+			Summary:     mockSentence(rng),
+			TargetWords: 200 + 50*rng.IntN(5),
+			Keywords:    []string{mockPhrase(rng, 2)},
+		})
+	}
+
+	return &OutlineResult{
+		Outline: outline,
+		Usage:   syntheticUsage(systemPrompt+userPrompt, fmt.Sprint(outline)),
+	}, nil
+}
+
+func (c *MockClient) GenerateTopicVariations(ctx context.Context, topic string, amount int) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	if c.fixtures != nil {
+		var variations []string
+		if err := c.fixtures.Load(fixtureOpTopicVariations, topicVariationsRequest{Topic: topic, Amount: amount}, &variations); err != nil {
+			return nil, err
+		}
+		return variations, nil
+	}
+
+	rng := mockRand(fixtureOpTopicVariations, topic)
+	offset := rng.IntN(len(mockVariationSuffixes))
+
+	variations := make([]string, 0, amount)
+	for i := 0; i < amount; i++ {
+		suffix := mockVariationSuffixes[(offset+i)%len(mockVariationSuffixes)]
+		if round := i / len(mockVariationSuffixes); round > 0 {
+			suffix = fmt.Sprintf("%s (Part %d)", suffix, round+1)
+		}
+		variations = append(variations, fmt.Sprintf("%s: %s", topic, suffix))
+	}
+
+	return variations, nil
+}
+
+func (c *MockClient) GenerateSitemapStructure(ctx context.Context, systemPrompt, userPrompt string) (*SitemapStructureResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	if c.fixtures != nil {
+		result := &SitemapStructureResult{}
+		if err := c.fixtures.Load(fixtureOpSitemapStructure, sitemapStructureRequest{SystemPrompt: systemPrompt, UserPrompt: userPrompt}, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	rng := mockRand(fixtureOpSitemapStructure, systemPrompt, userPrompt)
+
+	var nodes []SitemapGeneratedNode
+	for i := 1; i <= 3; i++ {
+		parent := syntheticSitemapNode(rng, fmt.Sprint(i))
+		for j := 1; j <= 2; j++ {
+			parent.Children = append(parent.Children, syntheticSitemapNode(rng, fmt.Sprintf("%d-%d", i, j)))
+		}
+		nodes = append(nodes, parent)
+	}
+
+	usage := syntheticUsage(systemPrompt+userPrompt, fmt.Sprint(nodes))
+
+	return &SitemapStructureResult{
+		Nodes:      nodes,
+		TokensUsed: usage.TotalTokens,
+		Usage:      usage,
+	}, nil
+}
+
+func (c *MockClient) GenerateLinkSuggestions(ctx context.Context, request *LinkSuggestionRequest) (*LinkSuggestionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	if c.fixtures != nil {
+		result := &LinkSuggestionResult{}
+		if err := c.fixtures.Load(fixtureOpLinkSuggestions, request, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	nodes := make([]LinkSuggestionNode, len(request.Nodes))
+	copy(nodes, request.Nodes)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	perNode := request.MaxOutgoing
+	if perNode <= 0 || perNode > 2 {
+		perNode = 2
+	}
+	perNode = min(perNode, len(nodes)-1)
+
+	// Link every page to the pages that follow it, wrapping around the list
+	var links []SuggestedLink
+	for i, source := range nodes {
+		for step := 1; step <= perNode; step++ {
+			target := nodes[(i+step)%len(nodes)]
+			rng := mockRand(fixtureOpLinkSuggestions, fmt.Sprint(source.ID), fmt.Sprint(target.ID))
+			links = append(links, SuggestedLink{
+				SourceNodeID: source.ID,
+				TargetNodeID: target.ID,
+				AnchorText:   target.Title,
+				Confidence:   0.5 + float64(rng.IntN(46))/100,
+			})
+		}
+	}
+
+	return &LinkSuggestionResult{
+		Links:       links,
+		Explanation: "Synthetic suggestions linking each page to the pages that follow it",
+		Usage:       syntheticUsage(request.SystemPrompt+request.UserPrompt, fmt.Sprint(links)),
+	}, nil
+}
+
+func (c *MockClient) InsertLinks(ctx context.Context, request *InsertLinksRequest) (*InsertLinksResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	if c.fixtures != nil {
+		result := &InsertLinksResult{}
+		if err := c.fixtures.Load(fixtureOpInsertLinks, request, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	content := request.Content
+	applied := 0
+
+	for _, link := range request.Links {
+		anchor := link.TargetTitle
+		if link.AnchorText != nil && *link.AnchorText != "" {
+			anchor = *link.AnchorText
+		}
+
+		tag := fmt.Sprintf(`<a href="%s">%s</a>`, link.TargetPath, anchor)
+		if idx := strings.Index(content, anchor); idx >= 0 && !strings.Contains(content, ">"+anchor+"</a>") {
+			content = content[:idx] + tag + content[idx+len(anchor):]
+		} else {
+			content += fmt.Sprintf("\n<p>See also: %s</p>", tag)
+		}
+		applied++
+	}
+
+	return &InsertLinksResult{
+		Content:      content,
+		LinksApplied: applied,
+		Usage:        syntheticUsage(request.Content, content),
+	}, nil
+}
+
+func (c *MockClient) RewriteArticle(ctx context.Context, request *RewriteArticleRequest) (*ArticleResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	if c.fixtures != nil {
+		result := &ArticleResult{}
+		if err := c.fixtures.Load(fixtureOpRewrite, request, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	// The synthetic revision keeps the article and appends a paragraph derived from the instructions
+	rng := mockRand(fixtureOpRewrite, request.Content, request.Instructions)
+	revision := fmt.Sprintf("<p>%s %s</p>\n", mockSentence(rng), mockSentence(rng))
+	content := strings.TrimRight(request.Content, "\n") + "\n" + revision
+
+	systemPrompt, userPrompt := rewritePrompts(request)
+	usage := syntheticUsage(systemPrompt+userPrompt, request.Title+content)
+
+	return &ArticleResult{
+		Title:      request.Title,
+		Excerpt:    mockSentence(rng),
+		Content:    content,
+		TokensUsed: usage.TotalTokens,
+		Usage:      usage,
+	}, nil
+}
+
+func (c *MockClient) Embed(ctx context.Context, texts []string) (*EmbeddingResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	if c.fixtures != nil {
+		result := &EmbeddingResult{}
+		if err := c.fixtures.Load(fixtureOpEmbed, embedRequest{Texts: texts}, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result := &EmbeddingResult{Model: c.model}
+	for _, text := range texts {
+		result.Vectors = append(result.Vectors, syntheticEmbedding(text))
+	}
+	inputTokens := estimateTokens(strings.Join(texts, "\n"))
+	result.Usage = Usage{InputTokens: inputTokens, TotalTokens: inputTokens}
+
+	return result, nil
+}
+
+// GenerateImage renders a deterministic gradient for the prompt, so image steps
+// can run offline
+func (c *MockClient) GenerateImage(ctx context.Context, request *ImageRequest) (*ImageResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	if c.fixtures != nil {
+		result := &ImageResult{}
+		if err := c.fixtures.Load(fixtureOpImage, request, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	data, err := stubImage(request.Prompt)
+	if err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+
+	inputTokens := estimateTokens(request.Prompt)
+	return &ImageResult{
+		Data:     data,
+		MimeType: "image/png",
+		Model:    c.model,
+		Usage:    Usage{InputTokens: inputTokens, TotalTokens: inputTokens},
+	}, nil
+}
+
+// Ping always succeeds, the mock has neither keys nor models to lose
+func (c *MockClient) Ping(ctx context.Context) (*PingResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.AI(mockProviderName, err)
+	}
+	return &PingResult{}, nil
+}
+
+func (c *MockClient) GetProviderName() string {
+	return mockProviderName
+}
+
+func (c *MockClient) GetModelName() string {
+	return c.model
+}
+
+// mockRand returns a generator seeded from the given parts
+func mockRand(parts ...string) *rand.Rand {
+	h := fnv.New64a()
+	for _, part := range parts {
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	seed := h.Sum64()
+	return rand.New(rand.NewPCG(seed, seed>>1))
+}
+
+func mockPhrase(rng *rand.Rand, words int) string {
+	parts := make([]string, words)
+	for i := range parts {
+		parts[i] = mockWords[rng.IntN(len(mockWords))]
+	}
+	return strings.Join(parts, " ")
+}
+
+func mockSentence(rng *rand.Rand) string {
+	sentence := mockPhrase(rng, 8+rng.IntN(8))
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
+
+func mockTitleCase(phrase string) string {
+	words := strings.Fields(phrase)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// syntheticArticle builds the article for the prompts. A seed, when given,
+// selects a different but equally reproducible variant.
+func syntheticArticle(systemPrompt, userPrompt string, seed *int64) *ArticleResult {
+	parts := []string{fixtureOpArticle, systemPrompt, userPrompt}
+	if seed != nil {
+		parts = append(parts, fmt.Sprint(*seed))
+	}
+	rng := mockRand(parts...)
+
+	title := syntheticTitle(userPrompt)
+	if title == "" {
+		title = mockTitleCase(mockPhrase(rng, 5))
+	}
+
+	var content strings.Builder
+	var excerpt string
+	for section := 0; section < 3; section++ {
+		content.WriteString("<h2>" + mockTitleCase(mockPhrase(rng, 4)) + "</h2>\n")
+		for paragraph := 0; paragraph < 2; paragraph++ {
+			sentences := make([]string, 3+rng.IntN(3))
+			for i := range sentences {
+				sentences[i] = mockSentence(rng)
+			}
+			if excerpt == "" {
+				excerpt = sentences[0]
+			}
+			content.WriteString("<p>" + strings.Join(sentences, " ") + "</p>\n")
+		}
+	}
+
+	usage := syntheticUsage(systemPrompt+userPrompt, title+excerpt+content.String())
+
+	return &ArticleResult{
+		Title:      title,
+		Excerpt:    excerpt,
+		Content:    content.String(),
+		TokensUsed: usage.TotalTokens,
+		Usage:      usage,
+	}
+}
+
+// syntheticTitle uses the first line of the user prompt, which usually names the topic
+func syntheticTitle(userPrompt string) string {
+	for _, line := range strings.Split(userPrompt, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "#*-:> ")
+		if line == "" {
+			continue
+		}
+		if len(line) > 80 {
+			line = strings.TrimSpace(line[:80])
+		}
+		return line
+	}
+	return ""
+}
+
+// syntheticSitemapNode builds a node whose slug is made unique by its position in the tree
+func syntheticSitemapNode(rng *rand.Rand, position string) SitemapGeneratedNode {
+	phrase := mockPhrase(rng, 2+rng.IntN(2))
+	return SitemapGeneratedNode{
+		Title:    mockTitleCase(phrase),
+		Slug:     strings.ReplaceAll(phrase, " ", "-") + "-" + position,
+		Keywords: []string{phrase, mockPhrase(rng, 2)},
+	}
+}
+
+func syntheticUsage(input, output string) Usage {
+	inputTokens := estimateTokens(input)
+	outputTokens := estimateTokens(output)
+	return Usage{
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		TotalTokens:  inputTokens + outputTokens,
+	}
+}
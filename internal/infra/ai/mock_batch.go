@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+var _ BatchClient = (*MockClient)(nil)
+
+// mockBatches holds the requests of submitted mock batches. Mock batches complete
+// immediately and live in memory, so they don't survive a restart.
+var mockBatches sync.Map
+
+type mockBatch struct {
+	requests  []BatchRequest
+	cancelled bool
+}
+
+func (c *MockClient) SubmitArticleBatch(ctx context.Context, requests []BatchRequest) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", errors.AI(mockProviderName, err)
+	}
+	if len(requests) == 0 {
+		return "", errors.Validation("Batch has no requests")
+	}
+
+	batchID := "mock_batch_" + uuid.NewString()
+	mockBatches.Store(batchID, &mockBatch{requests: append([]BatchRequest(nil), requests...)})
+	return batchID, nil
+}
+
+func (c *MockClient) GetBatch(_ context.Context, batchID string) (*BatchState, error) {
+	batch, err := loadMockBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := BatchStatusCompleted
+	if batch.cancelled {
+		status = BatchStatusCancelled
+	}
+
+	return &BatchState{
+		Status:    status,
+		Total:     len(batch.requests),
+		Succeeded: len(batch.requests),
+	}, nil
+}
+
+func (c *MockClient) GetBatchResults(ctx context.Context, batchID string) ([]*BatchItemResult, error) {
+	batch, err := loadMockBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*BatchItemResult, len(batch.requests))
+	for i, request := range batch.requests {
+		result := &BatchItemResult{CustomID: request.CustomID}
+		if batch.cancelled {
+			result.Err = errors.AI(mockProviderName, fmt.Errorf("request canceled"))
+		} else if article, err := c.GenerateArticle(ctx, request.SystemPrompt, request.UserPrompt, request.Options); err != nil {
+			result.Err = err
+		} else {
+			article.Usage = batchUsage(article.Usage)
+			article.Cost = article.Usage.CostUSD
+			result.Article = article
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+func (c *MockClient) CancelBatch(_ context.Context, batchID string) error {
+	batch, err := loadMockBatch(batchID)
+	if err != nil {
+		return err
+	}
+
+	batch.cancelled = true
+	return nil
+}
+
+func loadMockBatch(batchID string) (*mockBatch, error) {
+	batch, ok := mockBatches.Load(batchID)
+	if !ok {
+		return nil, errors.AI(mockProviderName, fmt.Errorf("unknown batch %s", batchID))
+	}
+	return batch.(*mockBatch), nil
+}
@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+func newSyntheticClient(t *testing.T) *MockClient {
+	t.Helper()
+
+	client, err := NewMockClient(MockConfig{Model: MockModelSynthetic})
+	if err != nil {
+		t.Fatalf("failed to create synthetic client: %v", err)
+	}
+	return client
+}
+
+func TestMockClientSyntheticIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	client := newSyntheticClient(t)
+
+	first, err := client.GenerateArticle(ctx, "system", "Best hiking trails\nWrite 1000 words", nil)
+	if err != nil {
+		t.Fatalf("GenerateArticle: %v", err)
+	}
+
+	second, err := client.GenerateArticle(ctx, "system", "Best hiking trails\nWrite 1000 words", nil)
+	if err != nil {
+		t.Fatalf("GenerateArticle: %v", err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("expected identical articles for identical prompts")
+	}
+	if first.Title != "Best hiking trails" {
+		t.Errorf("expected title from first prompt line, got %q", first.Title)
+	}
+	if first.Content == "" || first.Excerpt == "" {
+		t.Errorf("expected non-empty content and excerpt")
+	}
+	if first.Usage.CostUSD != 0 {
+		t.Errorf("expected zero cost, got %f", first.Usage.CostUSD)
+	}
+
+	other, err := client.GenerateArticle(ctx, "system", "Another topic", nil)
+	if err != nil {
+		t.Fatalf("GenerateArticle: %v", err)
+	}
+	if other.Content == first.Content {
+		t.Errorf("expected different content for a different prompt")
+	}
+}
+
+func TestMockClientStreamReportsProgress(t *testing.T) {
+	client := newSyntheticClient(t)
+
+	var last StreamProgress
+	calls := 0
+	result, err := client.GenerateArticleStream(context.Background(), "system", "Streamed title", nil, func(progress StreamProgress) {
+		calls++
+		last = progress
+	})
+	if err != nil {
+		t.Fatalf("GenerateArticleStream: %v", err)
+	}
+
+	if calls == 0 {
+		t.Fatal("expected progress callbacks")
+	}
+	if last.PartialTitle != result.Title {
+		t.Errorf("expected final progress title %q, got %q", result.Title, last.PartialTitle)
+	}
+}
+
+func TestMockClientStreamCancelled(t *testing.T) {
+	client := newSyntheticClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.GenerateArticleStream(ctx, "system", "user", nil, nil); !errors.IsAI(err) {
+		t.Fatalf("expected AI error on cancelled context, got %v", err)
+	}
+}
+
+func TestMockClientSyntheticLinkSuggestions(t *testing.T) {
+	client := newSyntheticClient(t)
+
+	result, err := client.GenerateLinkSuggestions(context.Background(), &LinkSuggestionRequest{
+		Nodes: []LinkSuggestionNode{
+			{ID: 3, Title: "Three"},
+			{ID: 1, Title: "One"},
+			{ID: 2, Title: "Two"},
+		},
+		MaxOutgoing: 1,
+	})
+	if err != nil {
+		t.Fatalf("GenerateLinkSuggestions: %v", err)
+	}
+
+	expected := map[int64]int64{1: 2, 2: 3, 3: 1}
+	if len(result.Links) != len(expected) {
+		t.Fatalf("expected %d links, got %d", len(expected), len(result.Links))
+	}
+	for _, link := range result.Links {
+		if expected[link.SourceNodeID] != link.TargetNodeID {
+			t.Errorf("unexpected link %d -> %d", link.SourceNodeID, link.TargetNodeID)
+		}
+		if link.Confidence < 0.5 || link.Confidence > 0.95 {
+			t.Errorf("confidence out of range: %f", link.Confidence)
+		}
+	}
+}
+
+func TestRecordingClientReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	recorder, err := NewRecordingClient(newSyntheticClient(t), dir)
+	if err != nil {
+		t.Fatalf("NewRecordingClient: %v", err)
+	}
+
+	recorded, err := recorder.GenerateArticleStream(ctx, "system", "Recorded article", nil, nil)
+	if err != nil {
+		t.Fatalf("GenerateArticleStream: %v", err)
+	}
+
+	structure, err := recorder.GenerateSitemapStructure(ctx, "system", "user")
+	if err != nil {
+		t.Fatalf("GenerateSitemapStructure: %v", err)
+	}
+
+	replay, err := CreateClient(&entities.Provider{
+		Type:     entities.TypeMock,
+		Model:    MockModelReplay,
+		BaseURL:  dir,
+		IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+
+	replayed, err := replay.GenerateArticle(ctx, "system", "Recorded article", nil)
+	if err != nil {
+		t.Fatalf("replayed GenerateArticle: %v", err)
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("replayed article differs from the recorded one")
+	}
+
+	replayedStructure, err := replay.GenerateSitemapStructure(ctx, "system", "user")
+	if err != nil {
+		t.Fatalf("replayed GenerateSitemapStructure: %v", err)
+	}
+	if !reflect.DeepEqual(structure.Nodes, replayedStructure.Nodes) {
+		t.Errorf("replayed sitemap structure differs from the recorded one")
+	}
+
+	if _, err = replay.GenerateArticle(ctx, "system", "Never recorded", nil); !errors.IsAI(err) {
+		t.Errorf("expected AI error for a missing fixture, got %v", err)
+	}
+}
+
+func TestCreateClientRecordsWhileRecordingDirIsSet(t *testing.T) {
+	SetRecordingDir(t.TempDir())
+	t.Cleanup(func() { SetRecordingDir("") })
+
+	recorded := func(provider *entities.Provider) bool {
+		t.Helper()
+
+		client, err := CreateClient(provider)
+		if err != nil {
+			t.Fatalf("CreateClient: %v", err)
+		}
+		for {
+			if _, ok := client.(*RecordingClient); ok {
+				return true
+			}
+			wrapper, ok := client.(interface{ Unwrap() Client })
+			if !ok {
+				return false
+			}
+			client = wrapper.Unwrap()
+		}
+	}
+
+	if !recorded(&entities.Provider{Type: entities.TypeOpenAICompatible, Model: "llama3", BaseURL: "http://localhost:11434/v1", IsActive: true}) {
+		t.Error("expected calls to a real provider to be recorded")
+	}
+	if recorded(&entities.Provider{Type: entities.TypeMock, Model: MockModelSynthetic, IsActive: true}) {
+		t.Error("expected mock calls not to be recorded")
+	}
+
+	SetRecordingDir("")
+	if recorded(&entities.Provider{Type: entities.TypeOpenAICompatible, Model: "llama3", BaseURL: "http://localhost:11434/v1", IsActive: true}) {
+		t.Error("expected no recording once the directory is cleared")
+	}
+}
+
+func TestRecordingClientReplaysFailures(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	store, err := newFixtureStore(dir)
+	if err != nil {
+		t.Fatalf("newFixtureStore: %v", err)
+	}
+
+	request := topicVariationsRequest{Topic: "coffee", Amount: 2}
+	if err = store.Save(fixtureOpTopicVariations, "openai", "gpt-4o-mini", request, nil, errors.AIRateLimit("openai")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	replay, err := NewMockClient(MockConfig{Model: MockModelReplay, FixturesDir: dir})
+	if err != nil {
+		t.Fatalf("NewMockClient: %v", err)
+	}
+
+	if _, err = replay.GenerateTopicVariations(ctx, "coffee", 2); !errors.IsAI(err) {
+		t.Fatalf("expected recorded failure to be replayed as AI error, got %v", err)
+	}
+}
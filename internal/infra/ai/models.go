@@ -1,8 +1,14 @@
 package ai
 
-import "github.com/davidmovas/postulator/internal/domain/entities"
+import (
+	"sync"
 
-var availableModels = map[entities.Type][]*entities.Model{
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+// defaultModels is the built-in catalog shipped with the app. User-defined entries
+// loaded with SetUserModels are layered over it.
+var defaultModels = map[entities.Type][]*entities.Model{
 	entities.TypeOpenAI: {
 		// GPT-5.2 - Best for coding and agentic tasks
 		{
@@ -158,15 +164,104 @@ var availableModels = map[entities.Type][]*entities.Model{
 	// 		TPM:             100000,
 	// 	},
 	// },
+
+	entities.TypeMock: {
+		// Synthetic - Deterministic offline output derived from the prompt
+		{
+			ID:              MockModelSynthetic,
+			Name:            "Synthetic (offline)",
+			Provider:        entities.TypeMock,
+			ContextWindow:   1000000,
+			MaxOutputTokens: 128000,
+			RPM:             6000,
+			TPM:             10000000,
+		},
+		// Replay - Responses recorded from a real provider
+		{
+			ID:              MockModelReplay,
+			Name:            "Replay recorded fixtures",
+			Provider:        entities.TypeMock,
+			ContextWindow:   1000000,
+			MaxOutputTokens: 128000,
+			RPM:             6000,
+			TPM:             10000000,
+		},
+	},
 }
 
-func GetModelInfo(providerType entities.Type, modelID string) *entities.Model {
-	models, exists := availableModels[providerType]
-	if !exists {
-		return nil
+// catalog is the effective model catalog: defaultModels merged with user-defined entries
+var catalog = struct {
+	mu     sync.RWMutex
+	models map[entities.Type][]*entities.Model
+}{
+	models: mergeModels(nil),
+}
+
+// SetUserModels replaces the user-defined catalog entries. An entry with the same
+// provider and ID as a built-in model overrides it, any other entry adds a new model.
+func SetUserModels(models []*entities.Model) {
+	merged := mergeModels(models)
+
+	catalog.mu.Lock()
+	catalog.models = merged
+	catalog.mu.Unlock()
+}
+
+func mergeModels(userModels []*entities.Model) map[entities.Type][]*entities.Model {
+	overrides := make(map[entities.Type]map[string]*entities.Model)
+	for _, m := range userModels {
+		if overrides[m.Provider] == nil {
+			overrides[m.Provider] = make(map[string]*entities.Model)
+		}
+		overrides[m.Provider][m.ID] = m
 	}
 
-	for _, m := range models {
+	merged := make(map[entities.Type][]*entities.Model)
+	for providerType, models := range defaultModels {
+		for _, m := range models {
+			model := *m
+			if override, ok := overrides[providerType][m.ID]; ok {
+				model = *override
+				model.IsUserDefined = true
+				delete(overrides[providerType], m.ID)
+			}
+			model.IsBuiltin = true
+			merged[providerType] = append(merged[providerType], &model)
+		}
+	}
+
+	// Remaining entries are new models, kept in the order they were given
+	for _, m := range userModels {
+		if _, ok := overrides[m.Provider][m.ID]; !ok {
+			continue
+		}
+		model := *m
+		model.IsBuiltin = false
+		model.IsUserDefined = true
+		merged[m.Provider] = append(merged[m.Provider], &model)
+	}
+
+	return merged
+}
+
+// GetDefaultModelInfo returns the built-in entry for a model, ignoring user overrides
+func GetDefaultModelInfo(providerType entities.Type, modelID string) *entities.Model {
+	for _, m := range defaultModels[providerType] {
+		if m.ID == modelID {
+			model := *m
+			model.IsBuiltin = true
+			return &model
+		}
+	}
+
+	return nil
+}
+
+func GetModelInfo(providerType entities.Type, modelID string) *entities.Model {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	for _, m := range catalog.models[providerType] {
 		if m.ID == modelID {
 			return m
 		}
@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+func TestSetUserModelsOverridesAndAddsModels(t *testing.T) {
+	t.Cleanup(func() { SetUserModels(nil) })
+
+	SetUserModels([]*entities.Model{
+		{ID: "gpt-4o-mini", Name: "GPT-4o Mini", Provider: entities.TypeOpenAI, InputCost: 1, OutputCost: 2, RPM: 100},
+		{ID: "gpt-6", Name: "GPT-6", Provider: entities.TypeOpenAI, InputCost: 10, OutputCost: 20},
+		{ID: "llama3", Name: "Llama 3", Provider: entities.TypeOpenAICompatible, InputCost: 0.5},
+	})
+
+	if cost := CalculateCost(entities.TypeOpenAI, "gpt-4o-mini", 1_000_000, 1_000_000); cost != 3 {
+		t.Errorf("expected overridden price to be used, got %v", cost)
+	}
+
+	override := GetModelInfo(entities.TypeOpenAI, "gpt-4o-mini")
+	if !override.IsBuiltin || !override.IsUserDefined {
+		t.Errorf("expected override to be flagged built-in and user-defined, got %+v", override)
+	}
+	if def := GetDefaultModelInfo(entities.TypeOpenAI, "gpt-4o-mini"); def.InputCost != 0.15 {
+		t.Errorf("expected default entry to be unchanged, got %v", def.InputCost)
+	}
+
+	if !ValidateModel(entities.TypeOpenAI, "gpt-6") {
+		t.Error("expected user-added model to be valid")
+	}
+	models := GetAvailableModels(entities.TypeOpenAI)
+	if last := models[len(models)-1]; last.ID != "gpt-6" || last.IsBuiltin {
+		t.Errorf("expected user-added model after built-in ones, got %+v", last)
+	}
+	if cost := CalculateCost(entities.TypeOpenAICompatible, "llama3", 2_000_000, 0); cost != 1 {
+		t.Errorf("expected custom provider model to be priced, got %v", cost)
+	}
+
+	SetUserModels(nil)
+
+	if cost := CalculateCost(entities.TypeOpenAI, "gpt-4o-mini", 1_000_000, 0); cost != 0.15 {
+		t.Errorf("expected default price after reset, got %v", cost)
+	}
+	if ValidateModel(entities.TypeOpenAI, "gpt-6") {
+		t.Error("expected removed model to be invalid")
+	}
+}
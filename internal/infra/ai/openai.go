@@ -2,7 +2,7 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/base64"
 	"fmt"
 	"strings"
 
@@ -32,12 +32,19 @@ type Config struct {
 	APIKey  string
 	Model   string
 	BaseURL string
+	// ProviderType is used for model lookups and cost accounting.
+	// Defaults to entities.TypeOpenAI.
+	ProviderType entities.Type
+	// EmbeddingModel is the model used by Embed. Defaults to the chat model.
+	EmbeddingModel string
 }
 
 type OpenAIClient struct {
 	client               *openaiSDK.Client
+	providerType         entities.Type
 	model                openaiSDK.ChatModel
 	modelName            string
+	embeddingModel       string
 	usesCompletionTokens bool
 	isReasoningModel     bool
 	contextWindow        int // Total context window size
@@ -45,7 +52,12 @@ type OpenAIClient struct {
 }
 
 func NewOpenAIClient(cfg Config) (*OpenAIClient, error) {
-	if cfg.APIKey == "" {
+	if cfg.ProviderType == "" {
+		cfg.ProviderType = entities.TypeOpenAI
+	}
+
+	// Self-hosted OpenAI-compatible servers usually don't require a key
+	if cfg.APIKey == "" && cfg.ProviderType != entities.TypeOpenAICompatible {
 		return nil, errors.Validation("OpenAI API key is required")
 	}
 
@@ -53,6 +65,10 @@ func NewOpenAIClient(cfg Config) (*OpenAIClient, error) {
 		cfg.Model = openaiSDK.ChatModelGPT4oMini
 	}
 
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = cfg.Model
+	}
+
 	opts := []option.RequestOption{
 		option.WithAPIKey(cfg.APIKey),
 	}
@@ -67,7 +83,7 @@ func NewOpenAIClient(cfg Config) (*OpenAIClient, error) {
 	isReasoningModel := false
 	contextWindow := 128000  // Default fallback
 	maxOutputTokens := 16384 // Default fallback
-	if modelInfo := GetModelInfo(entities.TypeOpenAI, cfg.Model); modelInfo != nil {
+	if modelInfo := GetModelInfo(cfg.ProviderType, cfg.Model); modelInfo != nil {
 		usesCompletionTokens = modelInfo.UsesCompletionTokens
 		isReasoningModel = modelInfo.IsReasoningModel
 		if modelInfo.ContextWindow > 0 {
@@ -80,8 +96,10 @@ func NewOpenAIClient(cfg Config) (*OpenAIClient, error) {
 
 	return &OpenAIClient{
 		client:               &client,
+		providerType:         cfg.ProviderType,
 		model:                cfg.Model,
 		modelName:            cfg.Model,
+		embeddingModel:       cfg.EmbeddingModel,
 		usesCompletionTokens: usesCompletionTokens,
 		isReasoningModel:     isReasoningModel,
 		contextWindow:        contextWindow,
@@ -90,7 +108,7 @@ func NewOpenAIClient(cfg Config) (*OpenAIClient, error) {
 }
 
 func (c *OpenAIClient) GetProviderName() string {
-	return "openai"
+	return string(c.providerType)
 }
 
 func (c *OpenAIClient) GetModelName() string {
@@ -102,7 +120,7 @@ func (c *OpenAIClient) GetModelName() string {
 // For mixed content (HTML, JSON), uses ~3.5 chars per token to be more conservative.
 func (c *OpenAIClient) EstimateTokens(text string) int {
 	// Average ~3.5 chars per token for mixed content (code, HTML, etc.)
-	return (len(text) * 10) / 35
+	return estimateTokens(text)
 }
 
 // CalculateAvailableOutputTokens determines how many output tokens are available
@@ -151,18 +169,69 @@ func (c *OpenAIClient) ValidateRequest(systemPrompt, userPrompt string, minRequi
 }
 
 func (c *OpenAIClient) GenerateArticle(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions) (*ArticleResult, error) {
+	params, maxTokens, err := c.articleParams(systemPrompt, userPrompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	chat, err := c.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
+	}
+
+	return c.articleResult(ctx, chat, c.usage(chat), maxTokens)
+}
+
+func (c *OpenAIClient) GenerateArticleStream(ctx context.Context, systemPrompt, userPrompt string, opts *GenerateArticleOptions, onProgress StreamProgressFunc) (*ArticleResult, error) {
+	params, maxTokens, err := c.articleParams(systemPrompt, userPrompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	params.StreamOptions = openaiSDK.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openaiSDK.Bool(true),
+	}
+
+	stream := c.client.Chat.Completions.NewStreaming(ctx, params)
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	tracker := newStreamTracker(onProgress)
+	acc := openaiSDK.ChatCompletionAccumulator{}
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+		if len(chunk.Choices) > 0 {
+			tracker.Write(chunk.Choices[0].Delta.Content)
+		}
+	}
+
+	if err = stream.Err(); err != nil {
+		return nil, classifyError(providerName, fmt.Errorf("stream error: %w", err))
+	}
+
+	tracker.Flush()
+
+	return c.articleResult(ctx, &acc.ChatCompletion, c.usage(&acc.ChatCompletion), maxTokens)
+}
+
+// articleParams builds the chat completion request for article generation
+func (c *OpenAIClient) articleParams(systemPrompt, userPrompt string, opts *GenerateArticleOptions) (openaiSDK.ChatCompletionNewParams, int, error) {
 	// Calculate dynamic token limits based on input size
 	// Default desired output is 4096 for articles, but we'll calculate what's actually available
-	const desiredArticleTokens = 8192 // Desired output for a typical article
-	const minArticleTokens = 2000     // Minimum tokens needed for a reasonable article
+	const minArticleTokens = 2000 // Minimum tokens needed for a reasonable article
+
+	// An explicit output limit replaces the default, and a smaller one relaxes the minimum
+	desiredTokens := opts.maxOutputTokens(defaultArticleOutputTokens)
 
 	// Validate request first
-	if err := c.ValidateRequest(systemPrompt, userPrompt, minArticleTokens); err != nil {
-		return nil, errors.AI(providerName, err)
+	if err := c.ValidateRequest(systemPrompt, userPrompt, min(minArticleTokens, desiredTokens)); err != nil {
+		return openaiSDK.ChatCompletionNewParams{}, 0, errors.AIContextLength(providerName, err)
 	}
 
 	// Calculate actual available tokens
-	maxTokens := c.CalculateAvailableOutputTokens(systemPrompt, userPrompt, desiredArticleTokens)
+	maxTokens := c.CalculateAvailableOutputTokens(systemPrompt, userPrompt, desiredTokens)
 
 	schema := generateSchema[ArticleContent]()
 
@@ -188,9 +257,19 @@ func (c *OpenAIClient) GenerateArticle(ctx context.Context, systemPrompt, userPr
 		Model: c.model,
 	}
 
-	// Reasoning models (o1, o3, gpt-5 series) don't support temperature
+	// Reasoning models (o1, o3, gpt-5 series) don't support temperature, top_p or stop
 	if !c.isReasoningModel {
-		params.Temperature = openaiSDK.Float(0.7)
+		params.Temperature = openaiSDK.Float(opts.temperature(0.7))
+		if topP := opts.topP(); topP != nil {
+			params.TopP = openaiSDK.Float(*topP)
+		}
+		if stop := opts.stop(); len(stop) > 0 {
+			params.Stop = openaiSDK.ChatCompletionNewParamsStopUnion{OfStringArray: stop}
+		}
+	}
+
+	if seed := opts.seed(); seed != nil {
+		params.Seed = openaiSDK.Int(*seed)
 	}
 
 	if c.usesCompletionTokens {
@@ -203,11 +282,12 @@ func (c *OpenAIClient) GenerateArticle(ctx context.Context, systemPrompt, userPr
 	fmt.Printf("[OpenAI] GenerateArticle: model=%s, inputEstimate=%d, maxTokens=%d, contextWindow=%d\n",
 		c.modelName, inputEstimate, maxTokens, c.contextWindow)
 
-	chat, err := c.client.Chat.Completions.New(ctx, params)
-	if err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf("API error: %w", err))
-	}
+	return params, maxTokens, nil
+}
 
+// articleResult parses the article of the completion. usage is what the completion is
+// charged, the repair calls a malformed response needs are added at the regular price.
+func (c *OpenAIClient) articleResult(ctx context.Context, chat *openaiSDK.ChatCompletion, usage Usage, maxTokens int) (*ArticleResult, error) {
 	if len(chat.Choices) == 0 {
 		return nil, errors.AI(providerName, fmt.Errorf("no response from API"))
 	}
@@ -228,7 +308,7 @@ func (c *OpenAIClient) GenerateArticle(ctx context.Context, systemPrompt, userPr
 			chat.Usage.CompletionTokens, maxTokens, chat.Usage.PromptTokens))
 	}
 	if finishReason == "content_filter" {
-		return nil, errors.AI(providerName, fmt.Errorf("content filtered by safety system"))
+		return nil, errors.AIContentFilter(providerName, fmt.Errorf("content filtered by safety system"))
 	}
 
 	content := choice.Message.Content
@@ -236,44 +316,73 @@ func (c *OpenAIClient) GenerateArticle(ctx context.Context, systemPrompt, userPr
 		return nil, errors.AI(providerName, fmt.Errorf("empty response from API (finish_reason: %s)", finishReason))
 	}
 
-	var article ArticleContent
-	if err = json.Unmarshal([]byte(content), &article); err != nil {
-		// Try sanitizing the JSON to fix invalid escape sequences
-		sanitized := sanitizeJSON(content)
-		if err2 := json.Unmarshal([]byte(sanitized), &article); err2 != nil {
-			// Log the raw content for debugging (first 500 chars)
-			preview := content
-			if len(preview) > 500 {
-				preview = preview[:500] + "..."
-			}
-			return nil, errors.AI(providerName, fmt.Errorf("failed to parse response (finish_reason: %s): %w, preview: %s", finishReason, err, preview))
-		}
+	article, repairUsage, err := parseStructured[ArticleContent](ctx, content, c.repairer(maxTokens))
+	if err != nil {
+		return nil, errors.AI(providerName, fmt.Errorf("failed to parse response (finish_reason: %s): %w", finishReason, err))
 	}
 
-	if article.Title == "" || article.Content == "" {
-		return nil, errors.AI(providerName, fmt.Errorf("empty title or content in response"))
-	}
-
-	inputTokens := int(chat.Usage.PromptTokens)
-	outputTokens := int(chat.Usage.CompletionTokens)
-	totalTokens := int(chat.Usage.TotalTokens)
-	cost := CalculateCost(entities.TypeOpenAI, c.modelName, inputTokens, outputTokens)
+	usage = usage.add(repairUsage)
 
 	return &ArticleResult{
 		Title:      article.Title,
 		Excerpt:    article.Excerpt,
 		Content:    article.Content,
-		TokensUsed: totalTokens,
-		Cost:       cost,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
+		TokensUsed: usage.TotalTokens,
+		Cost:       usage.CostUSD,
+		Usage:      usage,
 	}, nil
 }
 
+// repairer returns a repairFunc that asks the same model to fix a malformed response.
+// It uses plain JSON mode because strict schemas don't support recursive types.
+func (c *OpenAIClient) repairer(maxTokens int) repairFunc {
+	return func(ctx context.Context, systemPrompt, userPrompt string) (string, Usage, error) {
+		params := openaiSDK.ChatCompletionNewParams{
+			Messages: []openaiSDK.ChatCompletionMessageParamUnion{
+				openaiSDK.SystemMessage(systemPrompt),
+				openaiSDK.UserMessage(userPrompt),
+			},
+			ResponseFormat: openaiSDK.ChatCompletionNewParamsResponseFormatUnion{
+				OfJSONObject: &openaiSDK.ResponseFormatJSONObjectParam{},
+			},
+			Model: c.model,
+		}
+
+		if !c.isReasoningModel {
+			params.Temperature = openaiSDK.Float(0)
+		}
+
+		if c.usesCompletionTokens {
+			params.MaxCompletionTokens = openaiSDK.Int(int64(maxTokens))
+		} else {
+			params.MaxTokens = openaiSDK.Int(int64(maxTokens))
+		}
+
+		chat, err := c.client.Chat.Completions.New(ctx, params)
+		if err != nil {
+			return "", Usage{}, classifyError(providerName, fmt.Errorf("API error: %w", err))
+		}
+
+		if len(chat.Choices) == 0 {
+			return "", c.usage(chat), errors.AI(providerName, fmt.Errorf("no response from API"))
+		}
+
+		return chat.Choices[0].Message.Content, c.usage(chat), nil
+	}
+}
+
+func (c *OpenAIClient) usage(chat *openaiSDK.ChatCompletion) Usage {
+	inputTokens := int(chat.Usage.PromptTokens)
+	outputTokens := int(chat.Usage.CompletionTokens)
+
+	return Usage{
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		TotalTokens:  int(chat.Usage.TotalTokens),
+		CostUSD:      CalculateCost(c.providerType, c.modelName, inputTokens, outputTokens),
+	}
+}
+
 func (c *OpenAIClient) GenerateTopicVariations(ctx context.Context, topic string, amount int) ([]string, error) {
 	schema := generateSchema[TopicVariations]()
 
@@ -316,50 +425,73 @@ func (c *OpenAIClient) GenerateTopicVariations(ctx context.Context, topic string
 
 	chat, err := c.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
 	}
 
 	if len(chat.Choices) == 0 {
 		return nil, errors.AI(providerName, fmt.Errorf("no response from API"))
 	}
 
-	content := chat.Choices[0].Message.Content
-	var result TopicVariations
-	if err = json.Unmarshal([]byte(content), &result); err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf("failed to parse variations: %w", err))
+	result, _, err := parseStructured[TopicVariations](ctx, chat.Choices[0].Message.Content, c.repairer(1024))
+	if err != nil {
+		return nil, errors.AI(providerName, err)
 	}
 
-	if len(result.Variations) == 0 {
-		return nil, errors.AI(providerName, fmt.Errorf("no variations generated"))
+	return topicVariations(result, amount), nil
+}
+
+func (c *OpenAIClient) GenerateOutline(ctx context.Context, systemPrompt, userPrompt string) (*OutlineResult, error) {
+	const outlineTokens = 4096
+
+	schemaParam := openaiSDK.ResponseFormatJSONSchemaJSONSchemaParam{
+		Name:        "article_outline",
+		Description: openaiSDK.String("Outline of a long-form article"),
+		Schema:      generateSchema[ArticleOutline](),
+		Strict:      openaiSDK.Bool(true),
 	}
 
-	if len(result.Variations) > amount {
-		result.Variations = result.Variations[:amount]
+	params := openaiSDK.ChatCompletionNewParams{
+		Messages: []openaiSDK.ChatCompletionMessageParamUnion{
+			openaiSDK.SystemMessage(systemPrompt),
+			openaiSDK.UserMessage(userPrompt),
+		},
+		ResponseFormat: openaiSDK.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openaiSDK.ResponseFormatJSONSchemaParam{
+				JSONSchema: schemaParam,
+			},
+		},
+		Model: c.model,
 	}
 
-	cleanedVariations := make([]string, len(result.Variations))
-	for i, variation := range result.Variations {
-		cleanedVariations[i] = cleanQuotes(variation)
+	// Reasoning models (o1, o3, gpt-5 series) don't support temperature
+	if !c.isReasoningModel {
+		params.Temperature = openaiSDK.Float(0.5)
 	}
 
-	return cleanedVariations, nil
-}
+	if c.usesCompletionTokens {
+		params.MaxCompletionTokens = openaiSDK.Int(outlineTokens)
+	} else {
+		params.MaxTokens = openaiSDK.Int(outlineTokens)
+	}
 
-func cleanQuotes(s string) string {
-	s = strings.TrimSpace(s)
+	chat, err := c.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
+	}
 
-	if len(s) >= 2 {
-		firstChar := s[0]
-		lastChar := s[len(s)-1]
+	if len(chat.Choices) == 0 {
+		return nil, errors.AI(providerName, fmt.Errorf("no response from API"))
+	}
 
-		if (firstChar == '"' && lastChar == '"') ||
-			(firstChar == '\'' && lastChar == '\'') ||
-			(firstChar == '`' && lastChar == '`') {
-			return s[1 : len(s)-1]
-		}
+	result, repairUsage, err := parseStructured[ArticleOutline](ctx, chat.Choices[0].Message.Content, c.repairer(outlineTokens))
+	if err != nil {
+		return nil, errors.AI(providerName, err)
 	}
 
-	return s
+	return &OutlineResult{
+		Outline: *result,
+		Usage:   c.usage(chat).add(repairUsage),
+	}, nil
 }
 
 func (c *OpenAIClient) GenerateSitemapStructure(ctx context.Context, systemPrompt, userPrompt string) (*SitemapStructureResult, error) {
@@ -416,39 +548,25 @@ Do not include any text before or after the JSON object. Only output the JSON.`
 
 	chat, err := c.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
 	}
 
 	if len(chat.Choices) == 0 {
 		return nil, errors.AI(providerName, fmt.Errorf("no response from API"))
 	}
 
-	content := chat.Choices[0].Message.Content
-	var result SitemapStructureSchema
-	jsonStr := extractJSON(content)
-	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf("failed to parse response: %w, raw: %s", err, content[:min(200, len(content))]))
+	result, repairUsage, err := parseStructured[SitemapStructureSchema](ctx, chat.Choices[0].Message.Content, c.repairer(8192))
+	if err != nil {
+		return nil, errors.AI(providerName, err)
 	}
 
-	if len(result.Nodes) == 0 {
-		return nil, errors.AI(providerName, fmt.Errorf("no nodes generated"))
-	}
-
-	inputTokens := int(chat.Usage.PromptTokens)
-	outputTokens := int(chat.Usage.CompletionTokens)
-	totalTokens := int(chat.Usage.TotalTokens)
-	cost := CalculateCost(entities.TypeOpenAI, c.modelName, inputTokens, outputTokens)
+	usage := c.usage(chat).add(repairUsage)
 
 	return &SitemapStructureResult{
 		Nodes:      result.Nodes,
-		TokensUsed: totalTokens,
-		Cost:       cost,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
+		TokensUsed: usage.TotalTokens,
+		Cost:       usage.CostUSD,
+		Usage:      usage,
 	}, nil
 }
 
@@ -471,7 +589,7 @@ func (c *OpenAIClient) GenerateLinkSuggestions(ctx context.Context, request *Lin
 
 	// Validate request first - check that prompt isn't too large
 	if err := c.ValidateRequest(systemPrompt, userPrompt, minRequiredTokens); err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf(
+		return nil, errors.AIContextLength(providerName, fmt.Errorf(
 			"prompt too large for link suggestions (%d nodes): %w. Try reducing batch size or simplifying node data.",
 			len(request.Nodes), err))
 	}
@@ -520,7 +638,7 @@ func (c *OpenAIClient) GenerateLinkSuggestions(ctx context.Context, request *Lin
 
 	chat, err := c.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
 	}
 
 	if len(chat.Choices) == 0 {
@@ -543,7 +661,7 @@ func (c *OpenAIClient) GenerateLinkSuggestions(ctx context.Context, request *Lin
 			chat.Usage.CompletionTokens, maxTokens, chat.Usage.PromptTokens, len(request.Nodes)))
 	}
 	if finishReason == "content_filter" {
-		return nil, errors.AI(providerName, fmt.Errorf("content filtered by safety system"))
+		return nil, errors.AIContentFilter(providerName, fmt.Errorf("content filtered by safety system"))
 	}
 
 	content := choice.Message.Content
@@ -551,32 +669,15 @@ func (c *OpenAIClient) GenerateLinkSuggestions(ctx context.Context, request *Lin
 		return nil, errors.AI(providerName, fmt.Errorf("empty response from API (finishReason: %s)", finishReason))
 	}
 
-	var result LinkSuggestionSchema
-	if err = json.Unmarshal([]byte(content), &result); err != nil {
-		sanitized := sanitizeJSON(content)
-		if err2 := json.Unmarshal([]byte(sanitized), &result); err2 != nil {
-			preview := content
-			if len(preview) > 300 {
-				preview = preview[:300] + "..."
-			}
-			return nil, errors.AI(providerName, fmt.Errorf("failed to parse response: %w, raw: %s", err, preview))
-		}
+	result, repairUsage, err := parseStructured[LinkSuggestionSchema](ctx, content, c.repairer(maxTokens))
+	if err != nil {
+		return nil, errors.AI(providerName, err)
 	}
 
-	inputTokens := int(chat.Usage.PromptTokens)
-	outputTokens := int(chat.Usage.CompletionTokens)
-	totalTokens := int(chat.Usage.TotalTokens)
-	cost := CalculateCost(entities.TypeOpenAI, c.modelName, inputTokens, outputTokens)
-
 	return &LinkSuggestionResult{
 		Links:       result.Links,
 		Explanation: result.Explanation,
-		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
-		},
+		Usage:       c.usage(chat).add(repairUsage),
 	}, nil
 }
 
@@ -683,7 +784,7 @@ func (c *OpenAIClient) InsertLinks(ctx context.Context, request *InsertLinksRequ
 
 	// Validate request
 	if err := c.ValidateRequest(systemPrompt, userPrompt, contentTokenEstimate); err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf("content too large for link insertion: %w", err))
+		return nil, errors.AIContextLength(providerName, fmt.Errorf("content too large for link insertion: %w", err))
 	}
 
 	// Calculate actual available tokens
@@ -720,7 +821,7 @@ func (c *OpenAIClient) InsertLinks(ctx context.Context, request *InsertLinksRequ
 
 	chat, err := c.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return nil, errors.AI(providerName, fmt.Errorf("API error: %w", err))
+		return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
 	}
 
 	if len(chat.Choices) == 0 {
@@ -737,7 +838,7 @@ func (c *OpenAIClient) InsertLinks(ctx context.Context, request *InsertLinksRequ
 			chat.Usage.CompletionTokens, maxTokens, chat.Usage.PromptTokens, len(request.Content)))
 	}
 	if finishReason == "content_filter" {
-		return nil, errors.AI(providerName, fmt.Errorf("content filtered by safety system"))
+		return nil, errors.AIContentFilter(providerName, fmt.Errorf("content filtered by safety system"))
 	}
 
 	content := choice.Message.Content
@@ -745,35 +846,150 @@ func (c *OpenAIClient) InsertLinks(ctx context.Context, request *InsertLinksRequ
 		return nil, errors.AI(providerName, fmt.Errorf("empty response from API"))
 	}
 
-	var result InsertLinksContentSchema
-	if err = json.Unmarshal([]byte(content), &result); err != nil {
-		sanitized := sanitizeJSON(content)
-		if err2 := json.Unmarshal([]byte(sanitized), &result); err2 != nil {
-			preview := content
-			if len(preview) > 300 {
-				preview = preview[:300] + "..."
-			}
-			return nil, errors.AI(providerName, fmt.Errorf("failed to parse response: %w, raw: %s", err, preview))
-		}
+	result, repairUsage, err := parseStructured[InsertLinksContentSchema](ctx, content, c.repairer(maxTokens))
+	if err != nil {
+		return nil, errors.AI(providerName, err)
 	}
 
-	inputTokens := int(chat.Usage.PromptTokens)
-	outputTokens := int(chat.Usage.CompletionTokens)
-	totalTokens := int(chat.Usage.TotalTokens)
-	cost := CalculateCost(entities.TypeOpenAI, c.modelName, inputTokens, outputTokens)
+	usage := c.usage(chat).add(repairUsage)
 
-	fmt.Printf("[OpenAI] InsertLinks success: linksApplied=%d, cost=$%.4f\n", result.LinksApplied, cost)
+	fmt.Printf("[OpenAI] InsertLinks success: linksApplied=%d, cost=$%.4f\n", result.LinksApplied, usage.CostUSD)
 
 	return &InsertLinksResult{
 		Content:      result.Content,
 		LinksApplied: result.LinksApplied,
+		Usage:        usage,
+	}, nil
+}
+
+func (c *OpenAIClient) RewriteArticle(ctx context.Context, request *RewriteArticleRequest) (*ArticleResult, error) {
+	systemPrompt, userPrompt := rewritePrompts(request)
+	return c.GenerateArticle(ctx, systemPrompt, userPrompt, rewriteOptions(request))
+}
+
+func (c *OpenAIClient) Embed(ctx context.Context, texts []string) (*EmbeddingResult, error) {
+	result := &EmbeddingResult{Model: c.embeddingModel}
+
+	for _, batch := range embeddingBatches(texts) {
+		resp, err := c.client.Embeddings.New(ctx, openaiSDK.EmbeddingNewParams{
+			Input: openaiSDK.EmbeddingNewParamsInputUnion{OfArrayOfStrings: batch},
+			Model: c.embeddingModel,
+		})
+		if err != nil {
+			return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
+		}
+
+		if len(resp.Data) != len(batch) {
+			return nil, errors.AI(providerName, fmt.Errorf("expected %d embeddings, got %d", len(batch), len(resp.Data)))
+		}
+
+		vectors := make([][]float32, len(batch))
+		for _, item := range resp.Data {
+			if item.Index < 0 || int(item.Index) >= len(vectors) {
+				return nil, errors.AI(providerName, fmt.Errorf("embedding index %d out of range", item.Index))
+			}
+			vector := make([]float32, len(item.Embedding))
+			for i, v := range item.Embedding {
+				vector[i] = float32(v)
+			}
+			vectors[item.Index] = vector
+		}
+		result.Vectors = append(result.Vectors, vectors...)
+
+		// Self-hosted servers often leave usage empty
+		inputTokens := int(resp.Usage.PromptTokens)
+		if inputTokens == 0 {
+			inputTokens = estimateTokens(strings.Join(batch, "\n"))
+		}
+		result.Usage.InputTokens += inputTokens
+		result.Usage.TotalTokens += inputTokens
+	}
+
+	if c.providerType == entities.TypeOpenAI {
+		result.Usage.CostUSD = embeddingCost(c.embeddingModel, result.Usage.InputTokens)
+	}
+
+	return result, nil
+}
+
+// GenerateImage is only available on OpenAI itself, OpenAI-compatible servers rarely serve images
+func (c *OpenAIClient) GenerateImage(ctx context.Context, request *ImageRequest) (*ImageResult, error) {
+	if c.providerType != entities.TypeOpenAI {
+		return nil, errors.Validation("Image generation is not available for OpenAI-compatible providers")
+	}
+
+	size := request.Size
+	if size == "" {
+		size = DefaultImageSize
+	}
+
+	model := defaultImageModels[entities.TypeOpenAI]
+	resp, err := c.client.Images.Generate(ctx, openaiSDK.ImageGenerateParams{
+		Prompt:  request.Prompt,
+		Model:   openaiSDK.ImageModel(model),
+		Size:    openaiSDK.ImageGenerateParamsSize(size),
+		Quality: openaiSDK.ImageGenerateParamsQualityMedium,
+		N:       openaiSDK.Int(1),
+	})
+	if err != nil {
+		return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
+	}
+
+	if len(resp.Data) == 0 || resp.Data[0].B64JSON == "" {
+		return nil, errors.AI(providerName, fmt.Errorf("no image in response"))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(resp.Data[0].B64JSON)
+	if err != nil {
+		return nil, errors.AI(providerName, fmt.Errorf("invalid image data: %w", err))
+	}
+
+	mimeType := "image/png"
+	switch resp.OutputFormat {
+	case "jpeg":
+		mimeType = "image/jpeg"
+	case "webp":
+		mimeType = "image/webp"
+	}
+
+	result := &ImageResult{
+		Data:     data,
+		MimeType: mimeType,
+		Model:    model,
 		Usage: Usage{
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			TotalTokens:  totalTokens,
-			CostUSD:      cost,
+			InputTokens:  int(resp.Usage.InputTokens),
+			OutputTokens: int(resp.Usage.OutputTokens),
+			TotalTokens:  int(resp.Usage.TotalTokens),
 		},
-	}, nil
+	}
+	result.Usage.CostUSD = imageCost(model, result.Usage)
+
+	return result, nil
+}
+
+func (c *OpenAIClient) Ping(ctx context.Context) (*PingResult, error) {
+	params := openaiSDK.ChatCompletionNewParams{
+		Messages: []openaiSDK.ChatCompletionMessageParamUnion{
+			openaiSDK.UserMessage(pingPrompt),
+		},
+		Model: c.model,
+	}
+
+	switch {
+	case c.isReasoningModel:
+		params.MaxCompletionTokens = openaiSDK.Int(pingReasoningOutputTokens)
+	case c.usesCompletionTokens:
+		params.MaxCompletionTokens = openaiSDK.Int(pingOutputTokens)
+	default:
+		params.MaxTokens = openaiSDK.Int(pingOutputTokens)
+	}
+
+	chat, err := c.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return nil, classifyError(providerName, fmt.Errorf("API error: %w", err))
+	}
+
+	return &PingResult{Usage: c.usage(chat)}, nil
 }
 
 func buildInsertLinksSystemPrompt(language string) string {
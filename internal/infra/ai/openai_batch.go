@@ -0,0 +1,209 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+
+	openaiSDK "github.com/openai/openai-go/v3"
+)
+
+var _ BatchClient = (*OpenAIClient)(nil)
+
+// openAIBatchLine is a request line of a batch input file
+type openAIBatchLine struct {
+	CustomID string                            `json:"custom_id"`
+	Method   string                            `json:"method"`
+	URL      string                            `json:"url"`
+	Body     openaiSDK.ChatCompletionNewParams `json:"body"`
+}
+
+// openAIBatchOutput is a line of a batch output or error file
+type openAIBatchOutput struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SubmitArticleBatch uploads the requests as a JSONL file and starts a batch of chat completions
+func (c *OpenAIClient) SubmitArticleBatch(ctx context.Context, requests []BatchRequest) (string, error) {
+	if len(requests) == 0 {
+		return "", errors.Validation("Batch has no requests")
+	}
+
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, request := range requests {
+		params, _, err := c.articleParams(request.SystemPrompt, request.UserPrompt, request.Options)
+		if err != nil {
+			return "", fmt.Errorf("request %s: %w", request.CustomID, err)
+		}
+
+		err = encoder.Encode(openAIBatchLine{
+			CustomID: request.CustomID,
+			Method:   "POST",
+			URL:      string(openaiSDK.BatchNewParamsEndpointV1ChatCompletions),
+			Body:     params,
+		})
+		if err != nil {
+			return "", errors.Internal(err)
+		}
+	}
+
+	file, err := c.client.Files.New(ctx, openaiSDK.FileNewParams{
+		File:    openaiSDK.File(&input, "batch.jsonl", "application/jsonl"),
+		Purpose: openaiSDK.FilePurposeBatch,
+	})
+	if err != nil {
+		return "", classifyError(providerName, fmt.Errorf("batch upload error: %w", err))
+	}
+
+	batch, err := c.client.Batches.New(ctx, openaiSDK.BatchNewParams{
+		CompletionWindow: openaiSDK.BatchNewParamsCompletionWindow24h,
+		Endpoint:         openaiSDK.BatchNewParamsEndpointV1ChatCompletions,
+		InputFileID:      file.ID,
+	})
+	if err != nil {
+		return "", classifyError(providerName, fmt.Errorf("batch error: %w", err))
+	}
+
+	return batch.ID, nil
+}
+
+func (c *OpenAIClient) GetBatch(ctx context.Context, batchID string) (*BatchState, error) {
+	batch, err := c.client.Batches.Get(ctx, batchID)
+	if err != nil {
+		return nil, classifyError(providerName, fmt.Errorf("batch error: %w", err))
+	}
+
+	state := &BatchState{
+		Status:    openAIBatchStatus(batch.Status),
+		Total:     int(batch.RequestCounts.Total),
+		Succeeded: int(batch.RequestCounts.Completed),
+		Failed:    int(batch.RequestCounts.Failed),
+	}
+	if state.Status == BatchStatusFailed && len(batch.Errors.Data) > 0 {
+		state.Error = batch.Errors.Data[0].Message
+	}
+
+	return state, nil
+}
+
+func (c *OpenAIClient) GetBatchResults(ctx context.Context, batchID string) ([]*BatchItemResult, error) {
+	batch, err := c.client.Batches.Get(ctx, batchID)
+	if err != nil {
+		return nil, classifyError(providerName, fmt.Errorf("batch error: %w", err))
+	}
+
+	if !openAIBatchStatus(batch.Status).Finished() {
+		return nil, errors.Validation("Batch is not finished yet")
+	}
+
+	var results []*BatchItemResult
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+
+		fileResults, err := c.batchFileResults(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, fileResults...)
+	}
+
+	return results, nil
+}
+
+// batchFileResults parses an output or error file of a batch
+func (c *OpenAIClient) batchFileResults(ctx context.Context, fileID string) ([]*BatchItemResult, error) {
+	response, err := c.client.Files.Content(ctx, fileID)
+	if err != nil {
+		return nil, classifyError(providerName, fmt.Errorf("batch results error: %w", err))
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	var results []*BatchItemResult
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var line openAIBatchOutput
+		if err = json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, errors.AI(providerName, fmt.Errorf("malformed batch result: %w", err))
+		}
+
+		results = append(results, c.batchItemResult(ctx, &line))
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, errors.AI(providerName, fmt.Errorf("failed to read batch results: %w", err))
+	}
+
+	return results, nil
+}
+
+func (c *OpenAIClient) batchItemResult(ctx context.Context, line *openAIBatchOutput) *BatchItemResult {
+	result := &BatchItemResult{CustomID: line.CustomID}
+
+	switch {
+	case line.Error != nil:
+		result.Err = errors.AI(providerName, fmt.Errorf("%s: %s", line.Error.Code, line.Error.Message))
+	case line.Response == nil:
+		result.Err = errors.AI(providerName, fmt.Errorf("no response in batch result"))
+	case line.Response.StatusCode != 200:
+		result.Err = errors.AI(providerName, fmt.Errorf("request failed with status %d: %s", line.Response.StatusCode, line.Response.Body))
+	default:
+		var chat openaiSDK.ChatCompletion
+		if err := json.Unmarshal(line.Response.Body, &chat); err != nil {
+			result.Err = errors.AI(providerName, fmt.Errorf("malformed completion: %w", err))
+			break
+		}
+
+		article, err := c.articleResult(ctx, &chat, batchUsage(c.usage(&chat)), defaultArticleOutputTokens)
+		if err != nil {
+			result.Err = err
+			break
+		}
+		result.Article = article
+	}
+
+	return result
+}
+
+func (c *OpenAIClient) CancelBatch(ctx context.Context, batchID string) error {
+	if _, err := c.client.Batches.Cancel(ctx, batchID); err != nil {
+		return classifyError(providerName, fmt.Errorf("batch error: %w", err))
+	}
+	return nil
+}
+
+func openAIBatchStatus(status openaiSDK.BatchStatus) BatchStatus {
+	switch status {
+	case openaiSDK.BatchStatusCompleted:
+		return BatchStatusCompleted
+	case openaiSDK.BatchStatusExpired:
+		return BatchStatusExpired
+	case openaiSDK.BatchStatusCancelled:
+		return BatchStatusCancelled
+	case openaiSDK.BatchStatusFailed:
+		return BatchStatusFailed
+	default:
+		return BatchStatusPending
+	}
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+)
+
+func newTestOpenAIClient(t *testing.T, model string) *OpenAIClient {
+	t.Helper()
+
+	client, err := NewOpenAIClient(Config{APIKey: "test", Model: model, ProviderType: entities.TypeOpenAI})
+	if err != nil {
+		t.Fatalf("NewOpenAIClient: %v", err)
+	}
+	return client
+}
+
+func testArticleOptions() *GenerateArticleOptions {
+	temperature, topP, maxTokens, seed := 0.2, 0.9, 3000, int64(42)
+	return &GenerateArticleOptions{
+		Temperature:     &temperature,
+		TopP:            &topP,
+		MaxOutputTokens: &maxTokens,
+		Seed:            &seed,
+		Stop:            []string{"</article>"},
+	}
+}
+
+func TestOpenAIArticleParamsApplyOptions(t *testing.T) {
+	client := newTestOpenAIClient(t, "gpt-4o-mini")
+
+	params, maxTokens, err := client.articleParams("system", "user", testArticleOptions())
+	if err != nil {
+		t.Fatalf("articleParams: %v", err)
+	}
+
+	if params.Temperature.Value != 0.2 || params.TopP.Value != 0.9 || params.Seed.Value != 42 {
+		t.Errorf("unexpected sampling params: temperature=%v topP=%v seed=%v",
+			params.Temperature.Value, params.TopP.Value, params.Seed.Value)
+	}
+	if !reflect.DeepEqual(params.Stop.OfStringArray, []string{"</article>"}) {
+		t.Errorf("unexpected stop sequences: %v", params.Stop.OfStringArray)
+	}
+	if maxTokens != 3000 || params.MaxTokens.Value != 3000 || params.MaxCompletionTokens.Valid() {
+		t.Errorf("expected max_tokens=3000, got max_tokens=%d max_completion_tokens=%d",
+			params.MaxTokens.Value, params.MaxCompletionTokens.Value)
+	}
+}
+
+func TestOpenAIArticleParamsDefaults(t *testing.T) {
+	client := newTestOpenAIClient(t, "gpt-4o-mini")
+
+	params, _, err := client.articleParams("system", "user", nil)
+	if err != nil {
+		t.Fatalf("articleParams: %v", err)
+	}
+
+	if params.Temperature.Value != 0.7 {
+		t.Errorf("expected default temperature 0.7, got %v", params.Temperature.Value)
+	}
+	if params.TopP.Valid() || params.Seed.Valid() || len(params.Stop.OfStringArray) > 0 {
+		t.Error("expected top_p, seed and stop to be unset")
+	}
+}
+
+func TestOpenAIArticleParamsReasoningModel(t *testing.T) {
+	client := newTestOpenAIClient(t, "gpt-5-mini")
+
+	params, _, err := client.articleParams("system", "user", testArticleOptions())
+	if err != nil {
+		t.Fatalf("articleParams: %v", err)
+	}
+
+	if params.Temperature.Valid() || params.TopP.Valid() || len(params.Stop.OfStringArray) > 0 {
+		t.Error("expected temperature, top_p and stop to be omitted for a reasoning model")
+	}
+	if params.Seed.Value != 42 {
+		t.Errorf("expected seed to be kept, got %v", params.Seed.Value)
+	}
+	if params.MaxCompletionTokens.Value != 3000 || params.MaxTokens.Valid() {
+		t.Errorf("expected max_completion_tokens=3000, got %d", params.MaxCompletionTokens.Value)
+	}
+}
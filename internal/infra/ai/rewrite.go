@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rewriteOutputRatio sizes the output budget of a rewrite relative to the existing
+// content, leaving room for expansions and the JSON wrapper
+const rewriteOutputRatio = 1.5
+
+// rewritePrompts returns the prompts of a rewrite request, falling back to the
+// default ones when the request doesn't carry rendered prompts
+func rewritePrompts(request *RewriteArticleRequest) (systemPrompt, userPrompt string) {
+	systemPrompt = request.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = buildRewriteSystemPrompt(request.Language)
+	}
+	userPrompt = request.UserPrompt
+	if userPrompt == "" {
+		userPrompt = buildRewriteUserPrompt(request)
+	}
+	return systemPrompt, userPrompt
+}
+
+// rewriteOptions sizes the output budget to the existing content. An explicit
+// MaxOutputTokens is kept as is.
+func rewriteOptions(request *RewriteArticleRequest) *GenerateArticleOptions {
+	var opts GenerateArticleOptions
+	if request.Options != nil {
+		opts = *request.Options
+	}
+
+	if opts.MaxOutputTokens == nil {
+		budget := max(int(float64(estimateTokens(request.Content))*rewriteOutputRatio), defaultArticleOutputTokens)
+		opts.MaxOutputTokens = &budget
+	}
+
+	return &opts
+}
+
+func buildRewriteSystemPrompt(language string) string {
+	if language == "" {
+		language = "English"
+	}
+	return fmt.Sprintf(`You are an editor revising existing WordPress articles.
+
+TASK: Apply the requested changes to the article and return the full revised article.
+
+RULES:
+1. Change only what the instructions ask for, keep everything else as close to the original as possible
+2. Keep the existing HTML structure, headings and links unless the instructions say otherwise
+3. Keep facts consistent, do not invent statistics or sources
+4. Use only: <h2>, <h3>, <p>, <ul>, <li>, <ol>, <strong>, <em>, <a>
+5. Do NOT include <h1> - title is added separately
+
+Language: %s
+
+OUTPUT: Return the revised title, a brief excerpt and the complete revised HTML content.`, language)
+}
+
+func buildRewriteUserPrompt(request *RewriteArticleRequest) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("ARTICLE: \"%s\"\n\n", request.Title))
+
+	sb.WriteString("INSTRUCTIONS:\n")
+	sb.WriteString(strings.TrimSpace(request.Instructions))
+	sb.WriteString("\n\nCONTENT:\n")
+	sb.WriteString(request.Content)
+
+	return sb.String()
+}
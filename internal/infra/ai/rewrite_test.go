@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRewriteOptionsSizeBudget(t *testing.T) {
+	request := &RewriteArticleRequest{Content: strings.Repeat("<p>Long imported article.</p>", 2000)}
+
+	got := *rewriteOptions(request).MaxOutputTokens
+	if want := int(float64(estimateTokens(request.Content)) * rewriteOutputRatio); got != want {
+		t.Errorf("expected budget sized to the content (%d), got %d", want, got)
+	}
+
+	if got = *rewriteOptions(&RewriteArticleRequest{Content: "<p>Short</p>"}).MaxOutputTokens; got != defaultArticleOutputTokens {
+		t.Errorf("expected default budget for short content, got %d", got)
+	}
+
+	limit := 2048
+	request.Options = &GenerateArticleOptions{MaxOutputTokens: &limit}
+	if got = *rewriteOptions(request).MaxOutputTokens; got != limit {
+		t.Errorf("expected explicit limit to be kept, got %d", got)
+	}
+}
+
+func TestMockRewriteKeepsArticle(t *testing.T) {
+	client := newSyntheticClient(t)
+	request := &RewriteArticleRequest{
+		Title:        "Best hiking trails",
+		Content:      "<h2>Trails</h2>\n<p>Original text.</p>",
+		Instructions: "update for 2026",
+	}
+
+	first, err := client.RewriteArticle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("RewriteArticle: %v", err)
+	}
+	second, err := client.RewriteArticle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("RewriteArticle: %v", err)
+	}
+
+	if first.Title != request.Title {
+		t.Errorf("expected title to be kept, got %q", first.Title)
+	}
+	if !strings.HasPrefix(first.Content, request.Content) || first.Content == request.Content {
+		t.Errorf("expected revision to extend the original content, got %q", first.Content)
+	}
+	if first.Content != second.Content {
+		t.Error("expected synthetic rewrite to be deterministic")
+	}
+}
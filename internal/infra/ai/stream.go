@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// streamProgressInterval throttles progress callbacks so that consumers
+// (event bus, UI) aren't flooded with one event per token
+const streamProgressInterval = 250 * time.Millisecond
+
+// streamTracker accumulates streamed text and reports throttled progress
+type streamTracker struct {
+	buf        strings.Builder
+	onProgress StreamProgressFunc
+	lastEmit   time.Time
+}
+
+func newStreamTracker(onProgress StreamProgressFunc) *streamTracker {
+	return &streamTracker{onProgress: onProgress}
+}
+
+// Write appends a text delta and emits progress if the interval has passed
+func (t *streamTracker) Write(delta string) {
+	if delta == "" {
+		return
+	}
+
+	t.buf.WriteString(delta)
+
+	if t.onProgress != nil && time.Since(t.lastEmit) >= streamProgressInterval {
+		t.emit()
+	}
+}
+
+// Flush emits the final progress state regardless of throttling
+func (t *streamTracker) Flush() {
+	if t.onProgress != nil {
+		t.emit()
+	}
+}
+
+// String returns the full text received so far
+func (t *streamTracker) String() string {
+	return t.buf.String()
+}
+
+func (t *streamTracker) emit() {
+	t.lastEmit = time.Now()
+
+	text := t.buf.String()
+	t.onProgress(StreamProgress{
+		Chars:        len(text),
+		Tokens:       estimateTokens(text),
+		PartialTitle: partialJSONString(text, "title"),
+	})
+}
+
+// estimateTokens approximates token count at ~3.5 chars per token for mixed content
+func estimateTokens(text string) int {
+	return (len(text) * 10) / 35
+}
+
+// partialJSONString extracts the (possibly unterminated) string value of a key
+// from an incomplete JSON document, e.g. the title while the content is still streaming
+func partialJSONString(raw, key string) string {
+	idx := strings.Index(raw, `"`+key+`"`)
+	if idx == -1 {
+		return ""
+	}
+
+	rest := strings.TrimLeft(raw[idx+len(key)+2:], " \t\r\n")
+	if !strings.HasPrefix(rest, ":") {
+		return ""
+	}
+
+	rest = strings.TrimLeft(rest[1:], " \t\r\n")
+	if !strings.HasPrefix(rest, `"`) {
+		return ""
+	}
+	rest = rest[1:]
+
+	end := len(rest)
+	escaped := false
+	for i := 0; i < len(rest) && end == len(rest); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case rest[i] == '\\':
+			escaped = true
+		case rest[i] == '"':
+			end = i
+		}
+	}
+
+	value := rest[:end]
+	if escaped {
+		// Drop a dangling backslash from an escape sequence cut mid-stream
+		value = value[:len(value)-1]
+	}
+
+	var decoded string
+	if err := json.Unmarshal([]byte(`"`+value+`"`), &decoded); err == nil {
+		return decoded
+	}
+
+	// A \u escape cut mid-stream decodes once the rest of it arrives
+	if cut := strings.LastIndex(value, `\`); cut != -1 {
+		if err := json.Unmarshal([]byte(`"`+value[:cut]+`"`), &decoded); err == nil {
+			return decoded
+		}
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"testing"
+)
+
+func TestPartialJSONString(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "complete", raw: `{"title": "Go", "content": "<p>x</p>"}`, want: "Go"},
+		{name: "unterminated", raw: `{"title": "Streaming ti`, want: "Streaming ti"},
+		{name: "no space", raw: `{"title":"Go"}`, want: "Go"},
+		{name: "escaped quote", raw: `{"title": "Say \"hi\"", "content": ""}`, want: `Say "hi"`},
+		{name: "non-ascii", raw: `{"title": "Café guide`, want: "Café guide"},
+		{name: "unicode escape", raw: `{"title": "Caf\u00e9", "content": ""}`, want: "Café"},
+		{name: "dangling backslash", raw: `{"title": "Line\`, want: "Line"},
+		{name: "cut unicode escape", raw: `{"title": "Caf\u00`, want: "Caf"},
+		{name: "key not streamed yet", raw: `{"tit`, want: ""},
+		{name: "value not started", raw: `{"title": `, want: ""},
+		{name: "not a string", raw: `{"title": 42}`, want: ""},
+		{name: "missing key", raw: `{"content": "<p>x</p>"}`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partialJSONString(tt.raw, "title"); got != tt.want {
+				t.Errorf("partialJSONString(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamTrackerThrottlesProgress(t *testing.T) {
+	var progress []StreamProgress
+	tracker := newStreamTracker(func(p StreamProgress) {
+		progress = append(progress, p)
+	})
+
+	tracker.Write(`{"title": "Go`)
+	tracker.Write(`", "content": "`)
+	tracker.Write("")
+
+	if len(progress) != 1 {
+		t.Fatalf("expected the first delta only to report progress, got %d reports", len(progress))
+	}
+	if progress[0].PartialTitle != "Go" || progress[0].Chars != len(`{"title": "Go`) {
+		t.Errorf("unexpected first progress %+v", progress[0])
+	}
+
+	tracker.Flush()
+
+	if len(progress) != 2 {
+		t.Fatalf("expected Flush to report progress, got %d reports", len(progress))
+	}
+	last := progress[1]
+	if last.Chars != len(tracker.String()) || last.Tokens != estimateTokens(tracker.String()) {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+}
+
+func TestStreamTrackerWithoutCallback(t *testing.T) {
+	tracker := newStreamTracker(nil)
+	tracker.Write("partial ")
+	tracker.Write("text")
+	tracker.Flush()
+
+	if got := tracker.String(); got != "partial text" {
+		t.Errorf("expected accumulated text, got %q", got)
+	}
+}
@@ -0,0 +1,449 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/invopop/jsonschema"
+)
+
+// maxRepairAttempts bounds how many repair requests are sent for one malformed response
+const maxRepairAttempts = 2
+
+// maxReportedProblems limits the validation errors included in a repair request
+const maxReportedProblems = 10
+
+// repairFunc sends a plain JSON completion request to the same provider and model
+// that produced the malformed response
+type repairFunc func(ctx context.Context, systemPrompt, userPrompt string) (string, Usage, error)
+
+// semanticValidator is implemented by structured response types that need checks
+// the JSON schema cannot express, e.g. non-empty strings
+type semanticValidator interface {
+	validate() []string
+}
+
+const repairSystemPrompt = `You repair malformed JSON responses.
+
+RULES:
+- Return ONLY a single JSON object that conforms to the given JSON Schema
+- Keep the original values and content wherever possible, fix only what the errors describe
+- Do not add explanations, markdown code fences or any text outside the JSON object`
+
+// parseStructured decodes raw model output into T and validates it against the JSON
+// schema of T. If the output is invalid, up to maxRepairAttempts repair requests are
+// sent with the validation errors. The returned usage covers the repair requests only.
+func parseStructured[T any](ctx context.Context, raw string, repair repairFunc) (*T, Usage, error) {
+	var total Usage
+
+	result, problems := decodeStructured[T](raw)
+	if len(problems) == 0 {
+		return result, total, nil
+	}
+
+	if repair == nil {
+		return nil, total, structuredError(problems, raw)
+	}
+
+	schema, err := json.MarshalIndent(schemaFor[T](), "", "  ")
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	for attempt := 1; attempt <= maxRepairAttempts; attempt++ {
+		fmt.Printf("[AI] Structured response invalid (%d problems), repair attempt %d/%d\n",
+			len(problems), attempt, maxRepairAttempts)
+
+		repaired, usage, err := repair(ctx, repairSystemPrompt, buildRepairPrompt(string(schema), raw, problems))
+		total = total.add(usage)
+		if err != nil {
+			return nil, total, fmt.Errorf("repair request failed: %w", err)
+		}
+
+		raw = repaired
+		if result, problems = decodeStructured[T](raw); len(problems) == 0 {
+			return result, total, nil
+		}
+	}
+
+	return nil, total, structuredError(problems, raw)
+}
+
+// decodeStructured extracts the JSON object from raw model output, validates it against
+// the JSON schema of T and decodes it. It returns the problems found instead of an error
+// so they can be sent back to the model.
+func decodeStructured[T any](raw string) (*T, []string) {
+	jsonStr := extractJSON(raw)
+	if jsonStr == "" {
+		return nil, []string{"response is empty, expected a JSON object"}
+	}
+
+	value, err := decodeJSONValue(jsonStr)
+	if err != nil {
+		// Models often emit invalid escape sequences inside HTML content
+		sanitized := sanitizeJSON(jsonStr)
+		if value, err = decodeJSONValue(sanitized); err != nil {
+			return nil, []string{fmt.Sprintf("response is not valid JSON: %v", err)}
+		}
+		jsonStr = sanitized
+	}
+
+	schema := schemaFor[T]()
+	problems := validateSchema(schema, schema, value, "$")
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	var result T
+	if err = json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		return nil, []string{fmt.Sprintf("response does not match the expected structure: %v", err)}
+	}
+
+	if v, ok := any(&result).(semanticValidator); ok {
+		if problems = v.validate(); len(problems) > 0 {
+			return nil, problems
+		}
+	}
+
+	return &result, nil
+}
+
+func decodeJSONValue(s string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after top-level value")
+	}
+
+	return value, nil
+}
+
+var schemaCache sync.Map // reflect.Type -> *jsonschema.Schema
+
+// schemaFor returns the cached validation schema for T. Unlike generateSchema, it keeps
+// $defs references so recursive types like SitemapGeneratedNode can be described.
+func schemaFor[T any]() *jsonschema.Schema {
+	t := reflect.TypeFor[T]()
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.(*jsonschema.Schema)
+	}
+
+	reflector := jsonschema.Reflector{
+		AllowAdditionalProperties: true,
+		ExpandedStruct:            true,
+	}
+	var v T
+	schema := reflector.Reflect(v)
+
+	actual, _ := schemaCache.LoadOrStore(t, schema)
+	return actual.(*jsonschema.Schema)
+}
+
+// validateSchema checks value against the subset of JSON Schema produced by the
+// reflector: $ref, type, required, properties and items.
+func validateSchema(root, schema *jsonschema.Schema, value any, path string) []string {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/$defs/")
+		def, ok := root.Definitions[name]
+		if !ok {
+			return nil
+		}
+		return validateSchema(root, def, value, path)
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object, got %s", path, jsonTypeName(value))}
+		}
+
+		var problems []string
+		required := make(map[string]bool, len(schema.Required))
+		for _, name := range schema.Required {
+			required[name] = true
+			if _, exists := obj[name]; !exists {
+				problems = append(problems, fmt.Sprintf("%s: missing required field %q", path, name))
+			}
+		}
+		if schema.Properties != nil {
+			for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				field, exists := obj[pair.Key]
+				// Optional fields may be null, which decodes to the zero value
+				if !exists || field == nil && !required[pair.Key] {
+					continue
+				}
+				problems = append(problems, validateSchema(root, pair.Value, field, path+"."+pair.Key)...)
+			}
+		}
+		return problems
+
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array, got %s", path, jsonTypeName(value))}
+		}
+
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, validateSchema(root, schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: expected string, got %s", path, jsonTypeName(value))}
+		}
+
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected integer, got %s", path, jsonTypeName(value))}
+		}
+		if _, err := number.Int64(); err != nil {
+			return []string{fmt.Sprintf("%s: expected integer, got %s", path, number)}
+		}
+
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return []string{fmt.Sprintf("%s: expected number, got %s", path, jsonTypeName(value))}
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected boolean, got %s", path, jsonTypeName(value))}
+		}
+	}
+
+	return nil
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func buildRepairPrompt(schema, raw string, problems []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("The following response does not match the required JSON Schema.\n\n")
+	sb.WriteString("JSON SCHEMA:\n")
+	sb.WriteString(schema)
+	sb.WriteString("\n\nVALIDATION ERRORS:\n")
+	for i, problem := range problems {
+		if i == maxReportedProblems {
+			sb.WriteString(fmt.Sprintf("- ...and %d more\n", len(problems)-maxReportedProblems))
+			break
+		}
+		sb.WriteString("- ")
+		sb.WriteString(problem)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nRESPONSE TO REPAIR:\n")
+	sb.WriteString(raw)
+
+	return sb.String()
+}
+
+func structuredError(problems []string, raw string) error {
+	if len(problems) > maxReportedProblems {
+		problems = append(problems[:maxReportedProblems:maxReportedProblems], fmt.Sprintf("...and %d more", len(problems)-maxReportedProblems))
+	}
+	return fmt.Errorf("invalid structured response: %s, raw: %s", strings.Join(problems, "; "), truncate(raw, 300))
+}
+
+// truncate cuts s to at most n bytes, on a rune boundary
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+// extractJSON attempts to extract a JSON object from a string that may contain other
+// text, such as markdown code fences or a short preamble
+func extractJSON(s string) string {
+	s = strings.TrimSpace(s)
+
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+
+	if start != -1 && end != -1 && end > start {
+		return s[start : end+1]
+	}
+
+	return s
+}
+
+// sanitizeJSON fixes common invalid escape sequences in JSON strings
+// This helps handle cases where AI generates content with invalid escapes like "\ " or "\x"
+func sanitizeJSON(s string) string {
+	// Valid JSON escapes: " \ / b f n r t u
+	var result bytes.Buffer
+	result.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch next := s[i+1]; next {
+			case '\\':
+				// Keep escaped backslashes intact so the following char isn't reinterpreted
+				result.WriteString(`\\`)
+				i++
+			case '"', '/', 'b', 'f', 'n', 'r', 't':
+				result.WriteByte(s[i])
+			case 'u':
+				// Unicode escape must be followed by 4 hex digits
+				if i+5 < len(s) && isHex(s[i+2:i+6]) {
+					result.WriteByte(s[i])
+				}
+			default:
+				// Invalid escape - drop the backslash
+			}
+		} else {
+			result.WriteByte(s[i])
+		}
+	}
+
+	return result.String()
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanQuotes strips a pair of quotes the model sometimes wraps around a title
+func cleanQuotes(s string) string {
+	s = strings.TrimSpace(s)
+
+	if len(s) >= 2 {
+		firstChar := s[0]
+		lastChar := s[len(s)-1]
+
+		if (firstChar == '"' && lastChar == '"') ||
+			(firstChar == '\'' && lastChar == '\'') ||
+			(firstChar == '`' && lastChar == '`') {
+			return s[1 : len(s)-1]
+		}
+	}
+
+	return s
+}
+
+// topicVariations trims the parsed variations to amount and strips wrapping quotes
+func topicVariations(result *TopicVariations, amount int) []string {
+	variations := result.Variations
+	if len(variations) > amount {
+		variations = variations[:amount]
+	}
+
+	cleaned := make([]string, len(variations))
+	for i, variation := range variations {
+		cleaned[i] = cleanQuotes(variation)
+	}
+
+	return cleaned
+}
+
+func (a *ArticleContent) validate() []string {
+	var problems []string
+	if strings.TrimSpace(a.Title) == "" {
+		problems = append(problems, `$.title: must not be empty`)
+	}
+	if strings.TrimSpace(a.Content) == "" {
+		problems = append(problems, `$.content: must not be empty`)
+	}
+	return problems
+}
+
+func (o *ArticleOutline) validate() []string {
+	var problems []string
+	if strings.TrimSpace(o.Title) == "" {
+		problems = append(problems, `$.title: must not be empty`)
+	}
+	if len(o.Sections) == 0 {
+		problems = append(problems, `$.sections: at least one section is required`)
+	}
+	for i, section := range o.Sections {
+		if strings.TrimSpace(section.Heading) == "" {
+			problems = append(problems, fmt.Sprintf("$.sections[%d].heading: must not be empty", i))
+		}
+		if section.TargetWords <= 0 {
+			problems = append(problems, fmt.Sprintf("$.sections[%d].targetWords: must be positive", i))
+		}
+	}
+	return problems
+}
+
+func (v *TopicVariations) validate() []string {
+	if len(v.Variations) == 0 {
+		return []string{`$.variations: at least one variation is required`}
+	}
+	return nil
+}
+
+func (s *SitemapStructureSchema) validate() []string {
+	if len(s.Nodes) == 0 {
+		return []string{`$.nodes: at least one node is required`}
+	}
+	return validateSitemapNodes(s.Nodes, "$.nodes")
+}
+
+func validateSitemapNodes(nodes []SitemapGeneratedNode, path string) []string {
+	var problems []string
+	for i, node := range nodes {
+		nodePath := fmt.Sprintf("%s[%d]", path, i)
+		if strings.TrimSpace(node.Title) == "" {
+			problems = append(problems, nodePath+".title: must not be empty")
+		}
+		problems = append(problems, validateSitemapNodes(node.Children, nodePath+".children")...)
+	}
+	return problems
+}
+
+func (u Usage) add(other Usage) Usage {
+	return Usage{
+		InputTokens:  u.InputTokens + other.InputTokens,
+		OutputTokens: u.OutputTokens + other.OutputTokens,
+		TotalTokens:  u.TotalTokens + other.TotalTokens,
+		CostUSD:      u.CostUSD + other.CostUSD,
+		DurationMs:   u.DurationMs + other.DurationMs,
+	}
+}
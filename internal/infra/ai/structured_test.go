@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDecodeStructuredExtractsFencedJSON(t *testing.T) {
+	raw := "Here is the article:\n```json\n{\"title\": \"Go\", \"excerpt\": \"\", \"content\": \"<p>C:\\ drive</p>\"}\n```"
+
+	article, problems := decodeStructured[ArticleContent](raw)
+	if len(problems) > 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if article.Title != "Go" || article.Content != "<p>C: drive</p>" {
+		t.Errorf("unexpected article: %+v", article)
+	}
+}
+
+func TestDecodeStructuredReportsSchemaProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "wrong type",
+			raw:  `{"links": [{"sourceNodeId": "1", "targetNodeId": 2, "anchorText": "a", "confidence": 0.5}], "explanation": ""}`,
+			want: "$.links[0].sourceNodeId: expected integer, got string",
+		},
+		{
+			name: "missing field",
+			raw:  `{"links": []}`,
+			want: `$: missing required field "explanation"`,
+		},
+		{
+			name: "not json",
+			raw:  `{"links": [`,
+			want: "response is not valid JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := decodeStructured[LinkSuggestionSchema](tt.raw)
+			if !strings.Contains(strings.Join(problems, "\n"), tt.want) {
+				t.Errorf("expected problem %q, got %v", tt.want, problems)
+			}
+		})
+	}
+}
+
+func TestDecodeStructuredValidatesRecursiveSitemap(t *testing.T) {
+	raw := `{"nodes": [{"title": "Root", "slug": "root", "children": [{"title": "", "slug": "child", "keywords": "x"}]}]}`
+
+	_, problems := decodeStructured[SitemapStructureSchema](raw)
+	if len(problems) != 1 || problems[0] != "$.nodes[0].children[0].keywords: expected array, got string" {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+
+	raw = `{"nodes": [{"title": "Root", "slug": "root", "children": [{"title": "", "slug": "child", "keywords": null}]}]}`
+
+	_, problems = decodeStructured[SitemapStructureSchema](raw)
+	if len(problems) != 1 || problems[0] != "$.nodes[0].children[0].title: must not be empty" {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+}
+
+func TestParseStructuredRepairsResponse(t *testing.T) {
+	var prompts []string
+	repair := func(ctx context.Context, systemPrompt, userPrompt string) (string, Usage, error) {
+		prompts = append(prompts, userPrompt)
+		return `{"nodes": [{"title": "Home", "slug": "home"}]}`, Usage{TotalTokens: 10, CostUSD: 0.01}, nil
+	}
+
+	result, usage, err := parseStructured[SitemapStructureSchema](context.Background(), `{"nodes": []}`, repair)
+	if err != nil {
+		t.Fatalf("parseStructured: %v", err)
+	}
+
+	if len(result.Nodes) != 1 || result.Nodes[0].Slug != "home" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if usage.TotalTokens != 10 {
+		t.Errorf("expected repair usage to be reported, got %+v", usage)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "at least one node is required") {
+		t.Errorf("expected repair prompt with validation errors, got %v", prompts)
+	}
+}
+
+func TestParseStructuredBoundsRepairAttempts(t *testing.T) {
+	calls := 0
+	repair := func(ctx context.Context, systemPrompt, userPrompt string) (string, Usage, error) {
+		calls++
+		return "still not json", Usage{TotalTokens: 5}, nil
+	}
+
+	_, usage, err := parseStructured[LinkSuggestionSchema](context.Background(), "not json", repair)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != maxRepairAttempts || usage.TotalTokens != 5*maxRepairAttempts {
+		t.Errorf("expected %d repair attempts, got %d (usage %+v)", maxRepairAttempts, calls, usage)
+	}
+
+	calls = 0
+	failing := func(ctx context.Context, systemPrompt, userPrompt string) (string, Usage, error) {
+		calls++
+		return "", Usage{}, fmt.Errorf("rate limited")
+	}
+
+	if _, _, err = parseStructured[LinkSuggestionSchema](context.Background(), "not json", failing); err == nil || calls != 1 {
+		t.Errorf("expected repair error after one call, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	got := truncate(strings.Repeat("я", 10), 5)
+	if !utf8.ValidString(got) || got != "яя..." {
+		t.Errorf("expected the cut on a rune boundary, got %q", got)
+	}
+	if got = truncate("short", 10); got != "short" {
+		t.Errorf("expected a short string to be kept, got %q", got)
+	}
+}
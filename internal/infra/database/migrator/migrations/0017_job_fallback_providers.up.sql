@@ -0,0 +1,23 @@
+-- +goose Up
+-- =========================================================================
+-- JOB FALLBACK PROVIDERS
+-- =========================================================================
+
+-- Ordered list of providers tried after jobs.ai_provider_id fails.
+-- Deleting a provider simply drops it from every fallback chain.
+CREATE TABLE job_fallback_providers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    provider_id INTEGER NOT NULL,
+    order_index INTEGER NOT NULL DEFAULT 0,
+
+    FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE,
+    FOREIGN KEY (provider_id) REFERENCES ai_providers(id) ON DELETE CASCADE,
+    UNIQUE(job_id, provider_id)
+);
+
+CREATE INDEX idx_job_fallback_providers_order ON job_fallback_providers(job_id, order_index);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_job_fallback_providers_order;
+DROP TABLE IF EXISTS job_fallback_providers;
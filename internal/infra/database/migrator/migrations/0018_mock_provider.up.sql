@@ -0,0 +1,68 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- =========================================================================
+-- MOCK PROVIDER: offline synthetic / replay provider type
+-- =========================================================================
+
+-- SQLite can't alter CHECK constraints, so the table is recreated.
+-- Foreign keys are disabled while swapping tables, otherwise dropping
+-- ai_providers would fire ON DELETE actions in referencing tables.
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+CREATE TABLE ai_providers_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    api_key TEXT NOT NULL,
+    base_url TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK (provider IN ('openai', 'anthropic', 'google', 'openai_compatible', 'mock'))
+);
+
+INSERT INTO ai_providers_new (id, name, provider, model, api_key, base_url, is_active, created_at, updated_at)
+SELECT id, name, provider, model, api_key, base_url, is_active, created_at, updated_at
+FROM ai_providers;
+
+DROP INDEX IF EXISTS idx_ai_providers_active;
+DROP TABLE ai_providers;
+ALTER TABLE ai_providers_new RENAME TO ai_providers;
+CREATE INDEX idx_ai_providers_active ON ai_providers(is_active);
+
+COMMIT;
+PRAGMA foreign_keys = ON;
+
+-- +goose Down
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+CREATE TABLE ai_providers_backup (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    api_key TEXT NOT NULL,
+    base_url TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK (provider IN ('openai', 'anthropic', 'google', 'openai_compatible'))
+);
+
+INSERT INTO ai_providers_backup (id, name, provider, model, api_key, base_url, is_active, created_at, updated_at)
+SELECT id, name, provider, model, api_key, base_url, is_active, created_at, updated_at
+FROM ai_providers
+WHERE provider != 'mock';
+
+DROP INDEX IF EXISTS idx_ai_providers_active;
+DROP TABLE ai_providers;
+ALTER TABLE ai_providers_backup RENAME TO ai_providers;
+CREATE INDEX idx_ai_providers_active ON ai_providers(is_active);
+
+COMMIT;
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- =========================================================================
+-- GENERATION PARAMS: sampling settings (temperature, top-p, max tokens, seed, stop)
+-- =========================================================================
+
+-- JSON encoded entities.GenerationParams, NULL keeps the provider defaults
+ALTER TABLE prompts ADD COLUMN generation_params TEXT;
+ALTER TABLE jobs ADD COLUMN generation_params TEXT;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN generation_params;
+ALTER TABLE prompts DROP COLUMN generation_params;
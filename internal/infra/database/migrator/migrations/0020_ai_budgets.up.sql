@@ -0,0 +1,29 @@
+-- +goose Up
+-- =========================================================================
+-- AI BUDGETS: daily / monthly spend limits, global or per site / provider
+-- =========================================================================
+
+CREATE TABLE IF NOT EXISTS ai_budgets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    scope TEXT NOT NULL CHECK (scope IN ('global', 'site', 'provider')),
+    site_id INTEGER REFERENCES sites(id) ON DELETE CASCADE,
+    provider_id INTEGER REFERENCES ai_providers(id) ON DELETE CASCADE,
+    period TEXT NOT NULL CHECK (period IN ('day', 'month')),
+    limit_usd REAL NOT NULL,
+    is_active INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_budgets_active ON ai_budgets(is_active);
+
+-- Provider spend is tracked by ID so renaming a provider keeps its budget history
+ALTER TABLE ai_usage_logs ADD COLUMN provider_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_ai_usage_provider_date ON ai_usage_logs(provider_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_ai_usage_provider_date;
+ALTER TABLE ai_usage_logs DROP COLUMN provider_id;
+DROP INDEX IF EXISTS idx_ai_budgets_active;
+DROP TABLE IF EXISTS ai_budgets;
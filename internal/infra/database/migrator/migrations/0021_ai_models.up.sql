@@ -0,0 +1,28 @@
+-- +goose Up
+-- =========================================================================
+-- AI MODELS: user-defined catalog entries layered over the built-in models
+-- =========================================================================
+
+-- A row with the provider and model ID of a built-in model overrides it,
+-- any other row adds a new model. Deleting a row restores the built-in entry.
+CREATE TABLE IF NOT EXISTS ai_models (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider TEXT NOT NULL,
+    model_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    context_window INTEGER NOT NULL DEFAULT 0,
+    max_output_tokens INTEGER NOT NULL DEFAULT 0,
+    input_cost REAL NOT NULL DEFAULT 0,
+    output_cost REAL NOT NULL DEFAULT 0,
+    rpm INTEGER NOT NULL DEFAULT 0,
+    tpm INTEGER NOT NULL DEFAULT 0,
+    uses_completion_tokens INTEGER NOT NULL DEFAULT 0,
+    is_reasoning_model INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (provider, model_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_models;
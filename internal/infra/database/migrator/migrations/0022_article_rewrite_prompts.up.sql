@@ -0,0 +1,105 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- =========================================================================
+-- ARTICLE REWRITE PROMPTS: article_rewrite category + builtin prompt
+-- =========================================================================
+
+-- SQLite can't alter CHECK constraints, so the table is recreated.
+-- Foreign keys are disabled while swapping tables, otherwise dropping
+-- prompts would fire ON DELETE actions in referencing tables.
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+CREATE TABLE prompts_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT 'post_gen'
+        CHECK (category IN ('post_gen', 'page_gen', 'link_suggest', 'link_apply', 'sitemap_gen', 'article_rewrite')),
+    is_builtin BOOLEAN NOT NULL DEFAULT 0,
+    system_prompt TEXT NOT NULL,
+    user_prompt TEXT NOT NULL,
+    placeholders TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    instructions TEXT NOT NULL DEFAULT '',
+    context_config TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    generation_params TEXT
+);
+
+INSERT INTO prompts_new (id, name, category, is_builtin, system_prompt, user_prompt, placeholders, created_at, updated_at, instructions, context_config, version, generation_params)
+SELECT id, name, category, is_builtin, system_prompt, user_prompt, placeholders, created_at, updated_at, instructions, context_config, version, generation_params
+FROM prompts;
+
+DROP INDEX IF EXISTS idx_prompts_category;
+DROP INDEX IF EXISTS idx_prompts_version;
+DROP TABLE prompts;
+ALTER TABLE prompts_new RENAME TO prompts;
+CREATE INDEX idx_prompts_category ON prompts(category);
+CREATE INDEX idx_prompts_version ON prompts(version);
+
+-- Builtin rewrite prompt, stored directly in the v2 format
+INSERT INTO prompts (name, category, is_builtin, system_prompt, user_prompt, placeholders, instructions, context_config, version) VALUES (
+    'Builtin: Article Rewrite',
+    'article_rewrite',
+    1,
+    '',
+    '',
+    '',
+    'You are an editor revising existing WordPress articles.
+
+TASK: Apply the requested changes to the article and return the full revised article.
+
+RULES:
+- Change only what the requested changes ask for, keep everything else as close to the original as possible
+- Keep the existing HTML structure, headings and links unless asked otherwise
+- When updating for a new year, refresh dates, prices and outdated statements, do not invent statistics or sources
+- When expanding a section, keep the new text consistent with the rest of the article
+
+HTML FORMAT:
+- Use only: <h2>, <h3>, <p>, <ul>, <li>, <ol>, <strong>, <em>, <a>
+- Do NOT include <h1> - title is added separately
+
+OUTPUT: Return the revised title, a brief excerpt and the complete revised HTML content.',
+    '{"articleTitle":{"enabled":true,"value":"true"},"rewriteInstructions":{"enabled":true,"value":"true"},"articleContent":{"enabled":true,"value":"true"},"language":{"enabled":true,"value":"English"},"rewriteTone":{"enabled":false}}',
+    2
+);
+
+COMMIT;
+PRAGMA foreign_keys = ON;
+
+-- +goose Down
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+CREATE TABLE prompts_backup (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT 'post_gen'
+        CHECK (category IN ('post_gen', 'page_gen', 'link_suggest', 'link_apply', 'sitemap_gen')),
+    is_builtin BOOLEAN NOT NULL DEFAULT 0,
+    system_prompt TEXT NOT NULL,
+    user_prompt TEXT NOT NULL,
+    placeholders TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    instructions TEXT NOT NULL DEFAULT '',
+    context_config TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    generation_params TEXT
+);
+
+INSERT INTO prompts_backup (id, name, category, is_builtin, system_prompt, user_prompt, placeholders, created_at, updated_at, instructions, context_config, version, generation_params)
+SELECT id, name, category, is_builtin, system_prompt, user_prompt, placeholders, created_at, updated_at, instructions, context_config, version, generation_params
+FROM prompts
+WHERE category != 'article_rewrite';
+
+DROP INDEX IF EXISTS idx_prompts_category;
+DROP INDEX IF EXISTS idx_prompts_version;
+DROP TABLE prompts;
+ALTER TABLE prompts_backup RENAME TO prompts;
+CREATE INDEX idx_prompts_category ON prompts(category);
+CREATE INDEX idx_prompts_version ON prompts(version);
+
+COMMIT;
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- =========================================================================
+-- ARTICLE TRANSLATIONS: content language + translation group
+-- =========================================================================
+
+-- Language code of the article content (e.g. 'en', 'fr'), empty when unknown
+ALTER TABLE articles ADD COLUMN language TEXT NOT NULL DEFAULT '';
+-- Articles sharing a group are translations of each other, NULL when untranslated
+ALTER TABLE articles ADD COLUMN translation_group TEXT;
+
+CREATE INDEX idx_articles_translation_group ON articles(translation_group);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_articles_translation_group;
+ALTER TABLE articles DROP COLUMN translation_group;
+ALTER TABLE articles DROP COLUMN language;
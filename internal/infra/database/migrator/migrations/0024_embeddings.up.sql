@@ -0,0 +1,54 @@
+-- +goose Up
+-- =========================================================================
+-- EMBEDDINGS: local vector index of topics, articles and sitemap nodes
+-- =========================================================================
+
+-- Empty keeps the provider's default embedding model
+ALTER TABLE ai_providers ADD COLUMN embedding_model TEXT NOT NULL DEFAULT '';
+
+-- Vectors are stored as little-endian float32 BLOBs. An entity has one row per
+-- embedding model, content_hash tells whether its text changed since indexing.
+CREATE TABLE IF NOT EXISTS embeddings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('topic', 'article', 'sitemap_node')),
+    entity_id INTEGER NOT NULL,
+    model TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    dimensions INTEGER NOT NULL,
+    vector BLOB NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_type, entity_id, model)
+);
+
+CREATE INDEX IF NOT EXISTS idx_embeddings_model ON embeddings(entity_type, model);
+
+-- Embedded entities live in different tables, so stale vectors are removed by triggers
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS trg_embeddings_topic_delete AFTER DELETE ON topics
+BEGIN
+    DELETE FROM embeddings WHERE entity_type = 'topic' AND entity_id = OLD.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS trg_embeddings_article_delete AFTER DELETE ON articles
+BEGIN
+    DELETE FROM embeddings WHERE entity_type = 'article' AND entity_id = OLD.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS trg_embeddings_sitemap_node_delete AFTER DELETE ON sitemap_nodes
+BEGIN
+    DELETE FROM embeddings WHERE entity_type = 'sitemap_node' AND entity_id = OLD.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_embeddings_sitemap_node_delete;
+DROP TRIGGER IF EXISTS trg_embeddings_article_delete;
+DROP TRIGGER IF EXISTS trg_embeddings_topic_delete;
+DROP INDEX IF EXISTS idx_embeddings_model;
+DROP TABLE IF EXISTS embeddings;
+ALTER TABLE ai_providers DROP COLUMN embedding_model;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- =========================================================================
+-- AI BATCHES: bulk generation through the providers' asynchronous batch APIs
+-- =========================================================================
+
+-- external_id is the provider's batch ID. settings holds the source-specific
+-- options needed to publish the results (JSON), source_id is the sitemap or job.
+CREATE TABLE IF NOT EXISTS ai_batches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    site_id INTEGER NOT NULL,
+    provider_id INTEGER NOT NULL,
+    external_id TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('sitemap', 'job')),
+    source_id INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'submitted' CHECK (status IN ('submitted', 'completed', 'failed', 'cancelled')),
+    settings TEXT NOT NULL DEFAULT '{}',
+    total_items INTEGER NOT NULL DEFAULT 0,
+    completed_items INTEGER NOT NULL DEFAULT 0,
+    failed_items INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME,
+    FOREIGN KEY (site_id) REFERENCES sites(id) ON DELETE CASCADE,
+    FOREIGN KEY (provider_id) REFERENCES ai_providers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_batches_site ON ai_batches(site_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_batches_status ON ai_batches(status);
+
+-- One row per request of a batch, entity_id is the sitemap node or topic it generates
+CREATE TABLE IF NOT EXISTS ai_batch_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    batch_id INTEGER NOT NULL,
+    custom_id TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    article_id INTEGER,
+    error TEXT,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (batch_id, custom_id),
+    FOREIGN KEY (batch_id) REFERENCES ai_batches(id) ON DELETE CASCADE,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_batch_items;
+DROP INDEX IF EXISTS idx_ai_batches_status;
+DROP INDEX IF EXISTS idx_ai_batches_site;
+DROP TABLE IF EXISTS ai_batches;
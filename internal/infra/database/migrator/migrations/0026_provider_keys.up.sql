@@ -0,0 +1,30 @@
+-- +goose Up
+-- =========================================================================
+-- PROVIDER KEYS: additional API keys rotated in on rate limit or quota errors
+-- =========================================================================
+
+-- The provider's own api_key stays its first key, these follow it in rotation
+CREATE TABLE IF NOT EXISTS ai_provider_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider_id INTEGER NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    api_key TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider_id, api_key),
+    FOREIGN KEY (provider_id) REFERENCES ai_providers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_provider_keys_provider ON ai_provider_keys(provider_id);
+
+-- Usage stays attributed to the provider, the fingerprint tells which of its keys was used
+ALTER TABLE ai_usage_logs ADD COLUMN key_fingerprint TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_key ON ai_usage_logs(key_fingerprint);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_ai_usage_key;
+ALTER TABLE ai_usage_logs DROP COLUMN key_fingerprint;
+DROP INDEX IF EXISTS idx_ai_provider_keys_provider;
+DROP TABLE IF EXISTS ai_provider_keys;
@@ -0,0 +1,62 @@
+-- +goose Up
+-- =========================================================================
+-- KNOWLEDGE BASE: local documents whose passages ground generated content
+-- =========================================================================
+
+-- A document without job_id applies to every job and sitemap of the site,
+-- one with a job_id only to that job
+CREATE TABLE IF NOT EXISTS knowledge_documents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    site_id INTEGER NOT NULL,
+    job_id INTEGER,
+    name TEXT NOT NULL,
+    file_type TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL DEFAULT 0,
+    chunk_count INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (site_id) REFERENCES sites(id) ON DELETE CASCADE,
+    FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_site ON knowledge_documents(site_id, job_id);
+
+CREATE TABLE IF NOT EXISTS knowledge_chunks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    document_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    FOREIGN KEY (document_id) REFERENCES knowledge_documents(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_chunks_document ON knowledge_chunks(document_id, position);
+
+-- Full-text index of the chunks, kept in sync by triggers
+CREATE VIRTUAL TABLE IF NOT EXISTS knowledge_chunks_fts USING fts5(
+    content,
+    content = 'knowledge_chunks',
+    content_rowid = 'id',
+    tokenize = 'porter unicode61'
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS trg_knowledge_chunks_insert AFTER INSERT ON knowledge_chunks
+BEGIN
+    INSERT INTO knowledge_chunks_fts(rowid, content) VALUES (NEW.id, NEW.content);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS trg_knowledge_chunks_delete AFTER DELETE ON knowledge_chunks
+BEGIN
+    INSERT INTO knowledge_chunks_fts(knowledge_chunks_fts, rowid, content) VALUES ('delete', OLD.id, OLD.content);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_knowledge_chunks_delete;
+DROP TRIGGER IF EXISTS trg_knowledge_chunks_insert;
+DROP TABLE IF EXISTS knowledge_chunks_fts;
+DROP INDEX IF EXISTS idx_knowledge_chunks_document;
+DROP TABLE IF EXISTS knowledge_chunks;
+DROP INDEX IF EXISTS idx_knowledge_documents_site;
+DROP TABLE IF EXISTS knowledge_documents;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- =========================================================================
+-- PROVIDER HEALTH: periodic checks of the API keys and models of AI providers
+-- =========================================================================
+
+-- One row per key checked, key_fingerprint is empty for keyless providers
+CREATE TABLE IF NOT EXISTS provider_health_checks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider_id INTEGER NOT NULL,
+    key_fingerprint TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL,
+    checked_at DATETIME NOT NULL,
+    status TEXT NOT NULL,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    FOREIGN KEY (provider_id) REFERENCES ai_providers(id) ON DELETE CASCADE,
+    CHECK (status IN ('healthy', 'rate_limited', 'unavailable', 'key_revoked', 'out_of_credit', 'model_retired', 'failing'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_health_provider_date ON provider_health_checks(provider_id, checked_at DESC);
+CREATE INDEX IF NOT EXISTS idx_provider_health_key ON provider_health_checks(provider_id, key_fingerprint, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_provider_health_key;
+DROP INDEX IF EXISTS idx_provider_health_provider_date;
+DROP TABLE IF EXISTS provider_health_checks;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- =========================================================================
+-- PIPELINE CHECKPOINTS: progress of running executions, kept to recover
+-- the executions interrupted when the app quits
+-- =========================================================================
+
+-- A row exists while the execution runs and is dropped once it ended, so the
+-- rows left at startup are the interrupted executions
+CREATE TABLE IF NOT EXISTS pipeline_checkpoints (
+    execution_id INTEGER PRIMARY KEY,
+    job_id INTEGER NOT NULL,
+    state TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (execution_id) REFERENCES job_executions(id) ON DELETE CASCADE,
+    FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS pipeline_checkpoints;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- =========================================================================
+-- JOB TIME ZONE: IANA zone the schedule of a job is evaluated in
+-- =========================================================================
+
+-- Empty evaluates the schedule in the local time of the machine
+ALTER TABLE jobs ADD COLUMN schedule_timezone TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN schedule_timezone;
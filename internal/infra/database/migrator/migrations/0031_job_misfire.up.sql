@@ -0,0 +1,35 @@
+-- +goose Up
+-- =========================================================================
+-- JOB MISFIRE POLICY: what happens to the runs a job missed while the app
+-- was closed or asleep, and the record of the missed slots
+-- =========================================================================
+
+-- skip: no run for the missed slots, the job goes on at its next slot
+-- run_once: a single run for all the missed slots
+-- run_all: a run for each missed slot, up to misfire_max_runs, spaced apart
+ALTER TABLE jobs ADD COLUMN misfire_policy TEXT NOT NULL DEFAULT 'run_once';
+ALTER TABLE jobs ADD COLUMN misfire_max_runs INTEGER NOT NULL DEFAULT 3;
+ALTER TABLE jobs ADD COLUMN misfire_spacing_minutes INTEGER NOT NULL DEFAULT 30;
+
+-- Catch-up runs still due for missed slots
+ALTER TABLE job_state ADD COLUMN catch_up_runs INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS job_missed_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    scheduled_at DATETIME NOT NULL,
+    policy TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    detected_at DATETIME NOT NULL,
+    FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_missed_runs_job ON job_missed_runs(job_id, scheduled_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_job_missed_runs_job;
+DROP TABLE IF EXISTS job_missed_runs;
+ALTER TABLE job_state DROP COLUMN catch_up_runs;
+ALTER TABLE jobs DROP COLUMN misfire_spacing_minutes;
+ALTER TABLE jobs DROP COLUMN misfire_max_runs;
+ALTER TABLE jobs DROP COLUMN misfire_policy;
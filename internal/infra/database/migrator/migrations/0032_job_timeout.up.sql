@@ -0,0 +1,9 @@
+-- +goose Up
+-- =========================================================================
+-- JOB TIMEOUT: how long a run of a job may take before it is cancelled
+-- =========================================================================
+
+ALTER TABLE jobs ADD COLUMN timeout_minutes INTEGER NOT NULL DEFAULT 10;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN timeout_minutes;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- =========================================================================
+-- BATCH KEY: the API key a batch was submitted with and publishing progress
+-- =========================================================================
+
+-- A batch can only be read with the key that submitted it. key_fingerprint is
+-- empty for keyless providers and for batches submitted before it was stored.
+ALTER TABLE ai_batches ADD COLUMN key_fingerprint TEXT NOT NULL DEFAULT '';
+
+-- Set before the results are handed to the publisher, a batch still submitted
+-- with it set was interrupted while publishing and is not published again.
+ALTER TABLE ai_batches ADD COLUMN publishing_at DATETIME;
+
+-- +goose Down
+ALTER TABLE ai_batches DROP COLUMN publishing_at;
+ALTER TABLE ai_batches DROP COLUMN key_fingerprint;
@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/davidmovas/postulator/internal/config"
+	"github.com/davidmovas/postulator/pkg/logger"
 )
 
 func SetupTestDB(t *testing.T) (*DB, func()) {
@@ -25,3 +28,52 @@ func SetupTestDB(t *testing.T) (*DB, func()) {
 
 	return db, cleanup
 }
+
+// SetupTestEnv returns a migrated test database and a logger that only reports
+// errors, both released when the test ends
+func SetupTestEnv(t *testing.T) (*DB, *logger.Logger) {
+	t.Helper()
+
+	db, cleanup := SetupTestDB(t)
+	t.Cleanup(cleanup)
+
+	log, err := logger.NewForTest(&config.Config{LogLevel: "error", LogDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	return db, log
+}
+
+// SeedTestSite stores an active site at url, without a WordPress password to
+// decrypt, and returns its ID
+func SeedTestSite(t *testing.T, db *DB, url string) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(
+		"INSERT INTO sites (name, url, wp_username, wp_password) VALUES (?, ?, 'user', '') RETURNING id",
+		"Test site "+url, url,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed site: %v", err)
+	}
+
+	return id
+}
+
+// SeedTestProvider stores an active mock provider with synthetic output and returns its ID
+func SeedTestProvider(t *testing.T, db *DB, name string) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(
+		"INSERT INTO ai_providers (name, provider, model, api_key, is_active) VALUES (?, 'mock', 'synthetic', '', 1) RETURNING id",
+		name,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed provider: %v", err)
+	}
+
+	return id
+}
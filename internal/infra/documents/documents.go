@@ -0,0 +1,55 @@
+package documents
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+// MaxFileSize is the largest file accepted for text extraction
+const MaxFileSize = 20 << 20
+
+var blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+
+// TextExtractor converts a document to plain text. Paragraphs are separated by
+// blank lines, so the text can be split on them.
+type TextExtractor interface {
+	Extract(filePath string) (string, error)
+}
+
+// SupportedTypes lists the file extensions text can be extracted from
+func SupportedTypes() []string {
+	return []string{".txt", ".md", ".markdown", ".pdf", ".docx", ".xlsx"}
+}
+
+func GetExtractor(filePath string) (TextExtractor, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+
+	switch ext {
+	case ".txt", ".md", ".markdown":
+		return NewPlainExtractor(), nil
+	case ".pdf":
+		return NewPdfExtractor(), nil
+	case ".docx":
+		return NewDocxExtractor(), nil
+	case ".xlsx":
+		return NewXlsxExtractor(), nil
+	default:
+		return nil, errors.Validation("unsupported file format: " + ext + ". Supported formats: " + strings.Join(SupportedTypes(), ", "))
+	}
+}
+
+// normalize unifies line endings and collapses runs of blank lines
+func normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
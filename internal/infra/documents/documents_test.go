@@ -0,0 +1,188 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// writePdf writes a one-page PDF whose content stream is Flate encoded. font is the
+// dictionary of the /F1 font, extra objects are numbered from 6 on.
+func writePdf(t *testing.T, content, font string, extra ...string) string {
+	t.Helper()
+
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	_, _ = w.Write([]byte(content))
+	_ = w.Close()
+
+	objects := append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()),
+		font,
+	}, extra...)
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	path := filepath.Join(t.TempDir(), "specs.pdf")
+	if err := os.WriteFile(path, pdf.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+const helvetica = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+
+// identityFont is a composite font addressing its glyphs by two-byte CIDs,
+// toUnicode is a reference to its ToUnicode CMap or empty
+func identityFont(toUnicode string) string {
+	font := "<< /Type /Font /Subtype /Type0 /BaseFont /Roboto /Encoding /Identity-H"
+	if toUnicode != "" {
+		font += " /ToUnicode " + toUnicode
+	}
+	return font + " >>"
+}
+
+// glyphCMap maps the CIDs 1 to 4 onto "Café" and the CID 5 onto a space
+const glyphCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CMapName /Glyphs def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0004> <00E9>
+<0005> <0020>
+endbfchar
+1 beginbfrange
+<0001> <0003> [<0043> <0061> <0066>]
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+func streamObject(data string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data)
+}
+
+func extract(t *testing.T, path string) string {
+	t.Helper()
+
+	extractor, err := GetExtractor(path)
+	if err != nil {
+		t.Fatalf("GetExtractor: %v", err)
+	}
+	text, err := extractor.Extract(path)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	return text
+}
+
+func TestPdfExtractor(t *testing.T) {
+	path := writePdf(t, `BT /F1 12 Tf 72 720 Td (Model X200 \(2024\)) Tj 0 -14 Td [(Max) -300 (load:) -300 (450 kg)] TJ ET
+BT /F1 12 Tf 72 680 Td (Serves Denver and Boulder) Tj ET`, helvetica)
+
+	text := extract(t, path)
+	for _, want := range []string{"Model X200 (2024)", "Max load: 450 kg", "Serves Denver and Boulder"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in %q", want, text)
+		}
+	}
+}
+
+func TestPdfExtractorDecodesToUnicodeCMap(t *testing.T) {
+	// "Café Café Café Café Café" drawn with glyph IDs, only the CMap knows the letters
+	glyphs := strings.Repeat("00010002000300040005", 5)
+	path := writePdf(t, "BT /F1 12 Tf 72 720 Td <"+glyphs+"> Tj ET", identityFont("6 0 R"), streamObject(glyphCMap))
+
+	if text := extract(t, path); text != strings.TrimSpace(strings.Repeat("Café ", 5)) {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestPdfExtractorRejectsUndecodableFonts(t *testing.T) {
+	glyphs := strings.Repeat("00010002000300040005", 5)
+	path := writePdf(t, "BT /F1 12 Tf 72 720 Td <"+glyphs+"> Tj ET", identityFont(""))
+
+	_, err := NewPdfExtractor().Extract(path)
+	if err == nil || !strings.Contains(err.Error(), "Unicode mapping") {
+		t.Fatalf("expected the undecodable font to be reported, got %v", err)
+	}
+}
+
+func TestPdfExtractorWithoutText(t *testing.T) {
+	path := writePdf(t, "0 0 m 100 100 l S", helvetica)
+
+	if _, err := NewPdfExtractor().Extract(path); err == nil {
+		t.Fatal("expected an error for a PDF without text")
+	}
+}
+
+func TestDocxExtractor(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, _ := archive.Create("word/document.xml")
+	_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Service areas</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">We serve </w:t></w:r><w:r><w:t>Austin.</w:t></w:r></w:p>
+</w:body></w:document>`))
+	_ = archive.Close()
+
+	path := filepath.Join(t.TempDir(), "areas.docx")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if text := extract(t, path); text != "Service areas\n\nWe serve Austin." {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestXlsxExtractor(t *testing.T) {
+	f := excelize.NewFile()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]string{"Product", "Weight", "Price"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]string{"X200", "12 kg", "$499"})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]string{"X300", "", "$799"})
+
+	path := filepath.Join(t.TempDir(), "products.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs: %v", err)
+	}
+
+	expected := "# Sheet1\n\nProduct: X200; Weight: 12 kg; Price: $499\n\nProduct: X300; Price: $799"
+	if text := extract(t, path); text != expected {
+		t.Errorf("expected %q, got %q", expected, text)
+	}
+}
+
+func TestGetExtractorUnsupported(t *testing.T) {
+	if _, err := GetExtractor("notes.odt"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,97 @@
+package documents
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+var _ TextExtractor = (*DocxExtractor)(nil)
+
+// DocxExtractor reads the body text of Word documents, one paragraph per block
+type DocxExtractor struct{}
+
+func NewDocxExtractor() *DocxExtractor {
+	return &DocxExtractor{}
+}
+
+func (e *DocxExtractor) Extract(filePath string) (string, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", errors.Import("docx", err)
+	}
+	defer func() {
+		_ = archive.Close()
+	}()
+
+	for _, file := range archive.File {
+		if file.Name != "word/document.xml" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return "", errors.Import("docx", err)
+		}
+		defer func() {
+			_ = rc.Close()
+		}()
+
+		text, err := docxText(rc)
+		if err != nil {
+			return "", errors.Import("docx", err)
+		}
+		return normalize(text), nil
+	}
+
+	return "", errors.Import("docx", fmt.Errorf("word/document.xml not found"))
+}
+
+// docxText walks the WordprocessingML body: runs of text inside paragraphs,
+// tabs and breaks within them
+func docxText(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var sb strings.Builder
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n\n")
+			case "tc":
+				sb.WriteString(" | ")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
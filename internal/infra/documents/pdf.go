@@ -0,0 +1,233 @@
+package documents
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// minPdfLetters is the least amount of letters a PDF must yield to count as text
+	minPdfLetters = 20
+	// tjSpacing is the TJ displacement, in thousandths of a text unit, read as a word gap
+	tjSpacing = -200
+	// maxPdfFormDepth bounds the nesting of form XObjects drawn by a page
+	maxPdfFormDepth = 8
+)
+
+var _ TextExtractor = (*PdfExtractor)(nil)
+
+// PdfExtractor reads the text drawn by the pages of a PDF, including the form XObjects
+// they place. Strings are decoded with the ToUnicode CMaps of the fonts or with their
+// simple encodings. Text of composite fonts without a Unicode mapping can't be recovered,
+// documents made of such text or of scanned pages have to be converted beforehand.
+type PdfExtractor struct{}
+
+func NewPdfExtractor() *PdfExtractor {
+	return &PdfExtractor{}
+}
+
+func (e *PdfExtractor) Extract(filePath string) (string, error) {
+	file, reader, err := pdf.Open(filePath)
+	if err != nil {
+		return "", errors.Import("pdf", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var (
+		sb          strings.Builder
+		undecodable int
+	)
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		text := &pdfText{}
+		if !text.page(page) {
+			continue
+		}
+		undecodable += text.undecodable
+
+		if content := text.String(); readable(content) {
+			sb.WriteString(content)
+			sb.WriteString("\n\n")
+		}
+	}
+
+	text := normalize(sb.String())
+	if countLetters(text) < minPdfLetters {
+		if undecodable > 0 {
+			return "", errors.Import("pdf", fmt.Errorf("the text uses fonts without a Unicode mapping and can't be decoded; convert the PDF to text first"))
+		}
+		return "", errors.Import("pdf", fmt.Errorf("no extractable text, the PDF may be scanned; convert it to text first"))
+	}
+
+	return text, nil
+}
+
+// pdfText interprets the text operators of content streams: the strings shown by Tj,
+// TJ, ' and " between BT and ET, with line moves turned into line breaks
+type pdfText struct {
+	strings.Builder
+	// undecodable counts the strings shown in fonts without a Unicode mapping
+	undecodable int
+}
+
+// page reads the text of a page, it reports false when the page is malformed
+func (t *pdfText) page(page pdf.Page) (ok bool) {
+	// The reader panics on malformed objects, such a page is skipped
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	t.content(page.V.Key("Contents"), page.Resources(), 0)
+	return true
+}
+
+func (t *pdfText) content(strm, resources pdf.Value, depth int) {
+	var (
+		enc    pdf.TextEncoding
+		inText bool
+	)
+
+	show := func(raw string) {
+		if !inText {
+			return
+		}
+		if enc == nil {
+			t.undecodable++
+			return
+		}
+		t.WriteString(pdfString(enc.Decode(raw)))
+	}
+
+	pdf.Interpret(strm, func(stk *pdf.Stack, op string) {
+		args := make([]pdf.Value, stk.Len())
+		for i := len(args) - 1; i >= 0; i-- {
+			args[i] = stk.Pop()
+		}
+
+		switch op {
+		case "BT":
+			inText = true
+		case "ET":
+			inText = false
+			t.WriteString("\n")
+		case "Tf":
+			if len(args) == 2 {
+				enc = fontEncoding(resources.Key("Font").Key(args[0].Name()))
+			}
+		case "Tj":
+			if len(args) == 1 {
+				show(args[0].RawString())
+			}
+		case "TJ":
+			if len(args) != 1 {
+				return
+			}
+			for i := 0; i < args[0].Len(); i++ {
+				item := args[0].Index(i)
+				switch item.Kind() {
+				case pdf.String:
+					show(item.RawString())
+				case pdf.Integer, pdf.Real:
+					if inText && item.Float64() < tjSpacing {
+						t.WriteString(" ")
+					}
+				}
+			}
+		case "'", "\"":
+			if inText && len(args) > 0 {
+				t.WriteString("\n")
+				show(args[len(args)-1].RawString())
+			}
+		case "T*", "Tm":
+			if inText {
+				t.WriteString("\n")
+			}
+		case "Td", "TD":
+			if inText {
+				if len(args) == 2 && args[1].Float64() != 0 {
+					t.WriteString("\n")
+				} else {
+					t.WriteString(" ")
+				}
+			}
+		case "Do":
+			if len(args) != 1 || depth >= maxPdfFormDepth {
+				return
+			}
+			xobject := resources.Key("XObject").Key(args[0].Name())
+			if xobject.Key("Subtype").Name() != "Form" {
+				return
+			}
+			formResources := xobject.Key("Resources")
+			if formResources.IsNull() {
+				formResources = resources
+			}
+			t.content(xobject, formResources, depth+1)
+		}
+	})
+}
+
+// fontEncoding returns the decoder of the strings shown in the font, nil when they
+// can't be decoded. Composite fonts address glyphs by CID, their text can only be
+// decoded through a ToUnicode CMap.
+func fontEncoding(font pdf.Value) pdf.TextEncoding {
+	if font.IsNull() {
+		return nil
+	}
+
+	if font.Key("Subtype").Name() == "Type0" &&
+		(font.Key("Encoding").Name() != "Identity-H" || font.Key("ToUnicode").Kind() != pdf.Stream) {
+		return nil
+	}
+
+	return pdf.Font{V: font}.Encoder()
+}
+
+// pdfString drops the control characters and unmapped glyphs of decoded text
+func pdfString(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if r == unicode.ReplacementChar {
+			continue
+		}
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// readable reports whether text looks like words rather than glyph IDs of a
+// font whose mapping could not be read
+func readable(text string) bool {
+	total := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			total++
+		}
+	}
+	return total > 0 && countLetters(text)*2 >= total
+}
+
+func countLetters(text string) int {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letters++
+		}
+	}
+	return letters
+}
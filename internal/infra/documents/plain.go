@@ -0,0 +1,31 @@
+package documents
+
+import (
+	"os"
+	"unicode/utf8"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+)
+
+var _ TextExtractor = (*PlainExtractor)(nil)
+
+// PlainExtractor reads text and Markdown files as they are, Markdown headings and
+// lists help the model as much as they help a reader
+type PlainExtractor struct{}
+
+func NewPlainExtractor() *PlainExtractor {
+	return &PlainExtractor{}
+}
+
+func (e *PlainExtractor) Extract(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", errors.Import("txt", err)
+	}
+
+	if !utf8.Valid(data) {
+		return "", errors.Validation("Text files must be UTF-8 encoded")
+	}
+
+	return normalize(string(data)), nil
+}
@@ -0,0 +1,77 @@
+package documents
+
+import (
+	"strings"
+
+	"github.com/davidmovas/postulator/pkg/errors"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var _ TextExtractor = (*XlsxExtractor)(nil)
+
+// XlsxExtractor turns spreadsheets such as product sheets into one block per row,
+// each cell labelled with its column header, so a passage is readable on its own
+type XlsxExtractor struct{}
+
+func NewXlsxExtractor() *XlsxExtractor {
+	return &XlsxExtractor{}
+}
+
+func (e *XlsxExtractor) Extract(filePath string) (string, error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return "", errors.Import("xlsx", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var sb strings.Builder
+	for _, sheet := range f.GetSheetList() {
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return "", errors.Import("xlsx", err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		sb.WriteString("# ")
+		sb.WriteString(sheet)
+		sb.WriteString("\n\n")
+
+		headers := rows[0]
+		for _, row := range rows[1:] {
+			if line := rowText(headers, row); line != "" {
+				sb.WriteString(line)
+				sb.WriteString("\n\n")
+			}
+		}
+
+		// A sheet of a single row has no headers to label
+		if len(rows) == 1 {
+			sb.WriteString(rowText(nil, headers))
+			sb.WriteString("\n\n")
+		}
+	}
+
+	return normalize(sb.String()), nil
+}
+
+func rowText(headers, row []string) string {
+	var cells []string
+	for i, cell := range row {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+
+		if i < len(headers) && strings.TrimSpace(headers[i]) != "" {
+			cells = append(cells, strings.TrimSpace(headers[i])+": "+cell)
+		} else {
+			cells = append(cells, cell)
+		}
+	}
+	return strings.Join(cells, "; ")
+}
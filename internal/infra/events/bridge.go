@@ -57,6 +57,7 @@ func (b *WailsBridge) SubscribeToPageGeneration() {
 		"pagegeneration.task.cancelled",
 		"pagegeneration.node.queued",
 		"pagegeneration.node.generating",
+		"pagegeneration.node.progress",
 		"pagegeneration.node.generated",
 		"pagegeneration.node.publishing",
 		"pagegeneration.node.completed",
@@ -91,3 +92,19 @@ func (b *WailsBridge) SubscribeToLinking() {
 		b.SubscribeAndForward(eventType)
 	}
 }
+
+// SubscribeToJobExecution sets up forwarding for job pipeline events
+func (b *WailsBridge) SubscribeToJobExecution() {
+	jobEvents := []EventType{
+		"pipeline.started",
+		"pipeline.completed",
+		"pipeline.failed",
+		"pipeline.paused",
+		"generation.progress",
+		"generation.completed",
+	}
+
+	for _, eventType := range jobEvents {
+		b.SubscribeAndForward(eventType)
+	}
+}
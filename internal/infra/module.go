@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/davidmovas/postulator/internal/config"
+	"github.com/davidmovas/postulator/internal/infra/ai"
 	"github.com/davidmovas/postulator/internal/infra/database"
 	"github.com/davidmovas/postulator/internal/infra/events"
 	"github.com/davidmovas/postulator/internal/infra/importer"
@@ -37,6 +38,11 @@ var Module = fx.Module("infra",
 		})
 	}),
 
+	// AI fixtures recording
+	fx.Invoke(func(cfg *config.Config) {
+		ai.SetRecordingDir(cfg.RecordFixturesDir)
+	}),
+
 	// Secret
 	fx.Provide(secret.NewManager),
 
@@ -54,6 +60,7 @@ var Module = fx.Module("infra",
 		bridge := events.NewWailsBridge(eventBus)
 		bridge.SubscribeToPageGeneration()
 		bridge.SubscribeToLinking()
+		bridge.SubscribeToJobExecution()
 		return bridge
 	}),
 )
@@ -9,6 +9,9 @@ import (
 
 type PostOptions struct {
 	Status string // "draft" or "publish"; default "publish" if empty
+	// Language and Translations are sent only when the site runs Polylang or WPML
+	Language     string         // Language code of the post, e.g. "fr"
+	Translations map[string]int // WP post IDs of the existing translations by language code
 }
 
 // MediaResult represents the result of uploading media to WordPress
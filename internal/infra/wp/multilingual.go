@@ -0,0 +1,80 @@
+package wp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/davidmovas/postulator/internal/domain/entities"
+	"github.com/go-resty/resty/v2"
+)
+
+// MultilingualPlugin is the translation plugin a site runs
+type MultilingualPlugin string
+
+const (
+	MultilingualNone     MultilingualPlugin = "none"
+	MultilingualPolylang MultilingualPlugin = "polylang"
+	MultilingualWPML     MultilingualPlugin = "wpml"
+)
+
+// multilingualPlugin detects the translation plugin from the REST namespaces of the site.
+// Detected plugins are cached, failed lookups are retried on the next call.
+func (c *restyClient) multilingualPlugin(ctx context.Context, s *entities.Site) MultilingualPlugin {
+	if plugin, ok := c.multilingual.Load(s.URL); ok {
+		return plugin.(MultilingualPlugin)
+	}
+
+	resp, err := c.resty.R().
+		SetContext(ctx).
+		SetBasicAuth(s.WPUsername, s.WPPassword).
+		Get(fmt.Sprintf("%s/wp-json", strings.TrimSuffix(s.URL, "/")))
+	if err != nil || resp.StatusCode() != http.StatusOK {
+		return MultilingualNone
+	}
+
+	var index struct {
+		Namespaces []string `json:"namespaces"`
+	}
+	if err = json.Unmarshal(resp.Body(), &index); err != nil {
+		return MultilingualNone
+	}
+
+	plugin := MultilingualNone
+	for _, ns := range index.Namespaces {
+		switch {
+		case strings.HasPrefix(ns, "pll/"):
+			plugin = MultilingualPolylang
+		case strings.HasPrefix(ns, "wpml/"):
+			plugin = MultilingualWPML
+		}
+	}
+
+	c.multilingual.Store(s.URL, plugin)
+	return plugin
+}
+
+// applyLanguage sets the post language and its translation links for the plugin of the site.
+// Polylang exposes writable lang and translations fields on posts. WPML assigns the language
+// from the lang query parameter and has no REST field to link translations, so for WPML sites
+// the relationship is only kept locally in the translation group.
+func (c *restyClient) applyLanguage(ctx context.Context, s *entities.Site, req *resty.Request, postData map[string]any, opts *PostOptions) {
+	switch c.multilingualPlugin(ctx, s) {
+	case MultilingualPolylang:
+		postData["lang"] = opts.Language
+		if len(opts.Translations) > 0 {
+			translations := make(map[string]int, len(opts.Translations))
+			for lang, id := range opts.Translations {
+				if lang != opts.Language && id > 0 {
+					translations[lang] = id
+				}
+			}
+			postData["translations"] = translations
+		}
+
+	case MultilingualWPML:
+		req.SetQueryParam("lang", opts.Language)
+	}
+}
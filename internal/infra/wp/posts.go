@@ -118,10 +118,14 @@ func (c *restyClient) CreatePost(ctx context.Context, s *entities.Site, article
 		postData["author"] = *article.Author
 	}
 
+	req := c.resty.R().SetContext(ctx)
+	if opts != nil && opts.Language != "" {
+		c.applyLanguage(ctx, s, req, postData, opts)
+	}
+
 	var createdPost wpPost
 
-	resp, err := c.resty.R().
-		SetContext(ctx).
+	resp, err := req.
 		SetBasicAuth(s.WPUsername, s.WPPassword).
 		SetBody(postData).
 		SetResult(&createdPost).
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -18,6 +19,8 @@ const (
 
 type restyClient struct {
 	resty *resty.Client
+	// multilingual caches the detected translation plugin by site URL
+	multilingual sync.Map
 }
 
 func NewRestyClient() Client {
@@ -0,0 +1,351 @@
+// Package cron parses cron expressions and computes their fire times.
+//
+// An expression has 5 fields (minute, hour, day of month, month, day of week) or 6
+// with a leading seconds field. Fields accept *, lists, ranges, steps and the names
+// of months and weekdays. The day of month accepts L for the last day of the month;
+// the day of week accepts FRI#2 for the second Friday of the month and FRIL for the
+// last one. As in standard cron, when both day fields are restricted a day matching
+// either of them fires. The macros @yearly, @monthly, @weekly, @daily and @hourly
+// stand for their usual expressions.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchYears bounds the search of the next fire time of expressions that rarely or
+// never fire, such as February 30th
+const searchYears = 8
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}
+	weekdayNames = map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}
+)
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondBounds  = bounds{min: 0, max: 59}
+	minuteBounds  = bounds{min: 0, max: 59}
+	hourBounds    = bounds{min: 0, max: 23}
+	domBounds     = bounds{min: 1, max: 31}
+	monthBounds   = bounds{min: 1, max: 12, names: monthNames}
+	weekdayBounds = bounds{min: 0, max: 7, names: weekdayNames}
+)
+
+// field is the set of values a field matches, one bit per value
+type field uint64
+
+func (f field) has(value int) bool {
+	return f&(1<<uint(value)) != 0
+}
+
+// nthWeekday is a weekday of the month by rank, 0 standing for the last one
+type nthWeekday struct {
+	weekday time.Weekday
+	n       int
+}
+
+// Expression is a parsed cron expression
+type Expression struct {
+	seconds field
+	minutes field
+	hours   field
+	dom     field
+	months  field
+	dow     field
+
+	lastDay     bool
+	nthWeekdays []nthWeekday
+
+	// domAny and dowAny are set for unrestricted day fields
+	domAny bool
+	dowAny bool
+}
+
+// Parse parses a 5 or 6 field cron expression or a macro
+func Parse(expr string) (*Expression, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty cron expression")
+	}
+
+	if strings.HasPrefix(expr, "@") {
+		macro, ok := macros[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron macro %q", expr)
+		}
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron expression must have 5 or 6 fields, got %d", len(fields))
+	}
+
+	e := &Expression{}
+	var err error
+
+	if e.seconds, err = parseField(fields[0], secondBounds); err != nil {
+		return nil, fmt.Errorf("seconds: %w", err)
+	}
+	if e.minutes, err = parseField(fields[1], minuteBounds); err != nil {
+		return nil, fmt.Errorf("minutes: %w", err)
+	}
+	if e.hours, err = parseField(fields[2], hourBounds); err != nil {
+		return nil, fmt.Errorf("hours: %w", err)
+	}
+	if err = e.parseDayOfMonth(fields[3]); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if e.months, err = parseField(fields[4], monthBounds); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if err = e.parseDayOfWeek(fields[5]); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+
+	return e, nil
+}
+
+// Next returns the first fire time strictly after the given time, in its location.
+// It returns the zero time when the expression never fires.
+func (e *Expression) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(searchYears, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !e.months.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !e.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !e.hours.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case !e.minutes.has(t.Minute()):
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		case !e.seconds.has(t.Second()):
+			t = t.Add(time.Second)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// NextN returns the next count fire times after the given time
+func (e *Expression) NextN(after time.Time, count int) []time.Time {
+	var times []time.Time
+	for len(times) < count {
+		after = e.Next(after)
+		if after.IsZero() {
+			break
+		}
+		times = append(times, after)
+	}
+	return times
+}
+
+func (e *Expression) matchesDay(t time.Time) bool {
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+
+	domMatch := e.dom.has(t.Day()) || (e.lastDay && t.Day() == daysInMonth)
+
+	dowMatch := e.dow.has(int(t.Weekday()))
+	for _, nth := range e.nthWeekdays {
+		if t.Weekday() != nth.weekday {
+			continue
+		}
+		if (nth.n == 0 && t.Day()+7 > daysInMonth) || (nth.n > 0 && (t.Day()-1)/7+1 == nth.n) {
+			dowMatch = true
+		}
+	}
+
+	if e.domAny || e.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func (e *Expression) parseDayOfMonth(value string) error {
+	if value == "*" || value == "?" {
+		e.domAny = true
+		e.dom = fullField(domBounds)
+		return nil
+	}
+
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		if strings.EqualFold(part, "L") {
+			e.lastDay = true
+			continue
+		}
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+
+	var err error
+	e.dom, err = parseField(strings.Join(parts, ","), domBounds)
+	return err
+}
+
+func (e *Expression) parseDayOfWeek(value string) error {
+	if value == "*" || value == "?" {
+		e.dowAny = true
+		e.dow = fullField(weekdayBounds)
+		return nil
+	}
+
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		upper := strings.ToUpper(part)
+
+		switch {
+		case strings.Contains(upper, "#"):
+			day, rank, _ := strings.Cut(upper, "#")
+			weekday, err := parseValue(day, weekdayBounds)
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(rank)
+			if err != nil || n < 1 || n > 5 {
+				return fmt.Errorf("weekday rank %q must be between 1 and 5", rank)
+			}
+			e.nthWeekdays = append(e.nthWeekdays, nthWeekday{weekday: time.Weekday(weekday % 7), n: n})
+
+		case len(upper) > 1 && strings.HasSuffix(upper, "L"):
+			weekday, err := parseValue(strings.TrimSuffix(upper, "L"), weekdayBounds)
+			if err != nil {
+				return err
+			}
+			e.nthWeekdays = append(e.nthWeekdays, nthWeekday{weekday: time.Weekday(weekday % 7)})
+
+		default:
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+
+	dow, err := parseField(strings.Join(parts, ","), weekdayBounds)
+	if err != nil {
+		return err
+	}
+
+	// 7 is Sunday as well
+	if dow.has(7) {
+		dow |= 1
+	}
+	e.dow = dow
+	return nil
+}
+
+func fullField(b bounds) field {
+	var f field
+	for v := b.min; v <= b.max; v++ {
+		f |= 1 << uint(v)
+	}
+	return f
+}
+
+// parseField parses a comma separated list of values, ranges and steps
+func parseField(value string, b bounds) (field, error) {
+	var f field
+
+	for _, part := range strings.Split(value, ",") {
+		if part == "" {
+			return 0, fmt.Errorf("empty value in %q", value)
+		}
+
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		var from, to int
+		switch {
+		case rangePart == "*":
+			from, to = b.min, b.max
+		case strings.Contains(rangePart, "-"):
+			start, end, _ := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = parseValue(start, b); err != nil {
+				return 0, err
+			}
+			if to, err = parseValue(end, b); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("range %q goes backwards", rangePart)
+			}
+		default:
+			var err error
+			if from, err = parseValue(rangePart, b); err != nil {
+				return 0, err
+			}
+			to = from
+			// A start value with a step runs to the end of the range
+			if hasStep {
+				to = b.max
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			f |= 1 << uint(v)
+		}
+	}
+
+	return f, nil
+}
+
+func parseValue(value string, b bounds) (int, error) {
+	if n, ok := b.names[strings.ToUpper(value)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if n < b.min || n > b.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, b.min, b.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,117 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func at(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	cases := []struct {
+		expr  string
+		after string
+		want  []string
+	}{
+		{
+			expr:  "15 9 * * MON-FRI",
+			after: "2026-10-16 10:00:00", // Friday
+			want:  []string{"2026-10-19 09:15:00", "2026-10-20 09:15:00"},
+		},
+		{
+			expr:  "15,40 9,16 * * 1-5",
+			after: "2026-10-16 09:20:00",
+			want:  []string{"2026-10-16 09:40:00", "2026-10-16 16:15:00", "2026-10-16 16:40:00"},
+		},
+		{
+			expr:  "0 8 * * MON#1",
+			after: "2026-10-16 00:00:00",
+			want:  []string{"2026-11-02 08:00:00", "2026-12-07 08:00:00"},
+		},
+		{
+			expr:  "0 18 * * FRIL",
+			after: "2026-10-16 00:00:00",
+			want:  []string{"2026-10-30 18:00:00", "2026-11-27 18:00:00"},
+		},
+		{
+			expr:  "0 0 L * *",
+			after: "2027-02-01 00:00:00",
+			want:  []string{"2027-02-28 00:00:00", "2027-03-31 00:00:00"},
+		},
+		{
+			expr:  "*/20 30 6 * * *",
+			after: "2026-10-16 06:30:10",
+			want:  []string{"2026-10-16 06:30:20", "2026-10-16 06:30:40", "2026-10-17 06:30:00"},
+		},
+		{
+			expr:  "@monthly",
+			after: "2026-10-16 12:00:00",
+			want:  []string{"2026-11-01 00:00:00"},
+		},
+		{
+			// Both day fields restricted: either one fires
+			expr:  "0 12 13 * FRI",
+			after: "2026-10-16 13:00:00",
+			want:  []string{"2026-10-23 12:00:00", "2026-10-30 12:00:00", "2026-11-06 12:00:00", "2026-11-13 12:00:00"},
+		},
+		{
+			expr:  "0 0 * * 7",
+			after: "2026-10-16 00:00:00",
+			want:  []string{"2026-10-18 00:00:00"},
+		},
+	}
+
+	for _, tc := range cases {
+		e, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.expr, err)
+		}
+
+		got := e.NextN(at(tc.after), len(tc.want))
+		if len(got) != len(tc.want) {
+			t.Fatalf("%q: expected %d fire times, got %v", tc.expr, len(tc.want), got)
+		}
+		for i, want := range tc.want {
+			if !got[i].Equal(at(want)) {
+				t.Errorf("%q: fire time %d is %s, want %s", tc.expr, i, got[i].Format(time.DateTime), want)
+			}
+		}
+	}
+}
+
+func TestNextNeverFires(t *testing.T) {
+	e, err := Parse("0 0 30 FEB *")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if next := e.Next(at("2026-10-16 00:00:00")); !next.IsZero() {
+		t.Errorf("expected no fire time, got %s", next)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * * MON#6",
+		"* * * * FOO",
+		"@sometimes",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 type ErrorCode string
@@ -21,8 +22,14 @@ const (
 
 	ErrCodeWordPress ErrorCode = "WORDPRESS"
 
-	ErrCodeAI          ErrorCode = "AI"
-	ErrCodeAIRateLimit ErrorCode = "AI_RATE_LIMIT"
+	ErrCodeAI              ErrorCode = "AI"
+	ErrCodeAIRateLimit     ErrorCode = "AI_RATE_LIMIT"
+	ErrCodeAIQuotaExceeded ErrorCode = "AI_QUOTA_EXCEEDED"
+	ErrCodeAIContextLength ErrorCode = "AI_CONTEXT_LENGTH"
+	ErrCodeAIContentFilter ErrorCode = "AI_CONTENT_FILTER"
+	ErrCodeAIAuth          ErrorCode = "AI_AUTH"
+	ErrCodeAIUnavailable   ErrorCode = "AI_UNAVAILABLE"
+	ErrCodeAIModelNotFound ErrorCode = "AI_MODEL_NOT_FOUND"
 
 	ErrCodeImport ErrorCode = "IMPORT"
 
@@ -30,6 +37,8 @@ const (
 	ErrCodeScheduler    ErrorCode = "SCHEDULER"
 
 	ErrCodeNoResources ErrorCode = "NO_RESOURCES"
+
+	ErrCodeBudgetExceeded ErrorCode = "BUDGET_EXCEEDED"
 )
 
 type AppError struct {
@@ -126,6 +135,46 @@ func AIRateLimit(provider string) *AppError {
 		WithContext("provider", provider)
 }
 
+// AIRateLimitAfter reports a 429 from the provider. retryAfter is the delay the
+// provider asked for, zero when it did not say.
+func AIRateLimitAfter(provider string, retryAfter time.Duration, err error) *AppError {
+	return Wrap(ErrCodeAIRateLimit, fmt.Sprintf("Rate limit exceeded for %s", provider), err).
+		WithContext("provider", provider).
+		WithContext("retry_after", retryAfter)
+}
+
+func AIQuotaExceeded(provider string, err error) *AppError {
+	return Wrap(ErrCodeAIQuotaExceeded, fmt.Sprintf("AI provider %s quota exhausted", provider), err).
+		WithContext("provider", provider)
+}
+
+func AIContextLength(provider string, err error) *AppError {
+	return Wrap(ErrCodeAIContextLength, fmt.Sprintf("Request exceeds the context window of %s", provider), err).
+		WithContext("provider", provider)
+}
+
+func AIContentFilter(provider string, err error) *AppError {
+	return Wrap(ErrCodeAIContentFilter, fmt.Sprintf("Content filtered by %s", provider), err).
+		WithContext("provider", provider)
+}
+
+func AIAuth(provider string, err error) *AppError {
+	return Wrap(ErrCodeAIAuth, fmt.Sprintf("AI provider %s rejected the credentials", provider), err).
+		WithContext("provider", provider)
+}
+
+func AIUnavailable(provider string, err error) *AppError {
+	return Wrap(ErrCodeAIUnavailable, fmt.Sprintf("AI provider %s is temporarily unavailable", provider), err).
+		WithContext("provider", provider)
+}
+
+// AIModelNotFound reports that the provider does not serve the requested model,
+// e.g. because it was retired
+func AIModelNotFound(provider string, err error) *AppError {
+	return Wrap(ErrCodeAIModelNotFound, fmt.Sprintf("AI provider %s does not serve the requested model", provider), err).
+		WithContext("provider", provider)
+}
+
 func Import(format string, err error) *AppError {
 	return Wrap(ErrCodeImport, fmt.Sprintf("Import error from format %s", format), err).
 		WithContext("format", format)
@@ -150,6 +199,13 @@ func NoResources(resource string) *AppError {
 		WithContext("resource", resource)
 }
 
+func BudgetExceeded(budget string, spentUSD, limitUSD float64) *AppError {
+	return New(ErrCodeBudgetExceeded, fmt.Sprintf("AI budget %q exceeded: $%.2f of $%.2f spent", budget, spentUSD, limitUSD)).
+		WithContext("budget", budget).
+		WithContext("spent_usd", spentUSD).
+		WithContext("limit_usd", limitUSD)
+}
+
 func Conflict(message string) *AppError {
 	return New(ErrCodeConflict, message)
 }
@@ -201,3 +257,87 @@ func IsNoResources(err error) bool {
 	}
 	return false
 }
+
+// IsAI reports whether err originates from an AI provider (including classified faults)
+func IsAI(err error) bool {
+	return findCode(err,
+		ErrCodeAI,
+		ErrCodeAIRateLimit,
+		ErrCodeAIQuotaExceeded,
+		ErrCodeAIContextLength,
+		ErrCodeAIContentFilter,
+		ErrCodeAIAuth,
+		ErrCodeAIUnavailable,
+		ErrCodeAIModelNotFound,
+	) != nil
+}
+
+// AICode returns the most specific AI error code in err's chain, or "" when err
+// does not originate from an AI provider
+func AICode(err error) ErrorCode {
+	ae := findCode(err,
+		ErrCodeAIRateLimit,
+		ErrCodeAIQuotaExceeded,
+		ErrCodeAIContextLength,
+		ErrCodeAIContentFilter,
+		ErrCodeAIAuth,
+		ErrCodeAIUnavailable,
+		ErrCodeAIModelNotFound,
+	)
+	if ae == nil && IsAI(err) {
+		return ErrCodeAI
+	}
+	if ae == nil {
+		return ""
+	}
+	return ae.Code
+}
+
+// IsAIRetryable reports whether the provider failure is transient: a rate limit or
+// a temporary outage. Quota, auth, content filter and context errors are not.
+func IsAIRetryable(err error) bool {
+	return findCode(err, ErrCodeAIRateLimit, ErrCodeAIUnavailable) != nil
+}
+
+// IsAIContextLength reports whether the request overflowed the model's context window
+func IsAIContextLength(err error) bool {
+	return findCode(err, ErrCodeAIContextLength) != nil
+}
+
+// RetryAfter returns the delay requested by a rate limited provider, zero if none
+func RetryAfter(err error) time.Duration {
+	ae := findCode(err, ErrCodeAIRateLimit)
+	if ae == nil {
+		return 0
+	}
+	retryAfter, _ := ae.Context["retry_after"].(time.Duration)
+	return retryAfter
+}
+
+// findCode returns the first AppError in err's chain carrying one of the codes
+func findCode(err error, codes ...ErrorCode) *AppError {
+	for err != nil {
+		var ae *AppError
+		if errors.As(err, &ae) {
+			for _, code := range codes {
+				if ae.Code == code {
+					return ae
+				}
+			}
+			err = ae.Unwrap()
+			continue
+		}
+		type unwrapper interface{ Unwrap() error }
+		if u, ok := err.(unwrapper); ok {
+			err = u.Unwrap()
+			continue
+		}
+		break
+	}
+	return nil
+}
+
+// IsBudgetExceeded reports whether err was caused by an exhausted AI spend budget
+func IsBudgetExceeded(err error) bool {
+	return findCode(err, ErrCodeBudgetExceeded) != nil
+}